@@ -104,6 +104,10 @@ func expandEnvVars(v *viper.Viper) {
 		"rate_limit.enabled",
 		"rate_limit.requests",
 		"rate_limit.window",
+		"geoip.database_path",
+		"short_code.strategy",
+		"short_code.salt",
+		"link_password.cookie_secret",
 	}
 
 	for _, key := range keys {
@@ -150,6 +154,82 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("rate_limit.enabled", true)
 	v.SetDefault("rate_limit.requests", 100)
 	v.SetDefault("rate_limit.window", "1m")
+
+	v.SetDefault("clicks.queue_size", 10000)
+	v.SetDefault("clicks.workers", 2)
+	v.SetDefault("clicks.batch_size", 200)
+	v.SetDefault("clicks.flush_interval", 2*time.Second)
+
+	v.SetDefault("geoip.database_path", "")
+	v.SetDefault("geoip.format", "auto")
+	v.SetDefault("geoip.cache_size", 10000)
+
+	v.SetDefault("rollups.enabled", true)
+	v.SetDefault("rollups.interval", time.Hour)
+	v.SetDefault("rollups.raw_retention", 7*24*time.Hour)
+	v.SetDefault("rollups.prune_raw", false)
+
+	v.SetDefault("bulk.sync_limit", 100)
+	v.SetDefault("bulk.max_rows", 10000)
+	v.SetDefault("bulk.background", true)
+	v.SetDefault("bulk.poll_interval", 2*time.Second)
+
+	v.SetDefault("short_code.strategy", "random")
+	v.SetDefault("short_code.length", 7)
+	v.SetDefault("short_code.min_length", 6)
+	v.SetDefault("short_code.words", 2)
+	v.SetDefault("short_code.digits", 2)
+	v.SetDefault("short_code.max_attempts", 5)
+	v.SetDefault("short_code.reserved", []string{"api", "uploads", "health"})
+
+	v.SetDefault("link_password.cookie_ttl", time.Hour)
+	v.SetDefault("link_password.max_attempts", 5)
+	v.SetDefault("link_password.attempt_window", 15*time.Minute)
+
+	v.SetDefault("link_schedule.enabled", true)
+	v.SetDefault("link_schedule.interval", time.Minute)
+
+	v.SetDefault("redirect.query_precedence", "link")
+	v.SetDefault("redirect.social_preview", true)
+
+	v.SetDefault("domains.verification_prefix", "_brevity")
+	v.SetDefault("domains.lookup_timeout", 5*time.Second)
+	v.SetDefault("domains.max_per_user", 10)
+
+	v.SetDefault("qr.default_size", 512)
+	v.SetDefault("qr.max_size", 2048)
+	v.SetDefault("qr.cache_size", 256)
+	v.SetDefault("qr.max_logo_bytes", 1<<20)
+
+	v.SetDefault("preview.enabled", true)
+	v.SetDefault("preview.interval", 10*time.Second)
+	v.SetDefault("preview.batch_size", 20)
+	v.SetDefault("preview.workers", 4)
+	v.SetDefault("preview.timeout", 5*time.Second)
+	v.SetDefault("preview.max_bytes", 512<<10)
+	v.SetDefault("preview.max_redirects", 5)
+	v.SetDefault("preview.user_agent", "BrevityBot/1.0 (+link preview)")
+	v.SetDefault("preview.allow_private_networks", false)
+
+	v.SetDefault("link_safety.threat_feed", "")
+	v.SetDefault("link_safety.scan_enabled", true)
+	v.SetDefault("link_safety.scan_interval", 5*time.Minute)
+	v.SetDefault("link_safety.scan_batch_size", 500)
+	v.SetDefault("link_safety.resolve_hosts", true)
+	v.SetDefault("link_safety.lookup_timeout", 2*time.Second)
+
+	v.SetDefault("health_checks.enabled", true)
+	v.SetDefault("health_checks.interval", 24*time.Hour)
+	v.SetDefault("health_checks.retry_interval", time.Hour)
+	v.SetDefault("health_checks.failure_threshold", 2)
+	v.SetDefault("health_checks.poll_interval", time.Minute)
+	v.SetDefault("health_checks.batch_size", 100)
+	v.SetDefault("health_checks.workers", 4)
+	v.SetDefault("health_checks.timeout", 10*time.Second)
+	v.SetDefault("health_checks.max_redirects", 5)
+	v.SetDefault("health_checks.user_agent", "BrevityBot/1.0 (+link health check)")
+	v.SetDefault("health_checks.digest_interval", 24*time.Hour)
+	v.SetDefault("health_checks.allow_private_networks", false)
 }
 
 func GetConfigPath() string {
@@ -3,15 +3,28 @@ package configs
 import "time"
 
 type Config struct {
-	App        AppConfig        `mapstructure:"app"`
-	Server     ServerConfig     `mapstructure:"server"`
-	Database   DatabaseConfig   `mapstructure:"database"`
-	JWT        JWTConfig        `mapstructure:"jwt"`
-	Email      EmailConfig      `mapstructure:"email"`
-	Cloudinary CloudinaryConfig `mapstructure:"cloudinary"`
-	Logger     LoggerConfig     `mapstructure:"logger"`
-	CORS       CORSConfig       `mapstructure:"cors"`
-	RateLimit  RateLimitConfig  `mapstructure:"rate_limit"`
+	App          AppConfig          `mapstructure:"app"`
+	Server       ServerConfig       `mapstructure:"server"`
+	Database     DatabaseConfig     `mapstructure:"database"`
+	JWT          JWTConfig          `mapstructure:"jwt"`
+	Email        EmailConfig        `mapstructure:"email"`
+	Cloudinary   CloudinaryConfig   `mapstructure:"cloudinary"`
+	Logger       LoggerConfig       `mapstructure:"logger"`
+	CORS         CORSConfig         `mapstructure:"cors"`
+	RateLimit    RateLimitConfig    `mapstructure:"rate_limit"`
+	Clicks       ClicksConfig       `mapstructure:"clicks"`
+	GeoIP        GeoIPConfig        `mapstructure:"geoip"`
+	Rollups      RollupsConfig      `mapstructure:"rollups"`
+	Bulk         BulkConfig         `mapstructure:"bulk"`
+	ShortCode    ShortCodeConfig    `mapstructure:"short_code"`
+	LinkPassword LinkPasswordConfig `mapstructure:"link_password"`
+	LinkSchedule LinkScheduleConfig `mapstructure:"link_schedule"`
+	Redirect     RedirectConfig     `mapstructure:"redirect"`
+	Domains      DomainsConfig      `mapstructure:"domains"`
+	QR           QRConfig           `mapstructure:"qr"`
+	Preview      PreviewConfig      `mapstructure:"preview"`
+	LinkSafety   LinkSafetyConfig   `mapstructure:"link_safety"`
+	HealthChecks HealthCheckConfig  `mapstructure:"health_checks"`
 }
 
 type AppConfig struct {
@@ -92,4 +105,142 @@ type RateLimitConfig struct {
 	Enabled  bool   `mapstructure:"enabled"`
 	Requests int    `mapstructure:"requests"`
 	Window   string `mapstructure:"window"`
-}
\ No newline at end of file
+}
+
+type ClicksConfig struct {
+	QueueSize     int           `mapstructure:"queue_size"`
+	Workers       int           `mapstructure:"workers"`
+	BatchSize     int           `mapstructure:"batch_size"`
+	FlushInterval time.Duration `mapstructure:"flush_interval"`
+}
+
+type GeoIPConfig struct {
+	DatabasePath string `mapstructure:"database_path"`
+	Format       string `mapstructure:"format"`
+	CacheSize    int    `mapstructure:"cache_size"`
+}
+
+type RollupsConfig struct {
+	Enabled      bool          `mapstructure:"enabled"`
+	Interval     time.Duration `mapstructure:"interval"`
+	RawRetention time.Duration `mapstructure:"raw_retention"`
+	PruneRaw     bool          `mapstructure:"prune_raw"`
+}
+
+type BulkConfig struct {
+	SyncLimit int `mapstructure:"sync_limit"`
+	MaxRows   int `mapstructure:"max_rows"`
+	// Background runs imports above SyncLimit as jobs picked up every
+	// PollInterval. When it is off such imports are rejected.
+	Background   bool          `mapstructure:"background"`
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+}
+
+type ShortCodeConfig struct {
+	Strategy    string   `mapstructure:"strategy"`
+	Alphabet    string   `mapstructure:"alphabet"`
+	Length      int      `mapstructure:"length"`
+	MinLength   int      `mapstructure:"min_length"`
+	Salt        string   `mapstructure:"salt"`
+	Words       int      `mapstructure:"words"`
+	Digits      int      `mapstructure:"digits"`
+	MaxAttempts int      `mapstructure:"max_attempts"`
+	Reserved    []string `mapstructure:"reserved"`
+}
+
+type LinkPasswordConfig struct {
+	CookieSecret  string        `mapstructure:"cookie_secret"`
+	CookieTTL     time.Duration `mapstructure:"cookie_ttl"`
+	MaxAttempts   int           `mapstructure:"max_attempts"`
+	AttemptWindow time.Duration `mapstructure:"attempt_window"`
+}
+
+type LinkScheduleConfig struct {
+	Enabled  bool          `mapstructure:"enabled"`
+	Interval time.Duration `mapstructure:"interval"`
+}
+
+type RedirectConfig struct {
+	// QueryPrecedence decides which value is kept when a visitor forwards a
+	// query parameter the link also sets: "link" or "request"
+	QueryPrecedence string `mapstructure:"query_precedence"`
+	// SocialPreview serves link preview crawlers a page with OpenGraph and
+	// Twitter card tags instead of the redirect
+	SocialPreview bool `mapstructure:"social_preview"`
+}
+
+type DomainsConfig struct {
+	// VerificationPrefix names the TXT record checked for a custom domain,
+	// e.g. _brevity.go.acme.com
+	VerificationPrefix string        `mapstructure:"verification_prefix"`
+	LookupTimeout      time.Duration `mapstructure:"lookup_timeout"`
+	MaxPerUser         int           `mapstructure:"max_per_user"`
+}
+
+type QRConfig struct {
+	DefaultSize int `mapstructure:"default_size"`
+	MaxSize     int `mapstructure:"max_size"`
+	// CacheSize is the number of rendered images kept in memory
+	CacheSize    int   `mapstructure:"cache_size"`
+	MaxLogoBytes int64 `mapstructure:"max_logo_bytes"`
+}
+
+type PreviewConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Interval is how often new links are picked up, BatchSize how many per
+	// run and Workers how many pages are fetched at once
+	Interval  time.Duration `mapstructure:"interval"`
+	BatchSize int           `mapstructure:"batch_size"`
+	Workers   int           `mapstructure:"workers"`
+	// Timeout bounds each fetch, redirects included, and MaxBytes how much
+	// of a page is read
+	Timeout      time.Duration `mapstructure:"timeout"`
+	MaxBytes     int64         `mapstructure:"max_bytes"`
+	MaxRedirects int           `mapstructure:"max_redirects"`
+	UserAgent    string        `mapstructure:"user_agent"`
+	// AllowPrivateNetworks lets the fetcher reach loopback and private
+	// addresses. Never enable it in production.
+	AllowPrivateNetworks bool `mapstructure:"allow_private_networks"`
+}
+
+type LinkSafetyConfig struct {
+	// ThreatFeed is a local hosts file or Safe Browsing style list of unsafe
+	// hosts and url prefixes. It is reloaded when it changes.
+	ThreatFeed string `mapstructure:"threat_feed"`
+	// ScanEnabled applies blocklist and feed changes to existing links every
+	// ScanInterval, ScanBatchSize links at a time. Without it changes to the
+	// feed file are not picked up either.
+	ScanEnabled   bool          `mapstructure:"scan_enabled"`
+	ScanInterval  time.Duration `mapstructure:"scan_interval"`
+	ScanBatchSize int           `mapstructure:"scan_batch_size"`
+	// ResolveHosts checks the addresses destination hosts resolve to against
+	// IP range entries when links are created or changed
+	ResolveHosts  bool          `mapstructure:"resolve_hosts"`
+	LookupTimeout time.Duration `mapstructure:"lookup_timeout"`
+}
+
+type HealthCheckConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Interval is how often each active link's destination is checked. New
+	// links get a random first check within it so checks are spread out.
+	Interval time.Duration `mapstructure:"interval"`
+	// RetryInterval is how soon a failing destination is checked again
+	// until FailureThreshold checks in a row failed and the link is broken
+	RetryInterval    time.Duration `mapstructure:"retry_interval"`
+	FailureThreshold int           `mapstructure:"failure_threshold"`
+	// PollInterval is how often due links are picked up, BatchSize how many
+	// per run and Workers how many are checked at once
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+	BatchSize    int           `mapstructure:"batch_size"`
+	Workers      int           `mapstructure:"workers"`
+	// Timeout bounds each check, redirects included
+	Timeout      time.Duration `mapstructure:"timeout"`
+	MaxRedirects int           `mapstructure:"max_redirects"`
+	UserAgent    string        `mapstructure:"user_agent"`
+	// DigestInterval is how often owners are emailed the links that broke
+	// since their last digest
+	DigestInterval time.Duration `mapstructure:"digest_interval"`
+	// AllowPrivateNetworks lets checks reach loopback and private
+	// addresses. Never enable it in production.
+	AllowPrivateNetworks bool `mapstructure:"allow_private_networks"`
+}
@@ -0,0 +1,94 @@
+package app
+
+import (
+	"fmt"
+	"net"
+	neturl "net/url"
+
+	"github.com/imraushankr/brevity/server/src/configs"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/auth"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/database"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/email"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/geoip"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/linkcheck"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/linksafety"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/shortcode"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/storage"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/useragent"
+	"github.com/imraushankr/brevity/server/src/internal/repository"
+	"github.com/imraushankr/brevity/server/src/internal/routes"
+	"github.com/imraushankr/brevity/server/src/internal/services"
+)
+
+// newDependencies builds every repository once and the services on top of
+// them
+func newDependencies(cfg *configs.Config, db *database.DB, log logger.Logger) (*routes.Dependencies, error) {
+	repos := repository.NewRepositories(db)
+
+	// Initialize GeoIP lookups
+	geoLocator, err := geoip.New(&cfg.GeoIP, log)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load geoip database: %w", err)
+	}
+
+	// Initialize storage service
+	storageService, err := storage.NewStorage(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize storage: %w", err)
+	}
+
+	authService := auth.NewAuth(&cfg.JWT)
+	emailService := email.NewEmailService(&cfg.Email, log)
+
+	// Link safety checks are shared by the API and background jobs
+	var selfHosts []string
+	if base, err := neturl.Parse(cfg.App.BaseURL); err == nil && base.Hostname() != "" {
+		selfHosts = append(selfHosts, base.Hostname())
+	}
+	linkSafety := services.NewLinkSafetyService(
+		repos.LinkSafety,
+		repos.Domains,
+		linksafety.New(selfHosts, net.DefaultResolver, cfg.LinkSafety.LookupTimeout),
+		&cfg.LinkSafety,
+	)
+
+	generator, err := shortcode.New(&cfg.ShortCode, shortcode.NamedSequence(repos.Sequences, "short_code"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid short code config: %w", err)
+	}
+	urlSvc := services.NewURLService(
+		repos.URLs,
+		repos.Users,
+		repos.Domains,
+		repos.Campaigns,
+		linkSafety,
+		generator,
+		emailService,
+		cfg,
+	)
+
+	return &routes.Dependencies{
+		Config:     cfg,
+		DB:         db,
+		Log:        log,
+		Auth:       authService,
+		Storage:    storageService,
+		Email:      emailService,
+		GeoLocator: geoLocator,
+		Repos:      repos,
+
+		ClickRecorder: services.NewClickRecorder(repos.Clicks, useragent.Default(), geoLocator, &cfg.Clicks),
+		Users:         services.NewUserService(repos.Users, authService, emailService, cfg, storageService),
+		URLs:          urlSvc,
+		Analytics:     services.NewAnalyticsService(repos.URLs, repos.Tags, repos.Campaigns, repos.Analytics),
+		QR:            services.NewQRService(repos.URLs, storageService, cfg),
+		Domains:       services.NewDomainService(repos.Domains, net.DefaultResolver, cfg),
+		Exports:       services.NewExportService(repos.URLs, repos.Clicks),
+		Tags:          services.NewTagService(repos.Tags),
+		Campaigns:     services.NewCampaignService(repos.Campaigns),
+		LinkSafety:    linkSafety,
+		LinkHealth:    services.NewLinkHealthService(repos.LinkHealth, linkcheck.New(&cfg.HealthChecks), emailService, cfg),
+		BulkImports:   services.NewBulkImportService(repos.BulkImports, urlSvc),
+	}, nil
+}
@@ -13,15 +13,22 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/imraushankr/brevity/server/src/configs"
 	"github.com/imraushankr/brevity/server/src/internal/pkg/database"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/geoip"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/jobs"
 	"github.com/imraushankr/brevity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/preview"
+	"github.com/imraushankr/brevity/server/src/internal/services"
 	"go.uber.org/zap"
 )
 
 type Server struct {
-	httpServer *http.Server
-	db         *database.DB
-	cfg        *configs.Config
-	router     *gin.Engine
+	httpServer    *http.Server
+	db            *database.DB
+	cfg           *configs.Config
+	router        *gin.Engine
+	clickRecorder services.ClickRecorder
+	geoLocator    *geoip.Locator
+	scheduler     *jobs.Scheduler
 }
 
 func NewServer(cfg *configs.Config) (*Server, error) {
@@ -41,8 +48,49 @@ func NewServer(cfg *configs.Config) (*Server, error) {
 	// Initialize logger
 	log := logger.Get()
 
+	// Initialize repositories and services
+	deps, err := newDependencies(cfg, db, log)
+	if err != nil {
+		return nil, err
+	}
+	repos := deps.Repos
+
+	// Initialize background jobs
+	scheduler := jobs.NewScheduler(log)
+	if cfg.Rollups.Enabled {
+		compactor := services.NewClickCompactor(repos.Rollups, &cfg.Rollups)
+		scheduler.Every("click-rollups", cfg.Rollups.Interval, compactor.Compact)
+	}
+	if cfg.LinkSchedule.Enabled {
+		linkScheduler := services.NewLinkScheduler(repos.LinkSchedules)
+		scheduler.Every("link-schedule", cfg.LinkSchedule.Interval, linkScheduler.Apply)
+	}
+	if cfg.Preview.Enabled {
+		previewer := services.NewLinkPreviewer(repos.LinkPreviews, preview.New(&cfg.Preview), &cfg.Preview)
+		scheduler.Every("link-previews", cfg.Preview.Interval, previewer.FetchPending)
+	}
+
+	if err := deps.LinkSafety.Reload(ctx); err != nil {
+		return nil, err
+	}
+	if cfg.LinkSafety.ScanEnabled {
+		scheduler.Every("link-safety", cfg.LinkSafety.ScanInterval, deps.LinkSafety.ScanLinks)
+	}
+
+	if cfg.HealthChecks.Enabled {
+		scheduler.Every("link-health", cfg.HealthChecks.PollInterval, deps.LinkHealth.CheckDue)
+		scheduler.Every("link-health-digest", cfg.HealthChecks.DigestInterval, deps.LinkHealth.SendDigests)
+	}
+
+	if err := deps.BulkImports.FailInterrupted(ctx); err != nil {
+		return nil, err
+	}
+	if cfg.Bulk.Background {
+		scheduler.Every("bulk-imports", cfg.Bulk.PollInterval, deps.BulkImports.ProcessPending)
+	}
+
 	// Initialize router
-	router, err := SetupRouter(cfg, db, log)
+	router, err := SetupRouter(deps)
 	if err != nil {
 		return nil, fmt.Errorf("failed to setup router: %w", err)
 	}
@@ -55,9 +103,12 @@ func NewServer(cfg *configs.Config) (*Server, error) {
 			WriteTimeout: cfg.Server.WriteTimeout,
 			IdleTimeout:  30 * time.Second,
 		},
-		db:     db,
-		cfg:    cfg,
-		router: router,
+		db:            db,
+		cfg:           cfg,
+		router:        router,
+		clickRecorder: deps.ClickRecorder,
+		geoLocator:    deps.GeoLocator,
+		scheduler:     scheduler,
 	}, nil
 }
 
@@ -101,6 +152,9 @@ func (s *Server) displayServerInfo() {
 
 func (s *Server) Start() error {
 	s.displayServerInfo()
+
+	s.clickRecorder.Start()
+	s.scheduler.Start()
 	
 	zap.L().Info("Starting server",
 		zap.String("app_name", s.cfg.App.Name),
@@ -145,6 +199,19 @@ func (s *Server) Stop(ctx context.Context) error {
 		return fmt.Errorf("server shutdown timed out: %w", ctx.Err())
 	}
 
+	if err := s.scheduler.Stop(ctx); err != nil {
+		zap.L().Error("Failed to stop background jobs", zap.Error(err))
+	}
+
+	// Flush queued clicks before the database goes away
+	if err := s.clickRecorder.Stop(ctx); err != nil {
+		zap.L().Error("Failed to flush click events", zap.Error(err))
+	}
+
+	if err := s.geoLocator.Close(); err != nil {
+		zap.L().Error("Failed to close geoip database", zap.Error(err))
+	}
+
 	if err := s.db.Close(); err != nil {
 		zap.L().Error("Failed to close database", zap.Error(err))
 		return fmt.Errorf("database shutdown failed: %w", err)
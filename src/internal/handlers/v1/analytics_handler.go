@@ -0,0 +1,226 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/imraushankr/brevity/server/src/internal/models"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/brevity/server/src/internal/services"
+	"github.com/imraushankr/brevity/server/src/internal/utils"
+)
+
+const (
+	defaultAnalyticsRange = 30 * 24 * time.Hour
+	defaultAnalyticsTop   = 10
+)
+
+type AnalyticsHandler struct {
+	analyticsService services.AnalyticsService
+	log              logger.Logger
+}
+
+func NewAnalyticsHandler(analyticsService services.AnalyticsService) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		analyticsService: analyticsService,
+		log:              logger.Get(),
+	}
+}
+
+// GetURLAnalytics godoc
+// @Summary Get url analytics
+// @Description Click totals, unique visitors, a time series and top breakdowns for a short url. Only the owner or an admin may call it. Bot clicks are excluded. Unique visitors are distinct IP addresses; where the range has been compacted into rollups they are summed per bucket and can overcount, and unique_visitors_approximate is set.
+// @Tags analytics
+// @Produce json
+// @Param id path string true "URL ID"
+// @Param from query string false "Range start, RFC3339 or YYYY-MM-DD (default 30 days before to)"
+// @Param to query string false "Range end, exclusive, RFC3339 or YYYY-MM-DD (default now)"
+// @Param interval query string false "Bucket size: hour, day or week (default day)"
+// @Param top query int false "Number of values per breakdown (default 10, max 50)"
+// @Security BearerAuth
+// @Success 200 {object} models.URLAnalytics
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /v1/urls/{id}/analytics [get]
+func (h *AnalyticsHandler) GetURLAnalytics(c *gin.Context) {
+	startTime := time.Now()
+	userID := c.GetString("user_id")
+	urlID := c.Param("id")
+	h.log.Info("Handling url analytics request",
+		logger.String("userID", userID),
+		logger.String("urlID", urlID))
+
+	query, err := parseAnalyticsQuery(c)
+	if err != nil {
+		h.log.Warn("Invalid analytics request",
+			logger.NamedError("error", err),
+			logger.String("urlID", urlID))
+		utils.APIError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	isAdmin := c.GetString("user_role") == string(models.RoleAdmin)
+	analytics, err := h.analyticsService.GetURLAnalytics(c.Request.Context(), userID, isAdmin, urlID, query)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrInvalidAnalyticsRange):
+			utils.APIError(c, http.StatusBadRequest, err.Error())
+		case errors.Is(err, models.ErrURLNotFound):
+			utils.APIError(c, http.StatusNotFound, "URL not found")
+		case errors.Is(err, models.ErrForbidden):
+			utils.APIError(c, http.StatusForbidden, "You do not have access to this url")
+		default:
+			h.log.Error("Failed to build url analytics",
+				logger.NamedError("error", err),
+				logger.String("urlID", urlID))
+			utils.APIError(c, http.StatusInternalServerError, "Failed to build url analytics")
+		}
+		return
+	}
+
+	h.log.Info("URL analytics built successfully",
+		logger.String("urlID", urlID),
+		logger.Duration("duration", time.Since(startTime)))
+
+	utils.APISuccess(c, http.StatusOK, analytics)
+}
+
+// GetTagAnalytics godoc
+// @Summary Get tag analytics
+// @Description Click totals, unique visitors, a time series, top breakdowns and the most clicked links across every link carrying a tag. Only the owner or an admin may call it. Bot clicks are excluded. Unique visitors are distinct IP addresses; where the range has been compacted into rollups they are summed per bucket and can overcount, and unique_visitors_approximate is set.
+// @Tags analytics
+// @Produce json
+// @Param id path string true "Tag ID"
+// @Param from query string false "Range start, RFC3339 or YYYY-MM-DD (default 30 days before to)"
+// @Param to query string false "Range end, exclusive, RFC3339 or YYYY-MM-DD (default now)"
+// @Param interval query string false "Bucket size: hour, day or week (default day)"
+// @Param top query int false "Number of values per breakdown and of top links (default 10, max 50)"
+// @Security BearerAuth
+// @Success 200 {object} models.GroupAnalytics
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /v1/tags/{id}/analytics [get]
+func (h *AnalyticsHandler) GetTagAnalytics(c *gin.Context) {
+	h.groupAnalytics(c, "tag", h.analyticsService.GetTagAnalytics)
+}
+
+// GetCampaignAnalytics godoc
+// @Summary Get campaign analytics
+// @Description Click totals, unique visitors, a time series, top breakdowns and the most clicked links across every link in a campaign. Only the owner or an admin may call it. Bot clicks are excluded. Unique visitors are distinct IP addresses; where the range has been compacted into rollups they are summed per bucket and can overcount, and unique_visitors_approximate is set.
+// @Tags analytics
+// @Produce json
+// @Param id path string true "Campaign ID"
+// @Param from query string false "Range start, RFC3339 or YYYY-MM-DD (default 30 days before to)"
+// @Param to query string false "Range end, exclusive, RFC3339 or YYYY-MM-DD (default now)"
+// @Param interval query string false "Bucket size: hour, day or week (default day)"
+// @Param top query int false "Number of values per breakdown and of top links (default 10, max 50)"
+// @Security BearerAuth
+// @Success 200 {object} models.GroupAnalytics
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /v1/campaigns/{id}/analytics [get]
+func (h *AnalyticsHandler) GetCampaignAnalytics(c *gin.Context) {
+	h.groupAnalytics(c, "campaign", h.analyticsService.GetCampaignAnalytics)
+}
+
+// groupAnalytics serves the analytics of the tag or campaign named by the id
+// path parameter through build
+func (h *AnalyticsHandler) groupAnalytics(
+	c *gin.Context,
+	kind string,
+	build func(ctx context.Context, userID string, isAdmin bool, id string, query *models.AnalyticsQuery) (*models.GroupAnalytics, error),
+) {
+	startTime := time.Now()
+	userID := c.GetString("user_id")
+	id := c.Param("id")
+	h.log.Info("Handling "+kind+" analytics request",
+		logger.String("userID", userID),
+		logger.String("id", id))
+
+	query, err := parseAnalyticsQuery(c)
+	if err != nil {
+		utils.APIError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	isAdmin := c.GetString("user_role") == string(models.RoleAdmin)
+	analytics, err := build(c.Request.Context(), userID, isAdmin, id, query)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrInvalidAnalyticsRange):
+			utils.APIError(c, http.StatusBadRequest, err.Error())
+		case errors.Is(err, models.ErrTagNotFound):
+			utils.APIError(c, http.StatusNotFound, "Tag not found")
+		case errors.Is(err, models.ErrCampaignNotFound):
+			utils.APIError(c, http.StatusNotFound, "Campaign not found")
+		case errors.Is(err, models.ErrForbidden):
+			utils.APIError(c, http.StatusForbidden, "You do not have access to this "+kind)
+		default:
+			h.log.Error("Failed to build "+kind+" analytics",
+				logger.NamedError("error", err),
+				logger.String("id", id))
+			utils.APIError(c, http.StatusInternalServerError, "Failed to build "+kind+" analytics")
+		}
+		return
+	}
+
+	h.log.Info("Group analytics built successfully",
+		logger.String(kind+"ID", id),
+		logger.Duration("duration", time.Since(startTime)))
+
+	utils.APISuccess(c, http.StatusOK, analytics)
+}
+
+// parseAnalyticsQuery reads the range, interval and top-N query parameters
+func parseAnalyticsQuery(c *gin.Context) (*models.AnalyticsQuery, error) {
+	query := &models.AnalyticsQuery{
+		To:       time.Now().UTC(),
+		Interval: models.AnalyticsInterval(c.DefaultQuery("interval", string(models.IntervalDay))),
+		Top:      defaultAnalyticsTop,
+	}
+
+	if raw := c.Query("to"); raw != "" {
+		to, err := parseAnalyticsTime(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid to: %w", err)
+		}
+		query.To = to
+	}
+
+	query.From = query.To.Add(-defaultAnalyticsRange)
+	if raw := c.Query("from"); raw != "" {
+		from, err := parseAnalyticsTime(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid from: %w", err)
+		}
+		query.From = from
+	}
+
+	if raw := c.Query("top"); raw != "" {
+		top, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid top: %w", err)
+		}
+		query.Top = top
+	}
+
+	return query, nil
+}
+
+func parseAnalyticsTime(raw string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t.UTC(), nil
+	}
+	return time.ParseInLocation(time.DateOnly, raw, time.UTC)
+}
@@ -0,0 +1,302 @@
+package v1
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/imraushankr/brevity/server/src/configs"
+	"github.com/imraushankr/brevity/server/src/internal/models"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/brevity/server/src/internal/services"
+	"github.com/imraushankr/brevity/server/src/internal/utils"
+)
+
+// bulkCSVColumns are the accepted CSV header names
+var bulkCSVColumns = map[string]bool{
+	"original_url":  true,
+	"custom_code":   true,
+	"domain":        true,
+	"title":         true,
+	"description":   true,
+	"activates_at":  true,
+	"expires_at":    true,
+	"fallback_url":  true,
+	"redirect_type": true,
+	"password":      true,
+	"max_clicks":    true,
+	"utm_source":    true,
+	"utm_medium":    true,
+	"utm_campaign":  true,
+	"utm_term":      true,
+	"utm_content":   true,
+	"forward_query": true,
+}
+
+type BulkHandler struct {
+	urlService        services.URLService
+	bulkImportService services.BulkImportService
+	cfg               *configs.Config
+	log               logger.Logger
+}
+
+func NewBulkHandler(urlService services.URLService, bulkImportService services.BulkImportService, cfg *configs.Config) *BulkHandler {
+	return &BulkHandler{
+		urlService:        urlService,
+		bulkImportService: bulkImportService,
+		cfg:               cfg,
+		log:               logger.Get(),
+	}
+}
+
+// BulkCreateURLs godoc
+// @Summary Bulk create short urls
+// @Description Create many short urls from a JSON array or a CSV upload (multipart field "file" or a text/csv body). CSV needs a header row with original_url and optionally custom_code, domain, title, description, activates_at, expires_at, fallback_url, redirect_type, password, max_clicks, the utm_source, utm_medium, utm_campaign, utm_term and utm_content tags and forward_query. By default nothing is created if any row is invalid; partial=true creates the valid rows. Imports above the sync limit are queued and return 202 with a job, or rejected with 413 when background imports are turned off.
+// @Tags urls
+// @Accept json
+// @Accept mpfd
+// @Produce json
+// @Param partial query bool false "Create valid rows even if others fail"
+// @Param request body []models.CreateURLRequest false "Rows to create"
+// @Param file formData file false "CSV file"
+// @Security BearerAuth
+// @Success 200 {object} models.BulkCreateResult
+// @Success 202 {object} models.BulkImportJob
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 413 {object} models.ErrorResponse
+// @Failure 422 {object} models.BulkCreateResult
+// @Failure 500 {object} models.ErrorResponse
+// @Router /v1/urls/bulk [post]
+func (h *BulkHandler) BulkCreateURLs(c *gin.Context) {
+	startTime := time.Now()
+	userID := c.GetString("user_id")
+	h.log.Info("Handling bulk create request", logger.String("userID", userID))
+
+	partial, err := strconv.ParseBool(c.DefaultQuery("partial", "false"))
+	if err != nil {
+		utils.APIError(c, http.StatusBadRequest, "Invalid partial flag")
+		return
+	}
+
+	reqs, err := h.readBulkRows(c)
+	if err != nil {
+		h.log.Warn("Invalid bulk create request",
+			logger.NamedError("error", err),
+			logger.String("userID", userID))
+		utils.APIError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(reqs) == 0 {
+		utils.APIError(c, http.StatusBadRequest, "No rows to import")
+		return
+	}
+	if len(reqs) > h.cfg.Bulk.MaxRows {
+		utils.APIError(c, http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("Too many rows, the limit is %d", h.cfg.Bulk.MaxRows))
+		return
+	}
+
+	if len(reqs) > h.cfg.Bulk.SyncLimit {
+		if !h.cfg.Bulk.Background {
+			utils.APIError(c, http.StatusRequestEntityTooLarge,
+				fmt.Sprintf("Too many rows, the limit is %d", h.cfg.Bulk.SyncLimit))
+			return
+		}
+		job, err := h.bulkImportService.Enqueue(c.Request.Context(), userID, reqs, partial)
+		if err != nil {
+			h.log.Error("Failed to queue bulk import",
+				logger.NamedError("error", err),
+				logger.String("userID", userID))
+			utils.APIError(c, http.StatusInternalServerError, "Failed to queue bulk import")
+			return
+		}
+		utils.APISuccess(c, http.StatusAccepted, job)
+		return
+	}
+
+	result, err := h.urlService.BulkCreateURLs(c.Request.Context(), userID, reqs, partial)
+	if err != nil {
+		h.log.Error("Failed to bulk create urls",
+			logger.NamedError("error", err),
+			logger.String("userID", userID))
+		utils.APIError(c, http.StatusInternalServerError, "Failed to bulk create urls")
+		return
+	}
+
+	h.log.Info("Bulk create finished",
+		logger.String("userID", userID),
+		logger.Int("created", result.Created),
+		logger.Int("failed", result.Failed),
+		logger.Duration("duration", time.Since(startTime)))
+
+	if result.Created == 0 {
+		utils.APIResponse(c, http.StatusUnprocessableEntity, false, result, "No urls were created")
+		return
+	}
+	utils.APISuccess(c, http.StatusOK, result)
+}
+
+// GetBulkImport godoc
+// @Summary Get a bulk import job
+// @Description Status and, once finished, per-row results of a queued bulk import
+// @Tags urls
+// @Produce json
+// @Param job_id path string true "Job ID"
+// @Security BearerAuth
+// @Success 200 {object} models.BulkImportJob
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /v1/urls/bulk/{job_id} [get]
+func (h *BulkHandler) GetBulkImport(c *gin.Context) {
+	userID := c.GetString("user_id")
+	jobID := c.Param("job_id")
+
+	job, err := h.bulkImportService.GetJob(c.Request.Context(), userID, jobID)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrBulkImportNotFound):
+			utils.APIError(c, http.StatusNotFound, "Bulk import not found")
+		case errors.Is(err, models.ErrForbidden):
+			utils.APIError(c, http.StatusForbidden, "You do not have access to this bulk import")
+		default:
+			h.log.Error("Failed to fetch bulk import",
+				logger.NamedError("error", err),
+				logger.String("jobID", jobID))
+			utils.APIError(c, http.StatusInternalServerError, "Failed to fetch bulk import")
+		}
+		return
+	}
+
+	utils.APISuccess(c, http.StatusOK, job)
+}
+
+// readBulkRows decodes the request body as a JSON array, a CSV body or an
+// uploaded CSV file
+func (h *BulkHandler) readBulkRows(c *gin.Context) ([]models.CreateURLRequest, error) {
+	switch c.ContentType() {
+	case "multipart/form-data":
+		header, err := c.FormFile("file")
+		if err != nil {
+			return nil, errors.New("missing CSV file")
+		}
+		file, err := header.Open()
+		if err != nil {
+			return nil, errors.New("failed to read CSV file")
+		}
+		defer file.Close()
+		return parseBulkCSV(file)
+	case "text/csv":
+		return parseBulkCSV(c.Request.Body)
+	default:
+		var reqs []models.CreateURLRequest
+		if err := json.NewDecoder(c.Request.Body).Decode(&reqs); err != nil {
+			return nil, errors.New("invalid request payload, expected a JSON array")
+		}
+		return reqs, nil
+	}
+}
+
+// parseBulkCSV reads create requests from CSV with a header row
+func parseBulkCSV(r io.Reader) ([]models.CreateURLRequest, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.New("CSV must start with a header row")
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if !bulkCSVColumns[name] {
+			return nil, fmt.Errorf("unknown CSV column %q", name)
+		}
+		columns[name] = i
+	}
+	if _, ok := columns["original_url"]; !ok {
+		return nil, errors.New("CSV header must include original_url")
+	}
+
+	var reqs []models.CreateURLRequest
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		req := models.CreateURLRequest{
+			OriginalURL: field("original_url"),
+			CustomCode:  field("custom_code"),
+			Domain:      field("domain"),
+			Title:       field("title"),
+			Description: field("description"),
+			FallbackURL: field("fallback_url"),
+			Password:    field("password"),
+		}
+		if v := field("activates_at"); v != "" {
+			activatesAt, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: activates_at must be RFC3339", line)
+			}
+			req.ActivatesAt = &activatesAt
+		}
+		if v := field("expires_at"); v != "" {
+			expiresAt, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: expires_at must be RFC3339", line)
+			}
+			req.ExpiresAt = &expiresAt
+		}
+		if v := field("redirect_type"); v != "" {
+			redirectType, err := strconv.Atoi(v)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: redirect_type must be a number", line)
+			}
+			req.RedirectType = redirectType
+		}
+		if v := field("max_clicks"); v != "" {
+			maxClicks, err := strconv.Atoi(v)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: max_clicks must be a number", line)
+			}
+			req.MaxClicks = &maxClicks
+		}
+		utm := models.UTMParams{
+			Source:   field("utm_source"),
+			Medium:   field("utm_medium"),
+			Campaign: field("utm_campaign"),
+			Term:     field("utm_term"),
+			Content:  field("utm_content"),
+		}
+		if !utm.IsZero() {
+			req.UTM = &utm
+		}
+		if v := field("forward_query"); v != "" {
+			forwardQuery, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: forward_query must be true or false", line)
+			}
+			req.ForwardQuery = forwardQuery
+		}
+		reqs = append(reqs, req)
+	}
+	return reqs, nil
+}
@@ -0,0 +1,193 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/imraushankr/brevity/server/src/internal/models"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/brevity/server/src/internal/services"
+	"github.com/imraushankr/brevity/server/src/internal/utils"
+)
+
+type CampaignHandler struct {
+	campaignService services.CampaignService
+	log             logger.Logger
+}
+
+func NewCampaignHandler(campaignService services.CampaignService) *CampaignHandler {
+	return &CampaignHandler{
+		campaignService: campaignService,
+		log:             logger.Get(),
+	}
+}
+
+// CreateCampaign godoc
+// @Summary Create a campaign
+// @Description Create a campaign to group links in. Names are unique per user.
+// @Tags campaigns
+// @Accept json
+// @Produce json
+// @Param request body models.CreateCampaignRequest true "Create campaign request"
+// @Security BearerAuth
+// @Success 201 {object} models.Campaign
+// @Failure 400 {object} models.ValidationErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /v1/campaigns [post]
+func (h *CampaignHandler) CreateCampaign(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	var req models.CreateCampaignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Warn("Invalid create campaign request",
+			logger.NamedError("error", err),
+			logger.String("userID", userID))
+		utils.APIError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	campaign, err := h.campaignService.CreateCampaign(c.Request.Context(), userID, &req)
+	if err != nil {
+		h.handleCampaignError(c, err, "Failed to create campaign")
+		return
+	}
+	utils.APISuccess(c, http.StatusCreated, campaign)
+}
+
+// ListCampaigns godoc
+// @Summary List my campaigns
+// @Description List the authenticated user's campaigns, newest first, with how many links each holds
+// @Tags campaigns
+// @Produce json
+// @Param page query int false "Page number"
+// @Param limit query int false "Page size"
+// @Security BearerAuth
+// @Success 200 {array} models.Campaign
+// @Failure 500 {object} models.ErrorResponse
+// @Router /v1/campaigns [get]
+func (h *CampaignHandler) ListCampaigns(c *gin.Context) {
+	userID := c.GetString("user_id")
+	page, limit := parsePagination(c)
+
+	campaigns, total, err := h.campaignService.ListCampaigns(c.Request.Context(), userID, page, limit)
+	if err != nil {
+		h.handleCampaignError(c, err, "Failed to list campaigns")
+		return
+	}
+
+	utils.PaginatedResponse(c, http.StatusOK, campaigns, gin.H{
+		"page":        page,
+		"limit":       limit,
+		"total":       total,
+		"total_pages": (total + int64(limit) - 1) / int64(limit),
+	})
+}
+
+// GetCampaign godoc
+// @Summary Get a campaign
+// @Description Get one of the authenticated user's campaigns. Its links are listed by GET /v1/urls?filter[campaign_id]=.
+// @Tags campaigns
+// @Produce json
+// @Param id path string true "Campaign ID"
+// @Security BearerAuth
+// @Success 200 {object} models.Campaign
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /v1/campaigns/{id} [get]
+func (h *CampaignHandler) GetCampaign(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	campaign, err := h.campaignService.GetCampaign(c.Request.Context(), userID, c.Param("id"))
+	if err != nil {
+		h.handleCampaignError(c, err, "Failed to get campaign")
+		return
+	}
+	utils.APISuccess(c, http.StatusOK, campaign)
+}
+
+// UpdateCampaign godoc
+// @Summary Update a campaign
+// @Description Rename a campaign or change its description
+// @Tags campaigns
+// @Accept json
+// @Produce json
+// @Param id path string true "Campaign ID"
+// @Param request body models.UpdateCampaignRequest true "Update campaign request"
+// @Security BearerAuth
+// @Success 200 {object} models.Campaign
+// @Failure 400 {object} models.ValidationErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /v1/campaigns/{id} [put]
+func (h *CampaignHandler) UpdateCampaign(c *gin.Context) {
+	userID := c.GetString("user_id")
+	campaignID := c.Param("id")
+
+	var req models.UpdateCampaignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Warn("Invalid update campaign request",
+			logger.NamedError("error", err),
+			logger.String("campaignID", campaignID))
+		utils.APIError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	campaign, err := h.campaignService.UpdateCampaign(c.Request.Context(), userID, campaignID, &req)
+	if err != nil {
+		h.handleCampaignError(c, err, "Failed to update campaign")
+		return
+	}
+	utils.APISuccess(c, http.StatusOK, campaign)
+}
+
+// DeleteCampaign godoc
+// @Summary Delete a campaign
+// @Description Delete a campaign. Its links are kept and no longer belong to a campaign.
+// @Tags campaigns
+// @Produce json
+// @Param id path string true "Campaign ID"
+// @Security BearerAuth
+// @Success 200 {object} models.MessageResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /v1/campaigns/{id} [delete]
+func (h *CampaignHandler) DeleteCampaign(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	if err := h.campaignService.DeleteCampaign(c.Request.Context(), userID, c.Param("id")); err != nil {
+		h.handleCampaignError(c, err, "Failed to delete campaign")
+		return
+	}
+
+	utils.APISuccess(c, http.StatusOK, models.MessageResponse{
+		Message: "Campaign deleted successfully",
+	})
+}
+
+// handleCampaignError maps campaign service errors to API responses
+func (h *CampaignHandler) handleCampaignError(c *gin.Context, err error, fallback string) {
+	if fields, ok := utils.ValidationErrors(err); ok {
+		utils.ValidationError(c, fields)
+		return
+	}
+
+	switch {
+	case errors.Is(err, models.ErrCampaignNotFound):
+		utils.APIError(c, http.StatusNotFound, "Campaign not found")
+	case errors.Is(err, models.ErrForbidden):
+		utils.APIError(c, http.StatusForbidden, "You do not have access to this campaign")
+	case errors.Is(err, models.ErrCampaignExists):
+		utils.APIError(c, http.StatusConflict, "Campaign already exists")
+	default:
+		h.log.Error(fallback,
+			logger.NamedError("error", err),
+			logger.String("path", c.Request.URL.Path))
+		utils.APIError(c, http.StatusInternalServerError, fallback)
+	}
+}
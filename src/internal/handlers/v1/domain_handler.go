@@ -0,0 +1,211 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/imraushankr/brevity/server/src/configs"
+	"github.com/imraushankr/brevity/server/src/internal/models"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/brevity/server/src/internal/services"
+	"github.com/imraushankr/brevity/server/src/internal/utils"
+)
+
+type DomainHandler struct {
+	domainService services.DomainService
+	cfg           *configs.Config
+	log           logger.Logger
+}
+
+func NewDomainHandler(domainService services.DomainService, cfg *configs.Config) *DomainHandler {
+	return &DomainHandler{
+		domainService: domainService,
+		cfg:           cfg,
+		log:           logger.Get(),
+	}
+}
+
+// AddDomain godoc
+// @Summary Add a custom domain
+// @Description Register a hostname to serve short links from. The response holds the DNS TXT record to publish before calling verify.
+// @Tags domains
+// @Accept json
+// @Produce json
+// @Param request body models.CreateDomainRequest true "Create domain request"
+// @Security BearerAuth
+// @Success 201 {object} models.DomainResponse
+// @Failure 400 {object} models.ValidationErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /v1/domains [post]
+func (h *DomainHandler) AddDomain(c *gin.Context) {
+	startTime := time.Now()
+	userID := c.GetString("user_id")
+	h.log.Info("Handling add domain request", logger.String("userID", userID))
+
+	var req models.CreateDomainRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Warn("Invalid add domain request",
+			logger.NamedError("error", err),
+			logger.String("userID", userID))
+		utils.APIError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	domain, err := h.domainService.AddDomain(c.Request.Context(), userID, &req)
+	if err != nil {
+		h.handleDomainError(c, err, "Failed to add domain")
+		return
+	}
+
+	h.log.Info("Domain added successfully",
+		logger.String("domainID", domain.ID),
+		logger.Duration("duration", time.Since(startTime)))
+
+	utils.APISuccess(c, http.StatusCreated, domain.ToResponse(h.cfg.Domains.VerificationPrefix))
+}
+
+// ListDomains godoc
+// @Summary List custom domains
+// @Description List the authenticated user's custom domains
+// @Tags domains
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.DomainResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /v1/domains [get]
+func (h *DomainHandler) ListDomains(c *gin.Context) {
+	userID := c.GetString("user_id")
+	h.log.Info("Listing domains", logger.String("userID", userID))
+
+	domains, err := h.domainService.ListDomains(c.Request.Context(), userID)
+	if err != nil {
+		h.handleDomainError(c, err, "Failed to list domains")
+		return
+	}
+
+	resp := make([]*models.DomainResponse, len(domains))
+	for i := range domains {
+		resp[i] = domains[i].ToResponse(h.cfg.Domains.VerificationPrefix)
+	}
+	utils.APISuccess(c, http.StatusOK, resp)
+}
+
+// GetDomain godoc
+// @Summary Get a custom domain
+// @Description Get one of the authenticated user's custom domains
+// @Tags domains
+// @Produce json
+// @Param id path string true "Domain ID"
+// @Security BearerAuth
+// @Success 200 {object} models.DomainResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /v1/domains/{id} [get]
+func (h *DomainHandler) GetDomain(c *gin.Context) {
+	userID := c.GetString("user_id")
+	domainID := c.Param("id")
+
+	domain, err := h.domainService.GetDomain(c.Request.Context(), userID, domainID)
+	if err != nil {
+		h.handleDomainError(c, err, "Failed to get domain")
+		return
+	}
+
+	utils.APISuccess(c, http.StatusOK, domain.ToResponse(h.cfg.Domains.VerificationPrefix))
+}
+
+// VerifyDomain godoc
+// @Summary Verify a custom domain
+// @Description Look up the domain's DNS TXT record and mark the domain verified when it matches. Links can only be created on verified domains.
+// @Tags domains
+// @Produce json
+// @Param id path string true "Domain ID"
+// @Security BearerAuth
+// @Success 200 {object} models.DomainResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 422 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /v1/domains/{id}/verify [post]
+func (h *DomainHandler) VerifyDomain(c *gin.Context) {
+	userID := c.GetString("user_id")
+	domainID := c.Param("id")
+	h.log.Info("Verifying domain",
+		logger.String("userID", userID),
+		logger.String("domainID", domainID))
+
+	domain, err := h.domainService.VerifyDomain(c.Request.Context(), userID, domainID)
+	if err != nil {
+		h.handleDomainError(c, err, "Failed to verify domain")
+		return
+	}
+
+	utils.APISuccess(c, http.StatusOK, domain.ToResponse(h.cfg.Domains.VerificationPrefix))
+}
+
+// DeleteDomain godoc
+// @Summary Delete a custom domain
+// @Description Remove a custom domain. Domains with short urls still on them cannot be deleted.
+// @Tags domains
+// @Produce json
+// @Param id path string true "Domain ID"
+// @Security BearerAuth
+// @Success 200 {object} models.MessageResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /v1/domains/{id} [delete]
+func (h *DomainHandler) DeleteDomain(c *gin.Context) {
+	userID := c.GetString("user_id")
+	domainID := c.Param("id")
+	h.log.Info("Deleting domain",
+		logger.String("userID", userID),
+		logger.String("domainID", domainID))
+
+	if err := h.domainService.DeleteDomain(c.Request.Context(), userID, domainID); err != nil {
+		h.handleDomainError(c, err, "Failed to delete domain")
+		return
+	}
+
+	utils.APISuccess(c, http.StatusOK, models.MessageResponse{
+		Message: "Domain deleted successfully",
+	})
+}
+
+// handleDomainError maps domain service errors to API responses
+func (h *DomainHandler) handleDomainError(c *gin.Context, err error, fallback string) {
+	if fields, ok := utils.ValidationErrors(err); ok {
+		utils.ValidationError(c, fields)
+		return
+	}
+
+	switch {
+	case errors.Is(err, models.ErrDomainNotFound):
+		utils.APIError(c, http.StatusNotFound, "Domain not found")
+	case errors.Is(err, models.ErrForbidden):
+		utils.APIError(c, http.StatusForbidden, "You do not have access to this domain")
+	case errors.Is(err, models.ErrDomainExists):
+		utils.APIError(c, http.StatusConflict, "Domain already added")
+	case errors.Is(err, models.ErrDomainReserved):
+		utils.APIError(c, http.StatusBadRequest, "Domain is reserved")
+	case errors.Is(err, models.ErrDomainLimit):
+		utils.APIError(c, http.StatusConflict, "Domain limit reached")
+	case errors.Is(err, models.ErrDomainTaken):
+		utils.APIError(c, http.StatusConflict, "Domain is already verified by another account")
+	case errors.Is(err, models.ErrDomainVerification):
+		utils.APIError(c, http.StatusUnprocessableEntity, "Verification record not found")
+	case errors.Is(err, models.ErrDomainInUse):
+		utils.APIError(c, http.StatusConflict, "Domain still has short urls")
+	default:
+		h.log.Error(fallback,
+			logger.NamedError("error", err),
+			logger.String("path", c.Request.URL.Path))
+		utils.APIError(c, http.StatusInternalServerError, fallback)
+	}
+}
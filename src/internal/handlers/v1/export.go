@@ -0,0 +1,103 @@
+package v1
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+
+	"github.com/imraushankr/brevity/server/src/internal/models"
+)
+
+// exportEncoder writes records in one export format. Record takes both the
+// value for JSON formats and the flattened row for CSV.
+type exportEncoder interface {
+	Header(columns []string) error
+	Record(v interface{}, row []string) error
+	Flush() error
+	Close() error
+}
+
+var exportContentTypes = map[models.ExportFormat]string{
+	models.ExportCSV:    "text/csv; charset=utf-8",
+	models.ExportJSON:   "application/json; charset=utf-8",
+	models.ExportNDJSON: "application/x-ndjson; charset=utf-8",
+}
+
+func newExportEncoder(format models.ExportFormat, w io.Writer) exportEncoder {
+	switch format {
+	case models.ExportCSV:
+		return &csvEncoder{w: csv.NewWriter(w)}
+	case models.ExportNDJSON:
+		return &ndjsonEncoder{enc: json.NewEncoder(w)}
+	default:
+		return &jsonArrayEncoder{w: w}
+	}
+}
+
+type csvEncoder struct {
+	w *csv.Writer
+}
+
+func (e *csvEncoder) Header(columns []string) error {
+	return e.w.Write(columns)
+}
+
+func (e *csvEncoder) Record(_ interface{}, row []string) error {
+	return e.w.Write(row)
+}
+
+func (e *csvEncoder) Flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvEncoder) Close() error {
+	return e.Flush()
+}
+
+type ndjsonEncoder struct {
+	enc *json.Encoder
+}
+
+func (e *ndjsonEncoder) Header([]string) error { return nil }
+
+func (e *ndjsonEncoder) Record(v interface{}, _ []string) error {
+	return e.enc.Encode(v)
+}
+
+func (e *ndjsonEncoder) Flush() error { return nil }
+
+func (e *ndjsonEncoder) Close() error { return nil }
+
+// jsonArrayEncoder writes records as one JSON array without buffering them
+type jsonArrayEncoder struct {
+	w       io.Writer
+	started bool
+}
+
+func (e *jsonArrayEncoder) Header([]string) error {
+	_, err := io.WriteString(e.w, "[")
+	return err
+}
+
+func (e *jsonArrayEncoder) Record(v interface{}, _ []string) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if e.started {
+		if _, err := io.WriteString(e.w, ","); err != nil {
+			return err
+		}
+	}
+	e.started = true
+	_, err = e.w.Write(data)
+	return err
+}
+
+func (e *jsonArrayEncoder) Flush() error { return nil }
+
+func (e *jsonArrayEncoder) Close() error {
+	_, err := io.WriteString(e.w, "]\n")
+	return err
+}
@@ -0,0 +1,321 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/imraushankr/brevity/server/src/configs"
+	"github.com/imraushankr/brevity/server/src/internal/models"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/brevity/server/src/internal/services"
+	"github.com/imraushankr/brevity/server/src/internal/utils"
+)
+
+var (
+	urlExportColumns = []string{
+		"id", "short_code", "short_url", "original_url", "title", "description",
+		"clicks", "is_active", "redirect_type", "expires_at", "created_at",
+	}
+	clickExportColumns = []string{
+		"id", "created_at", "ip_address", "referrer", "user_agent", "country",
+		"city", "device", "os", "browser", "is_bot", "variant_id",
+	}
+)
+
+type ExportHandler struct {
+	exportService services.ExportService
+	cfg           *configs.Config
+	log           logger.Logger
+}
+
+func NewExportHandler(exportService services.ExportService, cfg *configs.Config) *ExportHandler {
+	return &ExportHandler{
+		exportService: exportService,
+		cfg:           cfg,
+		log:           logger.Get(),
+	}
+}
+
+// exportStream writes the response headers on the first batch, so errors
+// that happen before any data can still become a JSON error response
+type exportStream struct {
+	c        *gin.Context
+	format   models.ExportFormat
+	filename string
+	columns  []string
+	enc      exportEncoder
+}
+
+func (s *exportStream) begin() error {
+	if s.enc != nil {
+		return nil
+	}
+	s.c.Header("Content-Type", exportContentTypes[s.format])
+	s.c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, s.filename))
+	s.c.Header("Cache-Control", "no-store")
+	s.c.Status(http.StatusOK)
+	s.enc = newExportEncoder(s.format, s.c.Writer)
+	return s.enc.Header(s.columns)
+}
+
+func (s *exportStream) flush() error {
+	if err := s.enc.Flush(); err != nil {
+		return err
+	}
+	s.c.Writer.Flush()
+	return nil
+}
+
+func (s *exportStream) close() error {
+	if err := s.begin(); err != nil {
+		return err
+	}
+	return s.enc.Close()
+}
+
+// ExportURLs godoc
+// @Summary Export my urls
+// @Description Stream the authenticated user's short urls as CSV, a JSON array or NDJSON
+// @Tags urls
+// @Produce text/csv
+// @Produce json
+// @Produce application/x-ndjson
+// @Param format query string false "csv, json or ndjson (default csv)"
+// @Param created_from query string false "Created at or after, RFC3339 or YYYY-MM-DD"
+// @Param created_to query string false "Created before, RFC3339 or YYYY-MM-DD"
+// @Param active query bool false "Only active or only inactive urls"
+// @Param tag query []string false "Only urls with every one of these tags" collectionFormat(multi)
+// @Security BearerAuth
+// @Success 200 {file} file
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /v1/urls/export [get]
+func (h *ExportHandler) ExportURLs(c *gin.Context) {
+	startTime := time.Now()
+	userID := c.GetString("user_id")
+	h.log.Info("Handling url export request", logger.String("userID", userID))
+
+	format := models.ExportFormat(c.DefaultQuery("format", string(models.ExportCSV)))
+	if !format.IsValid() {
+		utils.APIError(c, http.StatusBadRequest, "Format must be csv, json or ndjson")
+		return
+	}
+
+	filter, err := parseURLExportFilter(c)
+	if err != nil {
+		utils.APIError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	stream := &exportStream{
+		c:        c,
+		format:   format,
+		filename: fmt.Sprintf("links-%s.%s", time.Now().UTC().Format("20060102"), format),
+		columns:  urlExportColumns,
+	}
+
+	rows := 0
+	err = h.exportService.ExportURLs(c.Request.Context(), userID, filter, func(urls []models.URL) error {
+		if err := stream.begin(); err != nil {
+			return err
+		}
+		for i := range urls {
+			resp := urls[i].ToResponse(h.cfg.App.BaseURL)
+			if err := stream.enc.Record(resp, urlExportRow(resp)); err != nil {
+				return err
+			}
+		}
+		rows += len(urls)
+		return stream.flush()
+	})
+	if err == nil {
+		err = stream.close()
+	}
+	if err != nil {
+		h.failExport(c, stream, err, "Failed to export urls")
+		return
+	}
+
+	h.log.Info("URL export finished",
+		logger.String("userID", userID),
+		logger.Int("rows", rows),
+		logger.Duration("duration", time.Since(startTime)))
+}
+
+// ExportClicks godoc
+// @Summary Export url clicks
+// @Description Stream the click history of one of the authenticated user's short urls as CSV, a JSON array or NDJSON
+// @Tags urls
+// @Produce text/csv
+// @Produce json
+// @Produce application/x-ndjson
+// @Param id path string true "URL ID"
+// @Param format query string false "csv, json or ndjson (default csv)"
+// @Param from query string false "Clicks at or after, RFC3339 or YYYY-MM-DD"
+// @Param to query string false "Clicks before, RFC3339 or YYYY-MM-DD"
+// @Security BearerAuth
+// @Success 200 {file} file
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /v1/urls/{id}/clicks/export [get]
+func (h *ExportHandler) ExportClicks(c *gin.Context) {
+	startTime := time.Now()
+	userID := c.GetString("user_id")
+	urlID := c.Param("id")
+	h.log.Info("Handling click export request",
+		logger.String("userID", userID),
+		logger.String("urlID", urlID))
+
+	format := models.ExportFormat(c.DefaultQuery("format", string(models.ExportCSV)))
+	if !format.IsValid() {
+		utils.APIError(c, http.StatusBadRequest, "Format must be csv, json or ndjson")
+		return
+	}
+
+	var filter models.ClickExportFilter
+	var err error
+	if filter.From, err = parseOptionalTime(c, "from"); err != nil {
+		utils.APIError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if filter.To, err = parseOptionalTime(c, "to"); err != nil {
+		utils.APIError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	stream := &exportStream{
+		c:        c,
+		format:   format,
+		filename: fmt.Sprintf("clicks-%s-%s.%s", urlID, time.Now().UTC().Format("20060102"), format),
+		columns:  clickExportColumns,
+	}
+
+	rows := 0
+	err = h.exportService.ExportClicks(c.Request.Context(), userID, urlID, &filter, func(clicks []models.URLClick) error {
+		if err := stream.begin(); err != nil {
+			return err
+		}
+		for i := range clicks {
+			if err := stream.enc.Record(&clicks[i], clickExportRow(&clicks[i])); err != nil {
+				return err
+			}
+		}
+		rows += len(clicks)
+		return stream.flush()
+	})
+	if err == nil {
+		err = stream.close()
+	}
+	if err != nil {
+		switch {
+		case stream.enc != nil:
+			h.failExport(c, stream, err, "Failed to export clicks")
+		case errors.Is(err, models.ErrURLNotFound):
+			utils.APIError(c, http.StatusNotFound, "URL not found")
+		case errors.Is(err, models.ErrForbidden):
+			utils.APIError(c, http.StatusForbidden, "You do not have access to this url")
+		default:
+			h.failExport(c, stream, err, "Failed to export clicks")
+		}
+		return
+	}
+
+	h.log.Info("Click export finished",
+		logger.String("urlID", urlID),
+		logger.Int("rows", rows),
+		logger.Duration("duration", time.Since(startTime)))
+}
+
+// failExport reports an export error. Once streaming has started the status
+// is already sent, so the response is cut short instead.
+func (h *ExportHandler) failExport(c *gin.Context, stream *exportStream, err error, message string) {
+	h.log.Error(message,
+		logger.NamedError("error", err),
+		logger.String("path", c.Request.URL.Path))
+	if stream.enc == nil {
+		utils.APIError(c, http.StatusInternalServerError, message)
+		return
+	}
+	c.Abort()
+}
+
+func parseURLExportFilter(c *gin.Context) (*models.URLExportFilter, error) {
+	var filter models.URLExportFilter
+	var err error
+	if filter.CreatedFrom, err = parseOptionalTime(c, "created_from"); err != nil {
+		return nil, err
+	}
+	if filter.CreatedTo, err = parseOptionalTime(c, "created_to"); err != nil {
+		return nil, err
+	}
+	if raw := c.Query("active"); raw != "" {
+		active, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, errors.New("invalid active: must be true or false")
+		}
+		filter.IsActive = &active
+	}
+	if tags := c.QueryArray("tag"); len(tags) > 0 {
+		if filter.Tags, err = models.NormalizeTags(tags); err != nil {
+			return nil, err
+		}
+	}
+	return &filter, nil
+}
+
+// parseOptionalTime reads an RFC3339 or YYYY-MM-DD query parameter
+func parseOptionalTime(c *gin.Context, name string) (*time.Time, error) {
+	raw := c.Query(name)
+	if raw == "" {
+		return nil, nil
+	}
+	t, err := parseAnalyticsTime(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: use RFC3339 or YYYY-MM-DD", name)
+	}
+	return &t, nil
+}
+
+func urlExportRow(u *models.URLResponse) []string {
+	expiresAt := ""
+	if u.ExpiresAt != nil {
+		expiresAt = u.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+	return []string{
+		u.ID,
+		u.ShortCode,
+		u.ShortURL,
+		u.OriginalURL,
+		u.Title,
+		u.Description,
+		strconv.Itoa(u.Clicks),
+		strconv.FormatBool(u.IsActive),
+		strconv.Itoa(u.RedirectType),
+		expiresAt,
+		u.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+func clickExportRow(click *models.URLClick) []string {
+	return []string{
+		click.ID,
+		click.CreatedAt.UTC().Format(time.RFC3339),
+		click.IPAddress,
+		click.Referrer,
+		strings.ReplaceAll(click.UserAgent, "\n", " "),
+		click.Country,
+		click.City,
+		click.Device,
+		click.OS,
+		click.Browser,
+		strconv.FormatBool(click.IsBot),
+		click.VariantID,
+	}
+}
@@ -0,0 +1,64 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/imraushankr/brevity/server/src/configs"
+	"github.com/imraushankr/brevity/server/src/internal/models"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/brevity/server/src/internal/services"
+	"github.com/imraushankr/brevity/server/src/internal/utils"
+)
+
+type LinkHealthHandler struct {
+	healthService services.LinkHealthService
+	cfg           *configs.Config
+	log           logger.Logger
+}
+
+func NewLinkHealthHandler(healthService services.LinkHealthService, cfg *configs.Config) *LinkHealthHandler {
+	return &LinkHealthHandler{
+		healthService: healthService,
+		cfg:           cfg,
+		log:           logger.Get(),
+	}
+}
+
+// ListUnhealthyURLs godoc
+// @Summary List broken links
+// @Description List the current user's links whose destination failed its latest health checks, longest broken first. Each link includes the status code, latency and time of its latest check.
+// @Tags urls
+// @Produce json
+// @Param page query int false "Page number"
+// @Param limit query int false "Page size"
+// @Security BearerAuth
+// @Success 200 {array} models.URLResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /v1/urls/unhealthy [get]
+func (h *LinkHealthHandler) ListUnhealthyURLs(c *gin.Context) {
+	userID := c.GetString("user_id")
+	page, limit := parsePagination(c)
+
+	urls, total, err := h.healthService.ListUnhealthy(c.Request.Context(), userID, page, limit)
+	if err != nil {
+		h.log.Error("Failed to list unhealthy urls",
+			logger.NamedError("error", err),
+			logger.String("userID", userID))
+		utils.APIError(c, http.StatusInternalServerError, "Failed to list unhealthy urls")
+		return
+	}
+
+	responses := make([]*models.URLResponse, 0, len(urls))
+	for i := range urls {
+		responses = append(responses, urls[i].ToResponse(h.cfg.App.BaseURL))
+	}
+
+	utils.PaginatedResponse(c, http.StatusOK, responses, gin.H{
+		"page":        page,
+		"limit":       limit,
+		"total":       total,
+		"total_pages": (total + int64(limit) - 1) / int64(limit),
+	})
+}
@@ -0,0 +1,227 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/imraushankr/brevity/server/src/configs"
+	"github.com/imraushankr/brevity/server/src/internal/models"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/brevity/server/src/internal/services"
+	"github.com/imraushankr/brevity/server/src/internal/utils"
+)
+
+type LinkSafetyHandler struct {
+	safetyService services.LinkSafetyService
+	cfg           *configs.Config
+	log           logger.Logger
+}
+
+func NewLinkSafetyHandler(safetyService services.LinkSafetyService, cfg *configs.Config) *LinkSafetyHandler {
+	return &LinkSafetyHandler{
+		safetyService: safetyService,
+		cfg:           cfg,
+		log:           logger.Get(),
+	}
+}
+
+// AddBlocklistEntry godoc
+// @Summary Add a blocklist entry
+// @Description Block a domain and its subdomains, destination urls matching a regular expression, or hosts in an IP range. New links are checked at once; existing links are flagged by the next safety scan.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body models.CreateBlocklistEntryRequest true "Blocklist entry"
+// @Security BearerAuth
+// @Success 201 {object} models.BlocklistEntry
+// @Failure 400 {object} models.ValidationErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /v1/admin/blocklist [post]
+func (h *LinkSafetyHandler) AddBlocklistEntry(c *gin.Context) {
+	startTime := time.Now()
+	adminID := c.GetString("user_id")
+
+	var req models.CreateBlocklistEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Warn("Invalid add blocklist entry request",
+			logger.NamedError("error", err),
+			logger.String("adminID", adminID))
+		utils.APIError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	entry, err := h.safetyService.AddBlocklistEntry(c.Request.Context(), adminID, &req)
+	if err != nil {
+		h.handleLinkSafetyError(c, err, "Failed to add blocklist entry")
+		return
+	}
+
+	h.log.Info("Blocklist entry added successfully",
+		logger.String("entryID", entry.ID),
+		logger.Duration("duration", time.Since(startTime)))
+
+	utils.APISuccess(c, http.StatusCreated, entry)
+}
+
+// ListBlocklistEntries godoc
+// @Summary List blocklist entries
+// @Description List the blocklist, newest entries first
+// @Tags admin
+// @Produce json
+// @Param kind query string false "Only entries of this kind: domain, regex or ip_range"
+// @Param page query int false "Page number"
+// @Param limit query int false "Page size"
+// @Security BearerAuth
+// @Success 200 {array} models.BlocklistEntry
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /v1/admin/blocklist [get]
+func (h *LinkSafetyHandler) ListBlocklistEntries(c *gin.Context) {
+	page, limit := parsePagination(c)
+
+	entries, total, err := h.safetyService.ListBlocklistEntries(c.Request.Context(), c.Query("kind"), page, limit)
+	if err != nil {
+		h.handleLinkSafetyError(c, err, "Failed to list blocklist entries")
+		return
+	}
+
+	utils.PaginatedResponse(c, http.StatusOK, entries, gin.H{
+		"page":        page,
+		"limit":       limit,
+		"total":       total,
+		"total_pages": (total + int64(limit) - 1) / int64(limit),
+	})
+}
+
+// DeleteBlocklistEntry godoc
+// @Summary Delete a blocklist entry
+// @Description Remove a blocklist entry. Links it already flagged stay in the review queue.
+// @Tags admin
+// @Produce json
+// @Param id path string true "Entry ID"
+// @Security BearerAuth
+// @Success 200 {object} models.MessageResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /v1/admin/blocklist/{id} [delete]
+func (h *LinkSafetyHandler) DeleteBlocklistEntry(c *gin.Context) {
+	entryID := c.Param("id")
+	h.log.Info("Deleting blocklist entry",
+		logger.String("adminID", c.GetString("user_id")),
+		logger.String("entryID", entryID))
+
+	if err := h.safetyService.DeleteBlocklistEntry(c.Request.Context(), entryID); err != nil {
+		h.handleLinkSafetyError(c, err, "Failed to delete blocklist entry")
+		return
+	}
+
+	utils.APISuccess(c, http.StatusOK, models.MessageResponse{
+		Message: "Blocklist entry deleted successfully",
+	})
+}
+
+// ListLinkFlags godoc
+// @Summary List flagged links
+// @Description List links the safety scanner disabled, oldest first. Pending flags make up the review queue.
+// @Tags admin
+// @Produce json
+// @Param status query string false "Only flags in this status: pending, approved or blocked (default pending)"
+// @Param page query int false "Page number"
+// @Param limit query int false "Page size"
+// @Security BearerAuth
+// @Success 200 {array} models.LinkFlagResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /v1/admin/flags [get]
+func (h *LinkSafetyHandler) ListLinkFlags(c *gin.Context) {
+	page, limit := parsePagination(c)
+	status := c.DefaultQuery("status", models.LinkFlagPending)
+
+	flags, total, err := h.safetyService.ListFlags(c.Request.Context(), status, page, limit)
+	if err != nil {
+		h.handleLinkSafetyError(c, err, "Failed to list flagged links")
+		return
+	}
+
+	responses := make([]*models.LinkFlagResponse, 0, len(flags))
+	for i := range flags {
+		responses = append(responses, flags[i].ToResponse(h.cfg.App.BaseURL))
+	}
+
+	utils.PaginatedResponse(c, http.StatusOK, responses, gin.H{
+		"page":        page,
+		"limit":       limit,
+		"total":       total,
+		"total_pages": (total + int64(limit) - 1) / int64(limit),
+	})
+}
+
+// ReviewLinkFlag godoc
+// @Summary Review a flagged link
+// @Description Approve a flagged link to make it redirect again and keep later scans from flagging it, or block it for good
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Flag ID"
+// @Param request body models.ReviewLinkFlagRequest true "Review"
+// @Security BearerAuth
+// @Success 200 {object} models.LinkFlagResponse
+// @Failure 400 {object} models.ValidationErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /v1/admin/flags/{id}/review [post]
+func (h *LinkSafetyHandler) ReviewLinkFlag(c *gin.Context) {
+	adminID := c.GetString("user_id")
+	flagID := c.Param("id")
+
+	var req models.ReviewLinkFlagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Warn("Invalid review link flag request",
+			logger.NamedError("error", err),
+			logger.String("flagID", flagID))
+		utils.APIError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	flag, err := h.safetyService.ReviewFlag(c.Request.Context(), adminID, flagID, &req)
+	if err != nil {
+		h.handleLinkSafetyError(c, err, "Failed to review flagged link")
+		return
+	}
+
+	utils.APISuccess(c, http.StatusOK, flag.ToResponse(h.cfg.App.BaseURL))
+}
+
+// handleLinkSafetyError maps link safety service errors to API responses
+func (h *LinkSafetyHandler) handleLinkSafetyError(c *gin.Context, err error, fallback string) {
+	if fields, ok := utils.ValidationErrors(err); ok {
+		utils.ValidationError(c, fields)
+		return
+	}
+
+	switch {
+	case errors.Is(err, models.ErrInvalidBlocklistEntry), errors.Is(err, models.ErrInvalidInput):
+		utils.APIError(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, models.ErrBlocklistEntryExists):
+		utils.APIError(c, http.StatusConflict, "Blocklist entry already exists")
+	case errors.Is(err, models.ErrBlocklistEntryNotFound):
+		utils.APIError(c, http.StatusNotFound, "Blocklist entry not found")
+	case errors.Is(err, models.ErrLinkFlagNotFound):
+		utils.APIError(c, http.StatusNotFound, "Flagged link not found")
+	case errors.Is(err, models.ErrLinkFlagReviewed):
+		utils.APIError(c, http.StatusConflict, "Flagged link was already reviewed")
+	default:
+		h.log.Error(fallback,
+			logger.NamedError("error", err),
+			logger.String("path", c.Request.URL.Path))
+		utils.APIError(c, http.StatusInternalServerError, fallback)
+	}
+}
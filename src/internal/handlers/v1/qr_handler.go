@@ -0,0 +1,207 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/imraushankr/brevity/server/src/configs"
+	"github.com/imraushankr/brevity/server/src/internal/models"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/brevity/server/src/internal/services"
+	"github.com/imraushankr/brevity/server/src/internal/utils"
+)
+
+type QRHandler struct {
+	qrService services.QRService
+	cfg       *configs.Config
+	log       logger.Logger
+}
+
+func NewQRHandler(qrService services.QRService, cfg *configs.Config) *QRHandler {
+	return &QRHandler{
+		qrService: qrService,
+		cfg:       cfg,
+		log:       logger.Get(),
+	}
+}
+
+// GetQRCode godoc
+// @Summary Get a url's QR code
+// @Description Render the short url as a QR code image. The link's uploaded logo is drawn in the middle unless logo=false; error correction then defaults to H so the code still scans.
+// @Tags urls
+// @Produce png
+// @Produce image/svg+xml
+// @Param id path string true "URL ID"
+// @Param format query string false "Image format: png or svg (default png)"
+// @Param size query int false "Width and height in pixels (default 512)"
+// @Param margin query int false "Quiet zone in modules, 0 to 16 (default 4)"
+// @Param fg query string false "Foreground hex color (default 000000)"
+// @Param bg query string false "Background hex color, RRGGBBAA for transparency (default ffffff)"
+// @Param ecc query string false "Error correction level: L, M, Q or H (default M, H with a logo)"
+// @Param logo query bool false "Draw the link's logo (default true)"
+// @Security BearerAuth
+// @Success 200 {file} binary
+// @Success 304 "Not modified"
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /v1/urls/{id}/qr [get]
+func (h *QRHandler) GetQRCode(c *gin.Context) {
+	startTime := time.Now()
+	userID := c.GetString("user_id")
+	urlID := c.Param("id")
+
+	opts, err := h.parseQROptions(c)
+	if err != nil {
+		utils.APIError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	img, err := h.qrService.RenderQR(c.Request.Context(), userID, urlID, opts)
+	if err != nil {
+		h.handleQRError(c, err, "Failed to render qr code")
+		return
+	}
+
+	c.Header("Cache-Control", "private, max-age=3600")
+	c.Header("ETag", img.ETag)
+	if c.GetHeader("If-None-Match") == img.ETag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	h.log.Debug("QR code served",
+		logger.String("urlID", urlID),
+		logger.Duration("duration", time.Since(startTime)))
+
+	c.Data(http.StatusOK, img.ContentType, img.Data)
+}
+
+// UploadQRLogo godoc
+// @Summary Upload a url's QR code logo
+// @Description Upload a PNG, JPEG or GIF image to draw in the middle of the url's QR codes. It replaces any previous logo.
+// @Tags urls
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "URL ID"
+// @Param logo formData file true "Logo image"
+// @Security BearerAuth
+// @Success 200 {object} models.QRLogoResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /v1/urls/{id}/qr/logo [put]
+func (h *QRHandler) UploadQRLogo(c *gin.Context) {
+	startTime := time.Now()
+	userID := c.GetString("user_id")
+	urlID := c.Param("id")
+
+	file, header, err := c.Request.FormFile("logo")
+	if err != nil {
+		h.log.Warn("Invalid qr logo upload request",
+			logger.NamedError("error", err),
+			logger.String("urlID", urlID))
+		utils.APIError(c, http.StatusBadRequest, "Logo file is required")
+		return
+	}
+	defer file.Close()
+
+	url, err := h.qrService.SetLogo(c.Request.Context(), userID, urlID, file, header)
+	if err != nil {
+		h.handleQRError(c, err, "Failed to upload qr logo")
+		return
+	}
+
+	h.log.Info("QR logo uploaded successfully",
+		logger.String("urlID", url.ID),
+		logger.Duration("duration", time.Since(startTime)))
+
+	utils.APISuccess(c, http.StatusOK, models.QRLogoResponse{
+		QRLogoURL: url.QRLogoURL,
+	})
+}
+
+// DeleteQRLogo godoc
+// @Summary Remove a url's QR code logo
+// @Description Stop drawing a logo on the url's QR codes
+// @Tags urls
+// @Produce json
+// @Param id path string true "URL ID"
+// @Security BearerAuth
+// @Success 200 {object} models.MessageResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /v1/urls/{id}/qr/logo [delete]
+func (h *QRHandler) DeleteQRLogo(c *gin.Context) {
+	userID := c.GetString("user_id")
+	urlID := c.Param("id")
+
+	if _, err := h.qrService.RemoveLogo(c.Request.Context(), userID, urlID); err != nil {
+		h.handleQRError(c, err, "Failed to remove qr logo")
+		return
+	}
+
+	utils.APISuccess(c, http.StatusOK, models.MessageResponse{
+		Message: "QR logo removed successfully",
+	})
+}
+
+// parseQROptions reads the rendering parameters, filling in defaults. Values
+// are range checked by the service.
+func (h *QRHandler) parseQROptions(c *gin.Context) (*models.QROptions, error) {
+	opts := &models.QROptions{
+		Format:     c.DefaultQuery("format", models.QRFormatPNG),
+		Size:       h.cfg.QR.DefaultSize,
+		Margin:     models.DefaultQRMargin,
+		Foreground: c.DefaultQuery("fg", "000000"),
+		Background: c.DefaultQuery("bg", "ffffff"),
+		ECC:        c.Query("ecc"),
+		Logo:       true,
+	}
+
+	if raw := c.Query("size"); raw != "" {
+		size, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, errors.New("invalid size")
+		}
+		opts.Size = size
+	}
+	if raw := c.Query("margin"); raw != "" {
+		margin, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, errors.New("invalid margin")
+		}
+		opts.Margin = margin
+	}
+	if raw := c.Query("logo"); raw != "" {
+		logo, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, errors.New("invalid logo flag")
+		}
+		opts.Logo = logo
+	}
+	return opts, nil
+}
+
+// handleQRError maps qr service errors to API responses
+func (h *QRHandler) handleQRError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, models.ErrInvalidQROptions), errors.Is(err, models.ErrInvalidQRLogo):
+		utils.APIError(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, models.ErrURLNotFound):
+		utils.APIError(c, http.StatusNotFound, "URL not found")
+	case errors.Is(err, models.ErrForbidden):
+		utils.APIError(c, http.StatusForbidden, "You do not have access to this url")
+	default:
+		h.log.Error(fallback,
+			logger.NamedError("error", err),
+			logger.String("path", c.Request.URL.Path))
+		utils.APIError(c, http.StatusInternalServerError, fallback)
+	}
+}
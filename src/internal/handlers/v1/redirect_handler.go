@@ -0,0 +1,498 @@
+package v1
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
+	"github.com/imraushankr/brevity/server/src/configs"
+	"github.com/imraushankr/brevity/server/src/internal/models"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/auth"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/geoip"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/ratelimit"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/useragent"
+	"github.com/imraushankr/brevity/server/src/internal/services"
+)
+
+// shortCodePattern mirrors the ShortCode validation on models.URL so that
+// obviously invalid paths never reach the database
+var shortCodePattern = regexp.MustCompile(`^[a-zA-Z0-9]{3,10}$`)
+
+// unfurlBotPattern matches the User-Agents of the crawlers that fetch links to
+// build chat and social previews
+var unfurlBotPattern = regexp.MustCompile(`(?i)facebookexternalhit|twitterbot|slackbot-linkexpanding|discordbot|^whatsapp/|telegrambot|linkedinbot`)
+
+// linkAccessCookie remembers that a visitor unlocked a password protected
+// link. It is scoped to the link's path, so each link needs its own.
+const linkAccessCookie = "link_access"
+
+// linkVariantCookie keeps a visitor on the variant of an A/B split they were
+// first sent to, scoped to the link's path like linkAccessCookie
+const (
+	linkVariantCookie = "link_variant"
+	linkVariantTTL    = 30 * 24 * time.Hour
+)
+
+type RedirectHandler struct {
+	urlService    services.URLService
+	clickRecorder services.ClickRecorder
+	uaParser      *useragent.Parser
+	geo           *geoip.Locator
+	failures      *ratelimit.Failures
+	cfg           *configs.Config
+	log           logger.Logger
+}
+
+func NewRedirectHandler(
+	urlService services.URLService,
+	clickRecorder services.ClickRecorder,
+	uaParser *useragent.Parser,
+	geo *geoip.Locator,
+	cfg *configs.Config,
+) *RedirectHandler {
+	return &RedirectHandler{
+		urlService:    urlService,
+		clickRecorder: clickRecorder,
+		uaParser:      uaParser,
+		geo:           geo,
+		failures:      ratelimit.NewFailures(cfg.LinkPassword.MaxAttempts, cfg.LinkPassword.AttemptWindow),
+		cfg:           cfg,
+		log:           logger.Get(),
+	}
+}
+
+// Redirect godoc
+// @Summary Follow a short link
+// @Description Redirect to the original url of an active, unexpired short link, to the target of its first matching redirect rule, or to the visitor's variant when the link splits traffic between several destinations. The link's UTM tags are added to the destination, as are the visitor's query parameters when the link forwards them. Password protected links show a password form until they are unlocked. Outside a link's activation window visitors go to its fallback url when one is set. Known chat and social network crawlers get a page with the link's OpenGraph and Twitter card tags instead of the redirect.
+// @Tags redirect
+// @Produce html
+// @Param short_code path string true "Short code"
+// @Success 200 "Password form or social preview page"
+// @Success 204 "HEAD request for a click limited link, which does not reveal the destination"
+// @Success 301 "Moved Permanently"
+// @Success 302 "Found"
+// @Success 307 "Temporary Redirect"
+// @Success 308 "Permanent Redirect"
+// @Failure 404 "Link not found or not live yet"
+// @Failure 410 "Link expired, disabled or out of clicks"
+// @Router /{short_code} [get]
+func (h *RedirectHandler) Redirect(c *gin.Context) {
+	startTime := time.Now()
+
+	url, ok := h.resolve(c)
+	if !ok {
+		return
+	}
+
+	if url.HasPassword() && !h.hasLinkAccess(c, url) {
+		h.renderPasswordForm(c, http.StatusOK, url.ShortCode, "")
+		return
+	}
+
+	if h.cfg.Redirect.SocialPreview {
+		// Crawlers and browsers get different responses for the same url
+		c.Header("Vary", "User-Agent")
+		if h.isUnfurlBot(c) && !url.HasPassword() {
+			h.renderSocialPreview(c, url)
+			return
+		}
+	}
+
+	status := url.RedirectType
+	if status == 0 {
+		status = models.DefaultRedirectType
+	}
+
+	// Temporary redirects must reach us every time so expiry and
+	// deactivation take effect immediately. Protected, click limited, rule
+	// based and split links are never cached since each visit is decided
+	// anew.
+	if status == http.StatusFound || status == http.StatusTemporaryRedirect ||
+		url.HasPassword() || url.MaxClicks != nil || len(url.Rules) > 0 || len(url.Variants) > 0 {
+		c.Header("Cache-Control", "private, no-cache, no-store, must-revalidate")
+	}
+
+	h.follow(c, url, status, startTime)
+}
+
+// Unlock godoc
+// @Summary Unlock a password protected short link
+// @Description Check the submitted password, remember the link in a short-lived cookie and redirect to the original url. Repeated failures from one IP are blocked for a while.
+// @Tags redirect
+// @Accept x-www-form-urlencoded
+// @Produce html
+// @Param short_code path string true "Short code"
+// @Param password formData string true "Link password"
+// @Success 303 "See Other"
+// @Failure 401 "Incorrect password"
+// @Failure 404 "Link not found"
+// @Failure 410 "Link expired, disabled or out of clicks"
+// @Failure 429 "Too many failed attempts"
+// @Router /{short_code} [post]
+func (h *RedirectHandler) Unlock(c *gin.Context) {
+	startTime := time.Now()
+
+	url, ok := h.resolve(c)
+	if !ok {
+		return
+	}
+
+	if url.HasPassword() {
+		key := c.ClientIP() + "|" + url.ShortCode
+		if blocked, retryAfter := h.failures.Blocked(key); blocked {
+			h.log.Warn("Link password attempts blocked",
+				logger.String("shortCode", url.ShortCode),
+				logger.String("ip", c.ClientIP()))
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			h.renderPage(c, http.StatusTooManyRequests, "Too many attempts",
+				"Too many incorrect passwords were entered for this link. Please try again later.")
+			return
+		}
+
+		err := h.urlService.CheckURLPassword(url, c.PostForm("password"))
+		if err != nil {
+			h.failures.Fail(key)
+			h.renderPasswordForm(c, http.StatusUnauthorized, url.ShortCode, "Incorrect password, please try again.")
+			return
+		}
+		h.failures.Reset(key)
+
+		if err := h.grantLinkAccess(c, url); err != nil {
+			h.log.Error("Failed to issue link access cookie",
+				logger.NamedError("error", err),
+				logger.String("shortCode", url.ShortCode))
+			h.renderPage(c, http.StatusInternalServerError, "Something went wrong",
+				"We could not open this link right now. Please try again later.")
+			return
+		}
+	}
+
+	// See Other makes the browser follow with a GET whatever the link's
+	// own redirect type is
+	c.Header("Cache-Control", "private, no-cache, no-store, must-revalidate")
+	h.follow(c, url, http.StatusSeeOther, startTime)
+}
+
+// resolve looks up the short code in the path and renders the error page
+// when it cannot be followed
+func (h *RedirectHandler) resolve(c *gin.Context) (*models.URL, bool) {
+	shortCode := c.Param("short_code")
+
+	if !shortCodePattern.MatchString(shortCode) {
+		h.renderNotFound(c)
+		return nil, false
+	}
+
+	url, err := h.urlService.ResolveShortCode(c.Request.Context(), c.Request.Host, shortCode)
+	if err != nil {
+		outsideWindow := errors.Is(err, models.ErrURLExpired) || errors.Is(err, models.ErrURLNotYetActive)
+		switch {
+		case outsideWindow && url.FallbackURL != "":
+			// Fallbacks are temporary by nature: the link itself may go
+			// live again
+			c.Header("Cache-Control", "private, no-cache, no-store, must-revalidate")
+			c.Redirect(http.StatusFound, url.FallbackURL)
+		case errors.Is(err, models.ErrURLNotFound):
+			h.renderNotFound(c)
+		case errors.Is(err, models.ErrURLNotYetActive):
+			h.renderPage(c, http.StatusNotFound, "Link not live yet",
+				"This short link is not active yet. Please check back later.")
+		case errors.Is(err, models.ErrURLExpired):
+			h.renderPage(c, http.StatusGone, "Link expired",
+				"This short link has expired and no longer points anywhere.")
+		case errors.Is(err, models.ErrURLFlagged):
+			h.renderPage(c, http.StatusGone, "Link disabled",
+				"This short link has been disabled because its destination was reported as unsafe.")
+		case errors.Is(err, models.ErrURLInactive):
+			h.renderPage(c, http.StatusGone, "Link disabled",
+				"This short link has been disabled by its owner.")
+		case errors.Is(err, models.ErrURLExhausted):
+			h.renderExhausted(c)
+		default:
+			h.log.Error("Failed to resolve short code",
+				logger.NamedError("error", err),
+				logger.String("shortCode", shortCode))
+			h.renderPage(c, http.StatusInternalServerError, "Something went wrong",
+				"We could not open this link right now. Please try again later.")
+		}
+		return nil, false
+	}
+	return url, true
+}
+
+// follow spends a click of a click limited url, records the click and
+// redirects to the first matching rule's target, the visitor's variant of a
+// split link or the original url, with the link's UTM tags and forwarded
+// query added. HEAD requests spend and record nothing, so they are not told
+// where a click limited url leads.
+func (h *RedirectHandler) follow(c *gin.Context, url *models.URL, status int, startTime time.Time) {
+	target := url.OriginalURL
+	var variant *models.URLVariant
+	if rule := h.matchingRule(c, url); rule != nil {
+		target = rule.TargetURL
+	} else if variant = h.pickVariant(c, url); variant != nil {
+		target = variant.TargetURL
+	}
+
+	if c.Request.Method == http.MethodHead {
+		if url.MaxClicks != nil {
+			c.Status(http.StatusNoContent)
+			return
+		}
+	} else {
+		if err := h.urlService.ConsumeClick(c.Request.Context(), url); err != nil {
+			if errors.Is(err, models.ErrURLExhausted) {
+				h.renderExhausted(c)
+				return
+			}
+			h.log.Error("Failed to consume click",
+				logger.NamedError("error", err),
+				logger.String("shortCode", url.ShortCode))
+			h.renderPage(c, http.StatusInternalServerError, "Something went wrong",
+				"We could not open this link right now. Please try again later.")
+			return
+		}
+
+		click := &models.URLClick{
+			URLID:     url.ID,
+			IPAddress: c.ClientIP(),
+			Referrer:  c.Request.Referer(),
+			UserAgent: c.Request.UserAgent(),
+			CreatedAt: time.Now().UTC(),
+		}
+		if variant != nil {
+			click.VariantID = variant.ID
+		}
+		h.clickRecorder.Record(click)
+	}
+
+	target = url.Destination(target, c.Request.URL.RawQuery, h.cfg.Redirect.QueryPrecedence == "request")
+
+	h.log.Debug("Redirecting short code",
+		logger.String("shortCode", url.ShortCode),
+		logger.Int("status", status),
+		logger.Duration("duration", time.Since(startTime)))
+
+	c.Redirect(status, target)
+}
+
+// isUnfurlBot reports whether the request comes from a crawler fetching the
+// link to show a preview of it in a chat or social feed. Only the crawlers'
+// own tokens are matched, not the social bot category, since that also
+// covers apps whose in-app browsers carry real visitors.
+func (h *RedirectHandler) isUnfurlBot(c *gin.Context) bool {
+	return unfurlBotPattern.MatchString(c.Request.UserAgent())
+}
+
+// renderSocialPreview serves crawlers the link's OpenGraph and Twitter card
+// tags. The owner's overrides win over the title, description and image read
+// from the destination. The visit is recorded like any bot click, but does
+// not spend a click of a click limited link, since a share is not a visit.
+func (h *RedirectHandler) renderSocialPreview(c *gin.Context, url *models.URL) {
+	page := socialPage{
+		AppName:     h.cfg.App.Name,
+		ShortURL:    models.ShortURL(h.cfg.App.BaseURL, url.Domain, url.ShortCode),
+		TargetURL:   url.Destination(url.OriginalURL, "", false),
+		Title:       url.OG.Title,
+		Description: url.OG.Description,
+		ImageURL:    url.OG.ImageURL,
+	}
+	if page.Title == "" {
+		page.Title = url.Title
+	}
+	if page.Title == "" {
+		page.Title = h.cfg.App.Name
+	}
+	if page.Description == "" {
+		page.Description = url.Description
+	}
+	if page.ImageURL == "" {
+		page.ImageURL = url.Preview.ImageURL
+	}
+	if url.MaxClicks != nil {
+		// Following the short url is what spends a click, so the page must
+		// not hand out the destination of a click limited link
+		page.TargetURL = page.ShortURL
+	}
+
+	if c.Request.Method != http.MethodHead {
+		h.clickRecorder.Record(&models.URLClick{
+			URLID:     url.ID,
+			IPAddress: c.ClientIP(),
+			Referrer:  c.Request.Referer(),
+			UserAgent: c.Request.UserAgent(),
+			CreatedAt: time.Now().UTC(),
+		})
+	}
+
+	h.log.Debug("Serving social preview",
+		logger.String("shortCode", url.ShortCode),
+		logger.String("userAgent", c.Request.UserAgent()))
+
+	c.Header("Cache-Control", "no-cache")
+	c.Render(http.StatusOK, render.HTML{
+		Template: pageTemplates,
+		Name:     "social.html",
+		Data:     page,
+	})
+}
+
+// matchingRule returns the url's first redirect rule matching the request
+func (h *RedirectHandler) matchingRule(c *gin.Context, url *models.URL) *models.RedirectRule {
+	if len(url.Rules) == 0 {
+		return nil
+	}
+	return url.MatchingRule(h.visitor(c))
+}
+
+// pickVariant assigns the visitor a variant of a split link and remembers it
+// in a cookie. Visitors without the cookie are placed by IP address and User
+// Agent, so clients that drop cookies still see a consistent variant.
+func (h *RedirectHandler) pickVariant(c *gin.Context, url *models.URL) *models.URLVariant {
+	if len(url.Variants) == 0 {
+		return nil
+	}
+
+	assigned, _ := c.Cookie(linkVariantCookie)
+	variant := url.PickVariant(assigned, c.ClientIP()+"|"+c.Request.UserAgent())
+	if variant.ID != assigned {
+		http.SetCookie(c.Writer, &http.Cookie{
+			Name:     linkVariantCookie,
+			Value:    variant.ID,
+			Path:     "/" + url.ShortCode,
+			MaxAge:   int(linkVariantTTL.Seconds()),
+			Secure:   h.cfg.JWT.SecureCookie,
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		})
+	}
+	return variant
+}
+
+// visitor describes the request for redirect rule matching
+func (h *RedirectHandler) visitor(c *gin.Context) *models.Visitor {
+	ua := h.uaParser.Parse(c.Request.UserAgent())
+	return &models.Visitor{
+		Device:   ua.Device,
+		OS:       ua.OS,
+		Country:  h.geo.Lookup(c.ClientIP()).Country,
+		Language: preferredLanguage(c.GetHeader("Accept-Language")),
+		Query:    c.Request.URL.Query(),
+		Time:     time.Now(),
+	}
+}
+
+// hasLinkAccess reports whether the request carries a valid unlock cookie
+// for url
+func (h *RedirectHandler) hasLinkAccess(c *gin.Context, url *models.URL) bool {
+	token, err := c.Cookie(linkAccessCookie)
+	if err != nil || token == "" {
+		return false
+	}
+	return auth.ValidateLinkAccessToken(h.linkAccessSecret(), token, url.ShortCode, url.PasswordHash) == nil
+}
+
+func (h *RedirectHandler) grantLinkAccess(c *gin.Context, url *models.URL) error {
+	token, expiresAt, err := auth.GenerateLinkAccessToken(h.linkAccessSecret(), url.ShortCode, url.PasswordHash, h.cfg.LinkPassword.CookieTTL)
+	if err != nil {
+		return err
+	}
+
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     linkAccessCookie,
+		Value:    token,
+		Path:     "/" + url.ShortCode,
+		Expires:  expiresAt,
+		MaxAge:   int(time.Until(expiresAt).Seconds()),
+		Secure:   h.cfg.JWT.SecureCookie,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return nil
+}
+
+// linkAccessSecret signs unlock cookies, falling back to the access token
+// secret when no dedicated one is configured
+func (h *RedirectHandler) linkAccessSecret() string {
+	if h.cfg.LinkPassword.CookieSecret != "" {
+		return h.cfg.LinkPassword.CookieSecret
+	}
+	return h.cfg.JWT.AccessTokenSecret
+}
+
+func (h *RedirectHandler) renderPasswordForm(c *gin.Context, status int, shortCode, message string) {
+	// The form posts back to the link with the visitor's query, so rules
+	// and forwarded parameters still see it once the link is unlocked
+	action := "/" + shortCode
+	if c.Request.URL.RawQuery != "" {
+		action += "?" + c.Request.URL.RawQuery
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Render(status, render.HTML{
+		Template: pageTemplates,
+		Name:     "password.html",
+		Data: passwordPage{
+			AppName: h.cfg.App.Name,
+			Action:  action,
+			Error:   message,
+		},
+	})
+}
+
+func (h *RedirectHandler) renderExhausted(c *gin.Context) {
+	h.renderPage(c, http.StatusGone, "Link used up",
+		"This short link has reached its click limit and no longer points anywhere.")
+}
+
+func (h *RedirectHandler) renderNotFound(c *gin.Context) {
+	h.renderPage(c, http.StatusNotFound, "Link not found",
+		"The short link you followed does not exist or has been removed.")
+}
+
+func (h *RedirectHandler) renderPage(c *gin.Context, status int, heading, message string) {
+	c.Header("Cache-Control", "no-store")
+	c.Render(status, render.HTML{
+		Template: pageTemplates,
+		Name:     "status.html",
+		Data: statusPage{
+			AppName: h.cfg.App.Name,
+			Status:  status,
+			Heading: heading,
+			Message: message,
+		},
+	})
+}
+
+// preferredLanguage returns the Accept-Language tag with the highest quality,
+// keeping header order between equal weights
+func preferredLanguage(header string) string {
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q > bestQ {
+			best, bestQ = tag, q
+		}
+	}
+	return best
+}
@@ -0,0 +1,236 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/imraushankr/brevity/server/src/configs"
+	"github.com/imraushankr/brevity/server/src/internal/models"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/useragent"
+	"github.com/imraushankr/brevity/server/src/internal/services"
+)
+
+// fakeRedirectService serves a single link and claims its clicks in memory
+type fakeRedirectService struct {
+	services.URLService
+	url *models.URL
+}
+
+func (s *fakeRedirectService) ResolveShortCode(_ context.Context, _, shortCode string) (*models.URL, error) {
+	if shortCode != s.url.ShortCode {
+		return nil, models.ErrURLNotFound
+	}
+	url := *s.url
+	if url.IsExhausted() {
+		return &url, models.ErrURLExhausted
+	}
+	return &url, nil
+}
+
+func (s *fakeRedirectService) ConsumeClick(_ context.Context, url *models.URL) error {
+	if url.MaxClicks == nil {
+		return nil
+	}
+	if s.url.IsExhausted() {
+		return models.ErrURLExhausted
+	}
+	s.url.ClaimedClicks++
+	return nil
+}
+
+type fakeClickRecorder struct {
+	services.ClickRecorder
+	clicks []*models.URLClick
+}
+
+func (r *fakeClickRecorder) Record(click *models.URLClick) bool {
+	r.clicks = append(r.clicks, click)
+	return true
+}
+
+func newRedirectTestRouter(url *models.URL) (*gin.Engine, *fakeRedirectService, *fakeClickRecorder) {
+	gin.SetMode(gin.TestMode)
+	svc := &fakeRedirectService{url: url}
+	recorder := &fakeClickRecorder{}
+	cfg := &configs.Config{App: configs.AppConfig{Name: "Brevity", BaseURL: "https://sho.rt"}}
+
+	h := NewRedirectHandler(svc, recorder, useragent.Default(), nil, cfg)
+	router := gin.New()
+	router.GET("/:short_code", h.Redirect)
+	router.HEAD("/:short_code", h.Redirect)
+	return router, svc, recorder
+}
+
+func serveRedirect(router *gin.Engine, method, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+	return w
+}
+
+func TestRedirectHeadDoesNotRevealClickLimitedLinks(t *testing.T) {
+	maxClicks := 1
+	router, svc, recorder := newRedirectTestRouter(&models.URL{
+		ID:           "u1",
+		ShortCode:    "once1",
+		OriginalURL:  "https://example.com/a?x=1",
+		IsActive:     true,
+		RedirectType: http.StatusFound,
+		MaxClicks:    &maxClicks,
+	})
+
+	for i := range 3 {
+		w := serveRedirect(router, http.MethodHead, "/once1")
+		if w.Code != http.StatusNoContent {
+			t.Errorf("HEAD %d: status = %d, want %d", i, w.Code, http.StatusNoContent)
+		}
+		if loc := w.Header().Get("Location"); loc != "" {
+			t.Errorf("HEAD %d: Location = %q, want none", i, loc)
+		}
+	}
+	if svc.url.ClaimedClicks != 0 || len(recorder.clicks) != 0 {
+		t.Fatalf("HEAD claimed %d clicks and recorded %d, want none", svc.url.ClaimedClicks, len(recorder.clicks))
+	}
+
+	w := serveRedirect(router, http.MethodGet, "/once1")
+	if w.Code != http.StatusFound || w.Header().Get("Location") != "https://example.com/a?x=1" {
+		t.Errorf("first GET = %d %q, want 302 to the destination", w.Code, w.Header().Get("Location"))
+	}
+	if len(recorder.clicks) != 1 {
+		t.Errorf("recorded %d clicks, want 1", len(recorder.clicks))
+	}
+
+	for _, method := range []string{http.MethodGet, http.MethodHead} {
+		w = serveRedirect(router, method, "/once1")
+		if w.Code != http.StatusGone || w.Header().Get("Location") != "" {
+			t.Errorf("%s after the last click = %d %q, want 410 without a Location", method, w.Code, w.Header().Get("Location"))
+		}
+	}
+}
+
+func TestRedirectHeadFollowsUnlimitedLinks(t *testing.T) {
+	router, svc, recorder := newRedirectTestRouter(&models.URL{
+		ID:           "u2",
+		ShortCode:    "open1",
+		OriginalURL:  "https://example.com/b",
+		IsActive:     true,
+		RedirectType: http.StatusMovedPermanently,
+	})
+
+	w := serveRedirect(router, http.MethodHead, "/open1")
+	if w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != "https://example.com/b" {
+		t.Errorf("HEAD = %d %q, want 301 to the destination", w.Code, w.Header().Get("Location"))
+	}
+	if svc.url.ClaimedClicks != 0 || len(recorder.clicks) != 0 {
+		t.Errorf("HEAD claimed %d clicks and recorded %d, want none", svc.url.ClaimedClicks, len(recorder.clicks))
+	}
+
+	if w := serveRedirect(router, http.MethodHead, "/nope1"); w.Code != http.StatusNotFound {
+		t.Errorf("HEAD of an unknown code = %d, want 404", w.Code)
+	}
+}
+
+func TestPreferredLanguage(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{header: "", want: ""},
+		{header: "de-AT", want: "de-AT"},
+		{header: "en-US,en;q=0.9,de;q=0.8", want: "en-US"},
+		// The highest quality wins wherever it is listed
+		{header: "fr;q=0.5, de-CH;q=0.9, en;q=0.7", want: "de-CH"},
+		{header: "da, en-gb;q=0.8, en;q=0.7", want: "da"},
+		// A tag without q is worth 1 and beats explicit lower values
+		{header: "en;q=0.9, pt-BR", want: "pt-BR"},
+		// Ties keep header order
+		{header: "es;q=0.8, it;q=0.8", want: "es"},
+		{header: "es, it;q=1", want: "es"},
+		// Wildcards, empty tags, broken and zero q values are skipped
+		{header: "*, ja;q=0.1", want: "ja"},
+		{header: " , ,ko;q=0.3", want: "ko"},
+		{header: "nl;q=abc, sv;q=0.2", want: "sv"},
+		{header: "en;q=0, fi;q=0.1", want: "fi"},
+		{header: "en;q=0", want: ""},
+		{header: "*", want: ""},
+	}
+	for _, tt := range tests {
+		if got := preferredLanguage(tt.header); got != tt.want {
+			t.Errorf("preferredLanguage(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestRedirectVariantsAreSticky(t *testing.T) {
+	router, _, recorder := newRedirectTestRouter(&models.URL{
+		ID:           "u3",
+		ShortCode:    "split1",
+		OriginalURL:  "https://example.com/",
+		IsActive:     true,
+		RedirectType: http.StatusFound,
+		Variants: []models.URLVariant{
+			{ID: "va", TargetURL: "https://example.com/a", Weight: 1},
+			{ID: "vb", TargetURL: "https://example.com/b", Weight: 1},
+		},
+	})
+	visit := func(ip, ua string, cookie *http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/split1", nil)
+		req.RemoteAddr = ip + ":4321"
+		req.Header.Set("User-Agent", ua)
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	variantCookie := func(w *httptest.ResponseRecorder) *http.Cookie {
+		for _, cookie := range w.Result().Cookies() {
+			if cookie.Name == linkVariantCookie {
+				return cookie
+			}
+		}
+		return nil
+	}
+
+	// Without a cookie the same address and User-Agent get the same variant
+	first := visit("198.51.100.7", "Mozilla/5.0", nil)
+	cookie := variantCookie(first)
+	if cookie == nil || cookie.Path != "/split1" {
+		t.Fatalf("first visit set cookie %+v, want one scoped to the link", cookie)
+	}
+	location := first.Header().Get("Location")
+	for range 5 {
+		if w := visit("198.51.100.7", "Mozilla/5.0", nil); w.Header().Get("Location") != location {
+			t.Fatalf("repeat visit went to %s, want %s", w.Header().Get("Location"), location)
+		}
+	}
+
+	// The cookie wins over the address and User-Agent, and is not set again
+	for _, ip := range []string{"198.51.100.7", "203.0.113.1", "192.0.2.99"} {
+		w := visit(ip, "curl/8.0", cookie)
+		if w.Header().Get("Location") != location {
+			t.Errorf("visit from %s with the cookie went to %s, want %s", ip, w.Header().Get("Location"), location)
+		}
+		if variantCookie(w) != nil {
+			t.Errorf("visit from %s with the cookie set it again", ip)
+		}
+	}
+
+	other := "vb"
+	if cookie.Value == "vb" {
+		other = "va"
+	}
+	w := visit("198.51.100.7", "Mozilla/5.0", &http.Cookie{Name: linkVariantCookie, Value: other})
+	if want := "https://example.com/" + other[1:]; w.Header().Get("Location") != want {
+		t.Errorf("visit with cookie %s went to %s, want %s", other, w.Header().Get("Location"), want)
+	}
+
+	for _, click := range recorder.clicks {
+		if click.VariantID != "va" && click.VariantID != "vb" {
+			t.Errorf("click recorded variant %q", click.VariantID)
+		}
+	}
+}
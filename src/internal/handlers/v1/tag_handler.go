@@ -0,0 +1,145 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/imraushankr/brevity/server/src/internal/models"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/brevity/server/src/internal/services"
+	"github.com/imraushankr/brevity/server/src/internal/utils"
+)
+
+type TagHandler struct {
+	tagService services.TagService
+	log        logger.Logger
+}
+
+func NewTagHandler(tagService services.TagService) *TagHandler {
+	return &TagHandler{
+		tagService: tagService,
+		log:        logger.Get(),
+	}
+}
+
+// ListTags godoc
+// @Summary List my tags
+// @Description List the authenticated user's tags by name, with how many links carry each
+// @Tags tags
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.Tag
+// @Failure 500 {object} models.ErrorResponse
+// @Router /v1/tags [get]
+func (h *TagHandler) ListTags(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	tags, err := h.tagService.ListTags(c.Request.Context(), userID)
+	if err != nil {
+		h.handleTagError(c, err, "Failed to list tags")
+		return
+	}
+	utils.APISuccess(c, http.StatusOK, tags)
+}
+
+// DeleteTag godoc
+// @Summary Delete a tag
+// @Description Remove a tag from every link carrying it and delete it
+// @Tags tags
+// @Produce json
+// @Param id path string true "Tag ID"
+// @Security BearerAuth
+// @Success 200 {object} models.MessageResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /v1/tags/{id} [delete]
+func (h *TagHandler) DeleteTag(c *gin.Context) {
+	userID := c.GetString("user_id")
+	tagID := c.Param("id")
+
+	if err := h.tagService.DeleteTag(c.Request.Context(), userID, tagID); err != nil {
+		h.handleTagError(c, err, "Failed to delete tag")
+		return
+	}
+
+	utils.APISuccess(c, http.StatusOK, models.MessageResponse{
+		Message: "Tag deleted successfully",
+	})
+}
+
+// BulkTagURLs godoc
+// @Summary Tag many urls
+// @Description Add tags to up to 1000 of the authenticated user's urls at once. Missing tags are created, and urls that do not exist or belong to someone else are skipped.
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param request body models.BulkTagRequest true "URLs and tags"
+// @Security BearerAuth
+// @Success 200 {object} models.BulkTagResult
+// @Failure 400 {object} models.ValidationErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /v1/urls/bulk/tag [post]
+func (h *TagHandler) BulkTagURLs(c *gin.Context) {
+	h.bulk(c, h.tagService.BulkTag, "Failed to tag urls")
+}
+
+// BulkUntagURLs godoc
+// @Summary Untag many urls
+// @Description Remove tags from up to 1000 of the authenticated user's urls at once. The tags themselves are kept.
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param request body models.BulkTagRequest true "URLs and tags"
+// @Security BearerAuth
+// @Success 200 {object} models.BulkTagResult
+// @Failure 400 {object} models.ValidationErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /v1/urls/bulk/untag [post]
+func (h *TagHandler) BulkUntagURLs(c *gin.Context) {
+	h.bulk(c, h.tagService.BulkUntag, "Failed to untag urls")
+}
+
+func (h *TagHandler) bulk(c *gin.Context, apply func(ctx context.Context, userID string, req *models.BulkTagRequest) (*models.BulkTagResult, error), fallback string) {
+	userID := c.GetString("user_id")
+
+	var req models.BulkTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Warn("Invalid bulk tag request",
+			logger.NamedError("error", err),
+			logger.String("userID", userID))
+		utils.APIError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	result, err := apply(c.Request.Context(), userID, &req)
+	if err != nil {
+		h.handleTagError(c, err, fallback)
+		return
+	}
+	utils.APISuccess(c, http.StatusOK, result)
+}
+
+// handleTagError maps tag service errors to API responses
+func (h *TagHandler) handleTagError(c *gin.Context, err error, fallback string) {
+	if fields, ok := utils.ValidationErrors(err); ok {
+		utils.ValidationError(c, fields)
+		return
+	}
+
+	switch {
+	case errors.Is(err, models.ErrTagNotFound):
+		utils.APIError(c, http.StatusNotFound, "Tag not found")
+	case errors.Is(err, models.ErrForbidden):
+		utils.APIError(c, http.StatusForbidden, "You do not have access to this tag")
+	case errors.Is(err, models.ErrInvalidTag):
+		utils.APIError(c, http.StatusBadRequest, err.Error())
+	default:
+		h.log.Error(fallback,
+			logger.NamedError("error", err),
+			logger.String("path", c.Request.URL.Path))
+		utils.APIError(c, http.StatusInternalServerError, fallback)
+	}
+}
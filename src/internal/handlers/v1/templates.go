@@ -0,0 +1,37 @@
+package v1
+
+import (
+	"embed"
+	"html/template"
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+// pageTemplates holds the HTML pages served outside the JSON API
+var pageTemplates = template.Must(template.ParseFS(templateFS, "templates/*.html"))
+
+// statusPage is the data rendered by templates/status.html
+type statusPage struct {
+	AppName string
+	Status  int
+	Heading string
+	Message string
+}
+
+// passwordPage is the data rendered by templates/password.html
+type passwordPage struct {
+	AppName string
+	Action  string
+	Error   string
+}
+
+// socialPage is the data rendered by templates/social.html
+type socialPage struct {
+	AppName     string
+	ShortURL    string
+	TargetURL   string
+	Title       string
+	Description string
+	ImageURL    string
+}
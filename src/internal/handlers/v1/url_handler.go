@@ -1 +1,358 @@
-package v1
\ No newline at end of file
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/imraushankr/brevity/server/src/configs"
+	"github.com/imraushankr/brevity/server/src/internal/models"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/pagination"
+	"github.com/imraushankr/brevity/server/src/internal/services"
+	"github.com/imraushankr/brevity/server/src/internal/utils"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+type URLHandler struct {
+	urlService services.URLService
+	cfg        *configs.Config
+	log        logger.Logger
+}
+
+func NewURLHandler(urlService services.URLService, cfg *configs.Config) *URLHandler {
+	return &URLHandler{
+		urlService: urlService,
+		cfg:        cfg,
+		log:        logger.Get(),
+	}
+}
+
+// CreateURL godoc
+// @Summary Create a short url
+// @Description Shorten a url, optionally on one of the user's verified custom domains, with a custom short code, ordered redirect rules that send matching visitors elsewhere, weighted A/B variants, UTM tags and query forwarding
+// @Tags urls
+// @Accept json
+// @Produce json
+// @Param request body models.CreateURLRequest true "Create url request"
+// @Security BearerAuth
+// @Success 201 {object} models.URLResponse
+// @Failure 400 {object} models.ValidationErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /v1/urls [post]
+func (h *URLHandler) CreateURL(c *gin.Context) {
+	startTime := time.Now()
+	userID := c.GetString("user_id")
+	h.log.Info("Handling create url request", logger.String("userID", userID))
+
+	var req models.CreateURLRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Warn("Invalid create url request",
+			logger.NamedError("error", err),
+			logger.String("userID", userID))
+		utils.APIError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	url, err := h.urlService.CreateURL(c.Request.Context(), userID, &req)
+	if err != nil {
+		h.handleURLError(c, err, "Failed to create url")
+		return
+	}
+
+	h.log.Info("URL created successfully",
+		logger.String("urlID", url.ID),
+		logger.String("shortCode", url.ShortCode),
+		logger.Duration("duration", time.Since(startTime)))
+
+	utils.APISuccess(c, http.StatusCreated, url.ToResponse(h.cfg.App.BaseURL))
+}
+
+// ListURLs godoc
+// @Summary List my urls
+// @Description List the authenticated user's short urls a page at a time. Pass next_cursor or prev_cursor from the pagination of a response as cursor to get the next or previous page. Sort by created_at, title, short_code or clicks, comma separated and prefixed with - for descending. Filter with filter[field]=value or filter[field][op]=value on created_at, expires_at and clicks (gt, gte, lt, lte), title and short_code (eq, contains), is_active, domain_id, campaign_id and tag (eq, in). Repeating filter[tag] requires every tag.
+// @Tags urls
+// @Produce json
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "Cursor of the page to get"
+// @Param sort query string false "Sort fields (default -created_at)"
+// @Param filter[tag] query []string false "Tag name" collectionFormat(multi)
+// @Param filter[campaign_id] query string false "Campaign ID"
+// @Security BearerAuth
+// @Success 200 {array} models.URLResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /v1/urls [get]
+func (h *URLHandler) ListURLs(c *gin.Context) {
+	startTime := time.Now()
+	userID := c.GetString("user_id")
+	h.log.Info("Listing urls", logger.String("userID", userID))
+
+	q, err := pagination.Parse(c.Request.URL.Query())
+	if err != nil {
+		utils.APIError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	urls, page, err := h.urlService.ListURLs(c.Request.Context(), userID, q)
+	if err != nil {
+		h.handleURLError(c, err, "Failed to list urls")
+		return
+	}
+
+	responses := make([]*models.URLResponse, 0, len(urls))
+	for i := range urls {
+		responses = append(responses, urls[i].ToResponse(h.cfg.App.BaseURL))
+	}
+
+	h.log.Info("URLs listed successfully",
+		logger.String("userID", userID),
+		logger.Int("count", len(responses)),
+		logger.Duration("duration", time.Since(startTime)))
+
+	utils.PaginatedResponse(c, http.StatusOK, responses, page)
+}
+
+// SearchURLs godoc
+// @Summary Search my urls
+// @Description Full-text search over the authenticated user's short urls by title, description, destination or short code, best match first. Every word must match, words match as prefixes, and matched words are wrapped in <mark> tags in highlights. Admins search every user's urls.
+// @Tags urls
+// @Produce json
+// @Param q query string true "Search words"
+// @Param page query int false "Page number"
+// @Param limit query int false "Page size"
+// @Security BearerAuth
+// @Success 200 {array} models.URLSearchResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /v1/urls/search [get]
+func (h *URLHandler) SearchURLs(c *gin.Context) {
+	startTime := time.Now()
+	userID := c.GetString("user_id")
+	page, limit := parsePagination(c)
+	isAdmin := c.GetString("user_role") == string(models.RoleAdmin)
+
+	hits, total, err := h.urlService.SearchURLs(c.Request.Context(), userID, isAdmin, c.Query("q"), page, limit)
+	if err != nil {
+		h.handleURLError(c, err, "Failed to search urls")
+		return
+	}
+
+	responses := make([]*models.URLSearchResponse, 0, len(hits))
+	for i := range hits {
+		responses = append(responses, hits[i].ToResponse(h.cfg.App.BaseURL))
+	}
+
+	h.log.Info("URLs searched successfully",
+		logger.String("userID", userID),
+		logger.Int64("total", total),
+		logger.Duration("duration", time.Since(startTime)))
+
+	utils.PaginatedResponse(c, http.StatusOK, responses, gin.H{
+		"page":        page,
+		"limit":       limit,
+		"total":       total,
+		"total_pages": (total + int64(limit) - 1) / int64(limit),
+	})
+}
+
+// GetURL godoc
+// @Summary Get a short url
+// @Description Get one of the authenticated user's short urls
+// @Tags urls
+// @Produce json
+// @Param id path string true "URL ID"
+// @Security BearerAuth
+// @Success 200 {object} models.URLResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /v1/urls/{id} [get]
+func (h *URLHandler) GetURL(c *gin.Context) {
+	userID := c.GetString("user_id")
+	urlID := c.Param("id")
+	h.log.Info("Fetching url",
+		logger.String("userID", userID),
+		logger.String("urlID", urlID))
+
+	url, err := h.urlService.GetURL(c.Request.Context(), userID, urlID)
+	if err != nil {
+		h.handleURLError(c, err, "Failed to fetch url")
+		return
+	}
+
+	utils.APISuccess(c, http.StatusOK, url.ToResponse(h.cfg.App.BaseURL))
+}
+
+// UpdateURL godoc
+// @Summary Update a short url
+// @Description Update the title, description, expiry, activation window, password, click limit, redirect rules, variants, UTM tags or query forwarding of a short url. Rules and variants replace the whole list; an empty list removes them. UTM tags replace all five tags.
+// @Tags urls
+// @Accept json
+// @Produce json
+// @Param id path string true "URL ID"
+// @Param request body models.UpdateURLRequest true "Update url request"
+// @Security BearerAuth
+// @Success 200 {object} models.URLResponse
+// @Failure 400 {object} models.ValidationErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /v1/urls/{id} [put]
+func (h *URLHandler) UpdateURL(c *gin.Context) {
+	startTime := time.Now()
+	userID := c.GetString("user_id")
+	urlID := c.Param("id")
+	h.log.Info("Updating url",
+		logger.String("userID", userID),
+		logger.String("urlID", urlID))
+
+	var req models.UpdateURLRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Warn("Invalid update url request",
+			logger.NamedError("error", err),
+			logger.String("urlID", urlID))
+		utils.APIError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	url, err := h.urlService.UpdateURL(c.Request.Context(), userID, urlID, &req)
+	if err != nil {
+		h.handleURLError(c, err, "Failed to update url")
+		return
+	}
+
+	h.log.Info("URL updated successfully",
+		logger.String("urlID", urlID),
+		logger.Duration("duration", time.Since(startTime)))
+
+	utils.APISuccess(c, http.StatusOK, url.ToResponse(h.cfg.App.BaseURL))
+}
+
+// DeactivateURL godoc
+// @Summary Deactivate a short url
+// @Description Stop a short url from redirecting without deleting it
+// @Tags urls
+// @Produce json
+// @Param id path string true "URL ID"
+// @Security BearerAuth
+// @Success 200 {object} models.MessageResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /v1/urls/{id}/deactivate [patch]
+func (h *URLHandler) DeactivateURL(c *gin.Context) {
+	userID := c.GetString("user_id")
+	urlID := c.Param("id")
+	h.log.Info("Deactivating url",
+		logger.String("userID", userID),
+		logger.String("urlID", urlID))
+
+	if err := h.urlService.DeactivateURL(c.Request.Context(), userID, urlID); err != nil {
+		h.handleURLError(c, err, "Failed to deactivate url")
+		return
+	}
+
+	utils.APISuccess(c, http.StatusOK, models.MessageResponse{
+		Message: "URL deactivated successfully",
+	})
+}
+
+// DeleteURL godoc
+// @Summary Delete a short url
+// @Description Soft delete a short url
+// @Tags urls
+// @Produce json
+// @Param id path string true "URL ID"
+// @Security BearerAuth
+// @Success 200 {object} models.MessageResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /v1/urls/{id} [delete]
+func (h *URLHandler) DeleteURL(c *gin.Context) {
+	userID := c.GetString("user_id")
+	urlID := c.Param("id")
+	h.log.Info("Deleting url",
+		logger.String("userID", userID),
+		logger.String("urlID", urlID))
+
+	if err := h.urlService.DeleteURL(c.Request.Context(), userID, urlID); err != nil {
+		h.handleURLError(c, err, "Failed to delete url")
+		return
+	}
+
+	utils.APISuccess(c, http.StatusOK, models.MessageResponse{
+		Message: "URL deleted successfully",
+	})
+}
+
+// handleURLError maps url service errors to API responses
+func (h *URLHandler) handleURLError(c *gin.Context, err error, fallback string) {
+	if fields, ok := utils.ValidationErrors(err); ok {
+		utils.ValidationError(c, fields)
+		return
+	}
+
+	var itemErr *models.InvalidItemError
+	if errors.As(err, &itemErr) {
+		utils.ValidationError(c, map[string]string{itemErr.Field(): itemErr.Reason})
+		return
+	}
+
+	switch {
+	case errors.Is(err, models.ErrURLNotFound):
+		utils.APIError(c, http.StatusNotFound, "URL not found")
+	case errors.Is(err, models.ErrForbidden):
+		utils.APIError(c, http.StatusForbidden, "You do not have access to this url")
+	case errors.Is(err, models.ErrShortCodeExists):
+		utils.APIError(c, http.StatusConflict, "Short code already exists")
+	case errors.Is(err, models.ErrShortCodeReserved):
+		utils.APIError(c, http.StatusBadRequest, "Short code is reserved")
+	case errors.Is(err, models.ErrInvalidExpiry):
+		utils.APIError(c, http.StatusBadRequest, "Expiry must be in the future")
+	case errors.Is(err, models.ErrInvalidActivationWindow):
+		utils.APIError(c, http.StatusBadRequest, "Activation must be before expiry")
+	case errors.Is(err, models.ErrDomainNotFound):
+		utils.APIError(c, http.StatusBadRequest, "Domain not found")
+	case errors.Is(err, models.ErrDomainNotVerified):
+		utils.APIError(c, http.StatusBadRequest, "Domain is not verified")
+	case errors.Is(err, models.ErrUnsafeURL):
+		utils.APIError(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, models.ErrInvalidTag):
+		utils.APIError(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, models.ErrCampaignNotFound):
+		utils.APIError(c, http.StatusBadRequest, "Campaign not found")
+	case errors.Is(err, models.ErrInvalidSearchQuery), errors.Is(err, pagination.ErrInvalid):
+		utils.APIError(c, http.StatusBadRequest, err.Error())
+	default:
+		h.log.Error(fallback,
+			logger.NamedError("error", err),
+			logger.String("path", c.Request.URL.Path))
+		utils.APIError(c, http.StatusInternalServerError, fallback)
+	}
+}
+
+// parsePagination reads page and limit query parameters with sane defaults
+func parsePagination(c *gin.Context) (int, int) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultPageSize)))
+	if err != nil || limit < 1 {
+		limit = defaultPageSize
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+	return page, limit
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/imraushankr/brevity/server/src/internal/models"
 	"github.com/imraushankr/brevity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/pagination"
 	"github.com/imraushankr/brevity/server/src/internal/services"
 	"github.com/imraushankr/brevity/server/src/internal/utils"
 )
@@ -444,4 +445,40 @@ func (h *UserHandler) UploadAvatar(c *gin.Context) {
 	utils.APISuccess(c, http.StatusOK, models.UploadAvatarResponse{
 		AvatarURL: avatarURL,
 	})
-}
\ No newline at end of file
+}
+
+// ListUsers godoc
+// @Summary List users
+// @Description List all users a page at a time, for admins. Pass next_cursor or prev_cursor from the pagination of a response as cursor to get the next or previous page. Sort by created_at, username or email, comma separated and prefixed with - for descending. Filter with filter[field]=value or filter[field][op]=value on created_at and last_login_at (gt, gte, lt, lte), username and email (eq, contains), role (eq, in), is_active and is_verified.
+// @Tags users
+// @Produce json
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "Cursor of the page to get"
+// @Param sort query string false "Sort fields (default -created_at)"
+// @Param filter[role] query string false "Role"
+// @Security BearerAuth
+// @Success 200 {array} models.User
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /v1/users [get]
+func (h *UserHandler) ListUsers(c *gin.Context) {
+	q, err := pagination.Parse(c.Request.URL.Query())
+	if err != nil {
+		utils.APIError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	users, page, err := h.userService.ListUsers(c.Request.Context(), q)
+	if err != nil {
+		if errors.Is(err, pagination.ErrInvalid) {
+			utils.APIError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.log.Error("Failed to list users", logger.NamedError("error", err))
+		utils.APIError(c, http.StatusInternalServerError, "Failed to list users")
+		return
+	}
+
+	utils.PaginatedResponse(c, http.StatusOK, users, page)
+}
@@ -0,0 +1,184 @@
+package models
+
+import "time"
+
+// AnalyticsInterval is the width of a click time-series bucket
+type AnalyticsInterval string
+
+const (
+	IntervalHour AnalyticsInterval = "hour"
+	IntervalDay  AnalyticsInterval = "day"
+	IntervalWeek AnalyticsInterval = "week"
+)
+
+// AnalyticsDimension is a click attribute that can be broken down into top values
+type AnalyticsDimension string
+
+const (
+	DimensionReferrer AnalyticsDimension = "referrer"
+	DimensionCountry  AnalyticsDimension = "country"
+	DimensionDevice   AnalyticsDimension = "device"
+	DimensionOS       AnalyticsDimension = "os"
+	DimensionBrowser  AnalyticsDimension = "browser"
+
+	// DimensionVariant is rolled up like the others but reported per
+	// variant in URLAnalytics.Variants rather than as a breakdown
+	DimensionVariant AnalyticsDimension = "variant"
+)
+
+// AnalyticsDimensions lists every supported breakdown in response order
+var AnalyticsDimensions = []AnalyticsDimension{
+	DimensionReferrer,
+	DimensionCountry,
+	DimensionDevice,
+	DimensionOS,
+	DimensionBrowser,
+}
+
+// IsValid reports whether the interval is supported
+func (i AnalyticsInterval) IsValid() bool {
+	switch i {
+	case IntervalHour, IntervalDay, IntervalWeek:
+		return true
+	}
+	return false
+}
+
+// Truncate returns the start of the bucket containing t, in UTC. Weeks start
+// on Monday.
+func (i AnalyticsInterval) Truncate(t time.Time) time.Time {
+	t = t.UTC()
+	switch i {
+	case IntervalHour:
+		return t.Truncate(time.Hour)
+	case IntervalWeek:
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// Next returns the start of the bucket following start
+func (i AnalyticsInterval) Next(start time.Time) time.Time {
+	switch i {
+	case IntervalHour:
+		return start.Add(time.Hour)
+	case IntervalWeek:
+		return start.AddDate(0, 0, 7)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// AnalyticsScope selects the links clicks are aggregated over: a single
+// link, or every link with a tag or in a campaign. Exactly one field is set.
+type AnalyticsScope struct {
+	URLID      string
+	TagID      string
+	CampaignID string
+}
+
+// String describes the scope for logs, e.g. "tag:abc123"
+func (s AnalyticsScope) String() string {
+	switch {
+	case s.TagID != "":
+		return "tag:" + s.TagID
+	case s.CampaignID != "":
+		return "campaign:" + s.CampaignID
+	default:
+		return "url:" + s.URLID
+	}
+}
+
+// AnalyticsQuery selects the clicks an analytics report covers. From is
+// inclusive and To is exclusive.
+type AnalyticsQuery struct {
+	From     time.Time
+	To       time.Time
+	Interval AnalyticsInterval
+	Top      int
+}
+
+type ClickBucket struct {
+	Start  time.Time `json:"start"`
+	Clicks int64     `json:"clicks"`
+	// UniqueVisitors counts distinct IP addresses. In compacted ranges it is
+	// summed over hourly or daily rollups and can overcount.
+	UniqueVisitors int64 `json:"unique_visitors"`
+}
+
+type BreakdownItem struct {
+	Value  string `json:"value"`
+	Clicks int64  `json:"clicks"`
+}
+
+// VariantStats compares one variant of an A/B split with the others. Shares
+// are fractions of the clicks on all variants; ExpectedShare follows from the
+// weights. Variants removed from the link keep their stats and are flagged.
+type VariantStats struct {
+	ID            string  `json:"id"`
+	Name          string  `json:"name,omitempty"`
+	TargetURL     string  `json:"target_url,omitempty"`
+	Weight        int     `json:"weight"`
+	ExpectedShare float64 `json:"expected_share"`
+	Clicks        int64   `json:"clicks"`
+	// UniqueVisitors counts distinct IP addresses. In compacted ranges it is
+	// summed over rollups and can overcount.
+	UniqueVisitors int64   `json:"unique_visitors"`
+	Share          float64 `json:"share"`
+	Removed        bool    `json:"removed,omitempty"`
+}
+
+// URLAnalytics reports the clicks on one link. Unique visitors are distinct
+// IP addresses, but once a range has been compacted only per bucket counts
+// are kept, and they are summed across buckets. UniqueVisitorsApproximate
+// says when that happened, so every unique visitor count in the report is an
+// upper bound rather than exact.
+type URLAnalytics struct {
+	URLID          string            `json:"url_id"`
+	From           time.Time         `json:"from"`
+	To             time.Time         `json:"to"`
+	Interval       AnalyticsInterval `json:"interval"`
+	TotalClicks    int64             `json:"total_clicks"`
+	UniqueVisitors int64             `json:"unique_visitors"`
+	// UniqueVisitorsApproximate is set when part of the range was answered
+	// from rollups, whose unique visitors are summed and can overcount
+	UniqueVisitorsApproximate bool                                   `json:"unique_visitors_approximate"`
+	TimeSeries                []ClickBucket                          `json:"time_series"`
+	Breakdowns                map[AnalyticsDimension][]BreakdownItem `json:"breakdowns"`
+	Variants                  []VariantStats                         `json:"variants,omitempty"`
+}
+
+// LinkClicks is a link's share of the clicks in a group report
+type LinkClicks struct {
+	URLID     string `json:"url_id"`
+	ShortCode string `json:"short_code,omitempty"`
+	Clicks    int64  `json:"clicks"`
+	// UniqueVisitors counts distinct IP addresses. In compacted ranges it is
+	// summed over rollups and can overcount.
+	UniqueVisitors int64 `json:"unique_visitors"`
+}
+
+// GroupAnalytics aggregates the clicks on every link with a tag or in a
+// campaign. Unique visitors are counted across the group, except in
+// compacted ranges where they are summed per link and bucket, which
+// UniqueVisitorsApproximate reports.
+type GroupAnalytics struct {
+	TagID          string            `json:"tag_id,omitempty"`
+	CampaignID     string            `json:"campaign_id,omitempty"`
+	Name           string            `json:"name"`
+	URLCount       int64             `json:"url_count"`
+	From           time.Time         `json:"from"`
+	To             time.Time         `json:"to"`
+	Interval       AnalyticsInterval `json:"interval"`
+	TotalClicks    int64             `json:"total_clicks"`
+	UniqueVisitors int64             `json:"unique_visitors"`
+	// UniqueVisitorsApproximate is set when part of the range was answered
+	// from rollups, whose unique visitors are summed and can overcount
+	UniqueVisitorsApproximate bool                                   `json:"unique_visitors_approximate"`
+	TimeSeries                []ClickBucket                          `json:"time_series"`
+	Breakdowns                map[AnalyticsDimension][]BreakdownItem `json:"breakdowns"`
+	TopLinks                  []LinkClicks                           `json:"top_links"`
+}
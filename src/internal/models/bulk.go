@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type BulkImportStatus string
+
+const (
+	BulkImportPending   BulkImportStatus = "pending"
+	BulkImportRunning   BulkImportStatus = "running"
+	BulkImportCompleted BulkImportStatus = "completed"
+	BulkImportFailed    BulkImportStatus = "failed"
+)
+
+// BulkRowResult reports what happened to one input row. Rows are numbered
+// from 1 in input order.
+type BulkRowResult struct {
+	Row       int               `json:"row"`
+	Created   bool              `json:"created"`
+	ID        string            `json:"id,omitempty"`
+	ShortCode string            `json:"short_code,omitempty"`
+	ShortURL  string            `json:"short_url,omitempty"`
+	Error     string            `json:"error,omitempty"`
+	Fields    map[string]string `json:"fields,omitempty"`
+}
+
+type BulkCreateResult struct {
+	Total   int             `json:"total"`
+	Created int             `json:"created"`
+	Failed  int             `json:"failed"`
+	Results []BulkRowResult `json:"results"`
+}
+
+// BulkImportJob is a bulk create that runs in the background. The input rows
+// and the results are stored as JSON.
+type BulkImportJob struct {
+	ID          string           `json:"id" gorm:"primaryKey;type:varchar(20)"`
+	UserID      string           `json:"user_id" gorm:"type:varchar(20);index"`
+	Status      BulkImportStatus `json:"status" gorm:"type:varchar(20)"`
+	Partial     bool             `json:"partial"`
+	Total       int              `json:"total"`
+	Created     int              `json:"created"`
+	Failed      int              `json:"failed"`
+	Error       string           `json:"error,omitempty"`
+	Payload     string           `json:"-"`
+	ResultsJSON string           `json:"-" gorm:"column:results"`
+	Results     []BulkRowResult  `json:"results,omitempty" gorm:"-"`
+	CreatedAt   time.Time        `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time        `json:"updated_at" gorm:"autoUpdateTime"`
+	CompletedAt *time.Time       `json:"completed_at,omitempty"`
+}
+
+func (j *BulkImportJob) BeforeCreate(tx *gorm.DB) error {
+	id, err := urlSid.Generate()
+	if err != nil {
+		return err
+	}
+	j.ID = id
+	return nil
+}
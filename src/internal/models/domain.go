@@ -0,0 +1,93 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// domainVerificationPrefix starts the value of a domain's TXT record
+const domainVerificationPrefix = "brevity-verification="
+
+// Domain is a hostname a user serves short links from. It must be verified
+// by publishing VerificationToken in a DNS TXT record before links can use it.
+type Domain struct {
+	ID                string     `json:"id" gorm:"primaryKey;type:varchar(20)"`
+	UserID            string     `json:"user_id" gorm:"type:varchar(20);index"`
+	Hostname          string     `json:"hostname" gorm:"type:varchar(253);not null"`
+	VerificationToken string     `json:"-" gorm:"type:varchar(64);not null"`
+	VerifiedAt        *time.Time `json:"verified_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt         time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (d *Domain) BeforeCreate(tx *gorm.DB) error {
+	id, err := urlSid.Generate()
+	if err != nil {
+		return err
+	}
+	d.ID = id
+	return nil
+}
+
+type CreateDomainRequest struct {
+	Hostname string `json:"hostname" validate:"required,fqdn,max=253"`
+}
+
+// DomainVerification is the DNS record that proves control of a domain
+type DomainVerification struct {
+	Type  string `json:"type"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type DomainResponse struct {
+	ID           string              `json:"id"`
+	Hostname     string              `json:"hostname"`
+	Verified     bool                `json:"verified"`
+	VerifiedAt   *time.Time          `json:"verified_at,omitempty"`
+	Verification *DomainVerification `json:"verification,omitempty"`
+	CreatedAt    time.Time           `json:"created_at"`
+}
+
+func (r *CreateDomainRequest) Validate() error {
+	return validate.Struct(r)
+}
+
+// NormalizeHostname lowercases a hostname and drops a trailing dot
+func NormalizeHostname(hostname string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(hostname)), ".")
+}
+
+// IsVerified reports whether the domain's DNS record has been checked
+func (d *Domain) IsVerified() bool {
+	return d.VerifiedAt != nil
+}
+
+// VerificationRecord returns the TXT record to publish, under a name made of
+// recordPrefix and the hostname
+func (d *Domain) VerificationRecord(recordPrefix string) DomainVerification {
+	return DomainVerification{
+		Type:  "TXT",
+		Name:  recordPrefix + "." + d.Hostname,
+		Value: domainVerificationPrefix + d.VerificationToken,
+	}
+}
+
+// ToResponse describes the domain. The verification record is included
+// until the domain is verified.
+func (d *Domain) ToResponse(recordPrefix string) *DomainResponse {
+	resp := &DomainResponse{
+		ID:         d.ID,
+		Hostname:   d.Hostname,
+		Verified:   d.IsVerified(),
+		VerifiedAt: d.VerifiedAt,
+		CreatedAt:  d.CreatedAt,
+	}
+	if !d.IsVerified() {
+		record := d.VerificationRecord(recordPrefix)
+		resp.Verification = &record
+	}
+	return resp
+}
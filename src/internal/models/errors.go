@@ -1,25 +1,87 @@
 package models
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
-	ErrInvalidEmail          = errors.New("invalid email format")
-	ErrEmailAlreadyExists    = errors.New("email already exists")
-	ErrUsernameAlreadyExists = errors.New("username already exists")
-	ErrInvalidCredentials    = errors.New("invalid credentials")
-	ErrUserNotFound          = errors.New("user not found")
-	ErrInvalidInput          = errors.New("invalid input")
-	ErrInvalidToken          = errors.New("invalid token")
-	ErrExpiredToken          = errors.New("token has expired")
-	ErrUnauthorized          = errors.New("unauthorized access")
-	ErrForbidden             = errors.New("forbidden access")
-	ErrTokenGenerationFailed = errors.New("failed to generate token")
-	ErrAccountNotVerified    = errors.New("account not verified")
-	ErrPasswordTooWeak       = errors.New("password is too weak")
-	ErrPasswordMismatch      = errors.New("passwords do not match")
-	ErrAvatarUploadFailed    = errors.New("failed to upload avatar")
+	ErrInvalidEmail            = errors.New("invalid email format")
+	ErrEmailAlreadyExists      = errors.New("email already exists")
+	ErrUsernameAlreadyExists   = errors.New("username already exists")
+	ErrInvalidCredentials      = errors.New("invalid credentials")
+	ErrUserNotFound            = errors.New("user not found")
+	ErrInvalidInput            = errors.New("invalid input")
+	ErrInvalidToken            = errors.New("invalid token")
+	ErrExpiredToken            = errors.New("token has expired")
+	ErrUnauthorized            = errors.New("unauthorized access")
+	ErrForbidden               = errors.New("forbidden access")
+	ErrTokenGenerationFailed   = errors.New("failed to generate token")
+	ErrAccountNotVerified      = errors.New("account not verified")
+	ErrPasswordTooWeak         = errors.New("password is too weak")
+	ErrPasswordMismatch        = errors.New("passwords do not match")
+	ErrAvatarUploadFailed      = errors.New("failed to upload avatar")
+	ErrURLNotFound             = errors.New("url not found")
+	ErrShortCodeExists         = errors.New("short code already exists")
+	ErrShortCodeGeneration     = errors.New("failed to generate unique short code")
+	ErrShortCodeReserved       = errors.New("short code is reserved")
+	ErrInvalidExpiry           = errors.New("expiry must be in the future")
+	ErrURLExpired              = errors.New("url has expired")
+	ErrURLInactive             = errors.New("url is inactive")
+	ErrURLNotYetActive         = errors.New("url is not active yet")
+	ErrInvalidActivationWindow = errors.New("activation must be before expiry")
+	ErrInvalidLinkPassword     = errors.New("invalid link password")
+	ErrInvalidRedirectRule     = errors.New("invalid redirect rule")
+	ErrInvalidVariant          = errors.New("invalid url variant")
+	ErrURLExhausted            = errors.New("url has reached its click limit")
+	ErrInvalidAnalyticsRange   = errors.New("invalid analytics range")
+	ErrBulkImportNotFound      = errors.New("bulk import job not found")
+	ErrDomainNotFound          = errors.New("domain not found")
+	ErrDomainExists            = errors.New("domain already registered")
+	ErrDomainReserved          = errors.New("domain is reserved")
+	ErrDomainTaken             = errors.New("domain is verified by another account")
+	ErrDomainNotVerified       = errors.New("domain is not verified")
+	ErrDomainVerification      = errors.New("domain verification record not found")
+	ErrDomainInUse             = errors.New("domain still has short urls")
+	ErrDomainLimit             = errors.New("domain limit reached")
+	ErrInvalidQROptions        = errors.New("invalid qr code options")
+	ErrInvalidQRLogo           = errors.New("invalid qr code logo")
+	ErrUnsafeURL               = errors.New("destination url is not allowed")
+	ErrURLFlagged              = errors.New("url was disabled as unsafe")
+	ErrInvalidBlocklistEntry   = errors.New("invalid blocklist entry")
+	ErrBlocklistEntryExists    = errors.New("blocklist entry already exists")
+	ErrBlocklistEntryNotFound  = errors.New("blocklist entry not found")
+	ErrLinkFlagNotFound        = errors.New("link flag not found")
+	ErrLinkFlagReviewed        = errors.New("link flag was already reviewed")
+	ErrInvalidTag              = errors.New("invalid tag")
+	ErrTagNotFound             = errors.New("tag not found")
+	ErrCampaignNotFound        = errors.New("campaign not found")
+	ErrCampaignExists          = errors.New("campaign already exists")
+	ErrInvalidSearchQuery      = errors.New("invalid search query")
 )
 
+// InvalidItemError reports which entry of a list field, such as a URL's
+// redirect rules, failed validation. Items are numbered from 1.
+type InvalidItemError struct {
+	List   string
+	Item   int
+	Reason string
+	Err    error
+}
+
+func (e *InvalidItemError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field(), e.Reason)
+}
+
+func (e *InvalidItemError) Unwrap() error {
+	return e.Err
+}
+
+// Field names the offending item the way validation errors name fields
+func (e *InvalidItemError) Field() string {
+	return fmt.Sprintf("%s[%d]", e.List, e.Item)
+}
+
 // package models
 
 // import "errors"
@@ -48,4 +110,4 @@ var (
 // 	ErrBadGateway              = errors.New("bad gateway")
 // 	ErrGatewayTimeout          = errors.New("gateway timeout")
 // 	ErrHTTPVersionNotSupported = errors.New("http version not supported")
-// )
\ No newline at end of file
+// )
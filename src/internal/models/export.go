@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+type ExportFormat string
+
+const (
+	ExportCSV    ExportFormat = "csv"
+	ExportJSON   ExportFormat = "json"
+	ExportNDJSON ExportFormat = "ndjson"
+)
+
+// IsValid reports whether the format is supported
+func (f ExportFormat) IsValid() bool {
+	switch f {
+	case ExportCSV, ExportJSON, ExportNDJSON:
+		return true
+	}
+	return false
+}
+
+// URLExportFilter narrows a link export. Nil fields are not applied.
+type URLExportFilter struct {
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	IsActive    *bool
+	// Tags are normalized tag names a link must all carry
+	Tags []string
+}
+
+// ClickExportFilter narrows a click export to [From, To)
+type ClickExportFilter struct {
+	From *time.Time
+	To   *time.Time
+}
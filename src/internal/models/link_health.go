@@ -0,0 +1,28 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// LinkHealth is the result of the latest check of a link's destination.
+// Failures counts checks failed in a row; a link is broken once it reaches
+// the configured threshold.
+type LinkHealth struct {
+	StatusCode   int        `json:"status_code,omitempty" gorm:"column:health_status_code;not null;default:0"`
+	LatencyMS    int64      `json:"latency_ms" gorm:"column:health_latency_ms;not null;default:0"`
+	Error        string     `json:"error,omitempty" gorm:"column:health_error;type:varchar(255);not null;default:''"`
+	CheckedAt    *time.Time `json:"checked_at,omitempty" gorm:"column:health_checked_at"`
+	Failures     int        `json:"failures" gorm:"column:health_failures;not null;default:0;index"`
+	FailingSince *time.Time `json:"failing_since,omitempty" gorm:"column:health_failing_since"`
+	Reported     bool       `json:"-" gorm:"column:health_reported;not null;default:false"`
+	NextCheckAt  *time.Time `json:"-" gorm:"column:health_next_check_at;index"`
+}
+
+// Problem describes why the latest check failed, e.g. "HTTP 404"
+func (h LinkHealth) Problem() string {
+	if h.StatusCode != 0 {
+		return fmt.Sprintf("HTTP %d", h.StatusCode)
+	}
+	return h.Error
+}
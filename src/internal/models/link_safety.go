@@ -0,0 +1,160 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Blocklist entry kinds
+const (
+	BlocklistDomain  = "domain"
+	BlocklistRegex   = "regex"
+	BlocklistIPRange = "ip_range"
+)
+
+// Safety states of a URL. Links are unchecked until a blocklist or threat
+// feed change makes the scanner flag them.
+const (
+	// URLSafetyFlagged links matched a blocklist and are disabled until an
+	// admin reviews them
+	URLSafetyFlagged = "flagged"
+	// URLSafetyBlocked links were confirmed unsafe and stay disabled
+	URLSafetyBlocked = "blocked"
+	// URLSafetyApproved links were cleared by an admin and are not flagged
+	// again
+	URLSafetyApproved = "approved"
+)
+
+// Review states of a link flag
+const (
+	LinkFlagPending  = "pending"
+	LinkFlagApproved = "approved"
+	LinkFlagBlocked  = "blocked"
+)
+
+// BlocklistEntry is an admin managed rule destinations are checked against.
+// Domains match themselves and their subdomains, regular expressions the
+// whole destination url and IP ranges hosts that are or resolve to an
+// address in the range.
+type BlocklistEntry struct {
+	ID        string    `json:"id" gorm:"primaryKey;type:varchar(20)"`
+	Kind      string    `json:"kind" gorm:"type:varchar(20);not null;uniqueIndex:idx_blocklist_kind_pattern"`
+	Pattern   string    `json:"pattern" gorm:"type:varchar(512);not null;uniqueIndex:idx_blocklist_kind_pattern"`
+	Reason    string    `json:"reason,omitempty" gorm:"type:varchar(255);not null;default:''"`
+	CreatedBy string    `json:"created_by" gorm:"type:varchar(20)"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (e *BlocklistEntry) BeforeCreate(tx *gorm.DB) error {
+	id, err := urlSid.Generate()
+	if err != nil {
+		return err
+	}
+	e.ID = id
+	return nil
+}
+
+type CreateBlocklistEntryRequest struct {
+	Kind    string `json:"kind" validate:"required,oneof=domain regex ip_range"`
+	Pattern string `json:"pattern" validate:"required,max=512"`
+	Reason  string `json:"reason" validate:"max=255"`
+}
+
+func (r *CreateBlocklistEntryRequest) Validate() error {
+	return validate.Struct(r)
+}
+
+// LinkFlag records a link the safety scanner disabled, and the admin review
+// of it
+type LinkFlag struct {
+	ID          string     `json:"id" gorm:"primaryKey;type:varchar(20)"`
+	URLID       string     `json:"url_id" gorm:"type:varchar(20);index"`
+	URL         *URL       `json:"-" validate:"-" gorm:"foreignKey:URLID"`
+	Destination string     `json:"destination" gorm:"not null"`
+	Source      string     `json:"source" gorm:"type:varchar(20);not null"`
+	Reason      string     `json:"reason" gorm:"not null"`
+	EntryID     string     `json:"entry_id,omitempty" gorm:"type:varchar(20);not null;default:''"`
+	Status      string     `json:"status" gorm:"type:varchar(20);not null;default:'pending';index"`
+	ReviewedBy  string     `json:"reviewed_by,omitempty" gorm:"type:varchar(20);not null;default:''"`
+	ReviewNote  string     `json:"review_note,omitempty" gorm:"type:varchar(255);not null;default:''"`
+	ReviewedAt  *time.Time `json:"reviewed_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (f *LinkFlag) BeforeCreate(tx *gorm.DB) error {
+	id, err := urlSid.Generate()
+	if err != nil {
+		return err
+	}
+	f.ID = id
+	return nil
+}
+
+// ReviewLinkFlagRequest settles a flag. Approving re-enables the link,
+// blocking keeps it disabled for good.
+type ReviewLinkFlagRequest struct {
+	Action string `json:"action" validate:"required,oneof=approve block"`
+	Note   string `json:"note" validate:"max=255"`
+}
+
+func (r *ReviewLinkFlagRequest) Validate() error {
+	return validate.Struct(r)
+}
+
+type LinkFlagResponse struct {
+	ID          string       `json:"id"`
+	URL         *URLResponse `json:"url,omitempty"`
+	Destination string       `json:"destination"`
+	Source      string       `json:"source"`
+	Reason      string       `json:"reason"`
+	EntryID     string       `json:"entry_id,omitempty"`
+	Status      string       `json:"status"`
+	ReviewedBy  string       `json:"reviewed_by,omitempty"`
+	ReviewNote  string       `json:"review_note,omitempty"`
+	ReviewedAt  *time.Time   `json:"reviewed_at,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+}
+
+// ToResponse describes the flag. URL must be loaded for the response to
+// include the link.
+func (f *LinkFlag) ToResponse(baseURL string) *LinkFlagResponse {
+	var url *URLResponse
+	if f.URL != nil {
+		url = f.URL.ToResponse(baseURL)
+	}
+	return &LinkFlagResponse{
+		ID:          f.ID,
+		URL:         url,
+		Destination: f.Destination,
+		Source:      f.Source,
+		Reason:      f.Reason,
+		EntryID:     f.EntryID,
+		Status:      f.Status,
+		ReviewedBy:  f.ReviewedBy,
+		ReviewNote:  f.ReviewNote,
+		ReviewedAt:  f.ReviewedAt,
+		CreatedAt:   f.CreatedAt,
+	}
+}
+
+// Destinations lists every url a link can send visitors to: the original
+// url, the fallback and the targets of its rules and variants
+func (u *URL) Destinations() []string {
+	destinations := []string{u.OriginalURL}
+	if u.FallbackURL != "" {
+		destinations = append(destinations, u.FallbackURL)
+	}
+	for _, rule := range u.Rules {
+		destinations = append(destinations, rule.TargetURL)
+	}
+	for _, v := range u.Variants {
+		destinations = append(destinations, v.TargetURL)
+	}
+	return destinations
+}
+
+// IsDisabledForSafety reports whether the safety scanner took the link down
+func (u *URL) IsDisabledForSafety() bool {
+	return u.SafetyStatus == URLSafetyFlagged || u.SafetyStatus == URLSafetyBlocked
+}
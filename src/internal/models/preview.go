@@ -0,0 +1,28 @@
+package models
+
+// LinkPreview is metadata read from a link's destination page when the link
+// is created. Title and description go to the URL's own fields when the
+// owner left them empty.
+type LinkPreview struct {
+	SiteName   string `json:"site_name,omitempty" gorm:"column:preview_site_name;not null;default:''"`
+	ImageURL   string `json:"image_url,omitempty" gorm:"column:preview_image_url;not null;default:''"`
+	FaviconURL string `json:"favicon_url,omitempty" gorm:"column:preview_favicon_url;not null;default:''"`
+}
+
+// IsZero reports whether nothing was found
+func (p LinkPreview) IsZero() bool {
+	return p == LinkPreview{}
+}
+
+// OGOverrides replace the tags on the page link preview crawlers are served.
+// Empty fields fall back to the URL's title, description and preview image.
+type OGOverrides struct {
+	Title       string `json:"title,omitempty" validate:"max=100" gorm:"column:og_title;not null;default:''"`
+	Description string `json:"description,omitempty" validate:"max=300" gorm:"column:og_description;not null;default:''"`
+	ImageURL    string `json:"image_url,omitempty" validate:"omitempty,http_url,max=2048" gorm:"column:og_image_url;not null;default:''"`
+}
+
+// IsZero reports whether no override is set
+func (o OGOverrides) IsZero() bool {
+	return o == OGOverrides{}
+}
@@ -0,0 +1,39 @@
+package models
+
+// QR code image formats
+const (
+	QRFormatPNG = "png"
+	QRFormatSVG = "svg"
+)
+
+// QR code rendering limits. The margin is the quiet zone around the symbol,
+// in modules; scanners expect at least 4.
+const (
+	DefaultQRMargin = 4
+	MaxQRMargin     = 16
+)
+
+// QROptions are the rendering parameters of a link's QR code
+type QROptions struct {
+	Format     string
+	Size       int
+	Margin     int
+	Foreground string
+	Background string
+	// ECC is the error correction level L, M, Q or H. Empty picks M, or H
+	// when a logo is drawn.
+	ECC  string
+	Logo bool
+}
+
+// QRImage is a rendered QR code
+type QRImage struct {
+	ContentType string
+	Data        []byte
+	// ETag identifies the rendering parameters and content
+	ETag string
+}
+
+type QRLogoResponse struct {
+	QRLogoURL string `json:"qr_logo_url"`
+}
@@ -0,0 +1,215 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	neturl "net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	// Rule time windows name IANA zones, which must resolve even on hosts
+	// without a zoneinfo database
+	_ "time/tzdata"
+)
+
+// RuleDevices are the device classes a rule can match on. They mirror the
+// classes reported by the User-Agent parser.
+var RuleDevices = []string{"desktop", "mobile", "tablet", "bot"}
+
+var ruleDays = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+var (
+	countryPattern  = regexp.MustCompile(`^[A-Za-z]{2}$`)
+	languagePattern = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+)
+
+// RedirectRule sends visitors matching every condition in Match to TargetURL.
+// A URL's rules are evaluated in order and the first match wins.
+type RedirectRule struct {
+	Match     RuleConditions `json:"match"`
+	TargetURL string         `json:"target_url"`
+}
+
+// RuleConditions are ANDed together; the values listed for one condition are
+// alternatives. Empty conditions match every visitor.
+type RuleConditions struct {
+	Devices   []string          `json:"devices,omitempty"`
+	OS        []string          `json:"os,omitempty"`
+	Countries []string          `json:"countries,omitempty"`
+	Languages []string          `json:"languages,omitempty"`
+	Time      *RuleTimeWindow   `json:"time,omitempty"`
+	Query     map[string]string `json:"query,omitempty"`
+}
+
+// RuleTimeWindow matches a time of day, optionally on certain weekdays, in
+// the given IANA timezone (UTC when empty). End before Start wraps past
+// midnight.
+type RuleTimeWindow struct {
+	Start    string   `json:"start"`
+	End      string   `json:"end"`
+	Timezone string   `json:"timezone,omitempty"`
+	Days     []string `json:"days,omitempty"`
+}
+
+// Visitor is what redirect rules are matched against
+type Visitor struct {
+	Device   string
+	OS       string
+	Country  string
+	Language string
+	Query    neturl.Values
+	Time     time.Time
+}
+
+// MatchingRule returns the first rule matching v, or nil when none does
+func (u *URL) MatchingRule(v *Visitor) *RedirectRule {
+	for i := range u.Rules {
+		if u.Rules[i].Match.Matches(v) {
+			return &u.Rules[i]
+		}
+	}
+	return nil
+}
+
+// Matches reports whether v satisfies every condition
+func (c *RuleConditions) Matches(v *Visitor) bool {
+	if len(c.Devices) > 0 && !containsFold(c.Devices, v.Device) {
+		return false
+	}
+	if len(c.OS) > 0 && !containsFold(c.OS, v.OS) {
+		return false
+	}
+	if len(c.Countries) > 0 && !containsFold(c.Countries, v.Country) {
+		return false
+	}
+	if len(c.Languages) > 0 && !matchesLanguage(c.Languages, v.Language) {
+		return false
+	}
+	if c.Time != nil && !c.Time.Contains(v.Time) {
+		return false
+	}
+	for key, want := range c.Query {
+		values, ok := v.Query[key]
+		if !ok || (want != "" && !slices.Contains(values, want)) {
+			return false
+		}
+	}
+	return true
+}
+
+// Contains reports whether t falls inside the window
+func (w *RuleTimeWindow) Contains(t time.Time) bool {
+	loc, err := time.LoadLocation(w.Timezone)
+	if err != nil {
+		return false
+	}
+	start, _ := parseClock(w.Start)
+	end, _ := parseClock(w.End)
+
+	local := t.In(loc)
+	if len(w.Days) > 0 && !containsFold(w.Days, ruleDays[local.Weekday()]) {
+		return false
+	}
+
+	now := local.Hour()*60 + local.Minute()
+	if start < end {
+		return now >= start && now < end
+	}
+	return now >= start || now < end
+}
+
+// Validate checks the rule's target and conditions
+func (r *RedirectRule) Validate() error {
+	target, err := neturl.Parse(r.TargetURL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return errors.New("target_url must be an absolute http or https url")
+	}
+
+	c := r.Match
+	for _, device := range c.Devices {
+		if !containsFold(RuleDevices, device) {
+			return fmt.Errorf("unknown device %q, expected one of %s", device, strings.Join(RuleDevices, ", "))
+		}
+	}
+	for _, os := range c.OS {
+		if strings.TrimSpace(os) == "" {
+			return errors.New("os values must not be empty")
+		}
+	}
+	for _, country := range c.Countries {
+		if !countryPattern.MatchString(country) {
+			return fmt.Errorf("country %q must be a two letter ISO code", country)
+		}
+	}
+	for _, language := range c.Languages {
+		if !languagePattern.MatchString(language) {
+			return fmt.Errorf("language %q must be a language tag such as de or pt-BR", language)
+		}
+	}
+	if c.Time != nil {
+		if err := c.Time.validate(); err != nil {
+			return err
+		}
+	}
+	for key := range c.Query {
+		if key == "" {
+			return errors.New("query parameter names must not be empty")
+		}
+	}
+	return nil
+}
+
+func (w *RuleTimeWindow) validate() error {
+	start, err := parseClock(w.Start)
+	if err != nil {
+		return fmt.Errorf("time start: %w", err)
+	}
+	end, err := parseClock(w.End)
+	if err != nil {
+		return fmt.Errorf("time end: %w", err)
+	}
+	if start == end {
+		return errors.New("time start and end must differ")
+	}
+	if _, err := time.LoadLocation(w.Timezone); err != nil {
+		return fmt.Errorf("unknown timezone %q", w.Timezone)
+	}
+	for _, day := range w.Days {
+		if !containsFold(ruleDays, day) {
+			return fmt.Errorf("unknown day %q, expected one of %s", day, strings.Join(ruleDays, ", "))
+		}
+	}
+	return nil
+}
+
+// parseClock turns "HH:MM" into minutes since midnight
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("%q must be HH:MM", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// matchesLanguage reports whether the visitor's language is one of the rule
+// languages or a more specific form of one, so "de" matches "de-AT"
+func matchesLanguage(languages []string, language string) bool {
+	for _, l := range languages {
+		if strings.EqualFold(l, language) ||
+			(len(language) > len(l) && language[len(l)] == '-' && strings.EqualFold(l, language[:len(l)])) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
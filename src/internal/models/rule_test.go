@@ -0,0 +1,163 @@
+package models
+
+import (
+	neturl "net/url"
+	"testing"
+	"time"
+)
+
+func TestRuleTimeWindowContains(t *testing.T) {
+	// 2026-06-01 is a Monday
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 6, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name   string
+		window RuleTimeWindow
+		t      time.Time
+		want   bool
+	}{
+		{name: "inside", window: RuleTimeWindow{Start: "09:00", End: "17:00"}, t: at(1, 12, 0), want: true},
+		{name: "start is inclusive", window: RuleTimeWindow{Start: "09:00", End: "17:00"}, t: at(1, 9, 0), want: true},
+		{name: "end is exclusive", window: RuleTimeWindow{Start: "09:00", End: "17:00"}, t: at(1, 17, 0)},
+		{name: "before", window: RuleTimeWindow{Start: "09:00", End: "17:00"}, t: at(1, 8, 59)},
+
+		// End before start wraps past midnight
+		{name: "wrap before midnight", window: RuleTimeWindow{Start: "22:00", End: "06:00"}, t: at(1, 23, 30), want: true},
+		{name: "wrap at midnight", window: RuleTimeWindow{Start: "22:00", End: "06:00"}, t: at(2, 0, 0), want: true},
+		{name: "wrap after midnight", window: RuleTimeWindow{Start: "22:00", End: "06:00"}, t: at(2, 5, 59), want: true},
+		{name: "wrap end", window: RuleTimeWindow{Start: "22:00", End: "06:00"}, t: at(2, 6, 0)},
+		{name: "wrap midday", window: RuleTimeWindow{Start: "22:00", End: "06:00"}, t: at(2, 12, 0)},
+		{name: "until midnight", window: RuleTimeWindow{Start: "18:00", End: "00:00"}, t: at(1, 23, 59), want: true},
+		{name: "from midnight", window: RuleTimeWindow{Start: "00:00", End: "01:00"}, t: at(1, 0, 30), want: true},
+
+		// The hour is taken in the window's timezone, 14:00 UTC is 10:00 in
+		// New York during daylight saving time and 23:00 in Tokyo
+		{name: "timezone inside", window: RuleTimeWindow{Start: "10:00", End: "11:00", Timezone: "America/New_York"}, t: at(1, 14, 0), want: true},
+		{name: "timezone outside", window: RuleTimeWindow{Start: "13:00", End: "15:00", Timezone: "America/New_York"}, t: at(1, 14, 0)},
+		{name: "timezone wrap", window: RuleTimeWindow{Start: "22:00", End: "02:00", Timezone: "Asia/Tokyo"}, t: at(1, 14, 0), want: true},
+		// and 09:00 there in winter
+		{name: "standard time", window: RuleTimeWindow{Start: "10:00", End: "11:00", Timezone: "America/New_York"}, t: time.Date(2026, 1, 5, 14, 0, 0, 0, time.UTC)},
+
+		// Days are weekdays in the window's timezone. 02:00 UTC on Monday is
+		// still Sunday evening in New York and already Monday in Tokyo.
+		{name: "weekday in utc", window: RuleTimeWindow{Start: "00:00", End: "23:59", Days: []string{"mon"}}, t: at(1, 2, 0), want: true},
+		{name: "weekday behind utc", window: RuleTimeWindow{Start: "00:00", End: "23:59", Timezone: "America/New_York", Days: []string{"sun"}}, t: at(1, 2, 0), want: true},
+		{name: "weekday behind utc excluded", window: RuleTimeWindow{Start: "00:00", End: "23:59", Timezone: "America/New_York", Days: []string{"mon"}}, t: at(1, 2, 0)},
+		{name: "weekday ahead of utc", window: RuleTimeWindow{Start: "00:00", End: "23:59", Timezone: "Asia/Tokyo", Days: []string{"Tue"}}, t: at(1, 16, 0), want: true},
+		{name: "weekday list", window: RuleTimeWindow{Start: "00:00", End: "23:59", Timezone: "Asia/Tokyo", Days: []string{"sat", "sun"}}, t: at(1, 16, 0)},
+
+		// A wrapping window's days are checked against the visitor's local
+		// day, so the early hours belong to the following day
+		{name: "wrap on the start day", window: RuleTimeWindow{Start: "22:00", End: "02:00", Days: []string{"fri"}}, t: at(5, 23, 0), want: true},
+		{name: "wrap into the next day", window: RuleTimeWindow{Start: "22:00", End: "02:00", Days: []string{"fri"}}, t: at(6, 1, 0)},
+
+		{name: "unknown timezone", window: RuleTimeWindow{Start: "00:00", End: "23:59", Timezone: "Mars/Olympus"}, t: at(1, 12, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.window.Contains(tt.t); got != tt.want {
+				t.Errorf("Contains(%s) = %v, want %v", tt.t.Format(time.RFC3339), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuleConditionsMatches(t *testing.T) {
+	visitor := &Visitor{
+		Device:   "mobile",
+		OS:       "iOS",
+		Country:  "DE",
+		Language: "de-AT",
+		Query:    neturl.Values{"ref": {"a", "b"}, "flag": {""}},
+		// A Monday
+		Time: time.Date(2026, 6, 1, 10, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name string
+		cond RuleConditions
+		want bool
+	}{
+		{name: "empty", cond: RuleConditions{}, want: true},
+		{name: "device", cond: RuleConditions{Devices: []string{"desktop", "Mobile"}}, want: true},
+		{name: "other device", cond: RuleConditions{Devices: []string{"desktop", "tablet"}}},
+		{name: "os", cond: RuleConditions{OS: []string{"ios"}}, want: true},
+		{name: "country", cond: RuleConditions{Countries: []string{"at", "de"}}, want: true},
+		{name: "other country", cond: RuleConditions{Countries: []string{"US"}}},
+
+		// A rule language matches the visitor's and more specific forms of it
+		{name: "language prefix", cond: RuleConditions{Languages: []string{"de"}}, want: true},
+		{name: "language exact", cond: RuleConditions{Languages: []string{"DE-at"}}, want: true},
+		{name: "other region", cond: RuleConditions{Languages: []string{"de-DE"}}},
+		{name: "prefix without a separator", cond: RuleConditions{Languages: []string{"d"}}},
+		{name: "other language", cond: RuleConditions{Languages: []string{"en", "fr"}}},
+
+		{name: "time", cond: RuleConditions{Time: &RuleTimeWindow{Start: "09:00", End: "11:00", Days: []string{"mon"}}}, want: true},
+		{name: "other time", cond: RuleConditions{Time: &RuleTimeWindow{Start: "11:00", End: "09:00"}}},
+		{name: "query value", cond: RuleConditions{Query: map[string]string{"ref": "b"}}, want: true},
+		{name: "query present", cond: RuleConditions{Query: map[string]string{"flag": ""}}, want: true},
+		{name: "query other value", cond: RuleConditions{Query: map[string]string{"ref": "c"}}},
+		{name: "query missing", cond: RuleConditions{Query: map[string]string{"src": ""}}},
+
+		// Conditions are ANDed
+		{name: "all", cond: RuleConditions{Devices: []string{"mobile"}, Countries: []string{"DE"}, Languages: []string{"de"}}, want: true},
+		{name: "one fails", cond: RuleConditions{Devices: []string{"mobile"}, Countries: []string{"DE"}, Languages: []string{"en"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cond.Matches(visitor); got != tt.want {
+				t.Errorf("Matches = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchesLanguage(t *testing.T) {
+	tests := []struct {
+		languages []string
+		language  string
+		want      bool
+	}{
+		{languages: []string{"pt"}, language: "pt-BR", want: true},
+		{languages: []string{"pt-BR"}, language: "pt-br", want: true},
+		{languages: []string{"zh-Hant"}, language: "zh-Hant-TW", want: true},
+		{languages: []string{"zh-Hant"}, language: "zh-Hans-CN"},
+		{languages: []string{"pt-BR"}, language: "pt"},
+		{languages: []string{"en"}, language: "eng"},
+		{languages: []string{"en"}, language: ""},
+	}
+	for _, tt := range tests {
+		if got := matchesLanguage(tt.languages, tt.language); got != tt.want {
+			t.Errorf("matchesLanguage(%v, %q) = %v, want %v", tt.languages, tt.language, got, tt.want)
+		}
+	}
+}
+
+func TestMatchingRule(t *testing.T) {
+	u := &URL{Rules: []RedirectRule{
+		{Match: RuleConditions{Countries: []string{"US"}}, TargetURL: "https://example.com/us"},
+		{Match: RuleConditions{Languages: []string{"de"}}, TargetURL: "https://example.com/de"},
+		{Match: RuleConditions{Devices: []string{"mobile"}}, TargetURL: "https://example.com/m"},
+	}}
+
+	tests := []struct {
+		visitor Visitor
+		want    string
+	}{
+		{visitor: Visitor{Country: "US", Language: "de", Device: "mobile"}, want: "https://example.com/us"},
+		{visitor: Visitor{Country: "AT", Language: "de-AT", Device: "mobile"}, want: "https://example.com/de"},
+		{visitor: Visitor{Country: "FR", Language: "fr", Device: "mobile"}, want: "https://example.com/m"},
+		{visitor: Visitor{Country: "FR", Language: "fr", Device: "desktop"}},
+	}
+	for _, tt := range tests {
+		rule := u.MatchingRule(&tt.visitor)
+		switch {
+		case tt.want == "" && rule != nil:
+			t.Errorf("MatchingRule(%+v) = %s, want none", tt.visitor, rule.TargetURL)
+		case tt.want != "" && (rule == nil || rule.TargetURL != tt.want):
+			t.Errorf("MatchingRule(%+v) = %v, want %s", tt.visitor, rule, tt.want)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type TransitionReason string
+
+const (
+	TransitionActivated TransitionReason = "activated"
+	TransitionExpired   TransitionReason = "expired"
+)
+
+// URLTransition records an is_active flip made by the link scheduler.
+// ScheduledAt is when the flip was due, CreatedAt when it was applied.
+type URLTransition struct {
+	ID          string           `json:"id" gorm:"primaryKey;type:varchar(20)"`
+	URLID       string           `json:"url_id" gorm:"type:varchar(20);index"`
+	IsActive    bool             `json:"is_active"`
+	Reason      TransitionReason `json:"reason" gorm:"type:varchar(20)"`
+	ScheduledAt time.Time        `json:"scheduled_at"`
+	CreatedAt   time.Time        `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (t *URLTransition) BeforeCreate(tx *gorm.DB) error {
+	id, err := urlSid.Generate()
+	if err != nil {
+		return err
+	}
+	t.ID = id
+	return nil
+}
@@ -0,0 +1,92 @@
+package models
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	maxSearchQuery = 200
+	maxSearchTerms = 10
+
+	// HighlightStart and HighlightEnd surround matched terms in the text the
+	// search index returns. Being control characters they survive HTML
+	// escaping, so the markup is only added after the text is escaped.
+	HighlightStart = "\x02"
+	HighlightEnd   = "\x03"
+)
+
+// URLSearchHit is a link matching a search with the matched terms of its
+// fields highlighted
+type URLSearchHit struct {
+	URL        URL
+	Score      float64
+	Highlights URLHighlights
+}
+
+// URLHighlights holds the searched fields of a link with the matched terms
+// wrapped in <mark> tags. The rest of the text is HTML escaped. Long
+// descriptions are cut down to the part around the match.
+type URLHighlights struct {
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	OriginalURL string `json:"original_url"`
+	ShortCode   string `json:"short_code"`
+}
+
+type URLSearchResponse struct {
+	*URLResponse
+	// Score ranks the hits, higher is a better match
+	Score      float64       `json:"score"`
+	Highlights URLHighlights `json:"highlights"`
+}
+
+func (h *URLSearchHit) ToResponse(baseURL string) *URLSearchResponse {
+	return &URLSearchResponse{
+		URLResponse: h.URL.ToResponse(baseURL),
+		Score:       h.Score,
+		Highlights:  h.Highlights,
+	}
+}
+
+// MarkHighlights escapes text returned by the search index and turns its
+// highlight markers into <mark> tags
+func MarkHighlights(text string) string {
+	text = html.EscapeString(text)
+	text = strings.ReplaceAll(text, HighlightStart, "<mark>")
+	return strings.ReplaceAll(text, HighlightEnd, "</mark>")
+}
+
+// SearchMatch turns a user's search into an FTS5 match expression. Every
+// word must match, either exactly or as the start of a longer one, so
+// "exam" finds links to example.com. Punctuation only splits words and FTS5
+// operators are not interpreted.
+func SearchMatch(query string) (string, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return "", fmt.Errorf("%w: q is required", ErrInvalidSearchQuery)
+	}
+	if utf8.RuneCountInString(query) > maxSearchQuery {
+		return "", fmt.Errorf("%w: q is longer than %d characters", ErrInvalidSearchQuery, maxSearchQuery)
+	}
+
+	terms := make([]string, 0, maxSearchTerms)
+	for _, word := range strings.Fields(query) {
+		if !strings.ContainsFunc(word, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsNumber(r) }) {
+			continue
+		}
+		if len(terms) == maxSearchTerms {
+			return "", fmt.Errorf("%w: at most %d words are allowed", ErrInvalidSearchQuery, maxSearchTerms)
+		}
+		// A quoted string is a phrase, so "example.com" matches the two
+		// words in order
+		terms = append(terms, `"`+strings.ReplaceAll(word, `"`, `""`)+`"*`)
+	}
+	if len(terms) == 0 {
+		return "", fmt.Errorf("%w: q has no words to search for", ErrInvalidSearchQuery)
+	}
+	return strings.Join(terms, " "), nil
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSearchMatch(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{query: "exam", want: `"exam"*`},
+		{query: "  launch   plan ", want: `"launch"* "plan"*`},
+		{query: "example.com", want: `"example.com"*`},
+		{query: `say "hi"`, want: `"say"* """hi"""*`},
+		// FTS5 operators and syntax are searched for as words
+		{query: "cats OR dogs", want: `"cats"* "OR"* "dogs"*`},
+		{query: "title:draft NEAR(a b)", want: `"title:draft"* "NEAR(a"* "b)"*`},
+		{query: "- * ^ launch", want: `"launch"*`},
+		{query: "Café 2026", want: `"Café"* "2026"*`},
+		{query: strings.Repeat("a ", maxSearchTerms), want: strings.TrimSpace(strings.Repeat(`"a"* `, maxSearchTerms))},
+		{query: strings.Repeat("é", maxSearchQuery), want: `"` + strings.Repeat("é", maxSearchQuery) + `"*`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got, err := SearchMatch(tt.query)
+			if err != nil {
+				t.Fatalf("SearchMatch: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("SearchMatch(%q) = %s, want %s", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchMatchInvalid(t *testing.T) {
+	for _, query := range []string{
+		"",
+		"   ",
+		"- * ! ...",
+		strings.Repeat("a", maxSearchQuery+1),
+		strings.Repeat("a ", maxSearchTerms+1),
+	} {
+		if got, err := SearchMatch(query); !errors.Is(err, ErrInvalidSearchQuery) {
+			t.Errorf("SearchMatch(%q) = %q, %v, want ErrInvalidSearchQuery", query, got, err)
+		}
+	}
+}
+
+func TestMarkHighlights(t *testing.T) {
+	tests := map[string]string{
+		"plain text":                        "plain text",
+		"a \x02match\x03 here":              "a <mark>match</mark> here",
+		"\x02one\x03 and \x02two\x03":       "<mark>one</mark> and <mark>two</mark>",
+		"<script>\x02x\x03</script> & more": "&lt;script&gt;<mark>x</mark>&lt;/script&gt; &amp; more",
+		`"quoted" 'single'`:                 "&#34;quoted&#34; &#39;single&#39;",
+	}
+	for in, want := range tests {
+		if got := MarkHighlights(in); got != want {
+			t.Errorf("MarkHighlights(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
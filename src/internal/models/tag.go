@@ -0,0 +1,136 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// MaxTagsPerURL caps the tags on one link
+	MaxTagsPerURL = 20
+	maxTagName    = 50
+)
+
+// Tag is a label owned by a user. Names are stored lowercased and are unique
+// per user; tags are created the first time a link is given them.
+type Tag struct {
+	ID        string    `json:"id" gorm:"primaryKey;type:varchar(20)"`
+	UserID    string    `json:"-" gorm:"type:varchar(20);not null;uniqueIndex:idx_tags_user_name"`
+	Name      string    `json:"name" gorm:"type:varchar(50);not null;uniqueIndex:idx_tags_user_name"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	// URLCount is only loaded when listing tags
+	URLCount int64 `json:"url_count" gorm:"->;-:migration"`
+}
+
+func (t *Tag) BeforeCreate(tx *gorm.DB) error {
+	id, err := urlSid.Generate()
+	if err != nil {
+		return err
+	}
+	t.ID = id
+	return nil
+}
+
+// Campaign groups links for reporting. A link belongs to at most one
+// campaign, so campaigns double as folders.
+type Campaign struct {
+	ID          string    `json:"id" gorm:"primaryKey;type:varchar(20)"`
+	UserID      string    `json:"-" gorm:"type:varchar(20);not null;uniqueIndex:idx_campaigns_user_name"`
+	Name        string    `json:"name" gorm:"type:varchar(100);not null;uniqueIndex:idx_campaigns_user_name"`
+	Description string    `json:"description" gorm:"type:varchar(255);not null;default:''"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+	// URLCount is only loaded when reading campaigns
+	URLCount int64 `json:"url_count" gorm:"->;-:migration"`
+}
+
+func (c *Campaign) BeforeCreate(tx *gorm.DB) error {
+	id, err := urlSid.Generate()
+	if err != nil {
+		return err
+	}
+	c.ID = id
+	return nil
+}
+
+type CreateCampaignRequest struct {
+	Name        string `json:"name" validate:"required,max=100"`
+	Description string `json:"description" validate:"max=255"`
+}
+
+func (r *CreateCampaignRequest) Validate() error {
+	return validate.Struct(r)
+}
+
+type UpdateCampaignRequest struct {
+	Name        *string `json:"name" validate:"omitempty,max=100"`
+	Description *string `json:"description" validate:"omitempty,max=255"`
+}
+
+func (r *UpdateCampaignRequest) Validate() error {
+	return validate.Struct(r)
+}
+
+// BulkTagRequest adds tags to or removes them from many links at once.
+// Links the user does not own are skipped.
+type BulkTagRequest struct {
+	URLIDs []string `json:"url_ids" validate:"required,min=1,max=1000,dive,required"`
+	Tags   []string `json:"tags" validate:"required,min=1,max=20"`
+}
+
+func (r *BulkTagRequest) Validate() error {
+	return validate.Struct(r)
+}
+
+// BulkTagResult reports how many of the requested links were found and how
+// many tag assignments were added or removed
+type BulkTagResult struct {
+	URLs    int64 `json:"urls"`
+	Changed int64 `json:"changed"`
+}
+
+// NormalizeTags trims, lowercases and deduplicates tag names, keeping their
+// order. Names may not be empty, contain commas or control characters, or be
+// longer than 50 characters.
+func NormalizeTags(names []string) ([]string, error) {
+	if len(names) > MaxTagsPerURL {
+		return nil, fmt.Errorf("%w: at most %d tags are allowed", ErrInvalidTag, MaxTagsPerURL)
+	}
+
+	seen := make(map[string]struct{}, len(names))
+	tags := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.ToLower(strings.Join(strings.Fields(name), " "))
+		switch {
+		case name == "":
+			return nil, fmt.Errorf("%w: tag names cannot be empty", ErrInvalidTag)
+		case utf8.RuneCountInString(name) > maxTagName:
+			return nil, fmt.Errorf("%w: %q is longer than %d characters", ErrInvalidTag, name, maxTagName)
+		case strings.ContainsFunc(name, func(r rune) bool { return r == ',' || unicode.IsControl(r) }):
+			return nil, fmt.Errorf("%w: %q contains a comma or control character", ErrInvalidTag, name)
+		}
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		tags = append(tags, name)
+	}
+	return tags, nil
+}
+
+// TagNames lists the names of the link's tags
+func (u *URL) TagNames() []string {
+	if len(u.Tags) == 0 {
+		return nil
+	}
+	names := make([]string, len(u.Tags))
+	for i, tag := range u.Tags {
+		names[i] = tag.Name
+	}
+	return names
+}
@@ -1,32 +1,57 @@
 package models
 
 import (
+	neturl "net/url"
 	"time"
 
 	"github.com/teris-io/shortid"
+	"gorm.io/gorm"
 )
 
 var (
 	urlSid, _ = shortid.New(1, shortid.DefaultABC, 3453)
 )
 
+// DefaultRedirectType is the HTTP status used when a link does not set one
+const DefaultRedirectType = 302
+
 type URL struct {
-	ID          string     `json:"id" gorm:"primaryKey;type:varchar(20)"`
-	OriginalURL string     `json:"original_url" validate:"required,url" gorm:"not null"`
-	ShortCode   string     `json:"short_code" validate:"required,alphanum,min=3,max=10" gorm:"unique;not null"`
-	UserID      string     `json:"user_id" gorm:"type:varchar(20);index"`
-	User        User       `json:"-" gorm:"foreignKey:UserID"`
-	Title       string     `json:"title" validate:"max=100"`
-	Description string     `json:"description" validate:"max=255"`
-	Clicks      int        `json:"clicks" gorm:"default:0"`
-	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
-	IsActive    bool       `json:"is_active" gorm:"default:true"`
-	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt   time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
-	DeletedAt   *time.Time `json:"-" gorm:"index"`
-}
-
-func (u *URL) BeforeCreate() error {
+	ID            string         `json:"id" gorm:"primaryKey;type:varchar(20)"`
+	OriginalURL   string         `json:"original_url" validate:"required,url" gorm:"not null"`
+	DomainID      string         `json:"domain_id,omitempty" gorm:"type:varchar(20);not null;default:'';uniqueIndex:idx_urls_domain_code"`
+	Domain        *Domain        `json:"-" validate:"-" gorm:"foreignKey:DomainID"`
+	ShortCode     string         `json:"short_code" validate:"required,alphanum,min=3,max=10" gorm:"not null;uniqueIndex:idx_urls_domain_code"`
+	UserID        string         `json:"user_id" gorm:"type:varchar(20);index"`
+	User          User           `json:"-" validate:"-" gorm:"foreignKey:UserID"`
+	Title         string         `json:"title" validate:"max=100"`
+	Description   string         `json:"description" validate:"max=255"`
+	Clicks        int            `json:"clicks" gorm:"default:0"`
+	ActivatesAt   *time.Time     `json:"activates_at,omitempty"`
+	ExpiresAt     *time.Time     `json:"expires_at,omitempty"`
+	FallbackURL   string         `json:"fallback_url,omitempty" validate:"omitempty,url" gorm:"not null;default:''"`
+	Rules         []RedirectRule `json:"rules,omitempty" validate:"-" gorm:"serializer:json"`
+	Variants      []URLVariant   `json:"variants,omitempty" validate:"-" gorm:"serializer:json"`
+	UTM           UTMParams      `json:"utm" gorm:"embedded"`
+	ForwardQuery  bool           `json:"forward_query" gorm:"not null;default:false"`
+	QRLogoURL     string         `json:"qr_logo_url,omitempty" gorm:"not null;default:''"`
+	Preview       LinkPreview    `json:"preview" gorm:"embedded"`
+	PreviewedAt   *time.Time     `json:"previewed_at,omitempty"`
+	OG            OGOverrides    `json:"og" gorm:"embedded"`
+	Health        LinkHealth     `json:"health" gorm:"embedded"`
+	Tags          []Tag          `json:"tags,omitempty" validate:"-" gorm:"many2many:url_tags"`
+	CampaignID    *string        `json:"campaign_id,omitempty" gorm:"type:varchar(20);index"`
+	SafetyStatus  string         `json:"safety_status,omitempty" gorm:"type:varchar(20);not null;default:''"`
+	IsActive      bool           `json:"is_active"`
+	RedirectType  int            `json:"redirect_type" validate:"oneof=301 302 307 308" gorm:"default:302"`
+	PasswordHash  string         `json:"-" gorm:"type:varchar(255);not null;default:''"`
+	MaxClicks     *int           `json:"max_clicks,omitempty"`
+	ClaimedClicks int            `json:"-" gorm:"not null;default:0"`
+	CreatedAt     time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt     *time.Time     `json:"-" gorm:"index"`
+}
+
+func (u *URL) BeforeCreate(tx *gorm.DB) error {
 	id, err := urlSid.Generate()
 	if err != nil {
 		return err
@@ -38,7 +63,7 @@ func (u *URL) BeforeCreate() error {
 type URLClick struct {
 	ID        string    `json:"id" gorm:"primaryKey;type:varchar(20)"`
 	URLID     string    `json:"url_id" gorm:"type:varchar(20);index"`
-	URL       URL       `json:"-" gorm:"foreignKey:URLID"`
+	URL       URL       `json:"-" validate:"-" gorm:"foreignKey:URLID"`
 	IPAddress string    `json:"ip_address" gorm:"type:varchar(45)"`
 	Referrer  string    `json:"referrer"`
 	UserAgent string    `json:"user_agent"`
@@ -47,10 +72,12 @@ type URLClick struct {
 	Device    string    `json:"device" gorm:"type:varchar(20)"`
 	OS        string    `json:"os" gorm:"type:varchar(20)"`
 	Browser   string    `json:"browser" gorm:"type:varchar(20)"`
+	IsBot     bool      `json:"is_bot" gorm:"default:false;index"`
+	VariantID string    `json:"variant_id,omitempty" gorm:"type:varchar(20)"`
 	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
 }
 
-func (uc *URLClick) BeforeCreate() error {
+func (uc *URLClick) BeforeCreate(tx *gorm.DB) error {
 	id, err := urlSid.Generate()
 	if err != nil {
 		return err
@@ -60,24 +87,82 @@ func (uc *URLClick) BeforeCreate() error {
 }
 
 type CreateURLRequest struct {
-	OriginalURL string     `json:"original_url" validate:"required,url"`
-	CustomCode  string     `json:"custom_code" validate:"omitempty,alphanum,min=3,max=10"`
-	Title       string     `json:"title" validate:"max=100"`
-	Description string     `json:"description" validate:"max=255"`
-	ExpiresAt   *time.Time `json:"expires_at"`
+	OriginalURL  string         `json:"original_url" validate:"required,url"`
+	CustomCode   string         `json:"custom_code" validate:"omitempty,alphanum,min=3,max=10"`
+	Domain       string         `json:"domain" validate:"omitempty,fqdn"`
+	Title        string         `json:"title" validate:"max=100"`
+	Description  string         `json:"description" validate:"max=255"`
+	ActivatesAt  *time.Time     `json:"activates_at"`
+	ExpiresAt    *time.Time     `json:"expires_at"`
+	FallbackURL  string         `json:"fallback_url" validate:"omitempty,url"`
+	Rules        []RedirectRule `json:"rules"`
+	Variants     []URLVariant   `json:"variants"`
+	UTM          *UTMParams     `json:"utm"`
+	ForwardQuery bool           `json:"forward_query"`
+	OG           *OGOverrides   `json:"og"`
+	RedirectType int            `json:"redirect_type" validate:"omitempty,oneof=301 302 307 308"`
+	Password     string         `json:"password" validate:"omitempty,min=4,max=72"`
+	MaxClicks    *int           `json:"max_clicks" validate:"omitempty,min=1"`
+	Tags         []string       `json:"tags"`
+	CampaignID   string         `json:"campaign_id"`
+	// PasswordHash is set instead of Password on rows queued by a bulk
+	// import, so the queue never holds the password itself
+	PasswordHash string `json:"-"`
+}
+
+type UpdateURLRequest struct {
+	Title           *string         `json:"title" validate:"omitempty,max=100"`
+	Description     *string         `json:"description" validate:"omitempty,max=255"`
+	ActivatesAt     *time.Time      `json:"activates_at"`
+	ClearActivation bool            `json:"clear_activation"`
+	ExpiresAt       *time.Time      `json:"expires_at"`
+	ClearExpiry     bool            `json:"clear_expiry"`
+	FallbackURL     *string         `json:"fallback_url" validate:"omitempty,url"`
+	ClearFallback   bool            `json:"clear_fallback"`
+	Rules           *[]RedirectRule `json:"rules"`
+	Variants        *[]URLVariant   `json:"variants"`
+	UTM             *UTMParams      `json:"utm"`
+	ForwardQuery    *bool           `json:"forward_query"`
+	OG              *OGOverrides    `json:"og"`
+	RedirectType    *int            `json:"redirect_type" validate:"omitempty,oneof=301 302 307 308"`
+	Password        *string         `json:"password" validate:"omitempty,min=4,max=72"`
+	ClearPassword   bool            `json:"clear_password"`
+	MaxClicks       *int            `json:"max_clicks" validate:"omitempty,min=1"`
+	ClearMaxClicks  bool            `json:"clear_max_clicks"`
+	Tags            *[]string       `json:"tags"`
+	CampaignID      *string         `json:"campaign_id"`
+	ClearCampaign   bool            `json:"clear_campaign"`
 }
 
 type URLResponse struct {
-	ID          string     `json:"id"`
-	OriginalURL string     `json:"original_url"`
-	ShortURL    string     `json:"short_url"`
-	ShortCode   string     `json:"short_code"`
-	Title       string     `json:"title"`
-	Description string     `json:"description"`
-	Clicks      int        `json:"clicks"`
-	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
-	IsActive    bool       `json:"is_active"`
-	CreatedAt   time.Time  `json:"created_at"`
+	ID              string         `json:"id"`
+	OriginalURL     string         `json:"original_url"`
+	ShortURL        string         `json:"short_url"`
+	ShortCode       string         `json:"short_code"`
+	Domain          string         `json:"domain,omitempty"`
+	Title           string         `json:"title"`
+	Description     string         `json:"description"`
+	Clicks          int            `json:"clicks"`
+	ActivatesAt     *time.Time     `json:"activates_at,omitempty"`
+	ExpiresAt       *time.Time     `json:"expires_at,omitempty"`
+	FallbackURL     string         `json:"fallback_url,omitempty"`
+	Rules           []RedirectRule `json:"rules,omitempty"`
+	Variants        []URLVariant   `json:"variants,omitempty"`
+	UTM             *UTMParams     `json:"utm,omitempty"`
+	ForwardQuery    bool           `json:"forward_query"`
+	QRLogoURL       string         `json:"qr_logo_url,omitempty"`
+	Preview         *LinkPreview   `json:"preview,omitempty"`
+	OG              *OGOverrides   `json:"og,omitempty"`
+	Health          *LinkHealth    `json:"health,omitempty"`
+	Tags            []string       `json:"tags,omitempty"`
+	CampaignID      string         `json:"campaign_id,omitempty"`
+	SafetyStatus    string         `json:"safety_status,omitempty"`
+	IsActive        bool           `json:"is_active"`
+	RedirectType    int            `json:"redirect_type"`
+	HasPassword     bool           `json:"has_password"`
+	MaxClicks       *int           `json:"max_clicks,omitempty"`
+	RemainingClicks *int           `json:"remaining_clicks,omitempty"`
+	CreatedAt       time.Time      `json:"created_at"`
 }
 
 func (u *URL) Validate() error {
@@ -88,17 +173,122 @@ func (u *CreateURLRequest) Validate() error {
 	return validate.Struct(u)
 }
 
+func (u *UpdateURLRequest) Validate() error {
+	return validate.Struct(u)
+}
+
+// IsExpired reports whether the URL has passed its expiry time
+func (u *URL) IsExpired() bool {
+	return u.ExpiresAt != nil && !u.ExpiresAt.After(time.Now())
+}
+
+// IsPending reports whether the URL is scheduled to go live in the future
+func (u *URL) IsPending() bool {
+	return u.ActivatesAt != nil && u.ActivatesAt.After(time.Now())
+}
+
+// IsLive reports whether the URL may be followed right now. A URL whose
+// activation time has passed is live even if the scheduler has not flipped
+// IsActive yet; deactivating a URL clears ActivatesAt, so this never revives
+// a link its owner switched off.
+func (u *URL) IsLive() bool {
+	if u.IsExpired() || u.IsPending() {
+		return false
+	}
+	return u.IsActive || u.ActivatesAt != nil
+}
+
+// IsExhausted reports whether a click limited URL has used up its clicks.
+// ClaimedClicks, unlike Clicks, is bumped synchronously on every redirect,
+// bots included, so it is exact.
+func (u *URL) IsExhausted() bool {
+	return u.MaxClicks != nil && u.ClaimedClicks >= *u.MaxClicks
+}
+
+// RemainingClicks returns how many redirects a click limited URL has left,
+// or nil when it has no limit
+func (u *URL) RemainingClicks() *int {
+	if u.MaxClicks == nil {
+		return nil
+	}
+	remaining := max(*u.MaxClicks-u.ClaimedClicks, 0)
+	return &remaining
+}
+
+// HasPassword reports whether visitors must enter a password to follow the URL
+func (u *URL) HasPassword() bool {
+	return u.PasswordHash != ""
+}
+
+// ShortURL returns the short link for code. Links on a custom domain use its
+// hostname with the scheme of baseURL, all others baseURL itself.
+func ShortURL(baseURL string, domain *Domain, code string) string {
+	if domain == nil {
+		return baseURL + "/" + code
+	}
+	scheme := "https"
+	if base, err := neturl.Parse(baseURL); err == nil && base.Scheme != "" {
+		scheme = base.Scheme
+	}
+	return scheme + "://" + domain.Hostname + "/" + code
+}
+
+// ToResponse describes the URL. Domain must be loaded for links on a custom
+// domain to get the right short url.
 func (u *URL) ToResponse(baseURL string) *URLResponse {
+	var domain string
+	if u.Domain != nil {
+		domain = u.Domain.Hostname
+	}
+	var utm *UTMParams
+	if !u.UTM.IsZero() {
+		utm = &u.UTM
+	}
+	var preview *LinkPreview
+	if !u.Preview.IsZero() {
+		preview = &u.Preview
+	}
+	var og *OGOverrides
+	if !u.OG.IsZero() {
+		og = &u.OG
+	}
+	var health *LinkHealth
+	if u.Health.CheckedAt != nil {
+		health = &u.Health
+	}
+	var campaignID string
+	if u.CampaignID != nil {
+		campaignID = *u.CampaignID
+	}
+
 	return &URLResponse{
-		ID:          u.ID,
-		OriginalURL: u.OriginalURL,
-		ShortURL:    baseURL + "/" + u.ShortCode,
-		ShortCode:   u.ShortCode,
-		Title:       u.Title,
-		Description: u.Description,
-		Clicks:      u.Clicks,
-		ExpiresAt:   u.ExpiresAt,
-		IsActive:    u.IsActive,
-		CreatedAt:   u.CreatedAt,
+		ID:              u.ID,
+		OriginalURL:     u.OriginalURL,
+		ShortURL:        ShortURL(baseURL, u.Domain, u.ShortCode),
+		ShortCode:       u.ShortCode,
+		Domain:          domain,
+		Title:           u.Title,
+		Description:     u.Description,
+		Clicks:          u.Clicks,
+		ActivatesAt:     u.ActivatesAt,
+		ExpiresAt:       u.ExpiresAt,
+		FallbackURL:     u.FallbackURL,
+		Rules:           u.Rules,
+		Variants:        u.Variants,
+		UTM:             utm,
+		ForwardQuery:    u.ForwardQuery,
+		QRLogoURL:       u.QRLogoURL,
+		Preview:         preview,
+		OG:              og,
+		Health:          health,
+		Tags:            u.TagNames(),
+		CampaignID:      campaignID,
+		SafetyStatus:    u.SafetyStatus,
+		IsActive:        u.IsActive,
+		RedirectType:    u.RedirectType,
+		HasPassword:     u.HasPassword(),
+		MaxClicks:       u.MaxClicks,
+		RemainingClicks: u.RemainingClicks(),
+		CreatedAt:       u.CreatedAt,
 	}
-}
\ No newline at end of file
+}
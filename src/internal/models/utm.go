@@ -0,0 +1,149 @@
+package models
+
+import (
+	neturl "net/url"
+	"strings"
+)
+
+// UTMParams are the campaign tags added to a link's destination on redirect.
+// Empty fields are left out.
+type UTMParams struct {
+	Source   string `json:"source,omitempty" validate:"max=255" gorm:"column:utm_source;not null;default:''"`
+	Medium   string `json:"medium,omitempty" validate:"max=255" gorm:"column:utm_medium;not null;default:''"`
+	Campaign string `json:"campaign,omitempty" validate:"max=255" gorm:"column:utm_campaign;not null;default:''"`
+	Term     string `json:"term,omitempty" validate:"max=255" gorm:"column:utm_term;not null;default:''"`
+	Content  string `json:"content,omitempty" validate:"max=255" gorm:"column:utm_content;not null;default:''"`
+}
+
+// IsZero reports whether no tag is set
+func (p UTMParams) IsZero() bool {
+	return p == UTMParams{}
+}
+
+func (p UTMParams) pairs() []queryPair {
+	tags := []queryPair{
+		{key: "utm_source", value: p.Source},
+		{key: "utm_medium", value: p.Medium},
+		{key: "utm_campaign", value: p.Campaign},
+		{key: "utm_term", value: p.Term},
+		{key: "utm_content", value: p.Content},
+	}
+
+	var pairs []queryPair
+	for _, tag := range tags {
+		if tag.value != "" {
+			tag.hasValue = true
+			pairs = append(pairs, tag)
+		}
+	}
+	return pairs
+}
+
+// Destination adds the URL's UTM tags and, when ForwardQuery is set, the
+// visitor's query parameters in rawQuery to target. UTM tags replace
+// parameters of the same name already in target. When the visitor sends a
+// parameter the link also sets, the link's value wins unless requestWins is
+// set. The query is re-encoded, so the result is always a well formed URL;
+// target is returned unchanged when there is nothing to add.
+func (u *URL) Destination(target, rawQuery string, requestWins bool) string {
+	utm := u.UTM.pairs()
+	var incoming []queryPair
+	if u.ForwardQuery {
+		incoming = parseQuery(rawQuery, false)
+	}
+	if len(utm) == 0 && len(incoming) == 0 {
+		return target
+	}
+
+	dest, err := neturl.Parse(target)
+	if err != nil {
+		return target
+	}
+
+	pairs := mergeQuery(parseQuery(dest.RawQuery, true), utm, true)
+	pairs = mergeQuery(pairs, incoming, requestWins)
+
+	dest.RawQuery = encodeQuery(pairs)
+	dest.ForceQuery = false
+	return dest.String()
+}
+
+// queryPair is one parameter of a query string. Keys may repeat and order is
+// kept, unlike url.Values.
+type queryPair struct {
+	key      string
+	value    string
+	hasValue bool
+}
+
+// parseQuery splits a raw query into decoded pairs. Pieces with broken
+// escapes are kept literally when keepInvalid is set and dropped otherwise.
+// Semicolons are not separators.
+func parseQuery(raw string, keepInvalid bool) []queryPair {
+	var pairs []queryPair
+	for _, piece := range strings.Split(raw, "&") {
+		if piece == "" {
+			continue
+		}
+		rawKey, rawValue, hasValue := strings.Cut(piece, "=")
+
+		key, errKey := neturl.QueryUnescape(rawKey)
+		value, errValue := neturl.QueryUnescape(rawValue)
+		if errKey != nil || errValue != nil {
+			if !keepInvalid {
+				continue
+			}
+			key, value = rawKey, rawValue
+		}
+		if key == "" {
+			continue
+		}
+		pairs = append(pairs, queryPair{key: key, value: value, hasValue: hasValue})
+	}
+	return pairs
+}
+
+// mergeQuery adds extra to base. When a key is in both, extra's pairs
+// replace base's if extraWins and are dropped otherwise.
+func mergeQuery(base, extra []queryPair, extraWins bool) []queryPair {
+	if len(extra) == 0 {
+		return base
+	}
+
+	inBase := make(map[string]bool, len(base))
+	for _, p := range base {
+		inBase[p.key] = true
+	}
+	inExtra := make(map[string]bool, len(extra))
+	for _, p := range extra {
+		inExtra[p.key] = true
+	}
+
+	merged := make([]queryPair, 0, len(base)+len(extra))
+	for _, p := range base {
+		if !extraWins || !inExtra[p.key] {
+			merged = append(merged, p)
+		}
+	}
+	for _, p := range extra {
+		if extraWins || !inBase[p.key] {
+			merged = append(merged, p)
+		}
+	}
+	return merged
+}
+
+func encodeQuery(pairs []queryPair) string {
+	var b strings.Builder
+	for i, p := range pairs {
+		if i > 0 {
+			b.WriteByte('&')
+		}
+		b.WriteString(neturl.QueryEscape(p.key))
+		if p.hasValue {
+			b.WriteByte('=')
+			b.WriteString(neturl.QueryEscape(p.value))
+		}
+	}
+	return b.String()
+}
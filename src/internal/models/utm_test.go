@@ -0,0 +1,177 @@
+package models
+
+import "testing"
+
+func TestDestination(t *testing.T) {
+	news := UTMParams{Source: "news", Medium: "email"}
+
+	tests := []struct {
+		name        string
+		target      string
+		utm         UTMParams
+		forward     bool
+		rawQuery    string
+		requestWins bool
+		want        string
+	}{
+		{
+			name:   "nothing to add",
+			target: "https://example.com/p?x=%zz&y",
+			want:   "https://example.com/p?x=%zz&y",
+		},
+		{
+			name:     "forwarding disabled",
+			target:   "https://example.com/p?a=1",
+			rawQuery: "b=2&a=3",
+			want:     "https://example.com/p?a=1",
+		},
+		{
+			name:     "forwarding disabled with tags",
+			target:   "https://example.com/p",
+			utm:      news,
+			rawQuery: "b=2",
+			want:     "https://example.com/p?utm_source=news&utm_medium=email",
+		},
+		{
+			name:   "target without a query",
+			target: "https://example.com/p",
+			utm:    UTMParams{Campaign: "spring sale/ü", Term: "a&b", Content: "x=y"},
+			want:   "https://example.com/p?utm_campaign=spring+sale%2F%C3%BC&utm_term=a%26b&utm_content=x%3Dy",
+		},
+		{
+			name:   "target with a query",
+			target: "https://example.com/p?a=1&b=2",
+			utm:    news,
+			want:   "https://example.com/p?a=1&b=2&utm_source=news&utm_medium=email",
+		},
+		{
+			name:   "tags replace the target's",
+			target: "https://example.com/p?utm_source=old&a=1&utm_source=older&utm_term=kept",
+			utm:    news,
+			want:   "https://example.com/p?a=1&utm_term=kept&utm_source=news&utm_medium=email",
+		},
+		{
+			name:   "repeated keys keep their order",
+			target: "https://example.com/s?q=a+b&q=c&z",
+			utm:    news,
+			want:   "https://example.com/s?q=a+b&q=c&z&utm_source=news&utm_medium=email",
+		},
+		{
+			name:   "invalid escapes in the target are kept literally",
+			target: "https://example.com/p?bad=%zz&ok=%41",
+			utm:    news,
+			want:   "https://example.com/p?bad=%25zz&ok=A&utm_source=news&utm_medium=email",
+		},
+		{
+			name:     "invalid escapes from the visitor are dropped",
+			target:   "https://example.com/p",
+			forward:  true,
+			rawQuery: "bad=%zz&%g=1&ok=1",
+			want:     "https://example.com/p?ok=1",
+		},
+		{
+			name:   "fragment",
+			target: "https://example.com/p?a=1#section-2",
+			utm:    news,
+			want:   "https://example.com/p?a=1&utm_source=news&utm_medium=email#section-2",
+		},
+		{
+			name:     "fragment without a query",
+			target:   "https://example.com/p#top",
+			forward:  true,
+			rawQuery: "ref=x",
+			want:     "https://example.com/p?ref=x#top",
+		},
+		{
+			name:     "link wins",
+			target:   "https://example.com/s?q=a+b&q=c",
+			utm:      news,
+			forward:  true,
+			rawQuery: "q=d&utm_source=visitor&r=1&r=2",
+			want:     "https://example.com/s?q=a+b&q=c&utm_source=news&utm_medium=email&r=1&r=2",
+		},
+		{
+			name:        "request wins",
+			target:      "https://example.com/s?q=a+b&q=c&keep=1",
+			utm:         news,
+			forward:     true,
+			rawQuery:    "q=d&utm_source=visitor&r=1&r=2",
+			requestWins: true,
+			want:        "https://example.com/s?keep=1&utm_medium=email&q=d&utm_source=visitor&r=1&r=2",
+		},
+		{
+			name:     "flags and empty values",
+			target:   "https://example.com/p?flag&empty=",
+			forward:  true,
+			rawQuery: "other&blank=&=nokey",
+			want:     "https://example.com/p?flag&empty=&other&blank=",
+		},
+		{
+			name:     "semicolons are not separators",
+			target:   "https://example.com/p",
+			forward:  true,
+			rawQuery: "a=1;b=2",
+			want:     "https://example.com/p?a=1%3Bb%3D2",
+		},
+		{
+			name:     "forced empty query",
+			target:   "https://example.com/p?",
+			forward:  true,
+			rawQuery: "a=1",
+			want:     "https://example.com/p?a=1",
+		},
+		{
+			name:     "empty visitor query",
+			target:   "https://example.com/p?",
+			forward:  true,
+			rawQuery: "&&",
+			want:     "https://example.com/p?",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := &URL{UTM: tt.utm, ForwardQuery: tt.forward}
+			if got := u.Destination(tt.target, tt.rawQuery, tt.requestWins); got != tt.want {
+				t.Errorf("Destination = %s\n want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeQuery(t *testing.T) {
+	base := []queryPair{{key: "a", value: "1", hasValue: true}, {key: "b", value: "2", hasValue: true}, {key: "a", value: "3", hasValue: true}}
+	extra := []queryPair{{key: "a", value: "x", hasValue: true}, {key: "c", hasValue: false}}
+
+	tests := []struct {
+		extraWins bool
+		want      string
+	}{
+		{extraWins: false, want: "a=1&b=2&a=3&c"},
+		{extraWins: true, want: "b=2&a=x&c"},
+	}
+	for _, tt := range tests {
+		if got := encodeQuery(mergeQuery(base, extra, tt.extraWins)); got != tt.want {
+			t.Errorf("mergeQuery(extraWins %v) = %s, want %s", tt.extraWins, got, tt.want)
+		}
+	}
+	if got := encodeQuery(mergeQuery(base, nil, true)); got != "a=1&b=2&a=3" {
+		t.Errorf("mergeQuery without extra = %s", got)
+	}
+}
+
+func TestEncodeQuery(t *testing.T) {
+	tests := []struct {
+		pairs []queryPair
+		want  string
+	}{
+		{pairs: nil, want: ""},
+		{pairs: []queryPair{{key: "a b", value: "c&d=e", hasValue: true}}, want: "a+b=c%26d%3De"},
+		{pairs: []queryPair{{key: "flag"}, {key: "empty", hasValue: true}}, want: "flag&empty="},
+		{pairs: []queryPair{{key: "é", value: "100%", hasValue: true}}, want: "%C3%A9=100%25"},
+	}
+	for _, tt := range tests {
+		if got := encodeQuery(tt.pairs); got != tt.want {
+			t.Errorf("encodeQuery(%v) = %s, want %s", tt.pairs, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	neturl "net/url"
+	"unicode/utf8"
+)
+
+// Variant limits. A split needs at least two destinations; weights are
+// relative, so 70 and 30 split traffic the same way 7 and 3 do.
+const (
+	MinVariants      = 2
+	MaxVariants      = 10
+	MaxVariantWeight = 1000
+	maxVariantName   = 50
+)
+
+// URLVariant is one destination of an A/B split. Visitors are assigned a
+// variant in proportion to its weight and keep it on later visits.
+type URLVariant struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	TargetURL string `json:"target_url"`
+	Weight    int    `json:"weight"`
+}
+
+// NewVariantID returns an identifier for a new variant
+func NewVariantID() (string, error) {
+	return urlSid.Generate()
+}
+
+// Variant returns the variant with the given ID, or nil when the URL has none
+func (u *URL) Variant(id string) *URLVariant {
+	if id == "" {
+		return nil
+	}
+	for i := range u.Variants {
+		if u.Variants[i].ID == id {
+			return &u.Variants[i]
+		}
+	}
+	return nil
+}
+
+// PickVariant assigns a visitor to one of the URL's variants. A visitor who
+// was assigned a variant that still exists keeps it; anyone else is placed
+// by hashing key, so the same key lands on the same variant as long as the
+// weights do not change. It returns nil when the URL is not split.
+func (u *URL) PickVariant(assigned, key string) *URLVariant {
+	if len(u.Variants) == 0 {
+		return nil
+	}
+	if v := u.Variant(assigned); v != nil {
+		return v
+	}
+
+	total := 0
+	for _, v := range u.Variants {
+		total += v.Weight
+	}
+	if total <= 0 {
+		return &u.Variants[0]
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(u.ID + "|" + key))
+	point := int(h.Sum64() % uint64(total))
+	for i := range u.Variants {
+		point -= u.Variants[i].Weight
+		if point < 0 {
+			return &u.Variants[i]
+		}
+	}
+	return &u.Variants[len(u.Variants)-1]
+}
+
+// Validate checks the variant's target, name and weight
+func (v *URLVariant) Validate() error {
+	target, err := neturl.Parse(v.TargetURL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return errors.New("target_url must be an absolute http or https url")
+	}
+	if utf8.RuneCountInString(v.Name) > maxVariantName {
+		return fmt.Errorf("name must be at most %d characters", maxVariantName)
+	}
+	if v.Weight < 1 || v.Weight > MaxVariantWeight {
+		return fmt.Errorf("weight must be between 1 and %d", MaxVariantWeight)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func splitURL(weights ...int) *URL {
+	u := &URL{ID: "url1"}
+	for i, w := range weights {
+		u.Variants = append(u.Variants, URLVariant{
+			ID:        fmt.Sprintf("v%d", i),
+			TargetURL: fmt.Sprintf("https://example.com/%d", i),
+			Weight:    w,
+		})
+	}
+	return u
+}
+
+func visitorKey(i int) string {
+	return fmt.Sprintf("198.51.%d.%d|Mozilla/5.0 (build %d)", i/256%256, i%256, i)
+}
+
+func TestPickVariantWeights(t *testing.T) {
+	tests := [][]int{
+		{50, 50},
+		{70, 20, 10},
+		{1, 3},
+		{999, 1},
+		{5, 5, 5, 5, 5, 5, 5, 5, 5, 5},
+	}
+	const picks = 50000
+	for _, weights := range tests {
+		t.Run(fmt.Sprint(weights), func(t *testing.T) {
+			u := splitURL(weights...)
+			counts := make(map[string]int)
+			for i := 0; i < picks; i++ {
+				counts[u.PickVariant("", visitorKey(i)).ID]++
+			}
+
+			total := 0
+			for _, w := range weights {
+				total += w
+			}
+			for i, w := range weights {
+				want := float64(w) / float64(total)
+				got := float64(counts[fmt.Sprintf("v%d", i)]) / picks
+				if math.Abs(got-want) > 0.01 {
+					t.Errorf("variant %d got %.3f of the picks, want %.3f", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPickVariantSticky(t *testing.T) {
+	u := splitURL(50, 30, 20)
+
+	// The same visitor key always lands on the same variant
+	for i := 0; i < 100; i++ {
+		key := visitorKey(i)
+		first := u.PickVariant("", key)
+		for range 5 {
+			if v := u.PickVariant("", key); v.ID != first.ID {
+				t.Fatalf("key %s moved from %s to %s", key, first.ID, v.ID)
+			}
+		}
+		// A cookie naming a variant that no longer exists falls back to
+		// the key
+		if v := u.PickVariant("gone", key); v.ID != first.ID {
+			t.Errorf("key %s with a stale cookie got %s, want %s", key, v.ID, first.ID)
+		}
+	}
+
+	// The cookie wins over the key, even for a variant the key would not
+	// pick
+	for _, assigned := range []string{"v0", "v1", "v2"} {
+		for i := 0; i < 20; i++ {
+			if v := u.PickVariant(assigned, visitorKey(i)); v.ID != assigned {
+				t.Errorf("cookie %s with key %d got %s", assigned, i, v.ID)
+			}
+		}
+	}
+
+	// The url is part of the hash, so a visitor does not land in the same
+	// bucket of every split link
+	other := splitURL(50, 30, 20)
+	other.ID = "url2"
+	moved := 0
+	for i := 0; i < 1000; i++ {
+		if u.PickVariant("", visitorKey(i)).ID != other.PickVariant("", visitorKey(i)).ID {
+			moved++
+		}
+	}
+	if moved == 0 {
+		t.Error("every visitor got the same variant on two different urls")
+	}
+}
+
+func TestPickVariantEdgeCases(t *testing.T) {
+	if v := splitURL().PickVariant("v0", visitorKey(1)); v != nil {
+		t.Errorf("PickVariant without variants = %+v, want nil", v)
+	}
+
+	single := splitURL(7)
+	for i := 0; i < 100; i++ {
+		if v := single.PickVariant("", visitorKey(i)); v.ID != "v0" {
+			t.Fatalf("single variant: got %s", v.ID)
+		}
+	}
+
+	// Without any weight every visitor gets the first variant
+	zero := splitURL(0, 0, 0)
+	for i := 0; i < 100; i++ {
+		if v := zero.PickVariant("", visitorKey(i)); v.ID != "v0" {
+			t.Fatalf("zero weights: got %s", v.ID)
+		}
+	}
+	if v := zero.PickVariant("v2", visitorKey(1)); v.ID != "v2" {
+		t.Errorf("zero weights with a cookie: got %s, want v2", v.ID)
+	}
+
+	// A variant with no weight is never picked by hash
+	partial := splitURL(0, 1, 0, 1)
+	for i := 0; i < 1000; i++ {
+		if v := partial.PickVariant("", visitorKey(i)); v.Weight == 0 {
+			t.Fatalf("picked %s, which has no weight", v.ID)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// LinkAccessClaims proves that a visitor entered the password of a protected
+// short link. The fingerprint ties the token to the password that was current
+// when it was issued, so changing the password locks everyone out again.
+type LinkAccessClaims struct {
+	ShortCode   string `json:"code"`
+	Fingerprint string `json:"fp"`
+	jwt.RegisteredClaims
+}
+
+// GenerateLinkAccessToken signs a token that unlocks shortCode until the
+// returned expiry
+func GenerateLinkAccessToken(secret, shortCode, passwordHash string, ttl time.Duration) (string, time.Time, error) {
+	expiresAt := time.Now().Add(ttl)
+	claims := &LinkAccessClaims{
+		ShortCode:   shortCode,
+		Fingerprint: passwordFingerprint(passwordHash),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	return token, expiresAt, err
+}
+
+// ValidateLinkAccessToken checks that token unlocks shortCode protected by
+// passwordHash
+func ValidateLinkAccessToken(secret, tokenString, shortCode, passwordHash string) error {
+	claims := &LinkAccessClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte(secret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return err
+	}
+
+	if claims.ShortCode != shortCode || claims.Fingerprint != passwordFingerprint(passwordHash) {
+		return errors.New("link access token does not match link")
+	}
+	return nil
+}
+
+func passwordFingerprint(passwordHash string) string {
+	sum := sha256.Sum256([]byte(passwordHash))
+	return hex.EncodeToString(sum[:8])
+}
@@ -34,7 +34,8 @@ func ConnectDB(cfg *configs.DatabaseConfig) (*DB, error) {
 	)
 
 	gormDB, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Silent),
+		Logger:         logger.Default.LogMode(logger.Silent),
+		TranslateError: true,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
@@ -2,6 +2,7 @@ package email
 
 import (
 	"fmt"
+	"html"
 	"net/smtp"
 	"strings"
 	"time"
@@ -63,6 +64,70 @@ func (e *EmailService) SendPasswordResetEmail(to, resetLink string) error {
 	return e.sendEmail(to, subject, body)
 }
 
+func (e *EmailService) SendLinkExhaustedEmail(to, shortURL, originalURL string, maxClicks int) error {
+	const subject = "Your Short Link Reached Its Click Limit"
+	body := fmt.Sprintf(`
+		<html>
+		<body>
+			<h2>Click Limit Reached</h2>
+			<p>Your short link <a href="%s" style="color: #2563eb; text-decoration: underline;">%s</a> has been followed %d times and no longer redirects.</p>
+			<p>It pointed to: %s</p>
+			<p>You can raise or remove the limit from your dashboard to enable it again.</p>
+			<hr>
+			<small>Brevity Team</small>
+		</body>
+		</html>
+	`, shortURL, shortURL, maxClicks, html.EscapeString(originalURL))
+
+	return e.sendEmail(to, subject, body)
+}
+
+// BrokenLink is a link listed in a broken link digest
+type BrokenLink struct {
+	ShortURL    string
+	OriginalURL string
+	// Problem describes the failed check, e.g. "HTTP 404"
+	Problem      string
+	FailingSince time.Time
+}
+
+func (e *EmailService) SendBrokenLinksDigest(to string, links []BrokenLink) error {
+	subject := "Some of Your Short Links Stopped Working"
+	if len(links) == 1 {
+		subject = "One of Your Short Links Stopped Working"
+	}
+
+	var rows strings.Builder
+	for _, link := range links {
+		rows.WriteString(fmt.Sprintf(`
+				<li>
+					<a href="%s" style="color: #2563eb; text-decoration: underline;">%s</a>
+					&rarr; %s<br>
+					<small>%s, failing since %s</small>
+				</li>`,
+			link.ShortURL, link.ShortURL,
+			html.EscapeString(link.OriginalURL),
+			html.EscapeString(link.Problem),
+			link.FailingSince.UTC().Format("Jan 2, 2006 15:04 MST")))
+	}
+
+	body := fmt.Sprintf(`
+		<html>
+		<body>
+			<h2>Broken Links</h2>
+			<p>The destinations of these short links stopped responding when we checked them:</p>
+			<ul>%s
+			</ul>
+			<p>Visitors following them may land on an error page. You can update or deactivate them from your dashboard.</p>
+			<hr>
+			<small>Brevity Team</small>
+		</body>
+		</html>
+	`, rows.String())
+
+	return e.sendEmail(to, subject, body)
+}
+
 func (e *EmailService) sendEmail(to, subject, body string) error {
 	from := e.cfg.SMTP.FromEmail
 	if from == "" {
@@ -0,0 +1,145 @@
+package geoip
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"sort"
+	"strings"
+)
+
+// ipRange is one row of a CSV range database
+type ipRange struct {
+	start    netip.Addr
+	end      netip.Addr
+	location Location
+}
+
+// csvReader resolves addresses against a sorted list of IP ranges loaded from
+// CSV. Two layouts are accepted, with an optional header row:
+//
+//	start_ip,end_ip,country[,city]
+//	cidr,country[,city]
+type csvReader struct {
+	ranges []ipRange
+}
+
+func newCSVReader(r io.Reader) (*csvReader, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+	cr.Comment = '#'
+
+	var ranges []ipRange
+	for line := 1; ; line++ {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("geoip: csv line %d: %w", line, err)
+		}
+
+		row, ok, err := parseCSVRow(record)
+		if err != nil {
+			if line == 1 {
+				// Header row
+				continue
+			}
+			return nil, fmt.Errorf("geoip: csv line %d: %w", line, err)
+		}
+		if ok {
+			ranges = append(ranges, row)
+		}
+	}
+
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i].start.Less(ranges[j].start)
+	})
+	return &csvReader{ranges: ranges}, nil
+}
+
+func parseCSVRow(record []string) (ipRange, bool, error) {
+	if len(record) < 2 {
+		return ipRange{}, false, errors.New("expected at least 2 columns")
+	}
+
+	var row ipRange
+	var rest []string
+	if strings.Contains(record[0], "/") {
+		prefix, err := netip.ParsePrefix(strings.TrimSpace(record[0]))
+		if err != nil {
+			return ipRange{}, false, err
+		}
+		prefix = prefix.Masked()
+		row.start = prefix.Addr().Unmap()
+		row.end = lastAddr(prefix).Unmap()
+		rest = record[1:]
+	} else {
+		if len(record) < 3 {
+			return ipRange{}, false, errors.New("expected start, end and country columns")
+		}
+		start, err := netip.ParseAddr(strings.TrimSpace(record[0]))
+		if err != nil {
+			return ipRange{}, false, err
+		}
+		end, err := netip.ParseAddr(strings.TrimSpace(record[1]))
+		if err != nil {
+			return ipRange{}, false, err
+		}
+		row.start, row.end = start.Unmap(), end.Unmap()
+		rest = record[2:]
+	}
+
+	if row.start.Is4() != row.end.Is4() || row.end.Less(row.start) {
+		return ipRange{}, false, errors.New("invalid ip range")
+	}
+
+	row.location.Country = strings.ToUpper(strings.TrimSpace(rest[0]))
+	if len(rest) > 1 {
+		row.location.City = strings.TrimSpace(rest[len(rest)-1])
+	}
+	// "ZZ" and "-" are commonly used for unknown or reserved ranges
+	if row.location.Country == "ZZ" || row.location.Country == "-" {
+		return ipRange{}, false, nil
+	}
+	return row, true, nil
+}
+
+func (r *csvReader) lookup(ip netip.Addr) (Location, error) {
+	ip = ip.Unmap()
+
+	// Find the last range starting at or before ip
+	i := sort.Search(len(r.ranges), func(i int) bool {
+		return ip.Less(r.ranges[i].start)
+	}) - 1
+	if i < 0 {
+		return Location{}, nil
+	}
+
+	row := r.ranges[i]
+	if row.start.Is4() != ip.Is4() || row.end.Less(ip) {
+		return Location{}, nil
+	}
+	return row.location, nil
+}
+
+// lastAddr returns the highest address covered by prefix
+func lastAddr(prefix netip.Prefix) netip.Addr {
+	a := prefix.Addr().As16()
+	bits := prefix.Bits()
+	if prefix.Addr().Is4() {
+		bits += 96
+	}
+	for i := bits; i < 128; i++ {
+		a[i/8] |= 1 << (7 - uint(i%8))
+	}
+
+	addr := netip.AddrFrom16(a)
+	if prefix.Addr().Is4() {
+		return addr.Unmap()
+	}
+	return addr
+}
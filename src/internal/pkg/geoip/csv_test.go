@@ -0,0 +1,102 @@
+package geoip
+
+import (
+	"net/netip"
+	"strings"
+	"testing"
+)
+
+const testCSV = `network,country,city
+# comments are skipped
+8.8.8.0/24,us,Mountain View
+9.9.9.0,9.9.9.255,CH,Zurich
+1.0.0.0,1.0.0.255,AU
+10.0.0.0/8,ZZ,Reserved
+2001:db8::/32,DE,Berlin
+::ffff:5.5.5.0/120,FR,Paris
+`
+
+func TestCSVLookup(t *testing.T) {
+	r, err := newCSVReader(strings.NewReader(testCSV))
+	if err != nil {
+		t.Fatalf("newCSVReader: %v", err)
+	}
+
+	tests := []struct {
+		ip   string
+		want Location
+	}{
+		{ip: "8.8.8.8", want: Location{Country: "US", City: "Mountain View"}},
+		{ip: "8.8.8.0", want: Location{Country: "US", City: "Mountain View"}},
+		{ip: "8.8.8.255", want: Location{Country: "US", City: "Mountain View"}},
+		{ip: "8.8.9.0", want: Location{}},
+		{ip: "9.9.9.9", want: Location{Country: "CH", City: "Zurich"}},
+		{ip: "1.0.0.1", want: Location{Country: "AU"}},
+		{ip: "0.255.255.255", want: Location{}},
+		{ip: "10.1.2.3", want: Location{}},
+		{ip: "::ffff:8.8.8.8", want: Location{Country: "US", City: "Mountain View"}},
+		{ip: "5.5.5.5", want: Location{Country: "FR", City: "Paris"}},
+		{ip: "2001:db8:ffff::1", want: Location{Country: "DE", City: "Berlin"}},
+		{ip: "2001:db9::1", want: Location{}},
+		// IPv6 addresses after the last IPv4 range are not in it
+		{ip: "::1:0:0:1", want: Location{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			got, err := r.lookup(netip.MustParseAddr(tt.ip))
+			if err != nil {
+				t.Fatalf("lookup: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("lookup(%s) = %+v, want %+v", tt.ip, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCSVWithoutHeader(t *testing.T) {
+	r, err := newCSVReader(strings.NewReader("8.8.8.0/24,US\n"))
+	if err != nil {
+		t.Fatalf("newCSVReader: %v", err)
+	}
+	if got, _ := r.lookup(netip.MustParseAddr("8.8.8.8")); got.Country != "US" {
+		t.Errorf("lookup = %+v, want US", got)
+	}
+}
+
+func TestCSVInvalidRows(t *testing.T) {
+	tests := []struct {
+		name string
+		csv  string
+	}{
+		{name: "one column", csv: "8.8.8.0/24,US\n8.8.4.0/24\n"},
+		{name: "bad cidr", csv: "8.8.8.0/24,US\n8.8.4.0/33,US\n"},
+		{name: "bad start", csv: "8.8.8.0/24,US\n8.8.4,8.8.4.255,US\n"},
+		{name: "missing country", csv: "8.8.8.0/24,US\n8.8.4.0,8.8.4.255\n"},
+		{name: "end before start", csv: "8.8.8.0/24,US\n8.8.4.255,8.8.4.0,US\n"},
+		{name: "mixed families", csv: "8.8.8.0/24,US\n8.8.4.0,2001:db8::1,US\n"},
+		{name: "unterminated quote", csv: "8.8.8.0/24,US\n\"8.8.4.0/24,US\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := newCSVReader(strings.NewReader(tt.csv)); err == nil {
+				t.Error("newCSVReader succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestLastAddr(t *testing.T) {
+	tests := map[string]string{
+		"8.8.8.0/24":    "8.8.8.255",
+		"10.0.0.0/8":    "10.255.255.255",
+		"1.2.3.4/32":    "1.2.3.4",
+		"0.0.0.0/0":     "255.255.255.255",
+		"2001:db8::/32": "2001:db8:ffff:ffff:ffff:ffff:ffff:ffff",
+	}
+	for prefix, want := range tests {
+		if got := lastAddr(netip.MustParsePrefix(prefix)); got.String() != want {
+			t.Errorf("lastAddr(%s) = %s, want %s", prefix, got, want)
+		}
+	}
+}
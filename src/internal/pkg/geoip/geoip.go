@@ -0,0 +1,207 @@
+// Package geoip resolves IP addresses to ISO country codes and city names
+// using a local MaxMind (MMDB) or CSV IP-range database.
+package geoip
+
+import (
+	"bytes"
+	"fmt"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/imraushankr/brevity/server/src/configs"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/logger"
+)
+
+// Supported database formats
+const (
+	FormatAuto = "auto"
+	FormatMMDB = "mmdb"
+	FormatCSV  = "csv"
+)
+
+// reloadDebounce groups the burst of events editors and downloaders emit
+// while replacing a file
+const reloadDebounce = 500 * time.Millisecond
+
+// Location is the result of a lookup. Empty fields mean unknown.
+type Location struct {
+	Country string `json:"country"`
+	City    string `json:"city"`
+}
+
+type database interface {
+	lookup(ip netip.Addr) (Location, error)
+}
+
+// Locator looks up IP addresses in the configured database. With no database
+// configured every lookup returns an empty Location. A Locator is safe for
+// concurrent use.
+type Locator struct {
+	path   string
+	format string
+	log    logger.Logger
+
+	db      atomic.Pointer[database]
+	cache   *lruCache
+	watcher *fsnotify.Watcher
+	done    chan struct{}
+	closeMu sync.Once
+}
+
+// New opens the database at cfg.DatabasePath and watches it for changes
+func New(cfg *configs.GeoIPConfig, log logger.Logger) (*Locator, error) {
+	l := &Locator{
+		path:   cfg.DatabasePath,
+		format: strings.ToLower(cfg.Format),
+		log:    log,
+		cache:  newLRUCache(cfg.CacheSize),
+		done:   make(chan struct{}),
+	}
+
+	if l.path == "" {
+		log.Info("No GeoIP database configured, click locations will be empty")
+		return l, nil
+	}
+
+	if err := l.load(); err != nil {
+		return nil, err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("geoip: failed to create file watcher: %w", err)
+	}
+	// Watch the directory so atomic replacements (rename over) are seen
+	if err := watcher.Add(filepath.Dir(l.path)); err != nil {
+		watcher.Close()
+		return nil, fmt.Errorf("geoip: failed to watch database: %w", err)
+	}
+	l.watcher = watcher
+	go l.watch()
+
+	return l, nil
+}
+
+// Lookup resolves ip, which may be IPv4 or IPv6. Invalid, private and
+// unknown addresses return an empty Location.
+func (l *Locator) Lookup(ip string) Location {
+	dbPtr := l.db.Load()
+	if dbPtr == nil {
+		return Location{}
+	}
+
+	if loc, ok := l.cache.get(ip); ok {
+		return loc
+	}
+
+	addr, err := netip.ParseAddr(ip)
+	if err != nil || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() {
+		return Location{}
+	}
+
+	loc, err := (*dbPtr).lookup(addr.WithZone(""))
+	if err != nil {
+		l.log.Warn("GeoIP lookup failed",
+			logger.ErrorField(err),
+			logger.String("ip", ip))
+		return Location{}
+	}
+
+	l.cache.add(ip, loc)
+	return loc
+}
+
+// Close stops watching the database file
+func (l *Locator) Close() error {
+	var err error
+	l.closeMu.Do(func() {
+		close(l.done)
+		if l.watcher != nil {
+			err = l.watcher.Close()
+		}
+	})
+	return err
+}
+
+func (l *Locator) load() error {
+	buf, err := os.ReadFile(l.path)
+	if err != nil {
+		return fmt.Errorf("geoip: failed to read database: %w", err)
+	}
+
+	var db database
+	switch l.detectFormat(buf) {
+	case FormatMMDB:
+		db, err = newMMDBReader(buf)
+	case FormatCSV:
+		db, err = newCSVReader(bytes.NewReader(buf))
+	default:
+		err = fmt.Errorf("geoip: unsupported database format %q", l.format)
+	}
+	if err != nil {
+		return err
+	}
+
+	l.db.Store(&db)
+	l.cache.purge()
+	l.log.Info("GeoIP database loaded", logger.String("path", l.path))
+	return nil
+}
+
+func (l *Locator) detectFormat(buf []byte) string {
+	if l.format != "" && l.format != FormatAuto {
+		return l.format
+	}
+	if strings.EqualFold(filepath.Ext(l.path), ".mmdb") || bytes.Contains(buf, mmdbMetadataMarker) {
+		return FormatMMDB
+	}
+	return FormatCSV
+}
+
+func (l *Locator) watch() {
+	var timer *time.Timer
+	var reload <-chan time.Time
+	target := filepath.Clean(l.path)
+
+	for {
+		select {
+		case <-l.done:
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+		case event, ok := <-l.watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(event.Name) != target || event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+				continue
+			}
+			if timer == nil {
+				timer = time.NewTimer(reloadDebounce)
+			} else {
+				timer.Reset(reloadDebounce)
+			}
+			reload = timer.C
+		case <-reload:
+			reload = nil
+			// Keep serving the previous database if the new file is broken
+			if err := l.load(); err != nil {
+				l.log.Error("Failed to reload GeoIP database",
+					logger.ErrorField(err),
+					logger.String("path", l.path))
+			}
+		case err, ok := <-l.watcher.Errors:
+			if !ok {
+				return
+			}
+			l.log.Warn("GeoIP watcher error", logger.ErrorField(err))
+		}
+	}
+}
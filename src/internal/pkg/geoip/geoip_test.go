@@ -0,0 +1,123 @@
+package geoip
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/imraushankr/brevity/server/src/configs"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/logger"
+)
+
+func newTestLocator(t *testing.T, name string, db []byte, cacheSize int) (*Locator, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, db, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := New(&configs.GeoIPConfig{DatabasePath: path, Format: FormatAuto, CacheSize: cacheSize}, logger.Get())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(func() { l.Close() })
+	return l, path
+}
+
+func TestLocatorLookup(t *testing.T) {
+	mmdb := mmdbFixture{recordSize: 28, ipVersion: 6, networks: testNetworks()}.build(t)
+
+	tests := []struct {
+		name string
+		file string
+		db   []byte
+	}{
+		{name: "mmdb", file: "city.mmdb", db: mmdb},
+		{name: "mmdb without extension", file: "city.db", db: mmdb},
+		{name: "csv", file: "ranges.csv", db: []byte("network,country,city\n1.2.3.0/24,US,Springfield\n2001:db8::/32,DE,Berlin\n")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, _ := newTestLocator(t, tt.file, tt.db, 10)
+
+			lookups := map[string]Location{
+				"1.2.3.4":       {Country: "US", City: "Springfield"},
+				"2001:db8::1":   {Country: "DE", City: "Berlin"},
+				"fe80::1%eth0":  {},
+				"127.0.0.1":     {},
+				"192.168.1.1":   {},
+				"0.0.0.0":       {},
+				"not an ip":     {},
+				"203.0.113.255": {},
+			}
+			for ip, want := range lookups {
+				// Twice, so the second lookup comes from the cache
+				for range 2 {
+					if got := l.Lookup(ip); got != want {
+						t.Errorf("Lookup(%q) = %+v, want %+v", ip, got, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestLocatorNonPositiveCacheSize(t *testing.T) {
+	for _, size := range []int{0, -5} {
+		l, _ := newTestLocator(t, "ranges.csv", []byte("1.2.3.0/24,US\n"), size)
+		if got := l.Lookup("1.2.3.4"); got.Country != "US" {
+			t.Errorf("cache size %d: Lookup = %+v, want US", size, got)
+		}
+	}
+}
+
+func TestLocatorWithoutDatabase(t *testing.T) {
+	l, err := New(&configs.GeoIPConfig{CacheSize: 10}, logger.Get())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer l.Close()
+
+	if got := l.Lookup("1.2.3.4"); got != (Location{}) {
+		t.Errorf("Lookup = %+v, want an empty location", got)
+	}
+}
+
+func TestLocatorRejectsBrokenDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "broken.mmdb")
+	if err := os.WriteFile(path, []byte("not a database"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New(&configs.GeoIPConfig{DatabasePath: path}, logger.Get()); err == nil {
+		t.Error("New succeeded, want an error")
+	}
+	if _, err := New(&configs.GeoIPConfig{DatabasePath: filepath.Join(t.TempDir(), "missing.csv")}, logger.Get()); err == nil {
+		t.Error("New succeeded with a missing file, want an error")
+	}
+}
+
+func TestLocatorReloadsReplacedDatabase(t *testing.T) {
+	l, path := newTestLocator(t, "ranges.csv", []byte("1.2.3.0/24,US\n"), 10)
+	if got := l.Lookup("1.2.3.4"); got.Country != "US" {
+		t.Fatalf("Lookup = %+v, want US", got)
+	}
+
+	// Replace the file the way downloaders do, by renaming over it
+	next := path + ".tmp"
+	if err := os.WriteFile(next, []byte("1.2.3.0/24,CA\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(next, path); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if l.Lookup("1.2.3.4").Country == "CA" {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Errorf("Lookup = %+v after replacing the database, want CA", l.Lookup("1.2.3.4"))
+}
@@ -0,0 +1,75 @@
+package geoip
+
+import (
+	"container/list"
+	"sync"
+)
+
+// lruCache is a fixed size, concurrency safe least-recently-used cache of
+// lookup results keyed by IP string. A capacity of zero or less disables it.
+type lruCache struct {
+	mu       sync.Mutex
+	capacity int
+	items    map[string]*list.Element
+	order    *list.List
+}
+
+type lruEntry struct {
+	key      string
+	location Location
+}
+
+func newLRUCache(capacity int) *lruCache {
+	capacity = max(capacity, 0)
+	return &lruCache{
+		capacity: capacity,
+		items:    make(map[string]*list.Element, capacity),
+		order:    list.New(),
+	}
+}
+
+func (c *lruCache) get(key string) (Location, bool) {
+	if c.capacity <= 0 {
+		return Location{}, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return Location{}, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*lruEntry).location, true
+}
+
+func (c *lruCache) add(key string, location Location) {
+	if c.capacity <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		el.Value.(*lruEntry).location = location
+		c.order.MoveToFront(el)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&lruEntry{key: key, location: location})
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruEntry).key)
+	}
+}
+
+func (c *lruCache) purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.items = make(map[string]*list.Element, c.capacity)
+	c.order.Init()
+}
@@ -0,0 +1,64 @@
+package geoip
+
+import "testing"
+
+func TestLRUCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := newLRUCache(2)
+	c.add("a", Location{Country: "AA"})
+	c.add("b", Location{Country: "BB"})
+
+	// Reading a makes b the oldest entry
+	if _, ok := c.get("a"); !ok {
+		t.Fatal("a missing")
+	}
+	c.add("c", Location{Country: "CC"})
+
+	if _, ok := c.get("b"); ok {
+		t.Error("b was not evicted")
+	}
+	for key, want := range map[string]string{"a": "AA", "c": "CC"} {
+		if got, ok := c.get(key); !ok || got.Country != want {
+			t.Errorf("get(%s) = %+v, %v, want %s", key, got, ok, want)
+		}
+	}
+}
+
+func TestLRUCacheUpdatesExistingEntries(t *testing.T) {
+	c := newLRUCache(2)
+	c.add("a", Location{Country: "AA"})
+	c.add("b", Location{Country: "BB"})
+	c.add("a", Location{Country: "XX"})
+	c.add("c", Location{Country: "CC"})
+
+	if got, ok := c.get("a"); !ok || got.Country != "XX" {
+		t.Errorf("get(a) = %+v, %v, want XX", got, ok)
+	}
+	if _, ok := c.get("b"); ok {
+		t.Error("b was not evicted")
+	}
+}
+
+func TestLRUCachePurge(t *testing.T) {
+	c := newLRUCache(2)
+	c.add("a", Location{Country: "AA"})
+	c.purge()
+
+	if _, ok := c.get("a"); ok {
+		t.Error("a survived purge")
+	}
+	c.add("b", Location{Country: "BB"})
+	if _, ok := c.get("b"); !ok {
+		t.Error("cache unusable after purge")
+	}
+}
+
+func TestLRUCacheDisabled(t *testing.T) {
+	for _, capacity := range []int{0, -1} {
+		c := newLRUCache(capacity)
+		c.add("a", Location{Country: "AA"})
+		if _, ok := c.get("a"); ok {
+			t.Errorf("capacity %d: cache kept an entry", capacity)
+		}
+		c.purge()
+	}
+}
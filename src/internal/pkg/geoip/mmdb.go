@@ -0,0 +1,375 @@
+package geoip
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net/netip"
+)
+
+// MaxMind DB format reference: https://maxmind.github.io/MaxMind-DB/
+
+var mmdbMetadataMarker = []byte("\xAB\xCD\xEFMaxMind.com")
+
+const (
+	mmdbDataSeparatorSize = 16
+	mmdbMaxDecodeDepth    = 32
+)
+
+const (
+	mmdbExtended = iota
+	mmdbPointer
+	mmdbString
+	mmdbDouble
+	mmdbBytes
+	mmdbUint16
+	mmdbUint32
+	mmdbMap
+	mmdbInt32
+	mmdbUint64
+	mmdbUint128
+	mmdbArray
+	mmdbContainer
+	mmdbEndMarker
+	mmdbBool
+	mmdbFloat
+)
+
+var errMMDBCorrupt = errors.New("geoip: corrupt mmdb data")
+
+// mmdbReader is a minimal read-only MaxMind DB reader that decodes just
+// enough of the format to resolve country and city names
+type mmdbReader struct {
+	buf        []byte
+	data       []byte
+	nodeCount  uint
+	recordSize uint
+	ipVersion  uint
+	ipv4Start  uint
+	nodeBytes  uint
+}
+
+func newMMDBReader(buf []byte) (*mmdbReader, error) {
+	idx := bytes.LastIndex(buf, mmdbMetadataMarker)
+	if idx == -1 {
+		return nil, errors.New("geoip: mmdb metadata marker not found")
+	}
+
+	metaDecoder := &mmdbDecoder{data: buf[idx+len(mmdbMetadataMarker):]}
+	raw, _, err := metaDecoder.decode(0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("geoip: failed to decode mmdb metadata: %w", err)
+	}
+	meta, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("geoip: mmdb metadata is not a map")
+	}
+
+	r := &mmdbReader{
+		buf:        buf,
+		nodeCount:  uint(toUint(meta["node_count"])),
+		recordSize: uint(toUint(meta["record_size"])),
+		ipVersion:  uint(toUint(meta["ip_version"])),
+	}
+	switch r.recordSize {
+	case 24, 28, 32:
+	default:
+		return nil, fmt.Errorf("geoip: unsupported mmdb record size %d", r.recordSize)
+	}
+	r.nodeBytes = r.recordSize / 4
+
+	treeSize := r.nodeCount * r.nodeBytes
+	if treeSize+mmdbDataSeparatorSize > uint(idx) {
+		return nil, errMMDBCorrupt
+	}
+	r.data = buf[treeSize+mmdbDataSeparatorSize : idx]
+
+	// IPv4 addresses live under ::/96 in IPv6 trees
+	if r.ipVersion == 6 {
+		node := uint(0)
+		for i := 0; i < 96 && node < r.nodeCount; i++ {
+			node, err = r.readRecord(node, 0)
+			if err != nil {
+				return nil, err
+			}
+		}
+		r.ipv4Start = node
+	}
+	return r, nil
+}
+
+func (r *mmdbReader) lookup(ip netip.Addr) (Location, error) {
+	ip = ip.Unmap()
+
+	var node uint
+	var bits []byte
+	switch {
+	case ip.Is4():
+		a := ip.As4()
+		bits = a[:]
+		node = r.ipv4Start
+	case r.ipVersion == 4:
+		return Location{}, nil
+	default:
+		a := ip.As16()
+		bits = a[:]
+	}
+
+	for i := 0; i < len(bits)*8 && node < r.nodeCount; i++ {
+		bit := (bits[i>>3] >> (7 - uint(i&7))) & 1
+		next, err := r.readRecord(node, uint(bit))
+		if err != nil {
+			return Location{}, err
+		}
+		node = next
+	}
+
+	if node == r.nodeCount {
+		return Location{}, nil
+	}
+	if node < r.nodeCount {
+		return Location{}, errMMDBCorrupt
+	}
+
+	offset := node - r.nodeCount - mmdbDataSeparatorSize
+	if offset >= uint(len(r.data)) {
+		return Location{}, errMMDBCorrupt
+	}
+
+	decoder := &mmdbDecoder{data: r.data}
+	record, _, err := decoder.decode(offset, 0)
+	if err != nil {
+		return Location{}, err
+	}
+	return locationFromRecord(record), nil
+}
+
+func (r *mmdbReader) readRecord(node, bit uint) (uint, error) {
+	base := node * r.nodeBytes
+	if base+r.nodeBytes > uint(len(r.buf)) {
+		return 0, errMMDBCorrupt
+	}
+	b := r.buf[base : base+r.nodeBytes]
+
+	switch r.recordSize {
+	case 24:
+		off := bit * 3
+		return uint(b[off])<<16 | uint(b[off+1])<<8 | uint(b[off+2]), nil
+	case 28:
+		if bit == 0 {
+			return uint(b[3]&0xF0)<<20 | uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2]), nil
+		}
+		return uint(b[3]&0x0F)<<24 | uint(b[4])<<16 | uint(b[5])<<8 | uint(b[6]), nil
+	default:
+		off := bit * 4
+		return uint(binary.BigEndian.Uint32(b[off : off+4])), nil
+	}
+}
+
+// locationFromRecord pulls country.iso_code and city.names.en out of a
+// GeoIP2/GeoLite2 style record
+func locationFromRecord(record interface{}) Location {
+	var loc Location
+	m, ok := record.(map[string]interface{})
+	if !ok {
+		return loc
+	}
+
+	for _, key := range []string{"country", "registered_country"} {
+		if country, ok := m[key].(map[string]interface{}); ok {
+			if code, ok := country["iso_code"].(string); ok && code != "" {
+				loc.Country = code
+				break
+			}
+		}
+	}
+
+	if city, ok := m["city"].(map[string]interface{}); ok {
+		if names, ok := city["names"].(map[string]interface{}); ok {
+			loc.City, _ = names["en"].(string)
+		}
+	}
+	return loc
+}
+
+type mmdbDecoder struct {
+	data []byte
+}
+
+// decode reads the value at offset and returns it with the offset of the next
+// value
+func (d *mmdbDecoder) decode(offset uint, depth int) (interface{}, uint, error) {
+	if depth > mmdbMaxDecodeDepth {
+		return nil, 0, errMMDBCorrupt
+	}
+
+	typ, size, offset, err := d.decodeControl(offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if typ == mmdbPointer {
+		pointer, next, err := d.decodePointer(size, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		value, _, err := d.decode(pointer, depth+1)
+		return value, next, err
+	}
+
+	end := offset + size
+	switch typ {
+	case mmdbMap:
+		m := make(map[string]interface{}, size)
+		for i := uint(0); i < size; i++ {
+			key, next, err := d.decode(offset, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			k, ok := key.(string)
+			if !ok {
+				return nil, 0, errMMDBCorrupt
+			}
+			value, next, err := d.decode(next, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			m[k] = value
+			offset = next
+		}
+		return m, offset, nil
+	case mmdbArray:
+		a := make([]interface{}, 0, size)
+		for i := uint(0); i < size; i++ {
+			value, next, err := d.decode(offset, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			a = append(a, value)
+			offset = next
+		}
+		return a, offset, nil
+	case mmdbBool:
+		return size != 0, offset, nil
+	}
+
+	if end > uint(len(d.data)) {
+		return nil, 0, errMMDBCorrupt
+	}
+	raw := d.data[offset:end]
+
+	switch typ {
+	case mmdbString:
+		return string(raw), end, nil
+	case mmdbBytes, mmdbUint128:
+		return append([]byte(nil), raw...), end, nil
+	case mmdbDouble:
+		if size != 8 {
+			return nil, 0, errMMDBCorrupt
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(raw)), end, nil
+	case mmdbFloat:
+		if size != 4 {
+			return nil, 0, errMMDBCorrupt
+		}
+		return math.Float32frombits(binary.BigEndian.Uint32(raw)), end, nil
+	case mmdbUint16, mmdbUint32, mmdbUint64:
+		if size > 8 {
+			return nil, 0, errMMDBCorrupt
+		}
+		var v uint64
+		for _, b := range raw {
+			v = v<<8 | uint64(b)
+		}
+		return v, end, nil
+	case mmdbInt32:
+		if size > 4 {
+			return nil, 0, errMMDBCorrupt
+		}
+		var v uint32
+		for _, b := range raw {
+			v = v<<8 | uint32(b)
+		}
+		return int32(v), end, nil
+	case mmdbContainer, mmdbEndMarker:
+		return nil, end, nil
+	default:
+		return nil, 0, fmt.Errorf("geoip: unknown mmdb data type %d", typ)
+	}
+}
+
+func (d *mmdbDecoder) decodeControl(offset uint) (typ, size, next uint, err error) {
+	if offset >= uint(len(d.data)) {
+		return 0, 0, 0, errMMDBCorrupt
+	}
+	ctrl := d.data[offset]
+	offset++
+
+	typ = uint(ctrl >> 5)
+	if typ == mmdbExtended {
+		if offset >= uint(len(d.data)) {
+			return 0, 0, 0, errMMDBCorrupt
+		}
+		typ = 7 + uint(d.data[offset])
+		offset++
+	}
+
+	size = uint(ctrl & 0x1F)
+	if typ == mmdbPointer || size < 29 {
+		return typ, size, offset, nil
+	}
+
+	extra := size - 28
+	if offset+extra > uint(len(d.data)) {
+		return 0, 0, 0, errMMDBCorrupt
+	}
+	b := d.data[offset : offset+extra]
+	switch extra {
+	case 1:
+		size = 29 + uint(b[0])
+	case 2:
+		size = 285 + (uint(b[0])<<8 | uint(b[1]))
+	default:
+		size = 65821 + (uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2]))
+	}
+	return typ, size, offset + extra, nil
+}
+
+func (d *mmdbDecoder) decodePointer(size, offset uint) (uint, uint, error) {
+	n := ((size >> 3) & 0x3) + 1
+	if offset+n > uint(len(d.data)) {
+		return 0, 0, errMMDBCorrupt
+	}
+	b := d.data[offset : offset+n]
+
+	var prefix uint
+	if n != 4 {
+		prefix = size & 0x7
+	}
+
+	var pointer uint
+	switch n {
+	case 1:
+		pointer = prefix<<8 | uint(b[0])
+	case 2:
+		pointer = (prefix<<16 | uint(b[0])<<8 | uint(b[1])) + 2048
+	case 3:
+		pointer = (prefix<<24 | uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])) + 526336
+	default:
+		pointer = uint(binary.BigEndian.Uint32(b))
+	}
+	return pointer, offset + n, nil
+}
+
+func toUint(v interface{}) uint64 {
+	switch n := v.(type) {
+	case uint64:
+		return n
+	case int32:
+		return uint64(n)
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,532 @@
+package geoip
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"net/netip"
+	"strings"
+	"testing"
+)
+
+// mmdbNetwork is one network of a fixture database and the record it maps to
+type mmdbNetwork struct {
+	prefix string
+	record []byte
+}
+
+// mmdbFixture builds a MaxMind DB in memory. It writes only what the reader
+// needs: the search tree, a data section and the metadata map.
+type mmdbFixture struct {
+	recordSize int
+	ipVersion  int
+	networks   []mmdbNetwork
+}
+
+type mmdbNode struct {
+	// A record is the index of a child node, or a data offset when leaf is set
+	records [2]int
+	leaf    [2]bool
+	child   [2]bool
+}
+
+func (f mmdbFixture) build(t *testing.T) []byte {
+	t.Helper()
+
+	nodes := []mmdbNode{{}}
+	var data bytes.Buffer
+	for _, network := range f.networks {
+		prefix := netip.MustParsePrefix(network.prefix)
+		addr, bits := prefix.Addr(), prefix.Bits()
+		var ip []byte
+		if f.ipVersion == 6 {
+			a := addr.As16()
+			ip = a[:]
+			if addr.Is4() {
+				// IPv4 networks live under ::/96 in IPv6 trees
+				a = [16]byte{}
+				v4 := addr.As4()
+				copy(a[12:], v4[:])
+				ip = a[:]
+				bits += 96
+			}
+		} else {
+			a := addr.As4()
+			ip = a[:]
+		}
+
+		offset := data.Len()
+		data.Write(network.record)
+
+		node := 0
+		for i := range bits {
+			bit := (ip[i/8] >> (7 - uint(i%8))) & 1
+			if i == bits-1 {
+				nodes[node].records[bit] = offset
+				nodes[node].leaf[bit] = true
+				break
+			}
+			if !nodes[node].child[bit] {
+				nodes = append(nodes, mmdbNode{})
+				nodes[node].records[bit] = len(nodes) - 1
+				nodes[node].child[bit] = true
+			}
+			node = nodes[node].records[bit]
+		}
+	}
+
+	nodeCount := len(nodes)
+	var out bytes.Buffer
+	for _, node := range nodes {
+		var values [2]uint32
+		for bit := range 2 {
+			switch {
+			case node.child[bit]:
+				values[bit] = uint32(node.records[bit])
+			case node.leaf[bit]:
+				values[bit] = uint32(nodeCount + mmdbDataSeparatorSize + node.records[bit])
+			default:
+				values[bit] = uint32(nodeCount)
+			}
+		}
+		out.Write(encodeNode(t, f.recordSize, values))
+	}
+	out.Write(make([]byte, mmdbDataSeparatorSize))
+	out.Write(data.Bytes())
+	out.Write(mmdbMetadataMarker)
+	out.Write(encMap(
+		"node_count", encUint(6, uint64(nodeCount)),
+		"record_size", encUint(5, uint64(f.recordSize)),
+		"ip_version", encUint(5, uint64(f.ipVersion)),
+		"database_type", encString("Test-City"),
+	))
+	return out.Bytes()
+}
+
+func encodeNode(t *testing.T, recordSize int, v [2]uint32) []byte {
+	t.Helper()
+	switch recordSize {
+	case 24:
+		return []byte{
+			byte(v[0] >> 16), byte(v[0] >> 8), byte(v[0]),
+			byte(v[1] >> 16), byte(v[1] >> 8), byte(v[1]),
+		}
+	case 28:
+		return []byte{
+			byte(v[0] >> 16), byte(v[0] >> 8), byte(v[0]),
+			byte(v[0]>>20)&0xF0 | byte(v[1]>>24)&0x0F,
+			byte(v[1] >> 16), byte(v[1] >> 8), byte(v[1]),
+		}
+	case 32:
+		b := make([]byte, 8)
+		binary.BigEndian.PutUint32(b, v[0])
+		binary.BigEndian.PutUint32(b[4:], v[1])
+		return b
+	}
+	t.Fatalf("unsupported record size %d", recordSize)
+	return nil
+}
+
+// encControl encodes the control byte, and any extended type and size
+// bytes, of a value
+func encControl(typ int, size int) []byte {
+	var ctrl []byte
+	first := byte(typ) << 5
+	var ext []byte
+	if typ > 7 {
+		first = 0
+		ext = []byte{byte(typ - 7)}
+	}
+
+	switch {
+	case size < 29:
+		ctrl = append(ctrl, first|byte(size))
+	case size < 285:
+		ctrl = append(ctrl, first|29)
+		ext = append(ext, byte(size-29))
+	case size < 65821:
+		ctrl = append(ctrl, first|30)
+		n := size - 285
+		ext = append(ext, byte(n>>8), byte(n))
+	default:
+		ctrl = append(ctrl, first|31)
+		n := size - 65821
+		ext = append(ext, byte(n>>16), byte(n>>8), byte(n))
+	}
+	return append(ctrl, ext...)
+}
+
+func encString(s string) []byte {
+	return append(encControl(mmdbString, len(s)), s...)
+}
+
+func encUint(typ int, v uint64) []byte {
+	var b []byte
+	for ; v > 0; v >>= 8 {
+		b = append([]byte{byte(v)}, b...)
+	}
+	return append(encControl(typ, len(b)), b...)
+}
+
+// encMap encodes alternating keys and already encoded values
+func encMap(pairs ...interface{}) []byte {
+	out := encControl(mmdbMap, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		out = append(out, encString(pairs[i].(string))...)
+		out = append(out, pairs[i+1].([]byte)...)
+	}
+	return out
+}
+
+// encPointer encodes a pointer to offset in the data section
+func encPointer(offset int) []byte {
+	switch {
+	case offset < 2048:
+		return []byte{byte(mmdbPointer)<<5 | byte(offset>>8), byte(offset)}
+	case offset < 526336:
+		n := offset - 2048
+		return []byte{byte(mmdbPointer)<<5 | 1<<3 | byte(n>>16), byte(n >> 8), byte(n)}
+	default:
+		n := offset - 526336
+		return []byte{byte(mmdbPointer)<<5 | 2<<3 | byte(n>>24), byte(n >> 16), byte(n >> 8), byte(n)}
+	}
+}
+
+func cityRecord(country, city string) []byte {
+	return encMap(
+		"city", encMap("geoname_id", encUint(6, 5391959), "names", encMap("en", encString(city))),
+		"country", encMap("iso_code", encString(country)),
+		"location", encMap("accuracy_radius", encUint(5, 10)),
+	)
+}
+
+func testNetworks() []mmdbNetwork {
+	return []mmdbNetwork{
+		{prefix: "1.2.3.0/24", record: cityRecord("US", "Springfield")},
+		{prefix: "81.2.68.0/23", record: encMap("registered_country", encMap("iso_code", encString("GB")))},
+		{prefix: "2001:db8::/32", record: cityRecord("DE", "Berlin")},
+	}
+}
+
+func TestMMDBLookup(t *testing.T) {
+	lookups := []struct {
+		ip     string
+		want   Location
+		wantV4 Location
+	}{
+		{ip: "1.2.3.4", want: Location{Country: "US", City: "Springfield"}, wantV4: Location{Country: "US", City: "Springfield"}},
+		{ip: "1.2.3.255", want: Location{Country: "US", City: "Springfield"}, wantV4: Location{Country: "US", City: "Springfield"}},
+		{ip: "::ffff:1.2.3.4", want: Location{Country: "US", City: "Springfield"}, wantV4: Location{Country: "US", City: "Springfield"}},
+		{ip: "81.2.69.200", want: Location{Country: "GB"}, wantV4: Location{Country: "GB"}},
+		{ip: "1.2.4.1", want: Location{}, wantV4: Location{}},
+		{ip: "2001:db8:1::1", want: Location{Country: "DE", City: "Berlin"}, wantV4: Location{}},
+		{ip: "2001:db9::1", want: Location{}, wantV4: Location{}},
+	}
+
+	for _, recordSize := range []int{24, 28, 32} {
+		for _, ipVersion := range []int{4, 6} {
+			networks := testNetworks()
+			if ipVersion == 4 {
+				networks = networks[:2]
+			}
+			db := mmdbFixture{recordSize: recordSize, ipVersion: ipVersion, networks: networks}.build(t)
+
+			r, err := newMMDBReader(db)
+			if err != nil {
+				t.Fatalf("record size %d, IPv%d: newMMDBReader: %v", recordSize, ipVersion, err)
+			}
+			for _, tt := range lookups {
+				want := tt.want
+				if ipVersion == 4 {
+					want = tt.wantV4
+				}
+				got, err := r.lookup(netip.MustParseAddr(tt.ip))
+				if err != nil {
+					t.Errorf("record size %d, IPv%d: lookup(%s): %v", recordSize, ipVersion, tt.ip, err)
+					continue
+				}
+				if got != want {
+					t.Errorf("record size %d, IPv%d: lookup(%s) = %+v, want %+v", recordSize, ipVersion, tt.ip, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestMMDBLargeTree(t *testing.T) {
+	// Enough nodes that records need all of their bits
+	var networks []mmdbNetwork
+	for i := range 4096 {
+		prefix := netip.AddrFrom4([4]byte{10, byte(i >> 4), byte(i << 4), 0})
+		networks = append(networks, mmdbNetwork{
+			prefix: netip.PrefixFrom(prefix, 28).String(),
+			record: encMap("country", encMap("iso_code", encString("NL"))),
+		})
+	}
+
+	for _, recordSize := range []int{24, 28, 32} {
+		db := mmdbFixture{recordSize: recordSize, ipVersion: 6, networks: networks}.build(t)
+		r, err := newMMDBReader(db)
+		if err != nil {
+			t.Fatalf("record size %d: newMMDBReader: %v", recordSize, err)
+		}
+		got, err := r.lookup(netip.MustParseAddr("10.255.240.3"))
+		if err != nil || got.Country != "NL" {
+			t.Errorf("record size %d: lookup = %+v, %v, want NL", recordSize, got, err)
+		}
+	}
+}
+
+func TestMMDBReadRecord(t *testing.T) {
+	tests := []struct {
+		recordSize  uint
+		node        []byte
+		left, right uint
+	}{
+		{recordSize: 24, node: []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06}, left: 0x010203, right: 0x040506},
+		{recordSize: 28, node: []byte{0x12, 0x34, 0x56, 0xAB, 0xCD, 0xEF, 0x01}, left: 0xA123456, right: 0xBCDEF01},
+		{recordSize: 32, node: []byte{0xDE, 0xAD, 0xBE, 0xEF, 0x01, 0x02, 0x03, 0x04}, left: 0xDEADBEEF, right: 0x01020304},
+	}
+	for _, tt := range tests {
+		// The node under test is the second one
+		buf := append(make([]byte, len(tt.node)), tt.node...)
+		r := &mmdbReader{buf: buf, recordSize: tt.recordSize, nodeBytes: tt.recordSize / 4}
+
+		left, err := r.readRecord(1, 0)
+		if err != nil || left != tt.left {
+			t.Errorf("record size %d: left = %#x, %v, want %#x", tt.recordSize, left, err, tt.left)
+		}
+		right, err := r.readRecord(1, 1)
+		if err != nil || right != tt.right {
+			t.Errorf("record size %d: right = %#x, %v, want %#x", tt.recordSize, right, err, tt.right)
+		}
+		if _, err := r.readRecord(2, 0); !errors.Is(err, errMMDBCorrupt) {
+			t.Errorf("record size %d: reading past the tree: error = %v, want errMMDBCorrupt", tt.recordSize, err)
+		}
+	}
+}
+
+func TestMMDBDecode(t *testing.T) {
+	long := strings.Repeat("a", 300)
+	huge := strings.Repeat("b", 70000)
+
+	tests := []struct {
+		name string
+		data []byte
+		want interface{}
+	}{
+		{name: "short string", data: encString("US"), want: "US"},
+		{name: "string with one size byte", data: encString(long[:100]), want: long[:100]},
+		{name: "string with two size bytes", data: encString(long), want: long},
+		{name: "string with three size bytes", data: encString(huge), want: huge},
+		{name: "uint16", data: encUint(mmdbUint16, 443), want: uint64(443)},
+		{name: "uint32", data: encUint(mmdbUint32, 1<<31), want: uint64(1 << 31)},
+		{name: "uint64", data: encUint(mmdbUint64, 1<<40), want: uint64(1 << 40)},
+		{name: "zero", data: encUint(mmdbUint32, 0), want: uint64(0)},
+		{name: "int32", data: append(encControl(mmdbInt32, 4), 0xFF, 0xFF, 0xFF, 0xFE), want: int32(-2)},
+		{name: "double", data: append(encControl(mmdbDouble, 8), 0x40, 0x09, 0x21, 0xFB, 0x54, 0x44, 0x2D, 0x18), want: 3.141592653589793},
+		{name: "float", data: append(encControl(mmdbFloat, 4), 0x3F, 0x80, 0x00, 0x00), want: float32(1)},
+		{name: "true", data: encControl(mmdbBool, 1), want: true},
+		{name: "false", data: encControl(mmdbBool, 0), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &mmdbDecoder{data: tt.data}
+			got, next, err := d.decode(0, 0)
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("decode = %v, want %v", got, tt.want)
+			}
+			if next != uint(len(tt.data)) {
+				t.Errorf("decode ended at %d, want %d", next, len(tt.data))
+			}
+		})
+	}
+}
+
+func TestMMDBDecodeContainers(t *testing.T) {
+	array := append(encControl(mmdbArray, 2), encString("en")...)
+	array = append(array, encString("de")...)
+	d := &mmdbDecoder{data: encMap("languages", array, "build_epoch", encUint(mmdbUint64, 1700000000))}
+
+	got, _, err := d.decode(0, 0)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	m, ok := got.(map[string]interface{})
+	if !ok {
+		t.Fatalf("decode = %T, want a map", got)
+	}
+	languages, ok := m["languages"].([]interface{})
+	if !ok || len(languages) != 2 || languages[0] != "en" || languages[1] != "de" {
+		t.Errorf("languages = %v, want [en de]", m["languages"])
+	}
+	if m["build_epoch"] != uint64(1700000000) {
+		t.Errorf("build_epoch = %v, want 1700000000", m["build_epoch"])
+	}
+}
+
+func TestMMDBDecodePointers(t *testing.T) {
+	for _, offset := range []int{0, 100, 3000, 600000} {
+		// The pointed to value sits at offset, and the pointer after it
+		data := make([]byte, offset)
+		data = append(data, encString("GB")...)
+		pointerAt := len(data)
+		data = append(data, encPointer(offset)...)
+
+		d := &mmdbDecoder{data: data}
+		got, next, err := d.decode(uint(pointerAt), 0)
+		if err != nil {
+			t.Fatalf("offset %d: decode: %v", offset, err)
+		}
+		if got != "GB" {
+			t.Errorf("offset %d: decode = %v, want GB", offset, got)
+		}
+		// Decoding carries on after the pointer, not after the value
+		if next != uint(len(data)) {
+			t.Errorf("offset %d: decode ended at %d, want %d", offset, next, len(data))
+		}
+	}
+}
+
+func TestMMDBSharedRecords(t *testing.T) {
+	// Databases point records at shared maps instead of repeating them
+	country := encMap("iso_code", encString("FR"))
+	first := encMap("country", country, "city", encMap("names", encMap("en", encString("Paris"))))
+	countryOffset := len(encControl(mmdbMap, 2)) + len(encString("country"))
+	second := encMap("country", encPointer(countryOffset))
+
+	db := mmdbFixture{recordSize: 24, ipVersion: 4, networks: []mmdbNetwork{
+		{prefix: "5.5.0.0/16", record: first},
+		{prefix: "6.6.0.0/16", record: second},
+	}}.build(t)
+	r, err := newMMDBReader(db)
+	if err != nil {
+		t.Fatalf("newMMDBReader: %v", err)
+	}
+
+	for ip, want := range map[string]Location{
+		"5.5.5.5": {Country: "FR", City: "Paris"},
+		"6.6.6.6": {Country: "FR"},
+	} {
+		got, err := r.lookup(netip.MustParseAddr(ip))
+		if err != nil || got != want {
+			t.Errorf("lookup(%s) = %+v, %v, want %+v", ip, got, err, want)
+		}
+	}
+}
+
+func TestMMDBCorrupt(t *testing.T) {
+	valid := mmdbFixture{recordSize: 24, ipVersion: 6, networks: testNetworks()}.build(t)
+	marker := bytes.LastIndex(valid, mmdbMetadataMarker)
+
+	withMetadata := func(meta []byte) []byte {
+		return append(append(append([]byte(nil), valid[:marker]...), mmdbMetadataMarker...), meta...)
+	}
+
+	tests := []struct {
+		name string
+		db   []byte
+	}{
+		{name: "empty", db: nil},
+		{name: "no metadata", db: valid[:marker]},
+		{name: "metadata not a map", db: withMetadata(encString("x"))},
+		{name: "truncated metadata", db: valid[:len(valid)-3]},
+		{name: "unsupported record size", db: withMetadata(encMap(
+			"node_count", encUint(6, 10), "record_size", encUint(5, 20), "ip_version", encUint(5, 6)))},
+		{name: "tree larger than file", db: withMetadata(encMap(
+			"node_count", encUint(6, 1<<20), "record_size", encUint(5, 24), "ip_version", encUint(5, 6)))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := newMMDBReader(tt.db); err == nil {
+				t.Error("newMMDBReader succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestMMDBCorruptData(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "empty", data: nil},
+		{name: "string past end", data: append(encControl(mmdbString, 10), "abc"...)},
+		{name: "missing extended type", data: []byte{0x00}},
+		{name: "missing size bytes", data: []byte{byte(mmdbString)<<5 | 30, 0x01}},
+		{name: "pointer past end", data: []byte{byte(mmdbPointer) << 5}},
+		{name: "pointer out of range", data: encPointer(500)},
+		{name: "map key not a string", data: append(encControl(mmdbMap, 1), encUint(mmdbUint16, 1)...)},
+		{name: "bad double size", data: append(encControl(mmdbDouble, 2), 0, 0)},
+		{name: "bad int32 size", data: append(encControl(mmdbInt32, 5), 0, 0, 0, 0, 0)},
+		{name: "pointer loop", data: encPointer(0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &mmdbDecoder{data: tt.data}
+			if _, _, err := d.decode(0, 0); err == nil {
+				t.Error("decode succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestMMDBLookupCorruptRecord(t *testing.T) {
+	// A record pointing past the data section
+	db := mmdbFixture{recordSize: 24, ipVersion: 4, networks: []mmdbNetwork{
+		{prefix: "7.7.7.0/24", record: encString("x")},
+	}}.build(t)
+	r, err := newMMDBReader(db)
+	if err != nil {
+		t.Fatalf("newMMDBReader: %v", err)
+	}
+	r.data = r.data[:0]
+
+	if _, err := r.lookup(netip.MustParseAddr("7.7.7.7")); !errors.Is(err, errMMDBCorrupt) {
+		t.Errorf("lookup error = %v, want errMMDBCorrupt", err)
+	}
+}
+
+func TestLocationFromRecord(t *testing.T) {
+	tests := []struct {
+		name   string
+		record interface{}
+		want   Location
+	}{
+		{name: "not a map", record: "US", want: Location{}},
+		{
+			name: "country wins over registered country",
+			record: map[string]interface{}{
+				"country":            map[string]interface{}{"iso_code": "CA"},
+				"registered_country": map[string]interface{}{"iso_code": "US"},
+			},
+			want: Location{Country: "CA"},
+		},
+		{
+			name: "registered country when country is empty",
+			record: map[string]interface{}{
+				"country":            map[string]interface{}{"iso_code": ""},
+				"registered_country": map[string]interface{}{"iso_code": "US"},
+			},
+			want: Location{Country: "US"},
+		},
+		{
+			name: "city without english name",
+			record: map[string]interface{}{
+				"country": map[string]interface{}{"iso_code": "JP"},
+				"city":    map[string]interface{}{"names": map[string]interface{}{"ja": "東京"}},
+			},
+			want: Location{Country: "JP"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := locationFromRecord(tt.record); got != tt.want {
+				t.Errorf("locationFromRecord = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+// Package jobs runs named background tasks on fixed intervals.
+package jobs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/imraushankr/brevity/server/src/internal/pkg/logger"
+)
+
+// Func is the work a job performs on each run. The context is cancelled when
+// the scheduler stops.
+type Func func(ctx context.Context) error
+
+type job struct {
+	name     string
+	interval time.Duration
+	run      Func
+}
+
+// Scheduler runs registered jobs in their own goroutines. A job never
+// overlaps with itself: a run that takes longer than the interval delays the
+// next one.
+type Scheduler struct {
+	log    logger.Logger
+	jobs   []job
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewScheduler creates an empty scheduler
+func NewScheduler(log logger.Logger) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		log:    log,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Every registers fn to run once at start and then every interval. It must be
+// called before Start. A job without a positive interval is logged and never
+// runs.
+func (s *Scheduler) Every(name string, interval time.Duration, fn Func) {
+	if interval <= 0 {
+		s.log.Error("Job not scheduled, its interval must be positive",
+			logger.String("job", name),
+			logger.Duration("interval", interval))
+		return
+	}
+	s.jobs = append(s.jobs, job{name: name, interval: interval, run: fn})
+}
+
+// Start launches all registered jobs
+func (s *Scheduler) Start() {
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(j)
+	}
+	s.log.Info("Job scheduler started", logger.Int("jobs", len(s.jobs)))
+}
+
+// Stop cancels running jobs and waits for them to return or ctx to expire
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Scheduler) loop(j job) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		s.runOnce(j)
+
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) runOnce(j job) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.log.Error("Job panicked",
+				logger.String("job", j.name),
+				logger.Any("panic", r))
+		}
+	}()
+
+	start := time.Now()
+	if err := j.run(s.ctx); err != nil {
+		if s.ctx.Err() != nil {
+			return
+		}
+		s.log.Error("Job failed",
+			logger.String("job", j.name),
+			logger.NamedError("error", err),
+			logger.Duration("duration", time.Since(start)))
+		return
+	}
+	s.log.Debug("Job finished",
+		logger.String("job", j.name),
+		logger.Duration("duration", time.Since(start)))
+}
@@ -0,0 +1,40 @@
+package jobs
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/imraushankr/brevity/server/src/internal/pkg/logger"
+)
+
+func TestEveryRejectsNonPositiveIntervals(t *testing.T) {
+	s := NewScheduler(logger.Get())
+	var runs atomic.Int32
+	count := func(context.Context) error {
+		runs.Add(1)
+		return nil
+	}
+
+	s.Every("zero", 0, count)
+	s.Every("negative", -time.Second, count)
+	s.Every("ok", time.Hour, count)
+	if len(s.jobs) != 1 || s.jobs[0].name != "ok" {
+		t.Fatalf("jobs = %+v, want only the job with a positive interval", s.jobs)
+	}
+
+	s.Start()
+	deadline := time.Now().Add(time.Second)
+	for runs.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := s.Stop(ctx); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	if got := runs.Load(); got != 1 {
+		t.Errorf("runs = %d, want 1", got)
+	}
+}
@@ -0,0 +1,106 @@
+// Package linkcheck tells whether a link destination still responds. It
+// sends a HEAD request and falls back to GET for servers that refuse or
+// mishandle HEAD, following redirects to the final response.
+package linkcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"time"
+
+	"github.com/imraushankr/brevity/server/src/configs"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/safehttp"
+)
+
+// maxDrain bounds how much of a GET response is read so the connection can
+// be reused
+const maxDrain = 4 << 10
+
+// Result is the outcome of a check. StatusCode is zero when no response was
+// received, in which case Err says why.
+type Result struct {
+	StatusCode int
+	Latency    time.Duration
+	Err        error
+}
+
+// OK reports whether the destination answered with a success or redirect
+// status
+func (r Result) OK() bool {
+	return r.Err == nil && r.StatusCode >= 200 && r.StatusCode < 400
+}
+
+// Problem describes a failed check, e.g. "HTTP 404"
+func (r Result) Problem() string {
+	if r.Err != nil {
+		return r.Err.Error()
+	}
+	if r.OK() {
+		return ""
+	}
+	return fmt.Sprintf("HTTP %d", r.StatusCode)
+}
+
+// Checker checks destinations with strict time limits, refusing non-public
+// addresses unless cfg allows them
+type Checker struct {
+	client    *http.Client
+	userAgent string
+}
+
+// New creates a checker from cfg
+func New(cfg *configs.HealthCheckConfig) *Checker {
+	return &Checker{
+		client: safehttp.NewClient(safehttp.Options{
+			Timeout:      cfg.Timeout,
+			MaxRedirects: cfg.MaxRedirects,
+			AllowPrivate: cfg.AllowPrivateNetworks,
+		}),
+		userAgent: cfg.UserAgent,
+	}
+}
+
+// Check requests rawURL with HEAD, and again with GET when HEAD fails or is
+// answered with an error status. The result of the last request is
+// returned.
+func (c *Checker) Check(ctx context.Context, rawURL string) Result {
+	u, err := neturl.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return Result{Err: fmt.Errorf("invalid url %q", rawURL)}
+	}
+
+	result := c.do(ctx, http.MethodHead, u.String())
+	if result.OK() || ctx.Err() != nil {
+		return result
+	}
+	return c.do(ctx, http.MethodGet, u.String())
+}
+
+func (c *Checker) do(ctx context.Context, method, rawURL string) Result {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return Result{Err: err}
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+	req.Header.Set("Accept", "*/*")
+
+	start := time.Now()
+	resp, err := c.client.Do(req)
+	latency := time.Since(start)
+	if err != nil {
+		// Report the cause without the method and url the client adds
+		var urlErr *neturl.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return Result{Latency: latency, Err: err}
+	}
+	defer resp.Body.Close()
+	_, _ = io.CopyN(io.Discard, resp.Body, maxDrain)
+
+	return Result{StatusCode: resp.StatusCode, Latency: latency}
+}
@@ -0,0 +1,135 @@
+package linksafety
+
+import (
+	"bufio"
+	"io"
+	"net/netip"
+	"os"
+	"strings"
+)
+
+// hostsAliases are names hosts files map to local addresses that are not
+// threats
+var hostsAliases = map[string]struct{}{
+	"localhost":             {},
+	"localhost.localdomain": {},
+	"local":                 {},
+	"broadcasthost":         {},
+	"ip6-localhost":         {},
+	"ip6-loopback":          {},
+}
+
+// Feed is a threat list of hosts and URL prefixes. Listed hosts match
+// themselves and their subdomains; prefixes match paths on a listed host
+// that start with them.
+type Feed struct {
+	hosts    map[string]struct{}
+	prefixes map[string][]string
+}
+
+// Len returns the number of entries in the feed
+func (f *Feed) Len() int {
+	n := len(f.hosts)
+	for _, paths := range f.prefixes {
+		n += len(paths)
+	}
+	return n
+}
+
+// LoadFeedFile reads a feed from the file at path, see ParseFeed
+func LoadFeedFile(path string) (*Feed, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ParseFeed(file)
+}
+
+// ParseFeed reads a threat list with one entry per line. Lines may be in
+// hosts file form, an address followed by host names, or Safe Browsing style
+// expressions: a host, optionally followed by a path prefix, with or without
+// a scheme. Blank lines and comments starting with # or ! are skipped, as
+// are lines that cannot be read.
+func ParseFeed(r io.Reader) (*Feed, error) {
+	feed := &Feed{
+		hosts:    make(map[string]struct{}),
+		prefixes: make(map[string][]string),
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexAny(line, "#!"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		// Hosts files list an address, usually 0.0.0.0 or 127.0.0.1,
+		// followed by the names it blocks
+		if _, err := netip.ParseAddr(fields[0]); err == nil && len(fields) > 1 {
+			for _, name := range fields[1:] {
+				host := normalizeHost(name)
+				if _, alias := hostsAliases[host]; !alias && host != "" {
+					feed.hosts[host] = struct{}{}
+				}
+			}
+			continue
+		}
+
+		feed.addExpression(fields[0])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return feed, nil
+}
+
+// addExpression adds a host or host and path prefix entry
+func (f *Feed) addExpression(expr string) {
+	if _, rest, ok := strings.Cut(expr, "://"); ok {
+		expr = rest
+	}
+	hostPart, path, hasPath := strings.Cut(expr, "/")
+	if at := strings.LastIndex(hostPart, "@"); at >= 0 {
+		hostPart = hostPart[at+1:]
+	}
+	if h, _, ok := strings.Cut(hostPart, ":"); ok && !strings.HasPrefix(hostPart, "[") {
+		hostPart = h
+	}
+
+	host := normalizeHost(hostPart)
+	if host == "" {
+		return
+	}
+	path = "/" + path
+	if !hasPath || path == "/" {
+		f.hosts[host] = struct{}{}
+		return
+	}
+	f.prefixes[host] = append(f.prefixes[host], path)
+}
+
+// match reports the entry matching host and path, if any
+func (f *Feed) match(host, path string) (string, bool) {
+	if f == nil || (len(f.hosts) == 0 && len(f.prefixes) == 0) {
+		return "", false
+	}
+	if path == "" {
+		path = "/"
+	}
+	for _, suffix := range hostSuffixes(host) {
+		if _, ok := f.hosts[suffix]; ok {
+			return suffix, true
+		}
+		for _, prefix := range f.prefixes[suffix] {
+			if strings.HasPrefix(path, prefix) {
+				return suffix + prefix, true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,96 @@
+package linksafety
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testFeed = `# hosts file style
+0.0.0.0 tracker.example ads.example
+127.0.0.1 localhost
+::1 ip6-localhost ip6-loopback
+0.0.0.0 Upper.EXAMPLE.   # trailing comment
+
+! safe browsing style expressions
+malware.example
+https://phish.example/account/verify
+http://user@creds.example:8080/login/
+bare-path.example/
+xn--80ak6aa92e.com
+`
+
+func TestParseFeed(t *testing.T) {
+	feed, err := ParseFeed(strings.NewReader(testFeed))
+	if err != nil {
+		t.Fatalf("ParseFeed: %v", err)
+	}
+	if got := feed.Len(); got != 8 {
+		t.Errorf("Len = %d, want 8", got)
+	}
+
+	tests := []struct {
+		host, path string
+		want       string
+	}{
+		{host: "tracker.example", path: "/", want: "tracker.example"},
+		{host: "cdn.ads.example", path: "/x.js", want: "ads.example"},
+		{host: "upper.example", path: "", want: "upper.example"},
+		{host: "malware.example", path: "/anything", want: "malware.example"},
+		{host: "phish.example", path: "/account/verify?id=1", want: "phish.example/account/verify"},
+		{host: "www.phish.example", path: "/account/verify/step2", want: "phish.example/account/verify"},
+		{host: "phish.example", path: "/account"},
+		{host: "phish.example", path: "/"},
+		{host: "creds.example", path: "/login/now", want: "creds.example/login/"},
+		{host: "creds.example", path: "/logout"},
+		{host: "bare-path.example", path: "/", want: "bare-path.example"},
+		{host: "xn--80ak6aa92e.com", path: "/", want: "xn--80ak6aa92e.com"},
+		// Local aliases of hosts files are not threats
+		{host: "localhost", path: "/"},
+		{host: "ip6-localhost", path: "/"},
+		{host: "example.com", path: "/"},
+		{host: "example", path: "/"},
+	}
+	for _, tt := range tests {
+		got, ok := feed.match(tt.host, tt.path)
+		if got != tt.want || ok != (tt.want != "") {
+			t.Errorf("match(%s, %s) = %q, %v, want %q", tt.host, tt.path, got, ok, tt.want)
+		}
+	}
+}
+
+func TestEmptyFeed(t *testing.T) {
+	var nilFeed *Feed
+	for _, feed := range []*Feed{nilFeed, {}} {
+		if _, ok := feed.match("malware.example", "/"); ok {
+			t.Errorf("empty feed %v matched", feed)
+		}
+	}
+
+	feed, err := ParseFeed(strings.NewReader("\n# only comments\n! and more\n"))
+	if err != nil {
+		t.Fatalf("ParseFeed: %v", err)
+	}
+	if feed.Len() != 0 {
+		t.Errorf("Len = %d, want 0", feed.Len())
+	}
+}
+
+func TestLoadFeedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "feed.txt")
+	if err := os.WriteFile(path, []byte("malware.example\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	feed, err := LoadFeedFile(path)
+	if err != nil {
+		t.Fatalf("LoadFeedFile: %v", err)
+	}
+	if _, ok := feed.match("malware.example", "/"); !ok {
+		t.Error("loaded feed does not match its entry")
+	}
+
+	if _, err := LoadFeedFile(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("LoadFeedFile succeeded for a missing file")
+	}
+}
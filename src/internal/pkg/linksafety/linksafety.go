@@ -0,0 +1,358 @@
+// Package linksafety checks destination URLs against blocklists of domains,
+// regular expressions and IP ranges, against a locally loaded threat feed,
+// and against the service's own host, so short links cannot be used to hide
+// phishing pages or to loop back into the shortener.
+package linksafety
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	neturl "net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/idna"
+)
+
+// Rule kinds
+const (
+	KindDomain  = "domain"
+	KindRegex   = "regex"
+	KindIPRange = "ip_range"
+)
+
+// Sources reported by a Match
+const (
+	SourceBlocklist  = "blocklist"
+	SourceThreatFeed = "threat_feed"
+	SourceSelf       = "self"
+)
+
+// maxPattern caps rule patterns, which are compiled on every reload
+const maxPattern = 512
+
+// Rule is a blocklist entry. Domains match themselves and their subdomains,
+// regular expressions the whole destination URL, and IP ranges hosts that are
+// or resolve to an address in the range.
+type Rule struct {
+	ID      string
+	Kind    string
+	Pattern string
+}
+
+// Match explains why a URL was refused
+type Match struct {
+	Source string
+	// RuleID is set for blocklist matches
+	RuleID  string
+	Pattern string
+}
+
+func (m *Match) String() string {
+	switch m.Source {
+	case SourceSelf:
+		return "destination points back at the shortener"
+	case SourceThreatFeed:
+		return fmt.Sprintf("destination is listed in the threat feed (%s)", m.Pattern)
+	default:
+		return fmt.Sprintf("destination matches blocklist entry %q", m.Pattern)
+	}
+}
+
+// Resolver looks up the addresses of a host. net.DefaultResolver satisfies
+// it.
+type Resolver interface {
+	LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error)
+}
+
+// Checker holds the compiled rules and feed. A Checker is safe for concurrent
+// use; rules and feed can be swapped while checks run.
+type Checker struct {
+	mu      sync.RWMutex
+	domains map[string]Rule
+	regexps []compiledRegexp
+	ranges  []compiledRange
+	feed    *Feed
+
+	selfHosts     map[string]struct{}
+	resolver      Resolver
+	lookupTimeout time.Duration
+	version       atomic.Uint64
+}
+
+type compiledRegexp struct {
+	rule Rule
+	re   *regexp.Regexp
+}
+
+type compiledRange struct {
+	rule   Rule
+	prefix netip.Prefix
+}
+
+// New creates a checker refusing URLs on selfHosts. When resolver is nil,
+// IP ranges only match hosts written as IP addresses.
+func New(selfHosts []string, resolver Resolver, lookupTimeout time.Duration) *Checker {
+	c := &Checker{
+		domains:       make(map[string]Rule),
+		feed:          &Feed{},
+		selfHosts:     make(map[string]struct{}, len(selfHosts)),
+		resolver:      resolver,
+		lookupTimeout: lookupTimeout,
+	}
+	for _, host := range selfHosts {
+		if host = normalizeHost(host); host != "" {
+			c.selfHosts[host] = struct{}{}
+		}
+	}
+	return c
+}
+
+// NormalizeRule validates a rule pattern and returns it in the form it is
+// matched in: domains lowercased in ASCII form, single addresses as ranges.
+func NormalizeRule(kind, pattern string) (string, error) {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" || len(pattern) > maxPattern {
+		return "", fmt.Errorf("pattern must be 1 to %d characters", maxPattern)
+	}
+
+	switch kind {
+	case KindDomain:
+		host := normalizeHost(strings.TrimPrefix(pattern, "*."))
+		if host == "" || strings.ContainsAny(host, "/:@ ") {
+			return "", fmt.Errorf("%q is not a domain name", pattern)
+		}
+		return host, nil
+	case KindRegex:
+		if _, err := regexp.Compile(pattern); err != nil {
+			return "", fmt.Errorf("invalid regular expression: %v", err)
+		}
+		return pattern, nil
+	case KindIPRange:
+		prefix, err := parseRange(pattern)
+		if err != nil {
+			return "", fmt.Errorf("%q is not an ip address or cidr range", pattern)
+		}
+		return prefix.String(), nil
+	default:
+		return "", fmt.Errorf("unknown kind %q", kind)
+	}
+}
+
+// SetRules replaces the blocklist. Rules that do not compile are skipped and
+// reported together in the returned error.
+func (c *Checker) SetRules(rules []Rule) error {
+	domains := make(map[string]Rule)
+	var regexps []compiledRegexp
+	var ranges []compiledRange
+	var errs []error
+
+	for _, rule := range rules {
+		pattern, err := NormalizeRule(rule.Kind, rule.Pattern)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("rule %s: %w", rule.ID, err))
+			continue
+		}
+		switch rule.Kind {
+		case KindDomain:
+			domains[pattern] = rule
+		case KindRegex:
+			regexps = append(regexps, compiledRegexp{rule: rule, re: regexp.MustCompile(pattern)})
+		case KindIPRange:
+			ranges = append(ranges, compiledRange{rule: rule, prefix: netip.MustParsePrefix(pattern)})
+		}
+	}
+
+	c.mu.Lock()
+	c.domains, c.regexps, c.ranges = domains, regexps, ranges
+	c.mu.Unlock()
+	c.version.Add(1)
+	return errors.Join(errs...)
+}
+
+// SetFeed replaces the threat feed
+func (c *Checker) SetFeed(feed *Feed) {
+	c.mu.Lock()
+	c.feed = feed
+	c.mu.Unlock()
+	c.version.Add(1)
+}
+
+// Version changes whenever the rules or the feed are replaced, so callers
+// can tell when stored URLs need checking again
+func (c *Checker) Version() uint64 {
+	return c.version.Load()
+}
+
+// Check reports whether rawURL is refused, resolving its host for IP range
+// rules. A nil Match means the URL is allowed. Lookup failures do not block
+// a URL.
+func (c *Checker) Check(ctx context.Context, rawURL string) *Match {
+	if m := c.Match(rawURL); m != nil {
+		return m
+	}
+
+	u, err := neturl.Parse(rawURL)
+	if err != nil || c.resolver == nil {
+		return nil
+	}
+	host := normalizeHost(u.Hostname())
+	if _, err := netip.ParseAddr(host); err == nil || host == "" {
+		return nil
+	}
+
+	c.mu.RLock()
+	hasRanges := len(c.ranges) > 0
+	c.mu.RUnlock()
+	if !hasRanges {
+		return nil
+	}
+
+	if c.lookupTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.lookupTimeout)
+		defer cancel()
+	}
+	addrs, err := c.resolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return nil
+	}
+	for _, addr := range addrs {
+		if m := c.matchAddr(addr); m != nil {
+			return m
+		}
+	}
+	return nil
+}
+
+// Match is Check without name resolution, for checking many stored URLs at
+// once. IP range rules only match hosts written as addresses.
+func (c *Checker) Match(rawURL string) *Match {
+	u, err := neturl.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return nil
+	}
+	host := normalizeHost(u.Hostname())
+	if host == "" {
+		return nil
+	}
+
+	if _, ok := c.selfHosts[host]; ok {
+		return &Match{Source: SourceSelf, Pattern: host}
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, suffix := range hostSuffixes(host) {
+		if rule, ok := c.domains[suffix]; ok {
+			return &Match{Source: SourceBlocklist, RuleID: rule.ID, Pattern: rule.Pattern}
+		}
+	}
+	for _, r := range c.regexps {
+		if r.re.MatchString(rawURL) {
+			return &Match{Source: SourceBlocklist, RuleID: r.rule.ID, Pattern: r.rule.Pattern}
+		}
+	}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if m := c.matchAddrLocked(addr); m != nil {
+			return m
+		}
+	}
+	if entry, ok := c.feed.match(host, u.EscapedPath()); ok {
+		return &Match{Source: SourceThreatFeed, Pattern: entry}
+	}
+	return nil
+}
+
+// Hostname returns the host of rawURL in the form rules are matched against,
+// or "" when it has none
+func Hostname(rawURL string) string {
+	u, err := neturl.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return ""
+	}
+	return normalizeHost(u.Hostname())
+}
+
+func (c *Checker) matchAddr(addr netip.Addr) *Match {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.matchAddrLocked(addr)
+}
+
+func (c *Checker) matchAddrLocked(addr netip.Addr) *Match {
+	addr = addr.Unmap()
+	for _, r := range c.ranges {
+		if r.prefix.Contains(addr) {
+			return &Match{Source: SourceBlocklist, RuleID: r.rule.ID, Pattern: r.rule.Pattern}
+		}
+	}
+	return nil
+}
+
+// parseRange reads a CIDR range or a single address
+func parseRange(s string) (netip.Prefix, error) {
+	if strings.Contains(s, "/") {
+		prefix, err := netip.ParsePrefix(s)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		return netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-unmapBits(prefix.Addr())).Masked(), nil
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// unmapBits is the prefix length lost when an IPv4-mapped IPv6 address is
+// unmapped
+func unmapBits(addr netip.Addr) int {
+	if addr.Is4In6() {
+		return 96
+	}
+	return 0
+}
+
+// normalizeHost lowercases host, drops a trailing dot and IPv6 brackets and
+// converts internationalized names to their ASCII form, so look-alike
+// Unicode spellings match the rules they imitate
+func normalizeHost(host string) string {
+	host = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	if host == "" {
+		return ""
+	}
+	if _, err := netip.ParseAddr(host); err == nil {
+		return host
+	}
+	if ascii, err := idna.Lookup.ToASCII(host); err == nil {
+		return ascii
+	}
+	return host
+}
+
+// hostSuffixes returns host and each of its parent domains, most specific
+// first. Addresses have no parents.
+func hostSuffixes(host string) []string {
+	if _, err := netip.ParseAddr(host); err == nil {
+		return []string{host}
+	}
+	suffixes := []string{host}
+	for {
+		_, parent, ok := strings.Cut(host, ".")
+		if !ok || parent == "" {
+			return suffixes
+		}
+		suffixes = append(suffixes, parent)
+		host = parent
+	}
+}
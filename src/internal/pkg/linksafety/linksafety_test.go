@@ -0,0 +1,275 @@
+package linksafety
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeRule(t *testing.T) {
+	tests := []struct {
+		kind    string
+		pattern string
+		want    string
+		wantErr bool
+	}{
+		{kind: KindDomain, pattern: " Example.COM. ", want: "example.com"},
+		{kind: KindDomain, pattern: "*.evil.example", want: "evil.example"},
+		{kind: KindDomain, pattern: "bücher.example", want: "xn--bcher-kva.example"},
+		{kind: KindDomain, pattern: "example.com/path", wantErr: true},
+		{kind: KindDomain, pattern: "user@example.com", wantErr: true},
+		{kind: KindDomain, pattern: "example.com:8080", wantErr: true},
+		{kind: KindDomain, pattern: "*.", wantErr: true},
+		{kind: KindRegex, pattern: `^https?://[^/]+/login\.php`, want: `^https?://[^/]+/login\.php`},
+		{kind: KindRegex, pattern: "(unclosed", wantErr: true},
+		{kind: KindIPRange, pattern: "10.1.2.3/8", want: "10.0.0.0/8"},
+		{kind: KindIPRange, pattern: "192.0.2.7", want: "192.0.2.7/32"},
+		{kind: KindIPRange, pattern: "2001:db8::1", want: "2001:db8::1/128"},
+		{kind: KindIPRange, pattern: "::ffff:10.0.0.0/104", want: "10.0.0.0/8"},
+		{kind: KindIPRange, pattern: "::ffff:192.0.2.1", want: "192.0.2.1/32"},
+		{kind: KindIPRange, pattern: "10.0.0.0/33", wantErr: true},
+		{kind: KindIPRange, pattern: "example.com", wantErr: true},
+		{kind: "keyword", pattern: "phish", wantErr: true},
+		{kind: KindDomain, pattern: "   ", wantErr: true},
+		{kind: KindRegex, pattern: strings.Repeat("a", maxPattern+1), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.kind+" "+tt.pattern, func(t *testing.T) {
+			got, err := NormalizeRule(tt.kind, tt.pattern)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("NormalizeRule = %q, want an error", got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("NormalizeRule = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func newTestChecker(t *testing.T, resolver Resolver) *Checker {
+	t.Helper()
+	c := New([]string{"Sho.rt", "[::1]"}, resolver, 0)
+	err := c.SetRules([]Rule{
+		{ID: "d1", Kind: KindDomain, Pattern: "evil.example"},
+		{ID: "d2", Kind: KindDomain, Pattern: "xn--pypal-4ve.com"},
+		{ID: "r1", Kind: KindRegex, Pattern: `/wp-login\.php`},
+		{ID: "i1", Kind: KindIPRange, Pattern: "203.0.113.0/24"},
+		{ID: "i2", Kind: KindIPRange, Pattern: "2001:db8:bad::/48"},
+	})
+	if err != nil {
+		t.Fatalf("SetRules: %v", err)
+	}
+	feed, err := ParseFeed(strings.NewReader("malware.example\nphish.example/account/\n"))
+	if err != nil {
+		t.Fatalf("ParseFeed: %v", err)
+	}
+	c.SetFeed(feed)
+	return c
+}
+
+func TestMatch(t *testing.T) {
+	c := newTestChecker(t, nil)
+
+	tests := []struct {
+		url    string
+		source string
+		ruleID string
+	}{
+		{url: "https://example.com/"},
+		{url: "https://notevil.example/"},
+		{url: "https://evil.example.org/"},
+		{url: "https://www.EVIL.example./path", source: SourceBlocklist, ruleID: "d1"},
+		{url: "https://a.b.evil.example:8443/", source: SourceBlocklist, ruleID: "d1"},
+		{url: "https://evil.example/", source: SourceBlocklist, ruleID: "d1"},
+		// Unicode look-alikes match their ASCII form
+		{url: "https://pаypal.com/", source: SourceBlocklist, ruleID: "d2"},
+		{url: "https://blog.example.com/wp-login.php?x=1", source: SourceBlocklist, ruleID: "r1"},
+		{url: "http://203.0.113.9/", source: SourceBlocklist, ruleID: "i1"},
+		{url: "http://[::ffff:203.0.113.9]/", source: SourceBlocklist, ruleID: "i1"},
+		{url: "http://[2001:db8:bad::1]:8080/", source: SourceBlocklist, ruleID: "i2"},
+		{url: "http://[2001:db8:good::1]/"},
+		{url: "https://sho.rt/abc", source: SourceSelf},
+		{url: "https://SHO.RT./abc", source: SourceSelf},
+		{url: "http://[::1]/", source: SourceSelf},
+		{url: "https://sub.sho.rt/abc"},
+		{url: "https://cdn.malware.example/file.exe", source: SourceThreatFeed},
+		{url: "https://phish.example/account/login", source: SourceThreatFeed},
+		{url: "https://phish.example/about"},
+		{url: "not a url"},
+		{url: "mailto:someone@evil.example"},
+		{url: "%zz"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			m := c.Match(tt.url)
+			switch {
+			case tt.source == "":
+				if m != nil {
+					t.Errorf("Match = %+v, want nil", m)
+				}
+			case m == nil:
+				t.Errorf("Match = nil, want %s", tt.source)
+			case m.Source != tt.source || m.RuleID != tt.ruleID:
+				t.Errorf("Match = %+v, want source %s rule %q", m, tt.source, tt.ruleID)
+			}
+		})
+	}
+}
+
+// fakeResolver answers lookups from a map and counts them
+type fakeResolver struct {
+	addrs   map[string][]netip.Addr
+	lookups []string
+}
+
+func (r *fakeResolver) LookupNetIP(_ context.Context, _, host string) ([]netip.Addr, error) {
+	r.lookups = append(r.lookups, host)
+	addrs, ok := r.addrs[host]
+	if !ok {
+		return nil, errors.New("no such host")
+	}
+	return addrs, nil
+}
+
+func TestCheckResolvesHosts(t *testing.T) {
+	resolver := &fakeResolver{addrs: map[string][]netip.Addr{
+		"bad.example":  {netip.MustParseAddr("198.51.100.1"), netip.MustParseAddr("203.0.113.5")},
+		"bad6.example": {netip.MustParseAddr("2001:db8:bad::7")},
+		"good.example": {netip.MustParseAddr("198.51.100.1")},
+	}}
+	c := newTestChecker(t, resolver)
+
+	tests := []struct {
+		url    string
+		ruleID string
+	}{
+		{url: "https://bad.example/", ruleID: "i1"},
+		{url: "https://BAD6.example./", ruleID: "i2"},
+		{url: "https://good.example/"},
+		// Lookup failures do not block
+		{url: "https://unknown.example/"},
+		// Earlier rules win without a lookup
+		{url: "https://evil.example/", ruleID: "d1"},
+	}
+	for _, tt := range tests {
+		m := c.Check(context.Background(), tt.url)
+		switch {
+		case tt.ruleID == "" && m != nil:
+			t.Errorf("Check(%s) = %+v, want nil", tt.url, m)
+		case tt.ruleID != "" && (m == nil || m.RuleID != tt.ruleID):
+			t.Errorf("Check(%s) = %+v, want rule %s", tt.url, m, tt.ruleID)
+		}
+	}
+
+	want := []string{"bad.example", "bad6.example", "good.example", "unknown.example"}
+	if !slices.Equal(resolver.lookups, want) {
+		t.Errorf("lookups = %v, want %v", resolver.lookups, want)
+	}
+}
+
+func TestCheckSkipsLookups(t *testing.T) {
+	resolver := &fakeResolver{}
+
+	// Without IP range rules there is nothing to resolve for
+	c := New(nil, resolver, 0)
+	if err := c.SetRules([]Rule{{ID: "d1", Kind: KindDomain, Pattern: "evil.example"}}); err != nil {
+		t.Fatal(err)
+	}
+	c.Check(context.Background(), "https://example.com/")
+
+	// Nor for addresses, which Match already compared
+	c = newTestChecker(t, resolver)
+	c.Check(context.Background(), "http://198.51.100.1/")
+	c.Check(context.Background(), "not a url")
+
+	if len(resolver.lookups) != 0 {
+		t.Errorf("lookups = %v, want none", resolver.lookups)
+	}
+}
+
+func TestSetRules(t *testing.T) {
+	c := New(nil, nil, 0)
+	v := c.Version()
+
+	err := c.SetRules([]Rule{
+		{ID: "ok", Kind: KindDomain, Pattern: "evil.example"},
+		{ID: "bad-regex", Kind: KindRegex, Pattern: "("},
+		{ID: "bad-range", Kind: KindIPRange, Pattern: "nope"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "bad-regex") || !strings.Contains(err.Error(), "bad-range") {
+		t.Errorf("SetRules: err = %v, want both broken rules reported", err)
+	}
+	if m := c.Match("https://evil.example/"); m == nil || m.RuleID != "ok" {
+		t.Errorf("Match = %+v, want the valid rule applied", m)
+	}
+	if c.Version() == v {
+		t.Error("Version did not change after SetRules")
+	}
+
+	// Replacing the rules drops the old ones
+	v = c.Version()
+	if err := c.SetRules(nil); err != nil {
+		t.Fatal(err)
+	}
+	if m := c.Match("https://evil.example/"); m != nil {
+		t.Errorf("Match = %+v after clearing the rules, want nil", m)
+	}
+	c.SetFeed(&Feed{})
+	if c.Version() != v+2 {
+		t.Errorf("Version = %d, want %d", c.Version(), v+2)
+	}
+}
+
+func TestMatchString(t *testing.T) {
+	tests := []struct {
+		match Match
+		want  string
+	}{
+		{match: Match{Source: SourceSelf, Pattern: "sho.rt"}, want: "destination points back at the shortener"},
+		{match: Match{Source: SourceThreatFeed, Pattern: "malware.example"}, want: "destination is listed in the threat feed (malware.example)"},
+		{match: Match{Source: SourceBlocklist, RuleID: "d1", Pattern: "evil.example"}, want: `destination matches blocklist entry "evil.example"`},
+	}
+	for _, tt := range tests {
+		if got := tt.match.String(); got != tt.want {
+			t.Errorf("String = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestHostSuffixes(t *testing.T) {
+	tests := map[string][]string{
+		"a.b.example.com": {"a.b.example.com", "b.example.com", "example.com", "com"},
+		"localhost":       {"localhost"},
+		"192.0.2.1":       {"192.0.2.1"},
+		"2001:db8::1":     {"2001:db8::1"},
+	}
+	for host, want := range tests {
+		if got := hostSuffixes(host); !slices.Equal(got, want) {
+			t.Errorf("hostSuffixes(%s) = %v, want %v", host, got, want)
+		}
+	}
+}
+
+func TestHostname(t *testing.T) {
+	tests := map[string]string{
+		"https://WWW.Example.COM./path": "www.example.com",
+		" https://example.com:8443/ ":   "example.com",
+		"https://bücher.example/":       "xn--bcher-kva.example",
+		"http://[2001:DB8::1]:8080/":    "2001:db8::1",
+		"https://user:pw@sho.rt/abc":    "sho.rt",
+		"mailto:someone@example.com":    "",
+		"/relative/path":                "",
+		"%zz":                           "",
+	}
+	for rawURL, want := range tests {
+		if got := Hostname(rawURL); got != want {
+			t.Errorf("Hostname(%q) = %q, want %q", rawURL, got, want)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+// Package pagination pages, sorts and filters list endpoints. Clients send
+// limit, cursor, sort and filter[...] query parameters, and each resource
+// whitelists the fields they may sort and filter on. Pages are read by
+// keyset rather than offset, so a cursor keeps its place while rows are
+// added or removed before it.
+package pagination
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+// ErrInvalid wraps every problem with the list parameters a client sent
+var ErrInvalid = errors.New("invalid list parameters")
+
+// Op is a filter operator, given as filter[field][op]=value. A filter
+// without an operator, filter[field]=value, is an equality test.
+type Op string
+
+const (
+	Eq       Op = "eq"
+	Ne       Op = "ne"
+	Gt       Op = "gt"
+	Gte      Op = "gte"
+	Lt       Op = "lt"
+	Lte      Op = "lte"
+	In       Op = "in"
+	Contains Op = "contains"
+)
+
+// Query holds the list parameters of a request. Sort fields and filters are
+// only checked against a resource's whitelist when it is applied.
+type Query struct {
+	Limit   int
+	Cursor  string
+	Sort    string
+	Filters []Filter
+}
+
+// Filter is one filter[field][op]=value parameter
+type Filter struct {
+	Field string
+	Op    Op
+	Value string
+}
+
+// Page describes the page of a list that was returned. Total counts every
+// row matching the filters, across all pages.
+type Page struct {
+	Limit      int    `json:"limit"`
+	Sort       string `json:"sort"`
+	Total      int64  `json:"total"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+}
+
+var filterParam = regexp.MustCompile(`^filter\[([a-z_]+)\](?:\[([a-z]+)\])?$`)
+
+// Parse reads the list parameters from a request's query string. A missing
+// limit is DefaultLimit and larger ones are capped at MaxLimit.
+func Parse(values url.Values) (*Query, error) {
+	q := &Query{
+		Limit:  DefaultLimit,
+		Cursor: values.Get("cursor"),
+		Sort:   values.Get("sort"),
+	}
+
+	if raw := values.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 {
+			return nil, fmt.Errorf("%w: limit must be a positive number", ErrInvalid)
+		}
+		q.Limit = min(limit, MaxLimit)
+	}
+
+	// Parameters are read in name order so the same query string always
+	// builds the same SQL
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		if strings.HasPrefix(key, "filter[") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		match := filterParam.FindStringSubmatch(key)
+		if match == nil {
+			return nil, fmt.Errorf("%w: malformed filter %q", ErrInvalid, key)
+		}
+		op := Op(match[2])
+		if op == "" {
+			op = Eq
+		}
+		for _, value := range values[key] {
+			q.Filters = append(q.Filters, Filter{Field: match[1], Op: op, Value: value})
+		}
+	}
+	return q, nil
+}
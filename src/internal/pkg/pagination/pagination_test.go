@@ -0,0 +1,85 @@
+package pagination
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  Query
+	}{
+		{
+			name:  "defaults",
+			query: "",
+			want:  Query{Limit: DefaultLimit},
+		},
+		{
+			name:  "limit, cursor and sort",
+			query: "limit=5&cursor=abc&sort=-created_at,title",
+			want:  Query{Limit: 5, Cursor: "abc", Sort: "-created_at,title"},
+		},
+		{
+			name:  "limit is capped",
+			query: "limit=1000",
+			want:  Query{Limit: MaxLimit},
+		},
+		{
+			name:  "filters in name order",
+			query: "filter[title][contains]=go&filter[is_active]=true&filter[clicks][gte]=3&filter[clicks][lt]=10",
+			want: Query{Limit: DefaultLimit, Filters: []Filter{
+				{Field: "clicks", Op: Gte, Value: "3"},
+				{Field: "clicks", Op: Lt, Value: "10"},
+				{Field: "is_active", Op: Eq, Value: "true"},
+				{Field: "title", Op: Contains, Value: "go"},
+			}},
+		},
+		{
+			name:  "repeated filters",
+			query: "filter[tag]=a&filter[tag]=b&other=1",
+			want: Query{Limit: DefaultLimit, Filters: []Filter{
+				{Field: "tag", Op: Eq, Value: "a"},
+				{Field: "tag", Op: Eq, Value: "b"},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := Parse(values)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("Parse(%q)\n got %+v\nwant %+v", tt.query, *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, query := range []string{
+		"limit=0",
+		"limit=-1",
+		"limit=ten",
+		"filter[title",
+		"filter[Title]=x",
+		"filter[title][eq][x]=1",
+		"filter[title][1]=x",
+	} {
+		values, err := url.ParseQuery(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Parse(values); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Parse(%q): err = %v, want ErrInvalid", query, err)
+		}
+	}
+}
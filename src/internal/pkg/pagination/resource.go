@@ -0,0 +1,369 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	maxSortFields = 3
+	maxInValues   = 100
+)
+
+// Kind is the type of a field's values, which filter values and cursors are
+// parsed into
+type Kind int
+
+const (
+	String Kind = iota
+	Int
+	Bool
+	Time
+)
+
+// Field is a field of a resource that clients may sort or filter on
+type Field[T any] struct {
+	// Column is the SQL expression the field is compared and sorted on
+	Column string
+	Kind   Kind
+	// Ops lists the filter operators allowed. Fields without any cannot be
+	// filtered on.
+	Ops []Op
+	// Sortable fields must never be NULL, and need Value to build cursors
+	Sortable bool
+	// Value reads the field from a row
+	Value func(*T) any
+	// Parse replaces the parsing of filter values by Kind
+	Parse func(string) (any, error)
+	// Apply replaces the comparison of Column for filters that need more,
+	// such as a subquery
+	Apply func(db *gorm.DB, op Op, value any) *gorm.DB
+}
+
+// Resource is the whitelist of fields a list endpoint can be sorted and
+// filtered on
+type Resource[T any] struct {
+	Fields map[string]Field[T]
+	// Key is a unique, sortable field that ends every sort so rows with
+	// equal sort values keep a stable order. It cannot be sorted on by name.
+	Key Field[T]
+	// DefaultSort is used when a request has no sort, for example
+	// "-created_at"
+	DefaultSort string
+}
+
+type sortTerm[T any] struct {
+	field Field[T]
+	desc  bool
+}
+
+// cursor marks the row a page starts after, or ends before when going back.
+// It holds the row's sort values and the sort they belong to.
+type cursor struct {
+	Sort   string            `json:"s"`
+	Back   bool              `json:"b,omitempty"`
+	Values []json.RawMessage `json:"v"`
+}
+
+// Find filters, sorts and pages db, which must select from the resource's
+// table, into a slice of rows. scopes apply to fetching the rows only, not to
+// counting them, and are where preloads belong.
+func (r *Resource[T]) Find(db *gorm.DB, q *Query, scopes ...func(*gorm.DB) *gorm.DB) ([]T, *Page, error) {
+	sortBy := q.Sort
+	if sortBy == "" {
+		sortBy = r.DefaultSort
+	}
+	terms, err := r.sortTerms(sortBy)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var after *cursor
+	var afterValues []any
+	if q.Cursor != "" {
+		if after, afterValues, err = decodeCursor(q.Cursor, sortBy, terms); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	for _, filter := range q.Filters {
+		if db, err = r.applyFilter(db, filter); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	page := &Page{Limit: q.Limit, Sort: sortBy}
+	if err := db.Count(&page.Total).Error; err != nil {
+		return nil, nil, err
+	}
+
+	back := after != nil && after.Back
+	if after != nil {
+		db = db.Where(keyset(terms, afterValues, back))
+	}
+	for _, term := range terms {
+		desc := term.desc != back
+		dir := "ASC"
+		if desc {
+			dir = "DESC"
+		}
+		db = db.Order(term.field.Column + " " + dir)
+	}
+
+	var rows []T
+	if err := db.Scopes(scopes...).Limit(q.Limit + 1).Find(&rows).Error; err != nil {
+		return nil, nil, err
+	}
+	more := len(rows) > q.Limit
+	if more {
+		rows = rows[:q.Limit]
+	}
+	if back {
+		slices.Reverse(rows)
+	}
+	if len(rows) == 0 {
+		return rows, page, nil
+	}
+
+	// Going forward there is a previous page when the request had a cursor,
+	// and a next one when more rows were found; going back it is the other
+	// way around
+	first, last := &rows[0], &rows[len(rows)-1]
+	if (!back && more) || back {
+		if page.NextCursor, err = encodeCursor(sortBy, terms, last, false); err != nil {
+			return nil, nil, err
+		}
+	}
+	if (back && more) || (!back && after != nil) {
+		if page.PrevCursor, err = encodeCursor(sortBy, terms, first, true); err != nil {
+			return nil, nil, err
+		}
+	}
+	return rows, page, nil
+}
+
+// sortTerms parses a comma separated list of field names, each descending
+// when prefixed with "-", and appends the key
+func (r *Resource[T]) sortTerms(sortBy string) ([]sortTerm[T], error) {
+	names := strings.Split(sortBy, ",")
+	if len(names) > maxSortFields {
+		return nil, fmt.Errorf("%w: at most %d sort fields are allowed", ErrInvalid, maxSortFields)
+	}
+
+	terms := make([]sortTerm[T], 0, len(names)+1)
+	seen := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		desc := strings.HasPrefix(name, "-")
+		name = strings.TrimPrefix(name, "-")
+
+		field, ok := r.Fields[name]
+		if !ok || !field.Sortable {
+			return nil, fmt.Errorf("%w: cannot sort by %q", ErrInvalid, name)
+		}
+		if _, ok := seen[name]; ok {
+			return nil, fmt.Errorf("%w: %q is sorted on twice", ErrInvalid, name)
+		}
+		seen[name] = struct{}{}
+		terms = append(terms, sortTerm[T]{field: field, desc: desc})
+	}
+	return append(terms, sortTerm[T]{field: r.Key}), nil
+}
+
+func (r *Resource[T]) applyFilter(db *gorm.DB, filter Filter) (*gorm.DB, error) {
+	field, ok := r.Fields[filter.Field]
+	if !ok || len(field.Ops) == 0 {
+		return nil, fmt.Errorf("%w: cannot filter by %q", ErrInvalid, filter.Field)
+	}
+	if !slices.Contains(field.Ops, filter.Op) {
+		return nil, fmt.Errorf("%w: filter[%s] does not support %q", ErrInvalid, filter.Field, filter.Op)
+	}
+
+	var value any
+	if filter.Op == In {
+		raw := strings.Split(filter.Value, ",")
+		if len(raw) > maxInValues {
+			return nil, fmt.Errorf("%w: filter[%s][in] takes at most %d values", ErrInvalid, filter.Field, maxInValues)
+		}
+		values := make([]any, len(raw))
+		for i, s := range raw {
+			v, err := field.parse(s)
+			if err != nil {
+				return nil, fmt.Errorf("%w: filter[%s]: %v", ErrInvalid, filter.Field, err)
+			}
+			values[i] = v
+		}
+		value = values
+	} else {
+		v, err := field.parse(filter.Value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: filter[%s]: %v", ErrInvalid, filter.Field, err)
+		}
+		value = v
+	}
+
+	if field.Apply != nil {
+		return field.Apply(db, filter.Op, value), nil
+	}
+	if s, ok := value.(string); ok && filter.Op == Contains {
+		value = containsPattern(s)
+	}
+	return db.Where(Compare(field.Column, filter.Op), value), nil
+}
+
+// Compare builds the SQL condition testing column with op against a single
+// placeholder
+func Compare(column string, op Op) string {
+	switch op {
+	case Ne:
+		return column + " <> ?"
+	case Gt:
+		return column + " > ?"
+	case Gte:
+		return column + " >= ?"
+	case Lt:
+		return column + " < ?"
+	case Lte:
+		return column + " <= ?"
+	case In:
+		return column + " IN ?"
+	case Contains:
+		return column + ` LIKE ? ESCAPE '\'`
+	default:
+		return column + " = ?"
+	}
+}
+
+func (f Field[T]) parse(s string) (any, error) {
+	s = strings.TrimSpace(s)
+	if f.Parse != nil {
+		return f.Parse(s)
+	}
+	switch f.Kind {
+	case Int:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a whole number", s)
+		}
+		return n, nil
+	case Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not true or false", s)
+		}
+		return b, nil
+	case Time:
+		if t, err := time.Parse(time.RFC3339, s); err == nil {
+			return t.UTC(), nil
+		}
+		t, err := time.ParseInLocation(time.DateOnly, s, time.UTC)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an RFC3339 time or YYYY-MM-DD date", s)
+		}
+		return t, nil
+	default:
+		return s, nil
+	}
+}
+
+// containsPattern turns s into a LIKE pattern matching values containing it
+func containsPattern(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+	return "%" + s + "%"
+}
+
+// keyset builds the condition selecting the rows after values in the sort
+// order, or before them when back is set:
+// (a > ?) OR (a = ? AND b > ?) OR (a = ? AND b = ? AND c > ?)
+func keyset[T any](terms []sortTerm[T], values []any, back bool) clause.Expr {
+	var sql strings.Builder
+	var args []any
+	for i, term := range terms {
+		if i > 0 {
+			sql.WriteString(" OR ")
+		}
+		sql.WriteString("(")
+		for j := range i {
+			sql.WriteString(terms[j].field.Column + " = ? AND ")
+			args = append(args, values[j])
+		}
+		op := " > ?"
+		if term.desc != back {
+			op = " < ?"
+		}
+		sql.WriteString(term.field.Column + op + ")")
+		args = append(args, values[i])
+	}
+	return clause.Expr{SQL: "(" + sql.String() + ")", Vars: args}
+}
+
+func encodeCursor[T any](sortBy string, terms []sortTerm[T], row *T, back bool) (string, error) {
+	c := cursor{Sort: sortBy, Back: back, Values: make([]json.RawMessage, len(terms))}
+	for i, term := range terms {
+		value, err := json.Marshal(term.field.Value(row))
+		if err != nil {
+			return "", fmt.Errorf("failed to encode cursor: %w", err)
+		}
+		c.Values[i] = value
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeCursor reads a cursor, which must have been made for the same sort
+func decodeCursor[T any](raw, sortBy string, terms []sortTerm[T]) (*cursor, []any, error) {
+	invalid := fmt.Errorf("%w: malformed cursor", ErrInvalid)
+
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, nil, invalid
+	}
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, nil, invalid
+	}
+	if c.Sort != sortBy {
+		return nil, nil, fmt.Errorf("%w: cursor was made for sort %q", ErrInvalid, c.Sort)
+	}
+	if len(c.Values) != len(terms) {
+		return nil, nil, invalid
+	}
+
+	values := make([]any, len(terms))
+	for i, term := range terms {
+		var err error
+		switch term.field.Kind {
+		case Int:
+			var v int64
+			err = json.Unmarshal(c.Values[i], &v)
+			values[i] = v
+		case Bool:
+			var v bool
+			err = json.Unmarshal(c.Values[i], &v)
+			values[i] = v
+		case Time:
+			var v time.Time
+			err = json.Unmarshal(c.Values[i], &v)
+			values[i] = v.UTC()
+		default:
+			var v string
+			err = json.Unmarshal(c.Values[i], &v)
+			values[i] = v
+		}
+		if err != nil {
+			return nil, nil, invalid
+		}
+	}
+	return &c, values, nil
+}
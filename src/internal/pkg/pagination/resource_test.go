@@ -0,0 +1,371 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type item struct {
+	ID        int64
+	Name      string
+	Score     int64
+	Active    bool
+	CreatedAt time.Time
+}
+
+var itemResource = Resource[item]{
+	DefaultSort: "-created_at",
+	Key: Field[item]{
+		Column: "items.id",
+		Kind:   Int,
+		Value:  func(i *item) any { return i.ID },
+	},
+	Fields: map[string]Field[item]{
+		"name": {
+			Column:   "items.name",
+			Ops:      []Op{Eq, Ne, In, Contains},
+			Sortable: true,
+			Value:    func(i *item) any { return i.Name },
+		},
+		"score": {
+			Column:   "items.score",
+			Kind:     Int,
+			Ops:      []Op{Eq, Gt, Gte, Lt, Lte, In},
+			Sortable: true,
+			Value:    func(i *item) any { return i.Score },
+		},
+		"active": {
+			Column: "items.active",
+			Kind:   Bool,
+			Ops:    []Op{Eq},
+		},
+		"created_at": {
+			Column:   "items.created_at",
+			Kind:     Time,
+			Ops:      []Op{Gte, Lt},
+			Sortable: true,
+			Value:    func(i *item) any { return i.CreatedAt },
+		},
+		// even matches items with an even score through Apply
+		"even": {
+			Kind: Bool,
+			Ops:  []Op{Eq},
+			Apply: func(db *gorm.DB, _ Op, value any) *gorm.DB {
+				if value.(bool) {
+					return db.Where("items.score % 2 = 0")
+				}
+				return db.Where("items.score % 2 = 1")
+			},
+		},
+	},
+}
+
+var day = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func newItemDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Every connection to :memory: is a new database
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if err := db.AutoMigrate(&item{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	items := []item{
+		{Name: "alpha", Score: 3, Active: true},
+		{Name: "bravo", Score: 1},
+		{Name: "charlie", Score: 3, Active: true},
+		{Name: "delta", Score: 2},
+		{Name: "echo", Score: 3},
+		{Name: "50%_off", Score: 5, Active: true},
+		{Name: "foxtrot", Score: 2, Active: true},
+		{Name: "golf", Score: 4},
+		{Name: "hotel", Score: 1, Active: true},
+		{Name: `back\slash`, Score: 0},
+	}
+	for i := range items {
+		items[i].CreatedAt = day.Add(time.Duration(i) * 24 * time.Hour)
+	}
+	if err := db.Create(&items).Error; err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	return db
+}
+
+func names(items []item) []string {
+	out := make([]string, len(items))
+	for i := range items {
+		out[i] = items[i].Name
+	}
+	return out
+}
+
+func TestFindWalksPages(t *testing.T) {
+	db := newItemDB(t)
+
+	tests := []struct {
+		sort string
+		want []string
+	}{
+		{sort: "", want: []string{`back\slash`, "hotel", "golf", "foxtrot", "50%_off", "echo", "delta", "charlie", "bravo", "alpha"}},
+		{sort: "name", want: []string{"50%_off", "alpha", `back\slash`, "bravo", "charlie", "delta", "echo", "foxtrot", "golf", "hotel"}},
+		// Ties on score fall back to the key, id
+		{sort: "-score", want: []string{"50%_off", "golf", "alpha", "charlie", "echo", "delta", "foxtrot", "bravo", "hotel", `back\slash`}},
+		{sort: "score,-name", want: []string{`back\slash`, "hotel", "bravo", "foxtrot", "delta", "echo", "charlie", "alpha", "golf", "50%_off"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			// Forward, page by page
+			var got []string
+			var pages []*Page
+			q := &Query{Limit: 3, Sort: tt.sort}
+			for {
+				rows, page, err := itemResource.Find(db.Model(&item{}), q)
+				if err != nil {
+					t.Fatalf("Find: %v", err)
+				}
+				if page.Total != int64(len(tt.want)) {
+					t.Errorf("Total = %d, want %d", page.Total, len(tt.want))
+				}
+				if (page.PrevCursor != "") != (len(pages) > 0) {
+					t.Errorf("page %d: PrevCursor = %q", len(pages), page.PrevCursor)
+				}
+				got = append(got, names(rows)...)
+				pages = append(pages, page)
+				if page.NextCursor == "" {
+					break
+				}
+				q = &Query{Limit: 3, Sort: tt.sort, Cursor: page.NextCursor}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("forward\n got %v\nwant %v", got, tt.want)
+			}
+			if len(pages) != 4 {
+				t.Fatalf("got %d pages, want 4", len(pages))
+			}
+
+			// And back again from the last page
+			var back []string
+			cursor := pages[len(pages)-1].PrevCursor
+			for cursor != "" {
+				rows, page, err := itemResource.Find(db.Model(&item{}), &Query{Limit: 3, Sort: tt.sort, Cursor: cursor})
+				if err != nil {
+					t.Fatalf("Find back: %v", err)
+				}
+				if page.NextCursor == "" {
+					t.Error("page reached going back has no NextCursor")
+				}
+				back = append(names(rows), back...)
+				cursor = page.PrevCursor
+			}
+			if want := tt.want[:9]; !slices.Equal(back, want) {
+				t.Errorf("back\n got %v\nwant %v", back, want)
+			}
+		})
+	}
+}
+
+func TestFindFilters(t *testing.T) {
+	db := newItemDB(t)
+
+	tests := []struct {
+		name    string
+		filters []Filter
+		want    []string
+	}{
+		{name: "eq", filters: []Filter{{Field: "name", Op: Eq, Value: "golf"}}, want: []string{"golf"}},
+		{name: "ne", filters: []Filter{{Field: "score", Op: Gte, Value: "3"}, {Field: "name", Op: Ne, Value: "echo"}}, want: []string{"50%_off", "golf", "alpha", "charlie"}},
+		{name: "range", filters: []Filter{{Field: "score", Op: Gt, Value: "1"}, {Field: "score", Op: Lte, Value: "2"}}, want: []string{"delta", "foxtrot"}},
+		{name: "in", filters: []Filter{{Field: "score", Op: In, Value: "0, 4,5"}}, want: []string{"50%_off", "golf", `back\slash`}},
+		{name: "bool", filters: []Filter{{Field: "active", Op: Eq, Value: "true"}, {Field: "score", Op: Lt, Value: "3"}}, want: []string{"foxtrot", "hotel"}},
+		{name: "date", filters: []Filter{{Field: "created_at", Op: Gte, Value: "2026-01-09"}}, want: []string{"hotel", `back\slash`}},
+		{name: "time", filters: []Filter{{Field: "created_at", Op: Lt, Value: "2026-01-02T00:00:00Z"}}, want: []string{"alpha"}},
+		{name: "contains", filters: []Filter{{Field: "name", Op: Contains, Value: "ha"}}, want: []string{"alpha", "charlie"}},
+		{name: "contains escapes percent", filters: []Filter{{Field: "name", Op: Contains, Value: "0%_"}}, want: []string{"50%_off"}},
+		{name: "contains escapes underscore", filters: []Filter{{Field: "name", Op: Contains, Value: "_"}}, want: []string{"50%_off"}},
+		{name: "contains escapes backslash", filters: []Filter{{Field: "name", Op: Contains, Value: `k\s`}}, want: []string{`back\slash`}},
+		{name: "apply", filters: []Filter{{Field: "even", Op: Eq, Value: "true"}, {Field: "score", Op: Gt, Value: "0"}}, want: []string{"golf", "delta", "foxtrot"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, page, err := itemResource.Find(db.Model(&item{}), &Query{Limit: 10, Sort: "-score,name", Filters: tt.filters})
+			if err != nil {
+				t.Fatalf("Find: %v", err)
+			}
+			if got := names(rows); !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			if page.Total != int64(len(tt.want)) {
+				t.Errorf("Total = %d, want %d", page.Total, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestFindScopesOnlyFetchRows(t *testing.T) {
+	db := newItemDB(t)
+
+	calls := 0
+	scope := func(db *gorm.DB) *gorm.DB {
+		calls++
+		return db
+	}
+	rows, page, err := itemResource.Find(db.Model(&item{}), &Query{Limit: 2}, scope)
+	if err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+	if len(rows) != 2 || page.Total != 10 || calls != 1 {
+		t.Errorf("got %d rows, total %d, %d scope calls; want 2, 10, 1", len(rows), page.Total, calls)
+	}
+}
+
+func TestFindInvalid(t *testing.T) {
+	db := newItemDB(t)
+
+	_, first, err := itemResource.Find(db.Model(&item{}), &Query{Limit: 3, Sort: "name"})
+	if err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+	manyValues := "1"
+	for i := range maxInValues {
+		manyValues += fmt.Sprintf(",%d", i)
+	}
+	encode := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+
+	tests := []struct {
+		name string
+		q    Query
+	}{
+		{name: "unknown sort", q: Query{Sort: "color"}},
+		{name: "unsortable field", q: Query{Sort: "active"}},
+		{name: "key is not sortable by name", q: Query{Sort: "id"}},
+		{name: "sorted twice", q: Query{Sort: "name,-name"}},
+		{name: "too many sort fields", q: Query{Sort: "name,score,created_at,-name"}},
+		{name: "unknown filter", q: Query{Filters: []Filter{{Field: "color", Op: Eq, Value: "red"}}}},
+		{name: "unsupported op", q: Query{Filters: []Filter{{Field: "active", Op: Ne, Value: "true"}}}},
+		{name: "bad int", q: Query{Filters: []Filter{{Field: "score", Op: Eq, Value: "high"}}}},
+		{name: "bad bool", q: Query{Filters: []Filter{{Field: "active", Op: Eq, Value: "yes please"}}}},
+		{name: "bad time", q: Query{Filters: []Filter{{Field: "created_at", Op: Gte, Value: "yesterday"}}}},
+		{name: "bad in value", q: Query{Filters: []Filter{{Field: "score", Op: In, Value: "1,two"}}}},
+		{name: "too many in values", q: Query{Filters: []Filter{{Field: "score", Op: In, Value: manyValues}}}},
+		{name: "cursor for another sort", q: Query{Sort: "-name", Cursor: first.NextCursor}},
+		{name: "cursor not base64", q: Query{Sort: "name", Cursor: "not a cursor!"}},
+		{name: "cursor not json", q: Query{Sort: "name", Cursor: encode("nope")}},
+		{name: "cursor with too few values", q: Query{Sort: "name", Cursor: encode(`{"s":"name","v":["alpha"]}`)}},
+		{name: "cursor with wrong types", q: Query{Sort: "name", Cursor: encode(`{"s":"name","v":[1,"x"]}`)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.q.Limit = 3
+			if _, _, err := itemResource.Find(db.Model(&item{}), &tt.q); !errors.Is(err, ErrInvalid) {
+				t.Errorf("Find: err = %v, want ErrInvalid", err)
+			}
+		})
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	terms, err := itemResource.sortTerms("-created_at,name,score")
+	if err != nil {
+		t.Fatal(err)
+	}
+	row := &item{ID: 42, Name: "alpha", Score: 7, CreatedAt: time.Date(2026, 3, 4, 5, 6, 7, 8, time.FixedZone("x", 3600))}
+
+	raw, err := encodeCursor("-created_at,name,score", terms, row, true)
+	if err != nil {
+		t.Fatalf("encodeCursor: %v", err)
+	}
+	c, values, err := decodeCursor(raw, "-created_at,name,score", terms)
+	if err != nil {
+		t.Fatalf("decodeCursor: %v", err)
+	}
+	if !c.Back {
+		t.Error("Back was lost")
+	}
+	want := []any{row.CreatedAt.UTC(), "alpha", int64(7), int64(42)}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("values = %#v, want %#v", values, want)
+	}
+}
+
+func TestKeyset(t *testing.T) {
+	terms, err := itemResource.sortTerms("-score,name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := []any{int64(3), "alpha", int64(1)}
+
+	tests := []struct {
+		back bool
+		sql  string
+	}{
+		{
+			back: false,
+			sql:  "((items.score < ?) OR (items.score = ? AND items.name > ?) OR (items.score = ? AND items.name = ? AND items.id > ?))",
+		},
+		{
+			back: true,
+			sql:  "((items.score > ?) OR (items.score = ? AND items.name < ?) OR (items.score = ? AND items.name = ? AND items.id < ?))",
+		},
+	}
+	for _, tt := range tests {
+		expr := keyset(terms, values, tt.back)
+		if expr.SQL != tt.sql {
+			t.Errorf("keyset(back=%v)\n got %s\nwant %s", tt.back, expr.SQL, tt.sql)
+		}
+		wantArgs := []any{int64(3), int64(3), "alpha", int64(3), "alpha", int64(1)}
+		if !reflect.DeepEqual(expr.Vars, wantArgs) {
+			t.Errorf("keyset(back=%v) args = %v, want %v", tt.back, expr.Vars, wantArgs)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := map[Op]string{
+		Eq:       "c = ?",
+		Ne:       "c <> ?",
+		Gt:       "c > ?",
+		Gte:      "c >= ?",
+		Lt:       "c < ?",
+		Lte:      "c <= ?",
+		In:       "c IN ?",
+		Contains: `c LIKE ? ESCAPE '\'`,
+	}
+	for op, want := range tests {
+		if got := Compare("c", op); got != want {
+			t.Errorf("Compare(%s) = %q, want %q", op, got, want)
+		}
+	}
+}
+
+func TestContainsPattern(t *testing.T) {
+	tests := map[string]string{
+		"go":      "%go%",
+		"50%":     `%50\%%`,
+		"a_b":     `%a\_b%`,
+		`c:\path`: `%c:\\path%`,
+		"":        "%%",
+	}
+	for in, want := range tests {
+		if got := containsPattern(in); got != want {
+			t.Errorf("containsPattern(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
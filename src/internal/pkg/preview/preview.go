@@ -0,0 +1,238 @@
+// Package preview downloads a web page and extracts the metadata used to
+// describe a link: its title, description, site name, preview image and
+// favicon, from the HTML title, meta description, OpenGraph and Twitter card
+// tags.
+package preview
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	neturl "net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/imraushankr/brevity/server/src/configs"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/safehttp"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"golang.org/x/net/html/charset"
+)
+
+// Field limits, matching the columns the metadata is stored in
+const (
+	MaxTitle       = 100
+	MaxDescription = 255
+	MaxSiteName    = 100
+	maxURL         = 2048
+)
+
+// ErrNotHTML is returned when the destination is not an HTML page
+var ErrNotHTML = errors.New("destination is not an html page")
+
+// Metadata describes a page. Empty fields were not found.
+type Metadata struct {
+	Title       string
+	Description string
+	SiteName    string
+	ImageURL    string
+	FaviconURL  string
+}
+
+// Fetcher downloads pages with strict time and size limits, refusing
+// non-public addresses unless cfg allows them
+type Fetcher struct {
+	client    *http.Client
+	maxBytes  int64
+	userAgent string
+}
+
+// New creates a fetcher from cfg
+func New(cfg *configs.PreviewConfig) *Fetcher {
+	return &Fetcher{
+		client: safehttp.NewClient(safehttp.Options{
+			Timeout:      cfg.Timeout,
+			MaxRedirects: cfg.MaxRedirects,
+			AllowPrivate: cfg.AllowPrivateNetworks,
+		}),
+		maxBytes:  cfg.MaxBytes,
+		userAgent: cfg.UserAgent,
+	}
+}
+
+// Fetch downloads rawURL and extracts its metadata. Only the first maxBytes
+// of the page are read; relative image and favicon links are resolved
+// against the final URL after redirects.
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (*Metadata, error) {
+	u, err := neturl.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid url %q", rawURL)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", f.userAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9,*/*;q=0.1")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	contentType := resp.Header.Get("Content-Type")
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, ErrNotHTML
+	}
+
+	body, err := charset.NewReader(io.LimitReader(resp.Body, f.maxBytes), contentType)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported charset: %w", err)
+	}
+	return Parse(body, resp.Request.URL), nil
+}
+
+// Parse reads metadata from the head of an HTML document served at base.
+// OpenGraph tags win over Twitter card tags, which win over the plain title
+// and meta description. Parsing stops at the body.
+func Parse(r io.Reader, base *neturl.URL) *Metadata {
+	var (
+		title      strings.Builder
+		inTitle    bool
+		titleDone  bool
+		tags       = make(map[string]string)
+		icon       string
+		touchIcon  string
+		resolveRef = base
+	)
+
+	z := html.NewTokenizer(r)
+loop:
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			break loop
+		case html.TextToken:
+			if inTitle {
+				title.Write(z.Text())
+			}
+		case html.EndTagToken:
+			if name, _ := z.TagName(); atom.Lookup(name) == atom.Title {
+				inTitle = false
+				titleDone = true
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			tag := atom.Lookup(name)
+			if tag == atom.Body {
+				break loop
+			}
+			if tag == atom.Title && tt == html.StartTagToken && !titleDone {
+				inTitle = true
+				continue
+			}
+			if !hasAttr {
+				continue
+			}
+
+			attrs := readAttrs(z)
+			switch tag {
+			case atom.Meta:
+				key := strings.ToLower(attrs["property"])
+				if key == "" {
+					key = strings.ToLower(attrs["name"])
+				}
+				if _, seen := tags[key]; key != "" && !seen {
+					tags[key] = attrs["content"]
+				}
+			case atom.Link:
+				rels := strings.Fields(strings.ToLower(attrs["rel"]))
+				for _, rel := range rels {
+					switch {
+					case rel == "icon" && icon == "":
+						icon = attrs["href"]
+					case rel == "apple-touch-icon" && touchIcon == "":
+						touchIcon = attrs["href"]
+					}
+				}
+			case atom.Base:
+				if href, err := base.Parse(attrs["href"]); err == nil && attrs["href"] != "" {
+					resolveRef = href
+				}
+			}
+		}
+	}
+
+	meta := &Metadata{
+		Title:       clean(first(tags["og:title"], tags["twitter:title"], title.String()), MaxTitle),
+		Description: clean(first(tags["og:description"], tags["twitter:description"], tags["description"]), MaxDescription),
+		SiteName:    clean(tags["og:site_name"], MaxSiteName),
+		ImageURL: absolute(resolveRef, first(tags["og:image"], tags["og:image:secure_url"], tags["og:image:url"],
+			tags["twitter:image"], tags["twitter:image:src"])),
+		FaviconURL: absolute(resolveRef, first(icon, touchIcon)),
+	}
+	if meta.FaviconURL == "" && base != nil {
+		meta.FaviconURL = absolute(base, "/favicon.ico")
+	}
+	return meta
+}
+
+func readAttrs(z *html.Tokenizer) map[string]string {
+	attrs := make(map[string]string)
+	for {
+		key, val, more := z.TagAttr()
+		k := strings.ToLower(string(key))
+		if _, seen := attrs[k]; !seen {
+			attrs[k] = string(val)
+		}
+		if !more {
+			return attrs
+		}
+	}
+}
+
+// first returns the first value that is not blank
+func first(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// clean collapses whitespace and cuts s to at most limit runes
+func clean(s string, limit int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+	runes := []rune(s)
+	return strings.TrimSpace(string(runes[:limit-1])) + "…"
+}
+
+// absolute resolves ref against base, keeping only http and https URLs
+func absolute(base *neturl.URL, ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" || base == nil {
+		return ""
+	}
+	u, err := base.Parse(ref)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ""
+	}
+	s := u.String()
+	if len(s) > maxURL {
+		return ""
+	}
+	return s
+}
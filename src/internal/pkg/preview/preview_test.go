@@ -0,0 +1,312 @@
+package preview
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	neturl "net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/imraushankr/brevity/server/src/configs"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/safehttp"
+)
+
+func TestParse(t *testing.T) {
+	base, _ := neturl.Parse("https://example.com/blog/post")
+
+	tests := []struct {
+		name string
+		html string
+		want Metadata
+	}{
+		{
+			name: "title and description",
+			html: `<html><head><title> Hello
+				world </title><meta name="description" content="A page"></head></html>`,
+			want: Metadata{Title: "Hello world", Description: "A page", FaviconURL: "https://example.com/favicon.ico"},
+		},
+		{
+			name: "opengraph wins over twitter and plain tags",
+			html: `<head><title>Plain</title>
+				<meta name="description" content="plain description">
+				<meta name="twitter:title" content="Twitter">
+				<meta name="twitter:description" content="twitter description">
+				<meta property="og:title" content="OpenGraph">
+				<meta property="og:description" content="og description">
+				<meta property="og:site_name" content="Example">
+				<meta name="twitter:image" content="/twitter.png">
+				<meta property="OG:IMAGE" content="/og.png">`,
+			want: Metadata{
+				Title: "OpenGraph", Description: "og description", SiteName: "Example",
+				ImageURL: "https://example.com/og.png", FaviconURL: "https://example.com/favicon.ico",
+			},
+		},
+		{
+			name: "twitter wins over plain tags",
+			html: `<title>Plain</title><meta name="twitter:title" content="Twitter"><meta name="twitter:image:src" content="img.png">`,
+			want: Metadata{Title: "Twitter", ImageURL: "https://example.com/blog/img.png", FaviconURL: "https://example.com/favicon.ico"},
+		},
+		{
+			name: "blank tags fall through",
+			html: `<title>Plain</title><meta property="og:title" content="  ">`,
+			want: Metadata{Title: "Plain", FaviconURL: "https://example.com/favicon.ico"},
+		},
+		{
+			name: "first tag wins",
+			html: `<meta property="og:title" content="First"><meta property="og:title" content="Second">`,
+			want: Metadata{Title: "First", FaviconURL: "https://example.com/favicon.ico"},
+		},
+		{
+			name: "entities are decoded",
+			html: `<title>Fish &amp; Chips</title><meta name="description" content="caf&eacute;">`,
+			want: Metadata{Title: "Fish & Chips", Description: "café", FaviconURL: "https://example.com/favicon.ico"},
+		},
+		{
+			name: "icon wins over apple touch icon",
+			html: `<link rel="apple-touch-icon" href="/touch.png"><link rel="shortcut icon" href="/icon.ico">`,
+			want: Metadata{FaviconURL: "https://example.com/icon.ico"},
+		},
+		{
+			name: "apple touch icon",
+			html: `<link rel="apple-touch-icon" href="//cdn.example.net/touch.png">`,
+			want: Metadata{FaviconURL: "https://cdn.example.net/touch.png"},
+		},
+		{
+			name: "base href",
+			html: `<base href="https://static.example.org/assets/"><meta property="og:image" content="cover.jpg"><link rel="icon" href="fav.png">`,
+			want: Metadata{ImageURL: "https://static.example.org/assets/cover.jpg", FaviconURL: "https://static.example.org/assets/fav.png"},
+		},
+		{
+			name: "non http urls are dropped",
+			html: `<meta property="og:image" content="javascript:alert(1)"><link rel="icon" href="data:image/png;base64,AAAA">`,
+			want: Metadata{FaviconURL: "https://example.com/favicon.ico"},
+		},
+		{
+			name: "overlong urls are dropped",
+			html: `<meta property="og:image" content="/` + strings.Repeat("a", maxURL) + `">`,
+			want: Metadata{FaviconURL: "https://example.com/favicon.ico"},
+		},
+		{
+			name: "parsing stops at the body",
+			html: `<head><title>Head</title></head><body><meta property="og:title" content="Body"><title>Other</title></body>`,
+			want: Metadata{Title: "Head", FaviconURL: "https://example.com/favicon.ico"},
+		},
+		{
+			name: "only the first title counts",
+			html: `<title>One</title><svg><title>Two</title></svg>`,
+			want: Metadata{Title: "One", FaviconURL: "https://example.com/favicon.ico"},
+		},
+		{
+			name: "long text is cut",
+			html: `<title>` + strings.Repeat("é", MaxTitle+5) + `</title><meta property="og:site_name" content="` + strings.Repeat("s", MaxSiteName) + `">`,
+			want: Metadata{
+				Title: strings.Repeat("é", MaxTitle-1) + "…", SiteName: strings.Repeat("s", MaxSiteName),
+				FaviconURL: "https://example.com/favicon.ico",
+			},
+		},
+		{
+			name: "empty document",
+			html: ``,
+			want: Metadata{FaviconURL: "https://example.com/favicon.ico"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Parse(strings.NewReader(tt.html), base); *got != tt.want {
+				t.Errorf("Parse\n got %+v\nwant %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseWithoutBase(t *testing.T) {
+	got := Parse(strings.NewReader(`<title>T</title><link rel="icon" href="/i.png">`), nil)
+	if *got != (Metadata{Title: "T"}) {
+		t.Errorf("Parse = %+v, want only the title", *got)
+	}
+}
+
+func newTestFetcher(allowPrivate bool) *Fetcher {
+	return New(&configs.PreviewConfig{
+		Timeout:              5 * time.Second,
+		MaxBytes:             1024,
+		MaxRedirects:         2,
+		UserAgent:            "BrevityTest/1.0",
+		AllowPrivateNetworks: allowPrivate,
+	})
+}
+
+func TestFetch(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		if ua := r.Header.Get("User-Agent"); ua != "BrevityTest/1.0" {
+			t.Errorf("User-Agent = %q", ua)
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, `<html><head>
+			<title>Example page</title>
+			<meta property="og:description" content="Described">
+			<meta property="og:image" content="/img/cover.png">
+			<link rel="icon" href="favicon.png">
+			</head><body>content</body></html>`)
+	})
+	mux.HandleFunc("/moved", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/docs/page", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/docs/page", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xhtml+xml")
+		fmt.Fprint(w, `<head><title>Moved</title><link rel="icon" href="icon.png"></head>`)
+	})
+	mux.HandleFunc("/latin1", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=iso-8859-1")
+		w.Write([]byte("<title>Caf\xe9</title>"))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	f := newTestFetcher(true)
+	tests := []struct {
+		path string
+		want Metadata
+	}{
+		{
+			path: "/page",
+			want: Metadata{
+				Title: "Example page", Description: "Described",
+				ImageURL: srv.URL + "/img/cover.png", FaviconURL: srv.URL + "/favicon.png",
+			},
+		},
+		{
+			// Relative links resolve against the URL after redirects
+			path: "/moved",
+			want: Metadata{Title: "Moved", FaviconURL: srv.URL + "/docs/icon.png"},
+		},
+		{
+			path: "/latin1",
+			want: Metadata{Title: "Café", FaviconURL: srv.URL + "/favicon.ico"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := f.Fetch(context.Background(), srv.URL+tt.path)
+			if err != nil {
+				t.Fatalf("Fetch: %v", err)
+			}
+			if *got != tt.want {
+				t.Errorf("Fetch\n got %+v\nwant %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchReadsAtMostMaxBytes(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<head><title>Kept</title>`)
+		// Padding pushes the description past the 1024 byte limit
+		fmt.Fprintf(w, `<!-- %s -->`, strings.Repeat("x", 1024))
+		fmt.Fprint(w, `<meta name="description" content="Too late"></head>`)
+	}))
+	defer srv.Close()
+
+	got, err := newTestFetcher(true).Fetch(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if got.Title != "Kept" {
+		t.Errorf("Title = %q, want Kept", got.Title)
+	}
+	if got.Description != "" {
+		t.Errorf("Description = %q, want it cut off by max_bytes", got.Description)
+	}
+}
+
+func TestFetchRedirectLimit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/"))
+		if n > 0 {
+			http.Redirect(w, r, "/"+strconv.Itoa(n-1), http.StatusFound)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<title>Arrived</title>`)
+	}))
+	defer srv.Close()
+
+	f := newTestFetcher(true)
+	if got, err := f.Fetch(context.Background(), srv.URL+"/2"); err != nil || got.Title != "Arrived" {
+		t.Errorf("Fetch with 2 redirects = %+v, %v, want Arrived", got, err)
+	}
+	if _, err := f.Fetch(context.Background(), srv.URL+"/3"); !errors.Is(err, safehttp.ErrTooManyRedirects) {
+		t.Errorf("Fetch with 3 redirects: err = %v, want ErrTooManyRedirects", err)
+	}
+}
+
+func TestFetchRefusesPrivateNetworks(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request reached the loopback server")
+	}))
+	defer srv.Close()
+
+	f := newTestFetcher(false)
+	targets := []string{
+		srv.URL,
+		strings.Replace(srv.URL, "127.0.0.1", "localhost", 1),
+		"http://10.0.0.1/",
+		"http://169.254.169.254/latest/meta-data/",
+		"http://[::1]:1/",
+	}
+	for _, target := range targets {
+		if _, err := f.Fetch(context.Background(), target); !errors.Is(err, safehttp.ErrBlockedAddress) {
+			t.Errorf("Fetch(%s): err = %v, want ErrBlockedAddress", target, err)
+		}
+	}
+}
+
+func TestFetchErrors(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{}`)
+	})
+	mux.HandleFunc("/untyped", func(w http.ResponseWriter, r *http.Request) {
+		w.Header()["Content-Type"] = nil
+		w.Write([]byte{0x89, 'P', 'N', 'G'})
+	})
+	mux.HandleFunc("/missing", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		http.Error(w, "<title>Not found</title>", http.StatusNotFound)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	f := newTestFetcher(true)
+	tests := []struct {
+		name    string
+		url     string
+		notHTML bool
+	}{
+		{name: "json", url: srv.URL + "/json", notHTML: true},
+		{name: "no content type", url: srv.URL + "/untyped", notHTML: true},
+		{name: "not found", url: srv.URL + "/missing"},
+		{name: "ftp scheme", url: "ftp://example.com/file"},
+		{name: "no host", url: "http:///path"},
+		{name: "unparsable", url: "http://exa mple.com/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := f.Fetch(context.Background(), tt.url)
+			if err == nil {
+				t.Fatalf("Fetch = %+v, want an error", got)
+			}
+			if errors.Is(err, ErrNotHTML) != tt.notHTML {
+				t.Errorf("Fetch: err = %v, ErrNotHTML expected: %v", err, tt.notHTML)
+			}
+		})
+	}
+}
@@ -0,0 +1,369 @@
+package qrcode
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// The golden symbols in testdata were made with an independent encoder. Each
+// file starts with a line holding the level, version, mask and quoted data,
+// followed by the rows of the symbol, # for dark modules. That encoder scores
+// masks with its own variant of the penalty rules, so where it chose another
+// mask the symbol is compared after switching to that mask.
+func TestEncodeGolden(t *testing.T) {
+	files, err := filepath.Glob("testdata/*.golden")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no golden files: %v", err)
+	}
+
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			level, version, mask, data, rows := readGolden(t, file)
+
+			c, err := Encode([]byte(data), level)
+			if err != nil {
+				t.Fatalf("Encode: %v", err)
+			}
+			if c.Version != version {
+				t.Fatalf("version %d, want %d", c.Version, version)
+			}
+			if c.Mask != mask {
+				c.applyMask(c.Mask)
+				c.applyMask(mask)
+				c.drawFormatBits(mask)
+			}
+			if c.Size() != len(rows) {
+				t.Fatalf("Size = %d, want %d", c.Size(), len(rows))
+			}
+
+			diff := 0
+			for y, row := range rows {
+				for x := range row {
+					if c.Dark(x, y) != (row[x] == '#') {
+						diff++
+					}
+				}
+			}
+			if diff > 0 {
+				t.Errorf("%d modules differ from the golden symbol", diff)
+			}
+		})
+	}
+}
+
+func readGolden(t *testing.T, file string) (Level, int, int, string, []string) {
+	t.Helper()
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(raw))
+	scanner.Buffer(nil, 1<<20)
+	scanner.Scan()
+	fields := strings.SplitN(scanner.Text(), " ", 4)
+	if len(fields) != 4 {
+		t.Fatalf("malformed header %q", scanner.Text())
+	}
+	level, err := ParseLevel(fields[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	version, _ := strconv.Atoi(fields[1])
+	mask, _ := strconv.Atoi(fields[2])
+	data, err := strconv.Unquote(fields[3])
+	if err != nil {
+		t.Fatalf("malformed data %s: %v", fields[3], err)
+	}
+
+	var rows []string
+	for scanner.Scan() {
+		rows = append(rows, scanner.Text())
+	}
+	return level, version, mask, data, rows
+}
+
+func TestEncodeChoosesSmallestVersion(t *testing.T) {
+	// Byte mode capacities of the standard's tables
+	tests := []struct {
+		level   Level
+		length  int
+		version int
+	}{
+		{level: LevelL, length: 17, version: 1},
+		{level: LevelL, length: 18, version: 2},
+		{level: LevelM, length: 14, version: 1},
+		{level: LevelQ, length: 11, version: 1},
+		{level: LevelH, length: 7, version: 1},
+		{level: LevelH, length: 8, version: 2},
+		{level: LevelM, length: 180, version: 9},
+		// Version 10 needs a 16 bit length field
+		{level: LevelM, length: 181, version: 10},
+		{level: LevelM, length: 213, version: 10},
+		{level: LevelM, length: 214, version: 11},
+		{level: LevelL, length: 2953, version: 40},
+		{level: LevelH, length: 1273, version: 40},
+	}
+	for _, tt := range tests {
+		c, err := Encode(bytes.Repeat([]byte("a"), tt.length), tt.level)
+		if err != nil {
+			t.Errorf("Encode(%d bytes, %s): %v", tt.length, tt.level, err)
+			continue
+		}
+		if c.Version != tt.version || c.Size() != 17+4*tt.version {
+			t.Errorf("Encode(%d bytes, %s) = version %d size %d, want version %d", tt.length, tt.level, c.Version, c.Size(), tt.version)
+		}
+	}
+}
+
+func TestEncodeErrors(t *testing.T) {
+	if _, err := Encode(bytes.Repeat([]byte("a"), 2954), LevelL); !errors.Is(err, ErrTooLong) {
+		t.Errorf("Encode too long: err = %v, want ErrTooLong", err)
+	}
+	if _, err := Encode(bytes.Repeat([]byte("a"), 1274), LevelH); !errors.Is(err, ErrTooLong) {
+		t.Errorf("Encode too long at H: err = %v, want ErrTooLong", err)
+	}
+	for _, level := range []Level{-1, LevelH + 1} {
+		if _, err := Encode([]byte("x"), level); err == nil {
+			t.Errorf("Encode with level %d succeeded", level)
+		}
+	}
+}
+
+// The worked example of the standard: "01234567" at level M, version 1
+func TestReedSolomon(t *testing.T) {
+	data := []byte{0x10, 0x20, 0x0C, 0x56, 0x61, 0x80, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11}
+	want := []byte{0xA5, 0x24, 0xD4, 0xC1, 0xED, 0x36, 0xC7, 0x87, 0x2C, 0x55}
+
+	if got := rsRemainder(data, rsDivisor(len(want))); !bytes.Equal(got, want) {
+		t.Errorf("rsRemainder = % X, want % X", got, want)
+	}
+}
+
+func TestGFMul(t *testing.T) {
+	tests := []struct{ x, y, want byte }{
+		{x: 0, y: 0x53, want: 0},
+		{x: 1, y: 0x53, want: 0x53},
+		{x: 2, y: 0x80, want: 0x1D},
+		{x: 0x53, y: 0xCA, want: 0x8F},
+		{x: 0xFF, y: 0xFF, want: 0xE2},
+		// 0x8E is the inverse of 2
+		{x: 0x8E, y: 2, want: 1},
+	}
+	for _, tt := range tests {
+		if got := gfMul(tt.x, tt.y); got != tt.want || gfMul(tt.y, tt.x) != tt.want {
+			t.Errorf("gfMul(%#x, %#x) = %#x, want %#x", tt.x, tt.y, got, tt.want)
+		}
+	}
+}
+
+// readFormat reads the 15 format bits around the top left finder, bit 14
+// first
+func readFormat(c *Code) int {
+	var bits int
+	for i := 0; i < 15; i++ {
+		var dark bool
+		switch {
+		case i <= 5:
+			dark = c.modules[i][8]
+		case i == 6:
+			dark = c.modules[7][8]
+		case i == 7:
+			dark = c.modules[8][8]
+		case i == 8:
+			dark = c.modules[8][7]
+		default:
+			dark = c.modules[8][14-i]
+		}
+		if dark {
+			bits |= 1 << i
+		}
+	}
+	return bits
+}
+
+// readFormatCopy reads the second copy, split between the top right and
+// bottom left finders
+func readFormatCopy(c *Code) int {
+	var bits int
+	for i := 0; i < 15; i++ {
+		var dark bool
+		if i < 8 {
+			dark = c.modules[8][c.size-1-i]
+		} else {
+			dark = c.modules[c.size-15+i][8]
+		}
+		if dark {
+			bits |= 1 << i
+		}
+	}
+	return bits
+}
+
+func TestFormatBits(t *testing.T) {
+	// From the format information table of the standard
+	tests := []struct {
+		level Level
+		mask  int
+		want  int
+	}{
+		{level: LevelL, mask: 0, want: 0x77C4},
+		{level: LevelL, mask: 7, want: 0x6976},
+		{level: LevelM, mask: 0, want: 0x5412},
+		{level: LevelM, mask: 5, want: 0x40CE},
+		{level: LevelQ, mask: 0, want: 0x355F},
+		{level: LevelQ, mask: 3, want: 0x3A06},
+		{level: LevelH, mask: 0, want: 0x1689},
+		{level: LevelH, mask: 7, want: 0x083B},
+	}
+	for _, tt := range tests {
+		c := newCode(1, tt.level)
+		c.drawFormatBits(tt.mask)
+		if got := readFormat(c); got != tt.want {
+			t.Errorf("%s mask %d: format = %015b, want %015b", tt.level, tt.mask, got, tt.want)
+		}
+		if got := readFormatCopy(c); got != tt.want {
+			t.Errorf("%s mask %d: second copy = %015b, want %015b", tt.level, tt.mask, got, tt.want)
+		}
+		if !c.modules[c.size-8][8] {
+			t.Errorf("%s mask %d: dark module missing", tt.level, tt.mask)
+		}
+	}
+}
+
+func TestVersionBits(t *testing.T) {
+	// From the version information table of the standard
+	tests := map[int]int{
+		7:  0x07C94,
+		8:  0x085BC,
+		21: 0x15683,
+		32: 0x209D5,
+		40: 0x28C69,
+	}
+	for version, want := range tests {
+		c := newCode(version, LevelL)
+		c.drawVersion()
+
+		var below, right int
+		for i := 0; i < 18; i++ {
+			a, b := c.size-11+i%3, i/3
+			if c.modules[b][a] {
+				below |= 1 << i
+			}
+			if c.modules[a][b] {
+				right |= 1 << i
+			}
+		}
+		if below != want || right != want {
+			t.Errorf("version %d: bits %018b and %018b, want %018b", version, below, right, want)
+		}
+	}
+
+	c := newCode(6, LevelL)
+	c.drawVersion()
+	for y := range c.isFunction {
+		for x := range c.isFunction[y] {
+			if c.isFunction[y][x] {
+				t.Fatalf("version 6 drew version bits at %d,%d", x, y)
+			}
+		}
+	}
+}
+
+func TestAlignmentPositions(t *testing.T) {
+	tests := map[int][]int{
+		1:  nil,
+		2:  {6, 18},
+		7:  {6, 22, 38},
+		14: {6, 26, 46, 66},
+		32: {6, 34, 60, 86, 112, 138},
+		36: {6, 24, 50, 76, 102, 128, 154},
+		40: {6, 30, 58, 86, 114, 142, 170},
+	}
+	for version, want := range tests {
+		if got := alignmentPositions(version); !slices.Equal(got, want) {
+			t.Errorf("alignmentPositions(%d) = %v, want %v", version, got, want)
+		}
+	}
+}
+
+func TestEncodePicksLowestPenalty(t *testing.T) {
+	for _, level := range []Level{LevelL, LevelM, LevelQ, LevelH} {
+		c, err := Encode([]byte("https://sho.rt/abc123?utm_source=qr"), level)
+		if err != nil {
+			t.Fatal(err)
+		}
+		chosen := c.Mask
+		best := c.penalty()
+
+		c.applyMask(chosen)
+		for mask := 0; mask < 8; mask++ {
+			c.applyMask(mask)
+			c.drawFormatBits(mask)
+			if p := c.penalty(); p < best || (p == best && mask < chosen) {
+				t.Errorf("%s: mask %d scores %d, below mask %d at %d", level, mask, p, chosen, best)
+			}
+			c.applyMask(mask)
+		}
+	}
+}
+
+func TestApplyMaskTwiceRestores(t *testing.T) {
+	c, err := Encode([]byte("https://sho.rt/x"), LevelM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := make([][]bool, c.size)
+	for y := range c.modules {
+		before[y] = slices.Clone(c.modules[y])
+	}
+	for mask := 0; mask < 8; mask++ {
+		c.applyMask(mask)
+		c.applyMask(mask)
+		for y := range c.modules {
+			if !slices.Equal(c.modules[y], before[y]) {
+				t.Fatalf("mask %d did not undo itself", mask)
+			}
+		}
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for s, want := range map[string]Level{"L": LevelL, "m": LevelM, "Q": LevelQ, "h": LevelH} {
+		got, err := ParseLevel(s)
+		if err != nil || got != want {
+			t.Errorf("ParseLevel(%q) = %v, %v, want %v", s, got, err, want)
+		}
+		if got.String() != strings.ToUpper(s) {
+			t.Errorf("%v.String() = %q, want %q", got, got.String(), strings.ToUpper(s))
+		}
+	}
+	for _, s := range []string{"", "X", "LM", "low"} {
+		if _, err := ParseLevel(s); err == nil {
+			t.Errorf("ParseLevel(%q) succeeded", s)
+		}
+	}
+}
+
+func TestDarkOutsideSymbol(t *testing.T) {
+	c, err := Encode([]byte("x"), LevelL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The top left corner of a finder is dark, everything outside is light
+	if !c.Dark(0, 0) {
+		t.Error("Dark(0, 0) = false")
+	}
+	for _, p := range [][2]int{{-1, 0}, {0, -1}, {c.Size(), 0}, {0, c.Size()}} {
+		if c.Dark(p[0], p[1]) {
+			t.Errorf("Dark(%d, %d) = true outside the symbol", p[0], p[1])
+		}
+	}
+}
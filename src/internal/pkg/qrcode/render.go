@@ -0,0 +1,235 @@
+package qrcode
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// logoFraction is the largest share of the symbol width a logo may cover.
+// A fifth of the width hides about 4% of the modules, which level H, and in
+// practice Q, recovers easily.
+const logoFraction = 5
+
+// ErrSizeTooSmall is returned when the image cannot give every module at
+// least one pixel
+var ErrSizeTooSmall = errors.New("image size too small for qr code")
+
+// Options control how a code is drawn
+type Options struct {
+	// Size is the width and height of the image in pixels
+	Size int
+	// Margin is the quiet zone around the symbol, in modules
+	Margin     int
+	Foreground color.NRGBA
+	Background color.NRGBA
+	// Logo, when set, is drawn over the middle of the symbol on a plate of
+	// the background colour
+	Logo image.Image
+}
+
+// MinSize is the smallest image that holds c with the given margin
+func (c *Code) MinSize(margin int) int {
+	return c.size + 2*margin
+}
+
+// Image draws the code at opts.Size pixels. Modules are mapped to pixels
+// proportionally, so they differ by at most one pixel when the size is not a
+// multiple of the module count.
+func (c *Code) Image(opts Options) (*image.NRGBA, error) {
+	total := c.MinSize(opts.Margin)
+	if opts.Size < total {
+		return nil, ErrSizeTooSmall
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, opts.Size, opts.Size))
+	for py := 0; py < opts.Size; py++ {
+		my := py*total/opts.Size - opts.Margin
+		for px := 0; px < opts.Size; px++ {
+			mx := px*total/opts.Size - opts.Margin
+			if c.Dark(mx, my) {
+				img.SetNRGBA(px, py, opts.Foreground)
+			} else {
+				img.SetNRGBA(px, py, opts.Background)
+			}
+		}
+	}
+
+	if opts.Logo != nil {
+		plate, logo := c.logoBox(opts)
+		draw.Draw(img, plate, &image.Uniform{C: opts.Background}, image.Point{}, draw.Src)
+		scaled := fit(opts.Logo, logo.Dx(), logo.Dy())
+		offset := image.Pt(
+			logo.Min.X+(logo.Dx()-scaled.Bounds().Dx())/2,
+			logo.Min.Y+(logo.Dy()-scaled.Bounds().Dy())/2,
+		)
+		draw.Draw(img, scaled.Bounds().Add(offset), scaled, image.Point{}, draw.Over)
+	}
+	return img, nil
+}
+
+// WritePNG encodes the drawn code as PNG
+func (c *Code) WritePNG(w io.Writer, opts Options) error {
+	img, err := c.Image(opts)
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
+
+// WriteSVG writes the code as an SVG document. Dark modules are merged into
+// horizontal runs of a single path, and the logo is embedded as a PNG data
+// URI sized for opts.Size.
+func (c *Code) WriteSVG(w io.Writer, opts Options) error {
+	total := c.MinSize(opts.Margin)
+	if opts.Size < total {
+		return ErrSizeTooSmall
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<?xml version="1.0" encoding="UTF-8"?>`+"\n")
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`+"\n",
+		opts.Size, opts.Size, total, total)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d"%s/>`+"\n", total, total, svgFill(opts.Background))
+
+	b.WriteString(`<path d="`)
+	for y := 0; y < c.size; y++ {
+		for x := 0; x < c.size; {
+			if !c.Dark(x, y) {
+				x++
+				continue
+			}
+			run := 1
+			for c.Dark(x+run, y) {
+				run++
+			}
+			fmt.Fprintf(&b, "M%d %dh%dv1h-%dz", x+opts.Margin, y+opts.Margin, run, run)
+			x += run
+		}
+	}
+	fmt.Fprintf(&b, `"%s/>`+"\n", svgFill(opts.Foreground))
+
+	if opts.Logo != nil {
+		// The logo box is computed in pixels and converted back to modules
+		plate, logo := c.logoBox(opts)
+		unit := float64(total) / float64(opts.Size)
+		fmt.Fprintf(&b, `<rect x="%s" y="%s" width="%s" height="%s"%s/>`+"\n",
+			svgNum(float64(plate.Min.X)*unit), svgNum(float64(plate.Min.Y)*unit),
+			svgNum(float64(plate.Dx())*unit), svgNum(float64(plate.Dy())*unit),
+			svgFill(opts.Background))
+
+		var logoPNG bytes.Buffer
+		if err := png.Encode(&logoPNG, fit(opts.Logo, logo.Dx(), logo.Dy())); err != nil {
+			return fmt.Errorf("failed to encode logo: %w", err)
+		}
+		fmt.Fprintf(&b, `<image x="%s" y="%s" width="%s" height="%s" preserveAspectRatio="xMidYMid meet" href="data:image/png;base64,%s"/>`+"\n",
+			svgNum(float64(logo.Min.X)*unit), svgNum(float64(logo.Min.Y)*unit),
+			svgNum(float64(logo.Dx())*unit), svgNum(float64(logo.Dy())*unit),
+			base64.StdEncoding.EncodeToString(logoPNG.Bytes()))
+	}
+	b.WriteString("</svg>\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// logoBox returns the pixel rectangles of the logo plate and of the logo
+// inside it, both centred on the image
+func (c *Code) logoBox(opts Options) (plate, logo image.Rectangle) {
+	total := c.MinSize(opts.Margin)
+	module := max(opts.Size/total, 1)
+	side := c.size * opts.Size / total / logoFraction
+	center := opts.Size / 2
+
+	logo = image.Rect(center-side/2, center-side/2, center-side/2+side, center-side/2+side)
+	plate = logo.Inset(-module)
+	return plate, logo
+}
+
+// ParseColor reads a hex colour as RGB, RGBA, RRGGBB or RRGGBBAA, with or
+// without a leading #
+func ParseColor(s string) (color.NRGBA, error) {
+	hex := strings.TrimPrefix(s, "#")
+	switch len(hex) {
+	case 3, 4:
+		var long strings.Builder
+		for _, r := range hex {
+			long.WriteRune(r)
+			long.WriteRune(r)
+		}
+		hex = long.String()
+	case 6, 8:
+	default:
+		return color.NRGBA{}, fmt.Errorf("invalid color %q", s)
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.NRGBA{}, fmt.Errorf("invalid color %q", s)
+	}
+	return color.NRGBA{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}, nil
+}
+
+// fit scales src down or up to fit within w by h, keeping its aspect ratio.
+// Each output pixel averages the source pixels it covers.
+func fit(src image.Image, w, h int) *image.NRGBA {
+	sb := src.Bounds()
+	if sb.Dx() == 0 || sb.Dy() == 0 || w <= 0 || h <= 0 {
+		return image.NewNRGBA(image.Rect(0, 0, 0, 0))
+	}
+	if sb.Dx()*h > sb.Dy()*w {
+		h = max(sb.Dy()*w/sb.Dx(), 1)
+	} else {
+		w = max(sb.Dx()*h/sb.Dy(), 1)
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		y0 := sb.Min.Y + y*sb.Dy()/h
+		y1 := max(sb.Min.Y+(y+1)*sb.Dy()/h, y0+1)
+		for x := 0; x < w; x++ {
+			x0 := sb.Min.X + x*sb.Dx()/w
+			x1 := max(sb.Min.X+(x+1)*sb.Dx()/w, x0+1)
+
+			// Sum premultiplied values so transparent pixels do not
+			// darken the edges
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(cr), g+uint64(cg), b+uint64(cb), a+uint64(ca)
+					n++
+				}
+			}
+			dst.Set(x, y, color.RGBA64{
+				R: uint16(r / n), G: uint16(g / n), B: uint16(b / n), A: uint16(a / n),
+			})
+		}
+	}
+	return dst
+}
+
+func svgFill(c color.NRGBA) string {
+	fill := fmt.Sprintf(` fill="#%02x%02x%02x"`, c.R, c.G, c.B)
+	if c.A != 0xff {
+		fill += fmt.Sprintf(` fill-opacity="%s"`, svgNum(float64(c.A)/0xff))
+	}
+	return fill
+}
+
+// svgNum formats a coordinate with at most three decimals
+func svgNum(f float64) string {
+	return strconv.FormatFloat(math.Round(f*1000)/1000, 'f', -1, 64)
+}
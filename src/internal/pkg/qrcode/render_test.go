@@ -0,0 +1,224 @@
+package qrcode
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+var (
+	black = color.NRGBA{A: 0xff}
+	white = color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	red   = color.NRGBA{R: 0xff, A: 0xff}
+)
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    color.NRGBA
+		wantErr bool
+	}{
+		{in: "#000000", want: black},
+		{in: "ffffff", want: white},
+		{in: "#FF0000", want: red},
+		{in: "#1a2B3c", want: color.NRGBA{R: 0x1a, G: 0x2b, B: 0x3c, A: 0xff}},
+		{in: "#f00", want: red},
+		{in: "abc", want: color.NRGBA{R: 0xaa, G: 0xbb, B: 0xcc, A: 0xff}},
+		{in: "#f008", want: color.NRGBA{R: 0xff, A: 0x88}},
+		{in: "#11223344", want: color.NRGBA{R: 0x11, G: 0x22, B: 0x33, A: 0x44}},
+		{in: "00000000", want: color.NRGBA{}},
+		{in: "", wantErr: true},
+		{in: "#", wantErr: true},
+		{in: "#12", wantErr: true},
+		{in: "#12345", wantErr: true},
+		{in: "#1234567", wantErr: true},
+		{in: "#123456789", wantErr: true},
+		{in: "#ggg", wantErr: true},
+		{in: "#12345z", wantErr: true},
+		{in: "##fff", wantErr: true},
+		{in: "+fffff", wantErr: true},
+		{in: "red", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseColor(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseColor = %v, want an error", got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("ParseColor = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSizeTooSmall(t *testing.T) {
+	c, err := Encode([]byte("https://sho.rt/x"), LevelL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.MinSize(4); got != 29 {
+		t.Fatalf("MinSize(4) = %d, want 29", got)
+	}
+
+	for _, opts := range []Options{
+		{Size: 28, Margin: 4},
+		{Size: 20, Margin: 0},
+		{Size: 0},
+		{Size: -1},
+	} {
+		if _, err := c.Image(opts); !errors.Is(err, ErrSizeTooSmall) {
+			t.Errorf("Image(%+v): err = %v, want ErrSizeTooSmall", opts, err)
+		}
+		var buf bytes.Buffer
+		if err := c.WritePNG(&buf, opts); !errors.Is(err, ErrSizeTooSmall) || buf.Len() != 0 {
+			t.Errorf("WritePNG(%+v): err = %v with %d bytes, want ErrSizeTooSmall and no output", opts, err, buf.Len())
+		}
+		if err := c.WriteSVG(&buf, opts); !errors.Is(err, ErrSizeTooSmall) || buf.Len() != 0 {
+			t.Errorf("WriteSVG(%+v): err = %v with %d bytes, want ErrSizeTooSmall and no output", opts, err, buf.Len())
+		}
+	}
+
+	// The smallest size draws one pixel per module
+	img, err := c.Image(Options{Size: 29, Margin: 4, Foreground: black, Background: white})
+	if err != nil {
+		t.Fatalf("Image at MinSize: %v", err)
+	}
+	for y := 0; y < 29; y++ {
+		for x := 0; x < 29; x++ {
+			want := white
+			if c.Dark(x-4, y-4) {
+				want = black
+			}
+			if got := img.NRGBAAt(x, y); got != want {
+				t.Fatalf("pixel %d,%d = %v, want %v", x, y, got, want)
+			}
+		}
+	}
+}
+
+func TestImageScalesModules(t *testing.T) {
+	c, err := Encode([]byte("https://sho.rt/x"), LevelL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := c.WritePNG(&buf, Options{Size: 100, Margin: 2, Foreground: black, Background: white}); err != nil {
+		t.Fatalf("WritePNG: %v", err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("png.Decode: %v", err)
+	}
+	if img.Bounds() != image.Rect(0, 0, 100, 100) {
+		t.Fatalf("bounds = %v, want 100x100", img.Bounds())
+	}
+
+	// 100 pixels over 25 modules is exactly 4 per module
+	for my := -2; my < c.Size()+2; my++ {
+		for mx := -2; mx < c.Size()+2; mx++ {
+			want := color.NRGBAModel.Convert(white)
+			if c.Dark(mx, my) {
+				want = color.NRGBAModel.Convert(black)
+			}
+			for _, p := range []image.Point{{0, 0}, {3, 3}} {
+				px, py := (mx+2)*4+p.X, (my+2)*4+p.Y
+				if got := color.NRGBAModel.Convert(img.At(px, py)); got != want {
+					t.Fatalf("module %d,%d pixel %d,%d = %v, want %v", mx, my, px, py, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestLogoBox(t *testing.T) {
+	tests := []struct {
+		version int
+		size    int
+		margin  int
+		plate   image.Rectangle
+		logo    image.Rectangle
+	}{
+		// 29 modules of 10 pixels, the logo covers 42 of the symbol's 210
+		{version: 1, size: 290, margin: 4, plate: image.Rect(114, 114, 176, 176), logo: image.Rect(124, 124, 166, 166)},
+		// 33 modules over 300 pixels round down to 9 per module
+		{version: 2, size: 300, margin: 4, plate: image.Rect(119, 119, 182, 182), logo: image.Rect(128, 128, 173, 173)},
+		// Without a margin, at one pixel per module
+		{version: 7, size: 45, margin: 0, plate: image.Rect(17, 17, 28, 28), logo: image.Rect(18, 18, 27, 27)},
+	}
+	for _, tt := range tests {
+		c := newCode(tt.version, LevelH)
+		opts := Options{Size: tt.size, Margin: tt.margin}
+		plate, logo := c.logoBox(opts)
+		if plate != tt.plate || logo != tt.logo {
+			t.Errorf("version %d at %d pixels: logoBox = %v, %v, want %v, %v", tt.version, tt.size, plate, logo, tt.plate, tt.logo)
+		}
+
+		// The logo never covers more than its share of the symbol, is
+		// square and stays inside the symbol
+		symbol := c.size * tt.size / c.MinSize(tt.margin)
+		if logo.Dx() != logo.Dy() || logo.Dx() > symbol/logoFraction {
+			t.Errorf("version %d: logo %v is larger than a %d pixel square", tt.version, logo, symbol/logoFraction)
+		}
+		quiet := tt.margin * tt.size / c.MinSize(tt.margin)
+		if !plate.In(image.Rect(quiet, quiet, tt.size-quiet, tt.size-quiet)) {
+			t.Errorf("version %d: plate %v leaves the symbol", tt.version, plate)
+		}
+	}
+}
+
+func TestImageDrawsLogo(t *testing.T) {
+	c, err := Encode([]byte("https://sho.rt/x"), LevelL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A logo twice as wide as tall is centred vertically in its box
+	wide := image.NewNRGBA(image.Rect(0, 0, 20, 10))
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 20; x++ {
+			wide.SetNRGBA(x, y, red)
+		}
+	}
+	opts := Options{Size: 290, Margin: 4, Foreground: black, Background: white, Logo: wide}
+
+	img, err := c.Image(opts)
+	if err != nil {
+		t.Fatalf("Image: %v", err)
+	}
+	plate, box := c.logoBox(opts)
+	if got := img.NRGBAAt(box.Min.X, box.Min.Y+box.Dy()/2); got != red {
+		t.Errorf("middle left of the logo box = %v, want the logo", got)
+	}
+	if got := img.NRGBAAt(box.Min.X+box.Dx()/2, box.Min.Y); got != white {
+		t.Errorf("top of the logo box = %v, want the plate", got)
+	}
+	for _, p := range []image.Point{plate.Min, plate.Max.Sub(image.Pt(1, 1))} {
+		if got := img.NRGBAAt(p.X, p.Y); got != white {
+			t.Errorf("plate corner %v = %v, want the background", p, got)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := c.WriteSVG(&buf, opts); err != nil {
+		t.Fatalf("WriteSVG: %v", err)
+	}
+	// 290 pixels over 29 modules, so pixels convert to modules at a tenth
+	for _, want := range []string{
+		`viewBox="0 0 29 29"`,
+		`<rect x="11.4" y="11.4" width="6.2" height="6.2" fill="#ffffff"/>`,
+		`<image x="12.4" y="12.4" width="4.2" height="4.2"`,
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("SVG does not contain %s", want)
+		}
+	}
+}
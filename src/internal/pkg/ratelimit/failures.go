@@ -0,0 +1,94 @@
+// Package ratelimit holds in-memory limiters for abuse-prone endpoints
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Failures counts failed attempts per key and blocks a key once it reaches
+// the limit within a fixed window that starts at its first failure. State is
+// kept in memory, so it is per process and reset on restart.
+type Failures struct {
+	mu        sync.Mutex
+	limit     int
+	window    time.Duration
+	entries   map[string]*failureEntry
+	lastSweep time.Time
+}
+
+type failureEntry struct {
+	count   int
+	resetAt time.Time
+}
+
+// NewFailures allows up to limit failures per key in each window
+func NewFailures(limit int, window time.Duration) *Failures {
+	return &Failures{
+		limit:   limit,
+		window:  window,
+		entries: make(map[string]*failureEntry),
+	}
+}
+
+// Blocked reports whether key has used up its attempts, and if so how long
+// until it may try again
+func (f *Failures) Blocked(key string) (bool, time.Duration) {
+	if f.limit <= 0 {
+		return false, 0
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := time.Now()
+	entry, ok := f.entries[key]
+	if !ok || !now.Before(entry.resetAt) {
+		return false, 0
+	}
+	if entry.count < f.limit {
+		return false, 0
+	}
+	return true, entry.resetAt.Sub(now)
+}
+
+// Fail records a failed attempt for key
+func (f *Failures) Fail(key string) {
+	if f.limit <= 0 {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := time.Now()
+	f.sweep(now)
+
+	entry, ok := f.entries[key]
+	if !ok || !now.Before(entry.resetAt) {
+		entry = &failureEntry{resetAt: now.Add(f.window)}
+		f.entries[key] = entry
+	}
+	entry.count++
+}
+
+// Reset forgets the failures of key, e.g. after a successful attempt
+func (f *Failures) Reset(key string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.entries, key)
+}
+
+// sweep drops expired entries at most once per window so the map cannot grow
+// without bound. Callers must hold f.mu.
+func (f *Failures) sweep(now time.Time) {
+	if now.Sub(f.lastSweep) < f.window {
+		return
+	}
+	f.lastSweep = now
+	for key, entry := range f.entries {
+		if !now.Before(entry.resetAt) {
+			delete(f.entries, key)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+// Package safehttp builds HTTP clients for fetching user supplied URLs. The
+// clients refuse to connect to loopback, private and other non-public
+// addresses. The check runs on the resolved address of every connection, so
+// redirects and DNS rebinding cannot get around it.
+package safehttp
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// ErrBlockedAddress is returned when a connection would reach a non-public
+// address
+var ErrBlockedAddress = errors.New("destination address is not public")
+
+// ErrTooManyRedirects is returned when a response redirects more often than
+// allowed
+var ErrTooManyRedirects = errors.New("too many redirects")
+
+// Options configure a client
+type Options struct {
+	// Timeout bounds a whole request, redirects and body included
+	Timeout      time.Duration
+	MaxRedirects int
+	// AllowPrivate turns the address check off. Only for development and
+	// tests against local servers.
+	AllowPrivate bool
+}
+
+// nonPublic lists special purpose ranges netip has no predicate for
+var nonPublic = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.0.2.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("198.51.100.0/24"),
+	netip.MustParsePrefix("203.0.113.0/24"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("100::/64"),
+	netip.MustParsePrefix("2001:db8::/32"),
+}
+
+// embedsIPv4 lists IPv6 translation ranges that carry an IPv4 address, which
+// must be public too
+var embedsIPv4 = []struct {
+	prefix netip.Prefix
+	offset int
+}{
+	{netip.MustParsePrefix("64:ff9b::/96"), 12},
+	{netip.MustParsePrefix("2002::/16"), 2},
+}
+
+// NewClient returns a client that only connects to public addresses. It
+// ignores proxy settings, since a proxy would make the address check
+// meaningless, and only follows redirects to http and https URLs.
+func NewClient(opts Options) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: opts.Timeout,
+	}
+	if !opts.AllowPrivate {
+		dialer.Control = checkAddress
+	}
+
+	transport := &http.Transport{
+		Proxy:                 nil,
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   opts.Timeout,
+		ResponseHeaderTimeout: opts.Timeout,
+		MaxIdleConns:          20,
+		IdleConnTimeout:       30 * time.Second,
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   opts.Timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > opts.MaxRedirects {
+				return ErrTooManyRedirects
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return fmt.Errorf("redirect to unsupported scheme %q", req.URL.Scheme)
+			}
+			return nil
+		},
+	}
+}
+
+// IsPublic reports whether addr is a globally routable unicast address
+func IsPublic(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range nonPublic {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	for _, e := range embedsIPv4 {
+		if e.prefix.Contains(addr) {
+			b := addr.As16()
+			return IsPublic(netip.AddrFrom4([4]byte(b[e.offset : e.offset+4])))
+		}
+	}
+	return true
+}
+
+// checkAddress is a dialer control function run after name resolution with
+// the IP address about to be connected to
+func checkAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if !IsPublic(addr) {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, addr)
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package safehttp
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIsPublic(t *testing.T) {
+	tests := map[string]bool{
+		"93.184.215.14":        true,
+		"8.8.8.8":              true,
+		"2606:4700::1111":      true,
+		"::ffff:8.8.8.8":       true,
+		"64:ff9b::808:808":     true,
+		"2002:808:808::1":      true,
+		"127.0.0.1":            false,
+		"::1":                  false,
+		"10.1.2.3":             false,
+		"172.16.0.1":           false,
+		"192.168.1.1":          false,
+		"169.254.169.254":      false,
+		"fe80::1":              false,
+		"fc00::1":              false,
+		"0.0.0.0":              false,
+		"::":                   false,
+		"100.64.0.1":           false,
+		"192.0.2.1":            false,
+		"198.18.0.1":           false,
+		"203.0.113.7":          false,
+		"240.0.0.1":            false,
+		"255.255.255.255":      false,
+		"224.0.0.1":            false,
+		"ff02::1":              false,
+		"2001:db8::1":          false,
+		"100::1":               false,
+		"::ffff:127.0.0.1":     false,
+		"::ffff:10.0.0.1":      false,
+		"64:ff9b::7f00:1":      false,
+		"64:ff9b::a9fe:a9fe":   false,
+		"2002:7f00:1::1":       false,
+		"2002:c0a8:101::1":     false,
+		"fe80::1%eth0":         false,
+		"2001:4860:4860::8888": true,
+	}
+	for addr, want := range tests {
+		if got := IsPublic(netip.MustParseAddr(addr)); got != want {
+			t.Errorf("IsPublic(%s) = %v, want %v", addr, got, want)
+		}
+	}
+	if IsPublic(netip.Addr{}) {
+		t.Error("IsPublic(zero Addr) = true, want false")
+	}
+}
+
+func TestCheckAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		blocked bool
+		invalid bool
+	}{
+		{address: "8.8.8.8:443"},
+		{address: "[2606:4700::1111]:80"},
+		{address: "127.0.0.1:80", blocked: true},
+		{address: "[::1]:80", blocked: true},
+		{address: "10.0.0.1:8080", blocked: true},
+		{address: "[fd00::1]:443", blocked: true},
+		{address: "169.254.169.254:80", blocked: true},
+		{address: "8.8.8.8", invalid: true},
+		{address: "example.com:80", invalid: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			err := checkAddress("tcp", tt.address, nil)
+			switch {
+			case tt.blocked:
+				if !errors.Is(err, ErrBlockedAddress) {
+					t.Errorf("checkAddress = %v, want ErrBlockedAddress", err)
+				}
+			case tt.invalid:
+				if err == nil || errors.Is(err, ErrBlockedAddress) {
+					t.Errorf("checkAddress = %v, want a parse error", err)
+				}
+			case err != nil:
+				t.Errorf("checkAddress = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestClientRefusesLoopback(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request reached the loopback server")
+	}))
+	defer srv.Close()
+
+	client := NewClient(Options{Timeout: 5 * time.Second, MaxRedirects: 3})
+	for _, target := range []string{srv.URL, strings.Replace(srv.URL, "127.0.0.1", "localhost", 1)} {
+		resp, err := client.Get(target)
+		if err == nil {
+			resp.Body.Close()
+		}
+		if !errors.Is(err, ErrBlockedAddress) {
+			t.Errorf("Get(%s) = %v, want ErrBlockedAddress", target, err)
+		}
+	}
+}
+
+func TestClientAllowPrivate(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ok")
+	}))
+	defer srv.Close()
+
+	resp, err := NewClient(Options{Timeout: 5 * time.Second, AllowPrivate: true}).Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want 200", resp.StatusCode)
+	}
+}
+
+func TestClientRedirectLimit(t *testing.T) {
+	// /n redirects to /n-1 until /0, which answers
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/"))
+		if n > 0 {
+			http.Redirect(w, r, "/"+strconv.Itoa(n-1), http.StatusFound)
+			return
+		}
+		fmt.Fprint(w, "done")
+	}))
+	defer srv.Close()
+
+	client := NewClient(Options{Timeout: 5 * time.Second, MaxRedirects: 3, AllowPrivate: true})
+
+	resp, err := client.Get(srv.URL + "/3")
+	if err != nil {
+		t.Fatalf("3 redirects: %v", err)
+	}
+	resp.Body.Close()
+	if resp.Request.URL.Path != "/0" {
+		t.Errorf("final path = %s, want /0", resp.Request.URL.Path)
+	}
+
+	resp, err = client.Get(srv.URL + "/4")
+	if err == nil {
+		resp.Body.Close()
+	}
+	if !errors.Is(err, ErrTooManyRedirects) {
+		t.Errorf("4 redirects: err = %v, want ErrTooManyRedirects", err)
+	}
+}
+
+func TestClientRefusesRedirectToOtherSchemes(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "ftp://example.com/file", http.StatusFound)
+	}))
+	defer srv.Close()
+
+	resp, err := NewClient(Options{Timeout: 5 * time.Second, MaxRedirects: 3, AllowPrivate: true}).Get(srv.URL)
+	if err == nil {
+		resp.Body.Close()
+		t.Fatal("Get followed a redirect to ftp")
+	}
+	if !strings.Contains(err.Error(), "unsupported scheme") {
+		t.Errorf("err = %v, want an unsupported scheme error", err)
+	}
+}
@@ -0,0 +1,102 @@
+package shortcode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// hashidsGenerator encodes numbers from a sequence in the style of Hashids: a
+// lottery character seeds salted shuffles of the alphabet that the number is
+// then written in. Codes are unique for unique numbers and
+// do not look sequential.
+type hashidsGenerator struct {
+	seq       Sequence
+	alphabet  []byte
+	salt      []byte
+	minLength int
+	offset    uint64
+}
+
+// NewHashids returns a generator of codes encoding numbers taken from seq
+func NewHashids(seq Sequence, alphabet, salt string, minLength int) (Generator, error) {
+	if seq == nil {
+		return nil, errors.New("shortcode: hashids strategy needs a sequence")
+	}
+	if minLength < MinLength || minLength > MaxLength {
+		return nil, fmt.Errorf("shortcode: hashids min length must be between %d and %d, got %d", MinLength, MaxLength, minLength)
+	}
+
+	g := &hashidsGenerator{
+		seq:       seq,
+		alphabet:  consistentShuffle([]byte(alphabet), []byte(salt)),
+		salt:      []byte(salt),
+		minLength: minLength,
+	}
+
+	// Offsetting the number pads every code to minLength without breaking
+	// uniqueness: one lottery character plus minLength-1 digits
+	g.offset = 1
+	for i := 0; i < minLength-2; i++ {
+		g.offset *= uint64(len(g.alphabet))
+	}
+	return g, nil
+}
+
+func (g *hashidsGenerator) Generate(ctx context.Context) (string, error) {
+	n, err := g.seq.Next(ctx)
+	if err != nil {
+		return "", fmt.Errorf("shortcode: failed to read sequence: %w", err)
+	}
+
+	code := g.encode(n + g.offset)
+	if len(code) > MaxLength {
+		return "", fmt.Errorf("shortcode: sequence value %d does not fit in %d characters", n, MaxLength)
+	}
+	return code, nil
+}
+
+func (g *hashidsGenerator) encode(n uint64) string {
+	size := uint64(len(g.alphabet))
+	lottery := g.alphabet[n%size]
+
+	var digits []uint64
+	for {
+		digits = append([]uint64{n % size}, digits...)
+		n /= size
+		if n == 0 {
+			break
+		}
+	}
+
+	// Reshuffle after every character, as Hashids does between numbers, so
+	// the zero digits of small offset numbers do not repeat one character.
+	// Each shuffle only depends on what was already written, which keeps the
+	// encoding reversible and so unique.
+	code := []byte{lottery}
+	alphabet := g.alphabet
+	for _, d := range digits {
+		buffer := append([]byte{code[len(code)-1]}, g.salt...)
+		buffer = append(buffer, alphabet...)
+		alphabet = consistentShuffle(alphabet, buffer[:len(alphabet)])
+		code = append(code, alphabet[d])
+	}
+	return string(code)
+}
+
+// consistentShuffle is the Hashids salted shuffle. The same salt always gives
+// the same order.
+func consistentShuffle(alphabet, salt []byte) []byte {
+	result := append([]byte(nil), alphabet...)
+	if len(salt) == 0 {
+		return result
+	}
+
+	for i, v, p := len(result)-1, 0, 0; i > 0; i-- {
+		p += int(salt[v])
+		j := (int(salt[v]) + v + p) % i
+		result[i], result[j] = result[j], result[i]
+		v = (v + 1) % len(salt)
+	}
+	return result
+}
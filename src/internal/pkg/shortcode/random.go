@@ -0,0 +1,31 @@
+package shortcode
+
+import (
+	"context"
+	"fmt"
+)
+
+type randomGenerator struct {
+	alphabet string
+	length   int
+}
+
+// NewRandom returns a generator of uniformly random codes of a fixed length
+func NewRandom(alphabet string, length int) (Generator, error) {
+	if length < MinLength || length > MaxLength {
+		return nil, fmt.Errorf("shortcode: random length must be between %d and %d, got %d", MinLength, MaxLength, length)
+	}
+	return &randomGenerator{alphabet: alphabet, length: length}, nil
+}
+
+func (g *randomGenerator) Generate(context.Context) (string, error) {
+	code := make([]byte, g.length)
+	for i := range code {
+		n, err := randomIndex(len(g.alphabet))
+		if err != nil {
+			return "", err
+		}
+		code[i] = g.alphabet[n]
+	}
+	return string(code), nil
+}
@@ -0,0 +1,111 @@
+// Package shortcode generates candidate short codes for links. Generators do
+// not check uniqueness; callers retry when a code is already taken.
+package shortcode
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/imraushankr/brevity/server/src/configs"
+)
+
+// Supported strategies
+const (
+	StrategyRandom  = "random"
+	StrategyHashids = "hashids"
+	StrategyWords   = "words"
+)
+
+// DefaultAlphabet is the base62 alphabet used when none is configured
+const DefaultAlphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// Short codes are stored and routed as 3 to 10 alphanumeric characters
+const (
+	MinLength = 3
+	MaxLength = 10
+)
+
+// Generator produces short code candidates
+type Generator interface {
+	Generate(ctx context.Context) (string, error)
+}
+
+// Sequence hands out increasing numbers for counter based codes
+type Sequence interface {
+	Next(ctx context.Context) (uint64, error)
+}
+
+// Counters stores named, atomically incremented counters
+type Counters interface {
+	Next(ctx context.Context, name string) (uint64, error)
+}
+
+// NamedSequence exposes a single counter of c as a Sequence
+func NamedSequence(c Counters, name string) Sequence {
+	return namedSequence{counters: c, name: name}
+}
+
+type namedSequence struct {
+	counters Counters
+	name     string
+}
+
+func (s namedSequence) Next(ctx context.Context) (uint64, error) {
+	return s.counters.Next(ctx, s.name)
+}
+
+// New builds the generator selected by cfg.Strategy. seq is only used by the
+// hashids strategy.
+func New(cfg *configs.ShortCodeConfig, seq Sequence) (Generator, error) {
+	alphabet := cfg.Alphabet
+	if alphabet == "" {
+		alphabet = DefaultAlphabet
+	}
+	if err := validateAlphabet(alphabet); err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(cfg.Strategy) {
+	case "", StrategyRandom:
+		return NewRandom(alphabet, cfg.Length)
+	case StrategyHashids:
+		return NewHashids(seq, alphabet, cfg.Salt, cfg.MinLength)
+	case StrategyWords:
+		return NewWords(cfg.Words, cfg.Digits)
+	default:
+		return nil, fmt.Errorf("shortcode: unknown strategy %q", cfg.Strategy)
+	}
+}
+
+func validateAlphabet(alphabet string) error {
+	seen := make(map[rune]bool, len(alphabet))
+	for _, r := range alphabet {
+		if !isAlphanumeric(r) {
+			return fmt.Errorf("shortcode: alphabet may only contain ASCII letters and digits, got %q", r)
+		}
+		if seen[r] {
+			return fmt.Errorf("shortcode: alphabet repeats %q", r)
+		}
+		seen[r] = true
+	}
+	if len(seen) < 16 {
+		return fmt.Errorf("shortcode: alphabet needs at least 16 characters, got %d", len(seen))
+	}
+	return nil
+}
+
+func isAlphanumeric(r rune) bool {
+	return (r >= '0' && r <= '9') || (r >= 'A' && r <= 'Z') || (r >= 'a' && r <= 'z')
+}
+
+// randomIndex returns a uniformly random int in [0, n)
+func randomIndex(n int) (int, error) {
+	v, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, err
+	}
+	return int(v.Int64()), nil
+}
@@ -0,0 +1,208 @@
+package shortcode
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/imraushankr/brevity/server/src/configs"
+)
+
+// counter is an in-memory Sequence
+type counter struct {
+	n   uint64
+	err error
+}
+
+func (c *counter) Next(context.Context) (uint64, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	c.n++
+	return c.n, nil
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     configs.ShortCodeConfig
+		seq     Sequence
+		pattern string
+		wantErr bool
+	}{
+		{name: "default", cfg: configs.ShortCodeConfig{Length: 7}, pattern: `^[0-9A-Za-z]{7}$`},
+		{name: "random", cfg: configs.ShortCodeConfig{Strategy: "Random", Length: 5, Alphabet: "0123456789abcdef"}, pattern: `^[0-9a-f]{5}$`},
+		{name: "hashids", cfg: configs.ShortCodeConfig{Strategy: StrategyHashids, MinLength: 6, Salt: "pepper"}, seq: &counter{}, pattern: `^[0-9A-Za-z]{6}$`},
+		{name: "words", cfg: configs.ShortCodeConfig{Strategy: StrategyWords, Words: 2, Digits: 2}, pattern: `^([A-Z][a-z]{2,3}){2}[0-9]{2}$`},
+		{name: "words without digits", cfg: configs.ShortCodeConfig{Strategy: StrategyWords, Words: 1}, pattern: `^[A-Z][a-z]{2,3}$`},
+		{name: "unknown strategy", cfg: configs.ShortCodeConfig{Strategy: "uuid", Length: 7}, wantErr: true},
+		{name: "random too short", cfg: configs.ShortCodeConfig{Length: MinLength - 1}, wantErr: true},
+		{name: "random too long", cfg: configs.ShortCodeConfig{Length: MaxLength + 1}, wantErr: true},
+		{name: "hashids without sequence", cfg: configs.ShortCodeConfig{Strategy: StrategyHashids, MinLength: 6}, wantErr: true},
+		{name: "hashids min length too long", cfg: configs.ShortCodeConfig{Strategy: StrategyHashids, MinLength: MaxLength + 1}, seq: &counter{}, wantErr: true},
+		{name: "words too long", cfg: configs.ShortCodeConfig{Strategy: StrategyWords, Words: 2, Digits: 3}, wantErr: true},
+		{name: "no words", cfg: configs.ShortCodeConfig{Strategy: StrategyWords, Digits: 4}, wantErr: true},
+		{name: "negative digits", cfg: configs.ShortCodeConfig{Strategy: StrategyWords, Words: 1, Digits: -1}, wantErr: true},
+		{name: "alphabet with symbols", cfg: configs.ShortCodeConfig{Length: 7, Alphabet: "0123456789abcdef-"}, wantErr: true},
+		{name: "alphabet with repeats", cfg: configs.ShortCodeConfig{Length: 7, Alphabet: "0123456789abcdefa"}, wantErr: true},
+		{name: "alphabet too small", cfg: configs.ShortCodeConfig{Length: 7, Alphabet: "0123456789abcde"}, wantErr: true},
+		{name: "non ascii alphabet", cfg: configs.ShortCodeConfig{Length: 7, Alphabet: "0123456789abcdeé"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := New(&tt.cfg, tt.seq)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("New succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+
+			re := regexp.MustCompile(tt.pattern)
+			for range 50 {
+				code, err := g.Generate(context.Background())
+				if err != nil {
+					t.Fatalf("Generate: %v", err)
+				}
+				if !re.MatchString(code) {
+					t.Fatalf("Generate = %q, want it to match %s", code, tt.pattern)
+				}
+			}
+		})
+	}
+}
+
+func TestHashidsCodesAreUnique(t *testing.T) {
+	tests := []struct {
+		alphabet  string
+		minLength int
+	}{
+		{alphabet: DefaultAlphabet, minLength: 3},
+		{alphabet: DefaultAlphabet, minLength: 7},
+		// A small alphabet reaches long codes quickly
+		{alphabet: "0123456789abcdef", minLength: MinLength},
+	}
+	for _, tt := range tests {
+		g, err := NewHashids(&counter{}, tt.alphabet, "salt", tt.minLength)
+		if err != nil {
+			t.Fatalf("NewHashids: %v", err)
+		}
+
+		seen := make(map[string]bool)
+		for range 20000 {
+			code, err := g.Generate(context.Background())
+			if err != nil {
+				t.Fatalf("Generate: %v", err)
+			}
+			if len(code) < tt.minLength || len(code) > MaxLength {
+				t.Fatalf("Generate = %q, want %d to %d characters", code, tt.minLength, MaxLength)
+			}
+			if strings.Trim(code, tt.alphabet) != "" {
+				t.Fatalf("Generate = %q, want only characters of %q", code, tt.alphabet)
+			}
+			if seen[code] {
+				t.Fatalf("Generate returned %q twice", code)
+			}
+			seen[code] = true
+		}
+	}
+}
+
+func TestHashidsDependsOnSalt(t *testing.T) {
+	generate := func(salt string) []string {
+		g, err := NewHashids(&counter{}, DefaultAlphabet, salt, 6)
+		if err != nil {
+			t.Fatalf("NewHashids: %v", err)
+		}
+		var codes []string
+		for range 5 {
+			code, err := g.Generate(context.Background())
+			if err != nil {
+				t.Fatalf("Generate: %v", err)
+			}
+			codes = append(codes, code)
+		}
+		return codes
+	}
+
+	a, b, other := generate("one"), generate("one"), generate("two")
+	for i := range a {
+		if a[i] != b[i] {
+			t.Errorf("code %d differs with the same salt: %q and %q", i, a[i], b[i])
+		}
+	}
+	if strings.Join(a, ",") == strings.Join(other, ",") {
+		t.Errorf("different salts gave the same codes %v", a)
+	}
+}
+
+func TestHashidsErrors(t *testing.T) {
+	seqErr := errors.New("sequence unavailable")
+	g, _ := NewHashids(&counter{err: seqErr}, DefaultAlphabet, "", 6)
+	if _, err := g.Generate(context.Background()); !errors.Is(err, seqErr) {
+		t.Errorf("Generate: err = %v, want the sequence error", err)
+	}
+
+	// Numbers needing more than MaxLength characters are refused
+	g, _ = NewHashids(&counter{n: 1 << 62}, DefaultAlphabet, "", 6)
+	if code, err := g.Generate(context.Background()); err == nil {
+		t.Errorf("Generate = %q, want an error", code)
+	}
+}
+
+func TestConsistentShuffle(t *testing.T) {
+	alphabet := []byte(DefaultAlphabet)
+
+	if got := consistentShuffle(alphabet, nil); string(got) != DefaultAlphabet {
+		t.Errorf("shuffle without salt = %s, want the alphabet unchanged", got)
+	}
+
+	got := consistentShuffle(alphabet, []byte("salt"))
+	if string(got) == DefaultAlphabet {
+		t.Error("shuffle with salt left the alphabet unchanged")
+	}
+	if string(alphabet) != DefaultAlphabet {
+		t.Error("shuffle modified its input")
+	}
+	for _, c := range alphabet {
+		if strings.Count(string(got), string(c)) != 1 {
+			t.Fatalf("shuffle = %s, not a permutation of the alphabet", got)
+		}
+	}
+}
+
+func TestWordList(t *testing.T) {
+	for _, word := range strings.Fields(string(wordList)) {
+		if len(word) > maxWordLength {
+			t.Errorf("%q is longer than maxWordLength", word)
+		}
+		if strings.Trim(word, "abcdefghijklmnopqrstuvwxyz") != "" {
+			t.Errorf("%q is not a lowercase ASCII word", word)
+		}
+	}
+}
+
+type fakeCounters map[string]uint64
+
+func (c fakeCounters) Next(_ context.Context, name string) (uint64, error) {
+	c[name]++
+	return c[name], nil
+}
+
+func TestNamedSequence(t *testing.T) {
+	counters := fakeCounters{"other": 10}
+	seq := NamedSequence(counters, "short_code")
+	for want := uint64(1); want <= 3; want++ {
+		if got, _ := seq.Next(context.Background()); got != want {
+			t.Errorf("Next = %d, want %d", got, want)
+		}
+	}
+	if counters["other"] != 10 {
+		t.Errorf("other counter = %d, want it untouched", counters["other"])
+	}
+}
@@ -0,0 +1,61 @@
+package shortcode
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	_ "embed"
+	"fmt"
+	"strings"
+)
+
+//go:embed words.txt
+var wordList []byte
+
+// maxWordLength is the longest entry in words.txt
+const maxWordLength = 4
+
+type wordsGenerator struct {
+	words  []string
+	count  int
+	digits int
+}
+
+// NewWords returns a generator of readable codes such as "CalmOwl42": count
+// capitalised words followed by digits random digits
+func NewWords(count, digits int) (Generator, error) {
+	if count < 1 || digits < 0 {
+		return nil, fmt.Errorf("shortcode: words needs at least one word and no negative digits")
+	}
+	if count*maxWordLength+digits > MaxLength {
+		return nil, fmt.Errorf("shortcode: %d words and %d digits can exceed %d characters", count, digits, MaxLength)
+	}
+
+	var words []string
+	scanner := bufio.NewScanner(bytes.NewReader(wordList))
+	for scanner.Scan() {
+		if word := strings.TrimSpace(scanner.Text()); word != "" {
+			words = append(words, strings.ToUpper(word[:1])+word[1:])
+		}
+	}
+	return &wordsGenerator{words: words, count: count, digits: digits}, nil
+}
+
+func (g *wordsGenerator) Generate(context.Context) (string, error) {
+	var b strings.Builder
+	for i := 0; i < g.count; i++ {
+		n, err := randomIndex(len(g.words))
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(g.words[n])
+	}
+	for i := 0; i < g.digits; i++ {
+		n, err := randomIndex(10)
+		if err != nil {
+			return "", err
+		}
+		b.WriteByte(byte('0' + n))
+	}
+	return b.String(), nil
+}
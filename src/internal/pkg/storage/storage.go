@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"mime/multipart"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
@@ -20,8 +21,12 @@ import (
 type Storage interface {
 	UploadFile(ctx context.Context, file multipart.File, header *multipart.FileHeader, folder string, publicID string) (string, error)
 	DeleteFile(ctx context.Context, publicID string) error
+	OpenFile(ctx context.Context, fileURL string) (io.ReadCloser, error)
 }
 
+// fetchTimeout bounds downloads of remotely stored files
+const fetchTimeout = 10 * time.Second
+
 // CloudinaryStorage implements Storage for Cloudinary
 type CloudinaryStorage struct {
 	cld    *cloudinary.Cloudinary
@@ -112,6 +117,44 @@ func (cs *CloudinaryStorage) DeleteFile(ctx context.Context, publicID string) er
 	return nil
 }
 
+// OpenFile reads back a file by the URL UploadFile returned. Files that fell
+// back to local storage are read from disk.
+func (cs *CloudinaryStorage) OpenFile(ctx context.Context, fileURL string) (io.ReadCloser, error) {
+	if cs.local.owns(fileURL) {
+		return cs.local.OpenFile(ctx, fileURL)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileURL, nil)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("invalid file url: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to fetch file from Cloudinary: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		cancel()
+		return nil, fmt.Errorf("failed to fetch file from Cloudinary: status %d", resp.StatusCode)
+	}
+	return &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}, nil
+}
+
+// cancelOnClose releases a download's context once its body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
+
 // UploadFile saves a file to the local filesystem
 func (ls *LocalStorage) UploadFile(ctx context.Context, file multipart.File, header *multipart.FileHeader, folder string, publicID string) (string, error) {
 	// Reset file reader after potential previous reads
@@ -170,4 +213,32 @@ func (ls *LocalStorage) DeleteFile(ctx context.Context, publicID string) error {
 		return fmt.Errorf("failed to delete local file: %w", err)
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+// OpenFile opens a file saved by UploadFile from the URL it returned
+func (ls *LocalStorage) OpenFile(ctx context.Context, fileURL string) (io.ReadCloser, error) {
+	if !ls.owns(fileURL) {
+		return nil, fmt.Errorf("file %q is not in local storage", fileURL)
+	}
+
+	rel := strings.TrimPrefix(fileURL, ls.urlPrefix())
+	filePath := filepath.Join(ls.uploadDir, filepath.FromSlash(rel))
+	if !strings.HasPrefix(filePath, filepath.Clean(ls.uploadDir)+string(filepath.Separator)) {
+		return nil, fmt.Errorf("file %q is outside the upload directory", fileURL)
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open local file: %w", err)
+	}
+	return file, nil
+}
+
+// owns reports whether fileURL points into local storage
+func (ls *LocalStorage) owns(fileURL string) bool {
+	return strings.HasPrefix(fileURL, ls.urlPrefix())
+}
+
+func (ls *LocalStorage) urlPrefix() string {
+	return strings.TrimRight(ls.baseURL, "/") + "/uploads/"
+}
@@ -0,0 +1,197 @@
+// Package useragent classifies raw User-Agent headers into device class,
+// operating system and browser families using an embedded rule set.
+package useragent
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Device classes reported by the parser
+const (
+	DeviceDesktop = "desktop"
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+	DeviceBot     = "bot"
+)
+
+// Unknown is reported when no rule matches
+const Unknown = "Other"
+
+// Bot categories used by the embedded rules
+const (
+	CategorySearch  = "search"
+	CategorySocial  = "social"
+	CategoryAI      = "ai"
+	CategorySEO     = "seo"
+	CategoryMonitor = "monitor"
+	CategoryTool    = "tool"
+	CategoryOther   = "other"
+)
+
+// maxUserAgentLength caps the input so pathological headers cannot make the
+// regular expressions expensive
+const maxUserAgentLength = 512
+
+//go:embed rules.json
+var embeddedRules []byte
+
+// Result is the classification of a single User-Agent string
+type Result struct {
+	Device      string `json:"device"`
+	OS          string `json:"os"`
+	Browser     string `json:"browser"`
+	IsBot       bool   `json:"is_bot"`
+	BotName     string `json:"bot_name,omitempty"`
+	BotCategory string `json:"bot_category,omitempty"`
+}
+
+// Parser matches User-Agent strings against ordered rule lists. The first
+// matching rule in each list wins. A Parser is safe for concurrent use.
+type Parser struct {
+	bots     []rule
+	devices  []rule
+	os       []rule
+	browsers []rule
+}
+
+type ruleSpec struct {
+	Name     string `json:"name"`
+	Pattern  string `json:"pattern"`
+	Exclude  string `json:"exclude,omitempty"`
+	Category string `json:"category,omitempty"`
+}
+
+type ruleSet struct {
+	Bots     []ruleSpec `json:"bots"`
+	Devices  []ruleSpec `json:"devices"`
+	OS       []ruleSpec `json:"os"`
+	Browsers []ruleSpec `json:"browsers"`
+}
+
+type rule struct {
+	name     string
+	category string
+	pattern  *regexp.Regexp
+	exclude  *regexp.Regexp
+}
+
+func (r rule) matches(ua string) bool {
+	return r.pattern.MatchString(ua) && (r.exclude == nil || !r.exclude.MatchString(ua))
+}
+
+var (
+	defaultParser *Parser
+	defaultOnce   sync.Once
+)
+
+// Default returns a shared parser built from the embedded rule set
+func Default() *Parser {
+	defaultOnce.Do(func() {
+		p, err := New(embeddedRules)
+		if err != nil {
+			// The embedded rules are part of the binary, so this is a programming error
+			panic(fmt.Sprintf("useragent: invalid embedded rules: %v", err))
+		}
+		defaultParser = p
+	})
+	return defaultParser
+}
+
+// New builds a parser from a JSON rule set with the same layout as rules.json
+func New(data []byte) (*Parser, error) {
+	var set ruleSet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to decode rules: %w", err)
+	}
+
+	p := &Parser{}
+	var err error
+	if p.bots, err = compileRules("bots", set.Bots); err != nil {
+		return nil, err
+	}
+	if p.devices, err = compileRules("devices", set.Devices); err != nil {
+		return nil, err
+	}
+	if p.os, err = compileRules("os", set.OS); err != nil {
+		return nil, err
+	}
+	if p.browsers, err = compileRules("browsers", set.Browsers); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func compileRules(section string, specs []ruleSpec) ([]rule, error) {
+	rules := make([]rule, 0, len(specs))
+	for _, spec := range specs {
+		pattern, err := regexp.Compile("(?i)" + spec.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s rule %q: %w", section, spec.Name, err)
+		}
+
+		r := rule{name: spec.Name, category: spec.Category, pattern: pattern}
+		if spec.Exclude != "" {
+			if r.exclude, err = regexp.Compile("(?i)" + spec.Exclude); err != nil {
+				return nil, fmt.Errorf("invalid %s rule %q exclude: %w", section, spec.Name, err)
+			}
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// Parse classifies a User-Agent string. An empty header is treated as a bot
+// since real browsers always send one.
+func (p *Parser) Parse(ua string) Result {
+	ua = strings.TrimSpace(ua)
+	if ua == "" {
+		return Result{
+			Device:      DeviceBot,
+			OS:          Unknown,
+			Browser:     Unknown,
+			IsBot:       true,
+			BotName:     Unknown,
+			BotCategory: CategoryOther,
+		}
+	}
+	if len(ua) > maxUserAgentLength {
+		ua = ua[:maxUserAgentLength]
+	}
+
+	result := Result{
+		OS:      firstMatch(p.os, ua, Unknown),
+		Browser: firstMatch(p.browsers, ua, Unknown),
+	}
+
+	for _, r := range p.bots {
+		if r.matches(ua) {
+			result.IsBot = true
+			result.BotName = r.name
+			result.BotCategory = r.category
+			result.Device = DeviceBot
+			return result
+		}
+	}
+
+	result.Device = firstMatch(p.devices, ua, DeviceDesktop)
+	return result
+}
+
+// IsBot reports whether the User-Agent belongs to a known crawler or tool
+func (p *Parser) IsBot(ua string) bool {
+	return p.Parse(ua).IsBot
+}
+
+func firstMatch(rules []rule, ua, fallback string) string {
+	for _, r := range rules {
+		if r.matches(ua) {
+			return r.name
+		}
+	}
+	return fallback
+}
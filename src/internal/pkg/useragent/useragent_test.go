@@ -0,0 +1,164 @@
+package useragent
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		ua   string
+		want Result
+	}{
+		{
+			name: "chrome on windows",
+			ua:   "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+			want: Result{Device: DeviceDesktop, OS: "Windows", Browser: "Chrome"},
+		},
+		{
+			name: "edge on windows",
+			ua:   "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36 Edg/124.0.2478.51",
+			want: Result{Device: DeviceDesktop, OS: "Windows", Browser: "Edge"},
+		},
+		{
+			name: "safari on macos",
+			ua:   "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Safari/605.1.15",
+			want: Result{Device: DeviceDesktop, OS: "macOS", Browser: "Safari"},
+		},
+		{
+			name: "firefox on linux",
+			ua:   "Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:125.0) Gecko/20100101 Firefox/125.0",
+			want: Result{Device: DeviceDesktop, OS: "Linux", Browser: "Firefox"},
+		},
+		{
+			name: "safari on iphone",
+			ua:   "Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Mobile/15E148 Safari/604.1",
+			want: Result{Device: DeviceMobile, OS: "iOS", Browser: "Safari"},
+		},
+		{
+			name: "safari on ipad",
+			ua:   "Mozilla/5.0 (iPad; CPU OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Mobile/15E148 Safari/604.1",
+			want: Result{Device: DeviceTablet, OS: "iOS", Browser: "Safari"},
+		},
+		{
+			name: "samsung internet on android phone",
+			ua:   "Mozilla/5.0 (Linux; Android 14; SM-S918B) AppleWebKit/537.36 (KHTML, like Gecko) SamsungBrowser/24.0 Chrome/117.0.0.0 Mobile Safari/537.36",
+			want: Result{Device: DeviceMobile, OS: "Android", Browser: "Samsung Internet"},
+		},
+		{
+			name: "chrome on android tablet",
+			ua:   "Mozilla/5.0 (Linux; Android 13; Pixel Tablet) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+			want: Result{Device: DeviceTablet, OS: "Android", Browser: "Chrome"},
+		},
+		{
+			name: "snapchat in-app browser",
+			ua:   "Mozilla/5.0 (Linux; Android 12; Pixel 6) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/118.0.5993.111 Mobile Safari/537.36 Snapchat/12.55.0.39 (like Safari/604.1)",
+			want: Result{Device: DeviceMobile, OS: "Android", Browser: "Snapchat"},
+		},
+		{
+			name: "snapchat on iphone",
+			ua:   "Mozilla/5.0 (iPhone; CPU iPhone OS 17_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Mobile/15E148 Snapchat/12.60.0.31 (like Safari/8617.2.4.10.8, panda)",
+			want: Result{Device: DeviceMobile, OS: "iOS", Browser: "Snapchat"},
+		},
+		{
+			name: "teams desktop",
+			ua:   "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Teams/1.6.00.4472 Chrome/102.0.5005.197 Electron/19.1.8 Safari/537.36",
+			want: Result{Device: DeviceDesktop, OS: "Windows", Browser: "Chrome"},
+		},
+		{
+			name: "pinterest in-app browser",
+			ua:   "Mozilla/5.0 (iPhone; CPU iPhone OS 16_6 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Mobile/15E148 [Pinterest/iOS]",
+			want: Result{Device: DeviceMobile, OS: "iOS", Browser: "Safari"},
+		},
+		{
+			name: "viber in-app browser",
+			ua:   "Mozilla/5.0 (Linux; Android 13; SM-A536B) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/119.0.6045.163 Mobile Safari/537.36 Viber/21.2.0.0",
+			want: Result{Device: DeviceMobile, OS: "Android", Browser: "Chrome"},
+		},
+		{
+			name: "facebook in-app browser",
+			ua:   "Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Mobile/15E148 [FBAN/FBIOS;FBAV/460.0.0.38.104;FBBV/585307440]",
+			want: Result{Device: DeviceMobile, OS: "iOS", Browser: "Facebook"},
+		},
+		{
+			name: "googlebot",
+			ua:   "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+			want: Result{Device: DeviceBot, OS: Unknown, Browser: Unknown, IsBot: true, BotName: "Googlebot", BotCategory: CategorySearch},
+		},
+		{
+			name: "facebook crawler",
+			ua:   "facebookexternalhit/1.1 (+http://www.facebook.com/externalhit_uatext.php)",
+			want: Result{Device: DeviceBot, OS: Unknown, Browser: Unknown, IsBot: true, BotName: "Facebook", BotCategory: CategorySocial},
+		},
+		{
+			name: "slack unfurler",
+			ua:   "Slackbot-LinkExpanding 1.0 (+https://api.slack.com/robots)",
+			want: Result{Device: DeviceBot, OS: Unknown, Browser: Unknown, IsBot: true, BotName: "Slackbot", BotCategory: CategorySocial},
+		},
+		{
+			name: "whatsapp unfurler",
+			ua:   "WhatsApp/2.23.20.0 A",
+			want: Result{Device: DeviceBot, OS: Unknown, Browser: Unknown, IsBot: true, BotName: "WhatsApp", BotCategory: CategorySocial},
+		},
+		{
+			name: "pinterest crawler",
+			ua:   "Mozilla/5.0 (compatible; Pinterestbot/1.0; +http://www.pinterest.com/bot.html)",
+			want: Result{Device: DeviceBot, OS: Unknown, Browser: Unknown, IsBot: true, BotName: "Pinterest", BotCategory: CategorySocial},
+		},
+		{
+			name: "snap url preview",
+			ua:   "Mozilla/5.0 (compatible; Snap URL Preview Service; bot; snapchat; https://developers.snap.com/robots)",
+			want: Result{Device: DeviceBot, OS: Unknown, Browser: "Snapchat", IsBot: true, BotName: "Snapchat", BotCategory: CategorySocial},
+		},
+		{
+			name: "teams link preview",
+			ua:   "Mozilla/5.0 (Windows NT 6.1; WOW64) SkypeUriPreview Preview/0.5 MicrosoftPreview/2.0",
+			want: Result{Device: DeviceBot, OS: "Windows", Browser: Unknown, IsBot: true, BotName: "Skype", BotCategory: CategorySocial},
+		},
+		{
+			name: "gptbot",
+			ua:   "Mozilla/5.0 AppleWebKit/537.36 (KHTML, like Gecko; compatible; GPTBot/1.2; +https://openai.com/gptbot)",
+			want: Result{Device: DeviceBot, OS: Unknown, Browser: "Safari", IsBot: true, BotName: "GPTBot", BotCategory: CategoryAI},
+		},
+		{
+			name: "curl",
+			ua:   "curl/8.5.0",
+			want: Result{Device: DeviceBot, OS: Unknown, Browser: Unknown, IsBot: true, BotName: "curl", BotCategory: CategoryTool},
+		},
+		{
+			name: "generic crawler",
+			ua:   "ExampleCrawler/0.1 (+https://example.com/crawler)",
+			want: Result{Device: DeviceBot, OS: Unknown, Browser: Unknown, IsBot: true, BotName: "Generic Bot", BotCategory: CategoryOther},
+		},
+		{
+			name: "empty",
+			ua:   "  ",
+			want: Result{Device: DeviceBot, OS: Unknown, Browser: Unknown, IsBot: true, BotName: Unknown, BotCategory: CategoryOther},
+		},
+	}
+
+	p := Default()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.Parse(tt.ua); got != tt.want {
+				t.Errorf("Parse(%q)\n got %+v\nwant %+v", tt.ua, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRejectsInvalidRules(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules string
+	}{
+		{name: "malformed json", rules: `{"bots": [`},
+		{name: "invalid pattern", rules: `{"bots": [{"name": "x", "pattern": "("}]}`},
+		{name: "invalid exclude", rules: `{"devices": [{"name": "x", "pattern": "x", "exclude": "["}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New([]byte(tt.rules)); err == nil {
+				t.Errorf("New(%s) succeeded, want an error", tt.rules)
+			}
+		})
+	}
+}
@@ -0,0 +1,340 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/imraushankr/brevity/server/src/internal/models"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/database"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// bucketTimeLayout is the format bucket expressions and rollup bucket_start
+// values use
+const bucketTimeLayout = "2006-01-02 15:04:05"
+
+// bucketFormats describe how strftime groups a timestamp into UTC buckets.
+// Weeks start on Monday.
+var bucketFormats = map[models.AnalyticsInterval]struct {
+	format    string
+	modifiers string
+}{
+	models.IntervalHour: {format: "%Y-%m-%d %H:00:00"},
+	models.IntervalDay:  {format: "%Y-%m-%d 00:00:00"},
+	models.IntervalWeek: {format: "%Y-%m-%d 00:00:00", modifiers: ", 'weekday 0', '-6 days'"},
+}
+
+// dimensionColumns whitelists the url_clicks columns that can be grouped on
+var dimensionColumns = map[models.AnalyticsDimension]string{
+	models.DimensionReferrer: "referrer",
+	models.DimensionCountry:  "country",
+	models.DimensionDevice:   "device",
+	models.DimensionOS:       "os",
+	models.DimensionBrowser:  "browser",
+	models.DimensionVariant:  "variant_id",
+}
+
+// bucketExpr returns the SQL expression grouping column into interval buckets
+func bucketExpr(interval models.AnalyticsInterval, column string) string {
+	f := bucketFormats[interval]
+	return "strftime('" + f.format + "', " + column + f.modifiers + ")"
+}
+
+type analyticsRepository struct {
+	db  *database.DB
+	log logger.Logger
+}
+
+// NewAnalyticsRepository reads click aggregates. Ranges before the rollup
+// watermark are answered from the rollup tables and the rest from raw
+// clicks. Rollups only keep distinct visitors per bucket, so unique visitors
+// in compacted ranges are summed per bucket and can overcount; Watermark
+// tells callers where that starts.
+func NewAnalyticsRepository(db *database.DB) AnalyticsRepository {
+	return &analyticsRepository{
+		db:  db,
+		log: logger.Get(),
+	}
+}
+
+// clickRange splits a query range at the rollup watermark. Raw clicks answer
+// [rawFrom, to) and, when rollupTable is set, rollups answer [from, rollupTo).
+// Rollups only have hour resolution, so a partial bucket at from is skipped.
+type clickRange struct {
+	scope       models.AnalyticsScope
+	from        time.Time
+	to          time.Time
+	rawFrom     time.Time
+	rollupTable string
+	rollupTo    time.Time
+}
+
+func (r *analyticsRepository) splitRange(ctx context.Context, scope models.AnalyticsScope, from, to time.Time, interval models.AnalyticsInterval) (*clickRange, error) {
+	watermark, err := loadWatermark(r.db.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load rollup watermark: %w", err)
+	}
+
+	cr := &clickRange{
+		scope:   scope,
+		from:    from.UTC(),
+		to:      to.UTC(),
+		rawFrom: from.UTC(),
+	}
+	if !watermark.After(cr.from) {
+		return cr, nil
+	}
+
+	cr.rawFrom = watermark
+	cr.rollupTo = watermark
+	if cr.to.Before(watermark) {
+		cr.rawFrom = cr.to
+		cr.rollupTo = cr.to
+	}
+
+	cr.rollupTable = rollupsHourlyTable
+	if interval != models.IntervalHour && isMidnight(cr.from) && isMidnight(cr.rollupTo) {
+		cr.rollupTable = rollupsDailyTable
+	}
+	return cr, nil
+}
+
+func isMidnight(t time.Time) bool {
+	return t.Equal(models.IntervalDay.Truncate(t))
+}
+
+// inScope restricts a query to rows of the links in scope. Tag and campaign
+// membership is read when the query runs.
+func (cr *clickRange) inScope(db *gorm.DB) *gorm.DB {
+	switch {
+	case cr.scope.TagID != "":
+		links := db.Session(&gorm.Session{NewDB: true}).
+			Table("url_tags").Select("url_id").Where("tag_id = ?", cr.scope.TagID)
+		return db.Where("url_id IN (?)", links)
+	case cr.scope.CampaignID != "":
+		links := db.Session(&gorm.Session{NewDB: true}).
+			Table("urls").Select("id").Where("campaign_id = ?", cr.scope.CampaignID)
+		return db.Where("url_id IN (?)", links)
+	default:
+		return db.Where("url_id = ?", cr.scope.URLID)
+	}
+}
+
+// raw scopes a query to the range's non-bot raw clicks
+func (cr *clickRange) raw(db *gorm.DB) *gorm.DB {
+	return db.Table("url_clicks").
+		Scopes(cr.inScope).
+		Where("is_bot = ?", false).
+		Where("created_at >= ? AND created_at < ?", cr.rawFrom, cr.to)
+}
+
+// rollups scopes a query to the range's rollup rows for one dimension
+func (cr *clickRange) rollups(db *gorm.DB, dimension string) *gorm.DB {
+	return db.Table(cr.rollupTable).
+		Scopes(cr.inScope).
+		Where("dimension = ?", dimension).
+		Where("bucket_start >= ? AND bucket_start < ?",
+			cr.from.Format(bucketTimeLayout), cr.rollupTo.Format(bucketTimeLayout))
+}
+
+// combine selects from the union of the raw and rollup subqueries
+func (cr *clickRange) combine(db *gorm.DB, raw, rollups *gorm.DB) *gorm.DB {
+	if cr.rollupTable == "" {
+		return db.Table("(?) AS parts", raw)
+	}
+	return db.Table("(? UNION ALL ?) AS parts", raw, rollups)
+}
+
+// Watermark returns the time before which clicks are answered from rollups
+func (r *analyticsRepository) Watermark(ctx context.Context) (time.Time, error) {
+	return loadWatermark(r.db.WithContext(ctx))
+}
+
+func (r *analyticsRepository) ClickTotals(ctx context.Context, scope models.AnalyticsScope, from, to time.Time) (int64, int64, error) {
+	r.log.Debug("Counting clicks", logger.String("scope", scope.String()))
+
+	cr, err := r.splitRange(ctx, scope, from, to, models.IntervalDay)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	raw := cr.raw(r.db.DB).
+		Select("COUNT(*) AS clicks, COUNT(DISTINCT ip_address) AS unique_visitors")
+	rollups := cr.rollups(r.db.DB, rollupTotalDimension).
+		Select("COALESCE(SUM(clicks), 0) AS clicks, COALESCE(SUM(unique_visitors), 0) AS unique_visitors")
+
+	var totals struct {
+		Clicks         int64
+		UniqueVisitors int64
+	}
+	err = cr.combine(r.db.WithContext(ctx), raw, rollups).
+		Select("COALESCE(SUM(clicks), 0) AS clicks, COALESCE(SUM(unique_visitors), 0) AS unique_visitors").
+		Scan(&totals).Error
+	if err != nil {
+		r.log.Error("Failed to count clicks",
+			logger.NamedError("error", err),
+			logger.String("scope", scope.String()))
+		return 0, 0, err
+	}
+	return totals.Clicks, totals.UniqueVisitors, nil
+}
+
+func (r *analyticsRepository) ClickTimeSeries(ctx context.Context, scope models.AnalyticsScope, from, to time.Time, interval models.AnalyticsInterval) ([]models.ClickBucket, error) {
+	r.log.Debug("Aggregating click time series",
+		logger.String("scope", scope.String()),
+		logger.String("interval", string(interval)))
+
+	if _, ok := bucketFormats[interval]; !ok {
+		return nil, fmt.Errorf("unsupported analytics interval %q", interval)
+	}
+
+	cr, err := r.splitRange(ctx, scope, from, to, interval)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := cr.raw(r.db.DB).
+		Select(bucketExpr(interval, "created_at") + " AS bucket, COUNT(*) AS clicks, COUNT(DISTINCT ip_address) AS unique_visitors").
+		Group("bucket")
+	rollups := cr.rollups(r.db.DB, rollupTotalDimension).
+		Select(bucketExpr(interval, "bucket_start") + " AS bucket, SUM(clicks) AS clicks, SUM(unique_visitors) AS unique_visitors").
+		Group("bucket")
+
+	var rows []struct {
+		Bucket         string
+		Clicks         int64
+		UniqueVisitors int64
+	}
+	err = cr.combine(r.db.WithContext(ctx), raw, rollups).
+		Select("bucket, SUM(clicks) AS clicks, SUM(unique_visitors) AS unique_visitors").
+		Group("bucket").
+		Order("bucket").
+		Scan(&rows).Error
+	if err != nil {
+		r.log.Error("Failed to aggregate click time series",
+			logger.NamedError("error", err),
+			logger.String("scope", scope.String()))
+		return nil, err
+	}
+
+	buckets := make([]models.ClickBucket, 0, len(rows))
+	for _, row := range rows {
+		start, err := time.ParseInLocation(bucketTimeLayout, row.Bucket, time.UTC)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bucket %q: %w", row.Bucket, err)
+		}
+		buckets = append(buckets, models.ClickBucket{
+			Start:          start,
+			Clicks:         row.Clicks,
+			UniqueVisitors: row.UniqueVisitors,
+		})
+	}
+	return buckets, nil
+}
+
+func (r *analyticsRepository) TopValues(ctx context.Context, scope models.AnalyticsScope, dimension models.AnalyticsDimension, from, to time.Time, limit int) ([]models.BreakdownItem, error) {
+	r.log.Debug("Aggregating click breakdown",
+		logger.String("scope", scope.String()),
+		logger.String("dimension", string(dimension)))
+
+	column, ok := dimensionColumns[dimension]
+	if !ok {
+		return nil, fmt.Errorf("unsupported analytics dimension %q", dimension)
+	}
+
+	cr, err := r.splitRange(ctx, scope, from, to, models.IntervalDay)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := cr.raw(r.db.DB).
+		Select("COALESCE(" + column + ", '') AS value, COUNT(*) AS clicks").
+		Group("value")
+	rollups := cr.rollups(r.db.DB, string(dimension)).
+		Select("value, SUM(clicks) AS clicks").
+		Group("value")
+
+	items := make([]models.BreakdownItem, 0, limit)
+	err = cr.combine(r.db.WithContext(ctx), raw, rollups).
+		Select("value, SUM(clicks) AS clicks").
+		Group("value").
+		Order("clicks DESC, value").
+		Limit(limit).
+		Scan(&items).Error
+	if err != nil {
+		r.log.Error("Failed to aggregate click breakdown",
+			logger.NamedError("error", err),
+			logger.String("scope", scope.String()),
+			logger.String("dimension", string(dimension)))
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *analyticsRepository) VariantTotals(ctx context.Context, scope models.AnalyticsScope, from, to time.Time) ([]models.VariantStats, error) {
+	r.log.Debug("Counting clicks per variant", logger.String("scope", scope.String()))
+
+	cr, err := r.splitRange(ctx, scope, from, to, models.IntervalDay)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := cr.raw(r.db.DB).
+		Select("variant_id AS id, COUNT(*) AS clicks, COUNT(DISTINCT ip_address) AS unique_visitors").
+		Where("variant_id IS NOT NULL AND variant_id <> ''").
+		Group("variant_id")
+	rollups := cr.rollups(r.db.DB, string(models.DimensionVariant)).
+		Select("value AS id, SUM(clicks) AS clicks, SUM(unique_visitors) AS unique_visitors").
+		Where("value <> ''").
+		Group("value")
+
+	var totals []models.VariantStats
+	err = cr.combine(r.db.WithContext(ctx), raw, rollups).
+		Select("id, SUM(clicks) AS clicks, SUM(unique_visitors) AS unique_visitors").
+		Group("id").
+		Order("id").
+		Scan(&totals).Error
+	if err != nil {
+		r.log.Error("Failed to count clicks per variant",
+			logger.NamedError("error", err),
+			logger.String("scope", scope.String()))
+		return nil, err
+	}
+	return totals, nil
+}
+
+// TopURLs returns the links in scope with the most clicks, with their
+// short codes
+func (r *analyticsRepository) TopURLs(ctx context.Context, scope models.AnalyticsScope, from, to time.Time, limit int) ([]models.LinkClicks, error) {
+	r.log.Debug("Counting clicks per link", logger.String("scope", scope.String()))
+
+	cr, err := r.splitRange(ctx, scope, from, to, models.IntervalDay)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := cr.raw(r.db.DB).
+		Select("url_id, COUNT(*) AS clicks, COUNT(DISTINCT ip_address) AS unique_visitors").
+		Group("url_id")
+	rollups := cr.rollups(r.db.DB, rollupTotalDimension).
+		Select("url_id, SUM(clicks) AS clicks, SUM(unique_visitors) AS unique_visitors").
+		Group("url_id")
+
+	links := make([]models.LinkClicks, 0, limit)
+	err = cr.combine(r.db.WithContext(ctx), raw, rollups).
+		Select("parts.url_id, urls.short_code, SUM(parts.clicks) AS clicks, SUM(parts.unique_visitors) AS unique_visitors").
+		Joins("LEFT JOIN urls ON urls.id = parts.url_id").
+		Group("parts.url_id, urls.short_code").
+		Order("clicks DESC, parts.url_id").
+		Limit(limit).
+		Scan(&links).Error
+	if err != nil {
+		r.log.Error("Failed to count clicks per link",
+			logger.NamedError("error", err),
+			logger.String("scope", scope.String()))
+		return nil, err
+	}
+	return links, nil
+}
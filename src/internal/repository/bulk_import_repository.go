@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/imraushankr/brevity/server/src/internal/models"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/logger"
+	"gorm.io/gorm"
+)
+
+type bulkImportRepository struct {
+	db  *gorm.DB
+	log logger.Logger
+}
+
+func NewBulkImportRepository(db *gorm.DB) BulkImportRepository {
+	return &bulkImportRepository{
+		db:  db,
+		log: logger.Get(),
+	}
+}
+
+func (r *bulkImportRepository) Create(ctx context.Context, job *models.BulkImportJob) error {
+	r.log.Debug("Creating bulk import job",
+		logger.String("userID", job.UserID),
+		logger.Int("total", job.Total))
+
+	err := r.db.WithContext(ctx).Create(job).Error
+	if err != nil {
+		r.log.Error("Failed to create bulk import job", logger.NamedError("error", err))
+	}
+	return err
+}
+
+func (r *bulkImportRepository) FindByID(ctx context.Context, id string) (*models.BulkImportJob, error) {
+	r.log.Debug("Finding bulk import job", logger.String("jobID", id))
+
+	var job models.BulkImportJob
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&job).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrBulkImportNotFound
+	}
+	if err != nil {
+		r.log.Error("Failed to find bulk import job", logger.NamedError("error", err))
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ClaimNext marks the oldest pending job as running and returns it. It
+// returns nil when no job is pending.
+func (r *bulkImportRepository) ClaimNext(ctx context.Context) (*models.BulkImportJob, error) {
+	for {
+		var job models.BulkImportJob
+		err := r.db.WithContext(ctx).
+			Where("status = ?", models.BulkImportPending).
+			Order("created_at").
+			First(&job).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			r.log.Error("Failed to find pending bulk import job", logger.NamedError("error", err))
+			return nil, err
+		}
+
+		// Only one caller wins the pending -> running transition
+		result := r.db.WithContext(ctx).
+			Model(&models.BulkImportJob{}).
+			Where("id = ? AND status = ?", job.ID, models.BulkImportPending).
+			Update("status", models.BulkImportRunning)
+		if result.Error != nil {
+			r.log.Error("Failed to claim bulk import job", logger.NamedError("error", result.Error))
+			return nil, result.Error
+		}
+		if result.RowsAffected == 1 {
+			job.Status = models.BulkImportRunning
+			return &job, nil
+		}
+	}
+}
+
+// Finish stores the outcome of a job and drops its input rows, which are
+// not needed once the job has run
+func (r *bulkImportRepository) Finish(ctx context.Context, job *models.BulkImportJob) error {
+	r.log.Debug("Finishing bulk import job",
+		logger.String("jobID", job.ID),
+		logger.String("status", string(job.Status)))
+
+	err := r.db.WithContext(ctx).
+		Model(&models.BulkImportJob{}).
+		Where("id = ?", job.ID).
+		Updates(map[string]interface{}{
+			"status":       job.Status,
+			"created":      job.Created,
+			"failed":       job.Failed,
+			"error":        job.Error,
+			"results":      job.ResultsJSON,
+			"payload":      "",
+			"completed_at": job.CompletedAt,
+		}).Error
+	if err != nil {
+		r.log.Error("Failed to finish bulk import job", logger.NamedError("error", err))
+	}
+	return err
+}
+
+// FailRunning marks jobs left running by a previous process as failed. They
+// are not retried because a partial import may already have created rows.
+func (r *bulkImportRepository) FailRunning(ctx context.Context, reason string) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.BulkImportJob{}).
+		Where("status = ?", models.BulkImportRunning).
+		Updates(map[string]interface{}{
+			"status":       models.BulkImportFailed,
+			"error":        reason,
+			"payload":      "",
+			"completed_at": time.Now().UTC(),
+		})
+	if result.Error != nil {
+		r.log.Error("Failed to fail interrupted bulk import jobs", logger.NamedError("error", result.Error))
+	}
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/imraushankr/brevity/server/src/internal/models"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// campaignURLCount counts the live links in a campaign
+const campaignURLCount = `(SELECT COUNT(*) FROM urls
+	WHERE urls.campaign_id = campaigns.id AND urls.deleted_at IS NULL) AS url_count`
+
+type campaignRepository struct {
+	db  *gorm.DB
+	log logger.Logger
+}
+
+func NewCampaignRepository(db *gorm.DB) CampaignRepository {
+	return &campaignRepository{
+		db:  db,
+		log: logger.Get(),
+	}
+}
+
+func (r *campaignRepository) Create(ctx context.Context, campaign *models.Campaign) error {
+	r.log.Debug("Creating campaign",
+		logger.String("userID", campaign.UserID),
+		logger.String("name", campaign.Name))
+
+	err := r.db.WithContext(ctx).Create(campaign).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return models.ErrCampaignExists
+	}
+	if err != nil {
+		r.log.Error("Failed to create campaign", logger.NamedError("error", err))
+	}
+	return err
+}
+
+func (r *campaignRepository) FindByID(ctx context.Context, id string) (*models.Campaign, error) {
+	var campaign models.Campaign
+	err := r.db.WithContext(ctx).Select("campaigns.*, "+campaignURLCount).Where("id = ?", id).First(&campaign).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrCampaignNotFound
+	}
+	if err != nil {
+		r.log.Error("Failed to find campaign", logger.NamedError("error", err))
+		return nil, err
+	}
+	return &campaign, nil
+}
+
+// ListByUser returns a page of a user's campaigns, newest first
+func (r *campaignRepository) ListByUser(ctx context.Context, userID string, offset, limit int) ([]models.Campaign, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.Campaign{}).Where("user_id = ?", userID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		r.log.Error("Failed to count campaigns", logger.NamedError("error", err))
+		return nil, 0, err
+	}
+
+	var campaigns []models.Campaign
+	err := query.Select("campaigns.*, " + campaignURLCount).
+		Order("created_at DESC, id").Offset(offset).Limit(limit).Find(&campaigns).Error
+	if err != nil {
+		r.log.Error("Failed to list campaigns", logger.NamedError("error", err))
+		return nil, 0, err
+	}
+	return campaigns, total, nil
+}
+
+func (r *campaignRepository) Update(ctx context.Context, campaign *models.Campaign) error {
+	err := r.db.WithContext(ctx).
+		Model(campaign).
+		Select("name", "description", "updated_at").
+		Updates(campaign).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return models.ErrCampaignExists
+	}
+	if err != nil {
+		r.log.Error("Failed to update campaign",
+			logger.NamedError("error", err),
+			logger.String("campaignID", campaign.ID))
+	}
+	return err
+}
+
+// Delete removes a campaign. Its links stay and no longer belong to one.
+func (r *campaignRepository) Delete(ctx context.Context, id string) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.URL{}).Where("campaign_id = ?", id).Update("campaign_id", nil).Error
+		if err != nil {
+			return err
+		}
+		result := tx.Where("id = ?", id).Delete(&models.Campaign{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return models.ErrCampaignNotFound
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, models.ErrCampaignNotFound) {
+		r.log.Error("Failed to delete campaign",
+			logger.NamedError("error", err),
+			logger.String("campaignID", id))
+	}
+	return err
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/imraushankr/brevity/server/src/internal/models"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/database"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/logger"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const clickInsertBatchSize = 100
+
+type clickRepository struct {
+	db  *database.DB
+	log logger.Logger
+}
+
+func NewClickRepository(db *database.DB) ClickRepository {
+	return &clickRepository{
+		db:  db,
+		log: logger.Get(),
+	}
+}
+
+// CreateBatch stores the clicks and bumps the matching URL click counters in
+// a single transaction. Bot clicks are stored but not counted.
+func (r *clickRepository) CreateBatch(ctx context.Context, clicks []models.URLClick) error {
+	if len(clicks) == 0 {
+		return nil
+	}
+	r.log.Debug("Persisting click batch", logger.Int("count", len(clicks)))
+
+	counts := make(map[string]int)
+	for _, click := range clicks {
+		if !click.IsBot {
+			counts[click.URLID]++
+		}
+	}
+
+	err := r.db.WithTx(ctx, func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).CreateInBatches(clicks, clickInsertBatchSize).Error; err != nil {
+			return err
+		}
+
+		for urlID, count := range counts {
+			err := tx.Model(&models.URL{}).
+				Where("id = ?", urlID).
+				UpdateColumn("clicks", gorm.Expr("clicks + ?", count)).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		r.log.Error("Failed to persist click batch",
+			logger.NamedError("error", err),
+			logger.Int("count", len(clicks)))
+	}
+	return err
+}
+
+// StreamByURLID walks a url's clicks oldest first, calling fn with each batch
+func (r *clickRepository) StreamByURLID(ctx context.Context, urlID string, filter *models.ClickExportFilter, batchSize int, fn func([]models.URLClick) error) error {
+	r.log.Debug("Streaming clicks for url", logger.String("urlID", urlID))
+
+	query := r.db.WithContext(ctx).Model(&models.URLClick{}).Where("url_id = ?", urlID)
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", filter.From.UTC())
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", filter.To.UTC())
+	}
+
+	var last *models.URLClick
+	for {
+		page := query.Session(&gorm.Session{})
+		if last != nil {
+			page = page.Where("(created_at > ? OR (created_at = ? AND id > ?))", last.CreatedAt, last.CreatedAt, last.ID)
+		}
+
+		var clicks []models.URLClick
+		if err := page.Order("created_at, id").Limit(batchSize).Find(&clicks).Error; err != nil {
+			r.log.Error("Failed to stream clicks", logger.NamedError("error", err))
+			return err
+		}
+		if len(clicks) == 0 {
+			return nil
+		}
+		if err := fn(clicks); err != nil {
+			return err
+		}
+		if len(clicks) < batchSize {
+			return nil
+		}
+		last = &clicks[len(clicks)-1]
+	}
+}
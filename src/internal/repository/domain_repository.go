@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/imraushankr/brevity/server/src/internal/models"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/logger"
+	"gorm.io/gorm"
+)
+
+type domainRepository struct {
+	db  *gorm.DB
+	log logger.Logger
+}
+
+func NewDomainRepository(db *gorm.DB) DomainRepository {
+	return &domainRepository{
+		db:  db,
+		log: logger.Get(),
+	}
+}
+
+func (r *domainRepository) Create(ctx context.Context, domain *models.Domain) error {
+	r.log.Debug("Creating domain",
+		logger.String("hostname", domain.Hostname),
+		logger.String("userID", domain.UserID))
+
+	err := r.db.WithContext(ctx).Create(domain).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return models.ErrDomainExists
+	}
+	if err != nil {
+		r.log.Error("Failed to create domain", logger.NamedError("error", err))
+	}
+	return err
+}
+
+func (r *domainRepository) FindByID(ctx context.Context, id string) (*models.Domain, error) {
+	r.log.Debug("Finding domain by id", logger.String("domainID", id))
+
+	var domain models.Domain
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&domain).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrDomainNotFound
+	}
+	if err != nil {
+		r.log.Error("Failed to find domain", logger.NamedError("error", err))
+		return nil, err
+	}
+	return &domain, nil
+}
+
+func (r *domainRepository) FindByUserAndHostname(ctx context.Context, userID, hostname string) (*models.Domain, error) {
+	r.log.Debug("Finding domain by hostname",
+		logger.String("userID", userID),
+		logger.String("hostname", hostname))
+
+	var domain models.Domain
+	err := r.db.WithContext(ctx).Where("user_id = ? AND hostname = ?", userID, hostname).First(&domain).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrDomainNotFound
+	}
+	if err != nil {
+		r.log.Error("Failed to find domain", logger.NamedError("error", err))
+		return nil, err
+	}
+	return &domain, nil
+}
+
+// FindVerifiedByHostname returns the domain that serves hostname. Unverified
+// registrations of the hostname are ignored.
+func (r *domainRepository) FindVerifiedByHostname(ctx context.Context, hostname string) (*models.Domain, error) {
+	var domain models.Domain
+	err := r.db.WithContext(ctx).Where("hostname = ? AND verified_at IS NOT NULL", hostname).First(&domain).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrDomainNotFound
+	}
+	if err != nil {
+		r.log.Error("Failed to find domain", logger.NamedError("error", err))
+		return nil, err
+	}
+	return &domain, nil
+}
+
+func (r *domainRepository) FindByUserID(ctx context.Context, userID string) ([]models.Domain, error) {
+	r.log.Debug("Listing domains for user", logger.String("userID", userID))
+
+	var domains []models.Domain
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("hostname").Find(&domains).Error
+	if err != nil {
+		r.log.Error("Failed to list domains", logger.NamedError("error", err))
+		return nil, err
+	}
+	return domains, nil
+}
+
+// MarkVerified records a successful DNS check. Only one domain per hostname
+// can be verified, so ErrDomainTaken is returned if another account got
+// there first.
+func (r *domainRepository) MarkVerified(ctx context.Context, id string, at time.Time) error {
+	err := r.db.WithContext(ctx).Model(&models.Domain{}).Where("id = ?", id).Update("verified_at", at).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return models.ErrDomainTaken
+	}
+	if err != nil {
+		r.log.Error("Failed to mark domain verified",
+			logger.NamedError("error", err),
+			logger.String("domainID", id))
+	}
+	return err
+}
+
+// HasURLs reports whether any short url that has not been deleted is bound
+// to the domain
+func (r *domainRepository) HasURLs(ctx context.Context, id string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.URL{}).Scopes(notDeleted).Where("domain_id = ?", id).Count(&count).Error
+	if err != nil {
+		r.log.Error("Failed to count domain urls", logger.NamedError("error", err))
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *domainRepository) Delete(ctx context.Context, id string) error {
+	r.log.Debug("Deleting domain", logger.String("domainID", id))
+
+	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.Domain{})
+	if result.Error != nil {
+		r.log.Error("Failed to delete domain", logger.NamedError("error", result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return models.ErrDomainNotFound
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/imraushankr/brevity/server/src/internal/models"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/pagination"
 )
 
 type UserRepository interface {
@@ -20,4 +21,112 @@ type UserRepository interface {
 	SaveResetToken(ctx context.Context, email, token string, expires time.Time) error
 	ResetPassword(ctx context.Context, token, newPassword string) error
 	UpdateAvatar(ctx context.Context, userID, avatarURL string) error
-}
\ No newline at end of file
+	List(ctx context.Context, q *pagination.Query) ([]models.User, *pagination.Page, error)
+}
+
+type URLRepository interface {
+	Create(ctx context.Context, url *models.URL) error
+	CreateBatch(ctx context.Context, urls []*models.URL) error
+	FindByID(ctx context.Context, id string) (*models.URL, error)
+	FindByShortCode(ctx context.Context, domainID, shortCode string) (*models.URL, error)
+	FindByUserID(ctx context.Context, userID string, q *pagination.Query) ([]models.URL, *pagination.Page, error)
+	Search(ctx context.Context, userID, match string, offset, limit int) ([]models.URLSearchHit, int64, error)
+	StreamByUserID(ctx context.Context, userID string, filter *models.URLExportFilter, batchSize int, fn func([]models.URL) error) error
+	ShortCodeExists(ctx context.Context, domainID, shortCode string) (bool, error)
+	Update(ctx context.Context, url *models.URL) error
+	ReplaceTags(ctx context.Context, url *models.URL) error
+	Deactivate(ctx context.Context, id string) error
+	ExpiredBySchedule(ctx context.Context, id string) (bool, error)
+	ClaimClick(ctx context.Context, id string) (int, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type DomainRepository interface {
+	Create(ctx context.Context, domain *models.Domain) error
+	FindByID(ctx context.Context, id string) (*models.Domain, error)
+	FindByUserAndHostname(ctx context.Context, userID, hostname string) (*models.Domain, error)
+	FindVerifiedByHostname(ctx context.Context, hostname string) (*models.Domain, error)
+	FindByUserID(ctx context.Context, userID string) ([]models.Domain, error)
+	MarkVerified(ctx context.Context, id string, at time.Time) error
+	HasURLs(ctx context.Context, id string) (bool, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type LinkScheduleRepository interface {
+	ActivateDue(ctx context.Context, now time.Time) ([]models.URLTransition, error)
+	ExpireDue(ctx context.Context, now time.Time) ([]models.URLTransition, error)
+}
+
+type LinkPreviewRepository interface {
+	FindPending(ctx context.Context, limit int) ([]models.URL, error)
+	Save(ctx context.Context, id string, title, description string, preview models.LinkPreview, at time.Time) error
+}
+
+type LinkSafetyRepository interface {
+	CreateEntry(ctx context.Context, entry *models.BlocklistEntry) error
+	ListEntries(ctx context.Context, kind string, offset, limit int) ([]models.BlocklistEntry, int64, error)
+	AllEntries(ctx context.Context) ([]models.BlocklistEntry, error)
+	DeleteEntry(ctx context.Context, id string) error
+	FindUnchecked(ctx context.Context, afterID string, limit int) ([]models.URL, error)
+	Flag(ctx context.Context, flag *models.LinkFlag) (bool, error)
+	ListFlags(ctx context.Context, status string, offset, limit int) ([]models.LinkFlag, int64, error)
+	FindFlag(ctx context.Context, id string) (*models.LinkFlag, error)
+	Review(ctx context.Context, flag *models.LinkFlag, urlStatus string, at time.Time) error
+}
+
+type LinkHealthRepository interface {
+	FindUnscheduled(ctx context.Context, limit int) ([]string, error)
+	Schedule(ctx context.Context, next map[string]time.Time) error
+	FindDue(ctx context.Context, now time.Time, limit int) ([]models.URL, error)
+	Save(ctx context.Context, id string, health models.LinkHealth) error
+	FindUnreported(ctx context.Context, threshold int) ([]models.URL, error)
+	MarkReported(ctx context.Context, ids []string) error
+	ListUnhealthy(ctx context.Context, userID string, threshold, offset, limit int) ([]models.URL, int64, error)
+}
+
+type TagRepository interface {
+	ListByUser(ctx context.Context, userID string) ([]models.Tag, error)
+	FindByID(ctx context.Context, id string) (*models.Tag, error)
+	Delete(ctx context.Context, id string) error
+	AddToURLs(ctx context.Context, userID string, urlIDs, names []string) (int64, int64, error)
+	RemoveFromURLs(ctx context.Context, userID string, urlIDs, names []string) (int64, int64, error)
+}
+
+type CampaignRepository interface {
+	Create(ctx context.Context, campaign *models.Campaign) error
+	FindByID(ctx context.Context, id string) (*models.Campaign, error)
+	ListByUser(ctx context.Context, userID string, offset, limit int) ([]models.Campaign, int64, error)
+	Update(ctx context.Context, campaign *models.Campaign) error
+	Delete(ctx context.Context, id string) error
+}
+
+type SequenceRepository interface {
+	Next(ctx context.Context, name string) (uint64, error)
+}
+
+type BulkImportRepository interface {
+	Create(ctx context.Context, job *models.BulkImportJob) error
+	FindByID(ctx context.Context, id string) (*models.BulkImportJob, error)
+	ClaimNext(ctx context.Context) (*models.BulkImportJob, error)
+	Finish(ctx context.Context, job *models.BulkImportJob) error
+	FailRunning(ctx context.Context, reason string) (int64, error)
+}
+
+type ClickRepository interface {
+	CreateBatch(ctx context.Context, clicks []models.URLClick) error
+	StreamByURLID(ctx context.Context, urlID string, filter *models.ClickExportFilter, batchSize int, fn func([]models.URLClick) error) error
+}
+
+type AnalyticsRepository interface {
+	ClickTotals(ctx context.Context, scope models.AnalyticsScope, from, to time.Time) (int64, int64, error)
+	ClickTimeSeries(ctx context.Context, scope models.AnalyticsScope, from, to time.Time, interval models.AnalyticsInterval) ([]models.ClickBucket, error)
+	TopValues(ctx context.Context, scope models.AnalyticsScope, dimension models.AnalyticsDimension, from, to time.Time, limit int) ([]models.BreakdownItem, error)
+	VariantTotals(ctx context.Context, scope models.AnalyticsScope, from, to time.Time) ([]models.VariantStats, error)
+	TopURLs(ctx context.Context, scope models.AnalyticsScope, from, to time.Time, limit int) ([]models.LinkClicks, error)
+	Watermark(ctx context.Context) (time.Time, error)
+}
+
+type RollupRepository interface {
+	Watermark(ctx context.Context) (time.Time, error)
+	Compact(ctx context.Context, cutoff time.Time, pruneRaw bool) (int64, error)
+}
@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/imraushankr/brevity/server/src/internal/models"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/logger"
+	"gorm.io/gorm"
+)
+
+type linkHealthRepository struct {
+	db  *gorm.DB
+	log logger.Logger
+}
+
+func NewLinkHealthRepository(db *gorm.DB) LinkHealthRepository {
+	return &linkHealthRepository{
+		db:  db,
+		log: logger.Get(),
+	}
+}
+
+// checkable scopes a query to links that redirect and so are worth checking
+func checkable(db *gorm.DB) *gorm.DB {
+	return db.Scopes(notDeleted).
+		Where("is_active = ? AND safety_status NOT IN ?", true, []string{models.URLSafetyFlagged, models.URLSafetyBlocked})
+}
+
+// FindUnscheduled returns the IDs of links that have no check scheduled yet
+func (r *linkHealthRepository) FindUnscheduled(ctx context.Context, limit int) ([]string, error) {
+	var ids []string
+	err := r.db.WithContext(ctx).
+		Model(&models.URL{}).
+		Scopes(notDeleted).
+		Where("health_next_check_at IS NULL").
+		Order("created_at").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	if err != nil {
+		r.log.Error("Failed to find unscheduled links", logger.NamedError("error", err))
+		return nil, err
+	}
+	return ids, nil
+}
+
+// Schedule sets when each link in next is checked first
+func (r *linkHealthRepository) Schedule(ctx context.Context, next map[string]time.Time) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for id, at := range next {
+			err := tx.Model(&models.URL{}).
+				Where("id = ? AND health_next_check_at IS NULL", id).
+				Update("health_next_check_at", at).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		r.log.Error("Failed to schedule link checks", logger.NamedError("error", err))
+	}
+	return err
+}
+
+// FindDue returns the links whose check is due, longest overdue first. Only
+// the destination and health fields are loaded.
+func (r *linkHealthRepository) FindDue(ctx context.Context, now time.Time, limit int) ([]models.URL, error) {
+	var urls []models.URL
+	err := r.db.WithContext(ctx).
+		Scopes(checkable).
+		Select("id", "original_url", "health_failures", "health_failing_since", "health_reported").
+		Where("health_next_check_at <= ?", now).
+		Order("health_next_check_at").
+		Limit(limit).
+		Find(&urls).Error
+	if err != nil {
+		r.log.Error("Failed to find links due for a check", logger.NamedError("error", err))
+		return nil, err
+	}
+	return urls, nil
+}
+
+// Save stores the result of a check and when the next one is due
+func (r *linkHealthRepository) Save(ctx context.Context, id string, health models.LinkHealth) error {
+	err := r.db.WithContext(ctx).
+		Model(&models.URL{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"health_status_code":   health.StatusCode,
+			"health_latency_ms":    health.LatencyMS,
+			"health_error":         health.Error,
+			"health_checked_at":    health.CheckedAt,
+			"health_failures":      health.Failures,
+			"health_failing_since": health.FailingSince,
+			"health_reported":      health.Reported,
+			"health_next_check_at": health.NextCheckAt,
+		}).Error
+	if err != nil {
+		r.log.Error("Failed to save link health",
+			logger.NamedError("error", err),
+			logger.String("urlID", id))
+	}
+	return err
+}
+
+// FindUnreported returns broken links their owners were not told about yet,
+// with owners and domains loaded, grouped by owner
+func (r *linkHealthRepository) FindUnreported(ctx context.Context, threshold int) ([]models.URL, error) {
+	var urls []models.URL
+	err := r.db.WithContext(ctx).
+		Scopes(checkable, withDomain).
+		Preload("User").
+		Where("health_failures >= ? AND health_reported = ?", threshold, false).
+		Order("user_id, health_failing_since").
+		Find(&urls).Error
+	if err != nil {
+		r.log.Error("Failed to find unreported broken links", logger.NamedError("error", err))
+		return nil, err
+	}
+	return urls, nil
+}
+
+// MarkReported records that the owners of ids were told their links broke
+func (r *linkHealthRepository) MarkReported(ctx context.Context, ids []string) error {
+	err := r.db.WithContext(ctx).
+		Model(&models.URL{}).
+		Where("id IN ?", ids).
+		Update("health_reported", true).Error
+	if err != nil {
+		r.log.Error("Failed to mark broken links reported", logger.NamedError("error", err))
+	}
+	return err
+}
+
+// ListUnhealthy returns a page of a user's broken links, longest broken
+// first
+func (r *linkHealthRepository) ListUnhealthy(ctx context.Context, userID string, threshold, offset, limit int) ([]models.URL, int64, error) {
+	query := r.db.WithContext(ctx).
+		Model(&models.URL{}).
+		Scopes(notDeleted).
+		Where("user_id = ? AND health_failures >= ?", userID, threshold)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		r.log.Error("Failed to count unhealthy links", logger.NamedError("error", err))
+		return nil, 0, err
+	}
+
+	var urls []models.URL
+	err := query.Scopes(withDomain).
+		Order("health_failing_since, id").Offset(offset).Limit(limit).Find(&urls).Error
+	if err != nil {
+		r.log.Error("Failed to list unhealthy links", logger.NamedError("error", err))
+		return nil, 0, err
+	}
+	return urls, total, nil
+}
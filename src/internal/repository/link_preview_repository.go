@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/imraushankr/brevity/server/src/internal/models"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/logger"
+	"gorm.io/gorm"
+)
+
+type linkPreviewRepository struct {
+	db  *gorm.DB
+	log logger.Logger
+}
+
+func NewLinkPreviewRepository(db *gorm.DB) LinkPreviewRepository {
+	return &linkPreviewRepository{
+		db:  db,
+		log: logger.Get(),
+	}
+}
+
+// FindPending returns the oldest links whose destination has not been
+// previewed yet
+func (r *linkPreviewRepository) FindPending(ctx context.Context, limit int) ([]models.URL, error) {
+	var urls []models.URL
+	err := r.db.WithContext(ctx).
+		Scopes(notDeleted).
+		Select("id", "original_url").
+		Where("previewed_at IS NULL").
+		Order("created_at").
+		Limit(limit).
+		Find(&urls).Error
+	if err != nil {
+		r.log.Error("Failed to find links pending preview", logger.NamedError("error", err))
+		return nil, err
+	}
+	return urls, nil
+}
+
+// Save stores a link's preview and marks it done. Title and description are
+// only filled in when still empty, so edits the owner made while the page
+// was being fetched are kept.
+func (r *linkPreviewRepository) Save(ctx context.Context, id string, title, description string, preview models.LinkPreview, at time.Time) error {
+	err := r.db.WithContext(ctx).
+		Model(&models.URL{}).
+		Where("id = ? AND previewed_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"title":               gorm.Expr("CASE WHEN title = '' THEN ? ELSE title END", title),
+			"description":         gorm.Expr("CASE WHEN description = '' THEN ? ELSE description END", description),
+			"preview_site_name":   preview.SiteName,
+			"preview_image_url":   preview.ImageURL,
+			"preview_favicon_url": preview.FaviconURL,
+			"previewed_at":        at,
+		}).Error
+	if err != nil {
+		r.log.Error("Failed to save link preview",
+			logger.NamedError("error", err),
+			logger.String("urlID", id))
+	}
+	return err
+}
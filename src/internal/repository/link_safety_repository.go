@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/imraushankr/brevity/server/src/internal/models"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/logger"
+	"gorm.io/gorm"
+)
+
+type linkSafetyRepository struct {
+	db  *gorm.DB
+	log logger.Logger
+}
+
+func NewLinkSafetyRepository(db *gorm.DB) LinkSafetyRepository {
+	return &linkSafetyRepository{
+		db:  db,
+		log: logger.Get(),
+	}
+}
+
+func (r *linkSafetyRepository) CreateEntry(ctx context.Context, entry *models.BlocklistEntry) error {
+	r.log.Debug("Creating blocklist entry",
+		logger.String("kind", entry.Kind),
+		logger.String("pattern", entry.Pattern))
+
+	err := r.db.WithContext(ctx).Create(entry).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return models.ErrBlocklistEntryExists
+	}
+	if err != nil {
+		r.log.Error("Failed to create blocklist entry", logger.NamedError("error", err))
+	}
+	return err
+}
+
+// ListEntries returns a page of entries, newest first, optionally of one kind
+func (r *linkSafetyRepository) ListEntries(ctx context.Context, kind string, offset, limit int) ([]models.BlocklistEntry, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.BlocklistEntry{})
+	if kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		r.log.Error("Failed to count blocklist entries", logger.NamedError("error", err))
+		return nil, 0, err
+	}
+
+	var entries []models.BlocklistEntry
+	err := query.Order("created_at DESC, id").Offset(offset).Limit(limit).Find(&entries).Error
+	if err != nil {
+		r.log.Error("Failed to list blocklist entries", logger.NamedError("error", err))
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
+
+// AllEntries returns every entry, for compiling the blocklist
+func (r *linkSafetyRepository) AllEntries(ctx context.Context) ([]models.BlocklistEntry, error) {
+	var entries []models.BlocklistEntry
+	if err := r.db.WithContext(ctx).Order("created_at, id").Find(&entries).Error; err != nil {
+		r.log.Error("Failed to load blocklist entries", logger.NamedError("error", err))
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (r *linkSafetyRepository) DeleteEntry(ctx context.Context, id string) error {
+	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.BlocklistEntry{})
+	if result.Error != nil {
+		r.log.Error("Failed to delete blocklist entry",
+			logger.NamedError("error", result.Error),
+			logger.String("entryID", id))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return models.ErrBlocklistEntryNotFound
+	}
+	return nil
+}
+
+// FindUnchecked returns the next links after afterID, by ID, that have not
+// been flagged or reviewed. Only the fields holding destinations are loaded.
+func (r *linkSafetyRepository) FindUnchecked(ctx context.Context, afterID string, limit int) ([]models.URL, error) {
+	var urls []models.URL
+	err := r.db.WithContext(ctx).
+		Scopes(notDeleted).
+		Select("id", "original_url", "fallback_url", "rules", "variants").
+		Where("safety_status = '' AND id > ?", afterID).
+		Order("id").
+		Limit(limit).
+		Find(&urls).Error
+	if err != nil {
+		r.log.Error("Failed to find links to check", logger.NamedError("error", err))
+		return nil, err
+	}
+	return urls, nil
+}
+
+// Flag stores flag and disables its link, unless the link was flagged or
+// reviewed in the meantime. It reports whether the flag was stored.
+func (r *linkSafetyRepository) Flag(ctx context.Context, flag *models.LinkFlag) (bool, error) {
+	flagged := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.URL{}).
+			Where("id = ? AND safety_status = ''", flag.URLID).
+			Update("safety_status", models.URLSafetyFlagged)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		flagged = true
+		return tx.Create(flag).Error
+	})
+	if err != nil {
+		r.log.Error("Failed to flag link",
+			logger.NamedError("error", err),
+			logger.String("urlID", flag.URLID))
+		return false, err
+	}
+	return flagged, nil
+}
+
+// ListFlags returns a page of flags with their links, oldest first so the
+// review queue is worked in order, optionally in one status
+func (r *linkSafetyRepository) ListFlags(ctx context.Context, status string, offset, limit int) ([]models.LinkFlag, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.LinkFlag{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		r.log.Error("Failed to count link flags", logger.NamedError("error", err))
+		return nil, 0, err
+	}
+
+	var flags []models.LinkFlag
+	err := query.Preload("URL").Preload("URL.Domain").
+		Order("created_at, id").Offset(offset).Limit(limit).Find(&flags).Error
+	if err != nil {
+		r.log.Error("Failed to list link flags", logger.NamedError("error", err))
+		return nil, 0, err
+	}
+	return flags, total, nil
+}
+
+func (r *linkSafetyRepository) FindFlag(ctx context.Context, id string) (*models.LinkFlag, error) {
+	var flag models.LinkFlag
+	err := r.db.WithContext(ctx).Preload("URL").Preload("URL.Domain").Where("id = ?", id).First(&flag).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrLinkFlagNotFound
+	}
+	if err != nil {
+		r.log.Error("Failed to find link flag", logger.NamedError("error", err))
+		return nil, err
+	}
+	return &flag, nil
+}
+
+// Review settles a pending flag and sets its link's safety status to match
+func (r *linkSafetyRepository) Review(ctx context.Context, flag *models.LinkFlag, urlStatus string, at time.Time) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.LinkFlag{}).
+			Where("id = ? AND status = ?", flag.ID, models.LinkFlagPending).
+			Updates(map[string]interface{}{
+				"status":      flag.Status,
+				"reviewed_by": flag.ReviewedBy,
+				"review_note": flag.ReviewNote,
+				"reviewed_at": at,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return models.ErrLinkFlagReviewed
+		}
+		return tx.Model(&models.URL{}).Where("id = ?", flag.URLID).Update("safety_status", urlStatus).Error
+	})
+	if err != nil && !errors.Is(err, models.ErrLinkFlagReviewed) {
+		r.log.Error("Failed to review link flag",
+			logger.NamedError("error", err),
+			logger.String("flagID", flag.ID))
+	}
+	return err
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/imraushankr/brevity/server/src/internal/models"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/logger"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type linkScheduleRepository struct {
+	db  *gorm.DB
+	log logger.Logger
+}
+
+func NewLinkScheduleRepository(db *gorm.DB) LinkScheduleRepository {
+	return &linkScheduleRepository{
+		db:  db,
+		log: logger.Get(),
+	}
+}
+
+// ActivateDue switches on links whose activation time has passed and that
+// have not expired since
+func (r *linkScheduleRepository) ActivateDue(ctx context.Context, now time.Time) ([]models.URLTransition, error) {
+	due := func(db *gorm.DB) *gorm.DB {
+		return db.Where("is_active = ? AND activates_at IS NOT NULL AND activates_at <= ? AND (expires_at IS NULL OR expires_at > ?)",
+			false, now, now)
+	}
+	return r.apply(ctx, due, "activates_at", true, models.TransitionActivated)
+}
+
+// ExpireDue switches off active links whose expiry time has passed
+func (r *linkScheduleRepository) ExpireDue(ctx context.Context, now time.Time) ([]models.URLTransition, error) {
+	due := func(db *gorm.DB) *gorm.DB {
+		return db.Where("is_active = ? AND expires_at IS NOT NULL AND expires_at <= ?", true, now)
+	}
+	return r.apply(ctx, due, "expires_at", false, models.TransitionExpired)
+}
+
+// apply flips is_active on every link matched by due and records one
+// transition per link, all in one transaction. The update repeats the due
+// condition so a link changed since it was read is left alone.
+func (r *linkScheduleRepository) apply(
+	ctx context.Context,
+	due func(*gorm.DB) *gorm.DB,
+	scheduledColumn string,
+	isActive bool,
+	reason models.TransitionReason,
+) ([]models.URLTransition, error) {
+	var transitions []models.URLTransition
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var rows []struct {
+			ID          string
+			ScheduledAt time.Time
+		}
+		err := tx.Model(&models.URL{}).
+			Scopes(notDeleted, due).
+			Select("id, " + scheduledColumn + " AS scheduled_at").
+			Find(&rows).Error
+		if err != nil || len(rows) == 0 {
+			return err
+		}
+
+		ids := make([]string, len(rows))
+		for i, row := range rows {
+			ids[i] = row.ID
+		}
+		err = tx.Model(&models.URL{}).
+			Scopes(notDeleted, due).
+			Where("id IN ?", ids).
+			Update("is_active", isActive).Error
+		if err != nil {
+			return err
+		}
+
+		transitions = make([]models.URLTransition, len(rows))
+		for i, row := range rows {
+			transitions[i] = models.URLTransition{
+				URLID:       row.ID,
+				IsActive:    isActive,
+				Reason:      reason,
+				ScheduledAt: row.ScheduledAt,
+			}
+		}
+		return tx.Omit(clause.Associations).CreateInBatches(transitions, urlInsertBatchSize).Error
+	})
+	if err != nil {
+		r.log.Error("Failed to apply link schedule",
+			logger.NamedError("error", err),
+			logger.String("reason", string(reason)))
+		return nil, err
+	}
+	return transitions, nil
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"github.com/imraushankr/brevity/server/src/internal/pkg/database"
+)
+
+// Repositories holds one instance of every repository, so services that
+// share a table also share its repository
+type Repositories struct {
+	Users         UserRepository
+	URLs          URLRepository
+	Domains       DomainRepository
+	LinkSchedules LinkScheduleRepository
+	LinkPreviews  LinkPreviewRepository
+	LinkSafety    LinkSafetyRepository
+	LinkHealth    LinkHealthRepository
+	Tags          TagRepository
+	Campaigns     CampaignRepository
+	Sequences     SequenceRepository
+	BulkImports   BulkImportRepository
+	Clicks        ClickRepository
+	Analytics     AnalyticsRepository
+	Rollups       RollupRepository
+}
+
+// NewRepositories creates every repository on db
+func NewRepositories(db *database.DB) *Repositories {
+	return &Repositories{
+		Users:         NewUserRepository(db.DB),
+		URLs:          NewURLRepository(db.DB),
+		Domains:       NewDomainRepository(db.DB),
+		LinkSchedules: NewLinkScheduleRepository(db.DB),
+		LinkPreviews:  NewLinkPreviewRepository(db.DB),
+		LinkSafety:    NewLinkSafetyRepository(db.DB),
+		LinkHealth:    NewLinkHealthRepository(db.DB),
+		Tags:          NewTagRepository(db.DB),
+		Campaigns:     NewCampaignRepository(db.DB),
+		Sequences:     NewSequenceRepository(db.DB),
+		BulkImports:   NewBulkImportRepository(db.DB),
+		Clicks:        NewClickRepository(db),
+		Analytics:     NewAnalyticsRepository(db),
+		Rollups:       NewRollupRepository(db),
+	}
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/imraushankr/brevity/server/src/internal/models"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/database"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/logger"
+	"gorm.io/gorm"
+)
+
+const (
+	rollupsHourlyTable   = "click_rollups_hourly"
+	rollupsDailyTable    = "click_rollups_daily"
+	rollupStateTable     = "click_rollup_state"
+	rollupTotalDimension = "total"
+)
+
+// rollupGranularity maps each rollup table to the interval of its buckets
+var rollupGranularity = map[string]models.AnalyticsInterval{
+	rollupsHourlyTable: models.IntervalHour,
+	rollupsDailyTable:  models.IntervalDay,
+}
+
+type rollupRepository struct {
+	db  *database.DB
+	log logger.Logger
+}
+
+func NewRollupRepository(db *database.DB) RollupRepository {
+	return &rollupRepository{
+		db:  db,
+		log: logger.Get(),
+	}
+}
+
+// loadWatermark returns the time before which raw clicks are covered by the
+// rollup tables
+func loadWatermark(db *gorm.DB) (time.Time, error) {
+	var state struct {
+		CompactedUntil time.Time
+	}
+	err := db.Table(rollupStateTable).Select("compacted_until").Where("id = 1").Scan(&state).Error
+	return state.CompactedUntil.UTC(), err
+}
+
+func (r *rollupRepository) Watermark(ctx context.Context) (time.Time, error) {
+	return loadWatermark(r.db.WithContext(ctx))
+}
+
+// Compact folds non-bot clicks between the current watermark and cutoff into
+// the hourly and daily rollups, moves the watermark to cutoff and, when
+// pruneRaw is set, deletes the raw clicks it covered. It returns the number
+// of raw clicks compacted.
+func (r *rollupRepository) Compact(ctx context.Context, cutoff time.Time, pruneRaw bool) (int64, error) {
+	cutoff = cutoff.UTC()
+	r.log.Debug("Compacting clicks", logger.Time("cutoff", cutoff))
+
+	var compacted int64
+	err := r.db.WithTx(ctx, func(tx *gorm.DB) error {
+		watermark, err := loadWatermark(tx)
+		if err != nil {
+			return err
+		}
+		if !cutoff.After(watermark) {
+			return nil
+		}
+
+		err = tx.Table("url_clicks").
+			Where("is_bot = ? AND created_at >= ? AND created_at < ?", false, watermark, cutoff).
+			Count(&compacted).Error
+		if err != nil {
+			return err
+		}
+
+		for table, interval := range rollupGranularity {
+			bucket := bucketExpr(interval, "created_at")
+			err := tx.Exec(`INSERT INTO `+table+` (url_id, bucket_start, dimension, value, clicks, unique_visitors)
+				SELECT url_id, `+bucket+`, ?, '', COUNT(*), COUNT(DISTINCT ip_address)
+				FROM url_clicks
+				WHERE is_bot = ? AND created_at >= ? AND created_at < ?
+				GROUP BY 1, 2
+				ON CONFLICT (url_id, bucket_start, dimension, value) DO UPDATE SET
+					clicks = clicks + excluded.clicks,
+					unique_visitors = unique_visitors + excluded.unique_visitors`,
+				rollupTotalDimension, false, watermark, cutoff).Error
+			if err != nil {
+				return err
+			}
+
+			for dimension, column := range dimensionColumns {
+				err := tx.Exec(`INSERT INTO `+table+` (url_id, bucket_start, dimension, value, clicks, unique_visitors)
+					SELECT url_id, `+bucket+`, ?, COALESCE(`+column+`, ''), COUNT(*), COUNT(DISTINCT ip_address)
+					FROM url_clicks
+					WHERE is_bot = ? AND created_at >= ? AND created_at < ?
+					GROUP BY 1, 2, 4
+					ON CONFLICT (url_id, bucket_start, dimension, value) DO UPDATE SET
+						clicks = clicks + excluded.clicks,
+						unique_visitors = unique_visitors + excluded.unique_visitors`,
+					string(dimension), false, watermark, cutoff).Error
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		err = tx.Table(rollupStateTable).Where("id = 1").Updates(map[string]interface{}{
+			"compacted_until": cutoff.Format(bucketTimeLayout),
+			"updated_at":      time.Now().UTC(),
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		if pruneRaw {
+			return tx.Where("created_at < ?", cutoff).Delete(&models.URLClick{}).Error
+		}
+		return nil
+	})
+	if err != nil {
+		r.log.Error("Failed to compact clicks",
+			logger.NamedError("error", err),
+			logger.Time("cutoff", cutoff))
+		return 0, err
+	}
+	return compacted, nil
+}
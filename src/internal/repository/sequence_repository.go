@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/imraushankr/brevity/server/src/internal/pkg/logger"
+	"gorm.io/gorm"
+)
+
+type sequenceRepository struct {
+	db  *gorm.DB
+	log logger.Logger
+}
+
+func NewSequenceRepository(db *gorm.DB) SequenceRepository {
+	return &sequenceRepository{
+		db:  db,
+		log: logger.Get(),
+	}
+}
+
+// Next atomically increments the named counter, creating it at 1
+func (r *sequenceRepository) Next(ctx context.Context, name string) (uint64, error) {
+	var value uint64
+	err := r.db.WithContext(ctx).Raw(`INSERT INTO sequences (name, value) VALUES (?, 1)
+		ON CONFLICT (name) DO UPDATE SET value = value + 1
+		RETURNING value`, name).Scan(&value).Error
+	if err != nil {
+		r.log.Error("Failed to advance sequence",
+			logger.NamedError("error", err),
+			logger.String("name", name))
+	}
+	return value, err
+}
@@ -0,0 +1,242 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/imraushankr/brevity/server/src/internal/models"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/logger"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// urlTag is a row of the url_tags join table
+type urlTag struct {
+	URLID string `gorm:"column:url_id"`
+	TagID string `gorm:"column:tag_id"`
+}
+
+func (urlTag) TableName() string {
+	return "url_tags"
+}
+
+// tagURLCount counts the live links carrying a tag
+const tagURLCount = `(SELECT COUNT(*) FROM url_tags JOIN urls ON urls.id = url_tags.url_id
+	WHERE url_tags.tag_id = tags.id AND urls.deleted_at IS NULL) AS url_count`
+
+type tagRepository struct {
+	db  *gorm.DB
+	log logger.Logger
+}
+
+func NewTagRepository(db *gorm.DB) TagRepository {
+	return &tagRepository{
+		db:  db,
+		log: logger.Get(),
+	}
+}
+
+// withTags loads the tags of URLs, in name order
+func withTags(db *gorm.DB) *gorm.DB {
+	return db.Preload("Tags", func(db *gorm.DB) *gorm.DB {
+		return db.Order("tags.name")
+	})
+}
+
+// ensureTags returns the user's tags with the given names in the same order,
+// creating the ones that do not exist yet
+func ensureTags(tx *gorm.DB, userID string, names []string) ([]models.Tag, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	var existing []models.Tag
+	if err := tx.Where("user_id = ? AND name IN ?", userID, names).Find(&existing).Error; err != nil {
+		return nil, err
+	}
+	byName := make(map[string]models.Tag, len(names))
+	for _, tag := range existing {
+		byName[tag.Name] = tag
+	}
+
+	created := false
+	for _, name := range names {
+		if _, ok := byName[name]; ok {
+			continue
+		}
+		// A concurrent request may create the same tag, so it is read back
+		// rather than trusting the generated ID
+		tag := models.Tag{UserID: userID, Name: name}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&tag).Error; err != nil {
+			return nil, err
+		}
+		created = true
+	}
+	if created {
+		existing = existing[:0]
+		if err := tx.Where("user_id = ? AND name IN ?", userID, names).Find(&existing).Error; err != nil {
+			return nil, err
+		}
+		for _, tag := range existing {
+			byName[tag.Name] = tag
+		}
+	}
+
+	tags := make([]models.Tag, 0, len(names))
+	for _, name := range names {
+		if tag, ok := byName[name]; ok {
+			tags = append(tags, tag)
+		}
+	}
+	return tags, nil
+}
+
+// saveURLTags attaches the tags named in each url's Tags to it, creating
+// missing ones, and fills in their IDs
+func saveURLTags(tx *gorm.DB, urls ...*models.URL) error {
+	for _, url := range urls {
+		if len(url.Tags) == 0 {
+			continue
+		}
+		tags, err := ensureTags(tx, url.UserID, url.TagNames())
+		if err != nil {
+			return err
+		}
+		rows := make([]urlTag, len(tags))
+		for i, tag := range tags {
+			rows[i] = urlTag{URLID: url.ID, TagID: tag.ID}
+		}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&rows).Error; err != nil {
+			return err
+		}
+		url.Tags = tags
+	}
+	return nil
+}
+
+// ListByUser returns all of a user's tags by name, with how many links carry
+// each
+func (r *tagRepository) ListByUser(ctx context.Context, userID string) ([]models.Tag, error) {
+	var tags []models.Tag
+	err := r.db.WithContext(ctx).
+		Select("tags.*, "+tagURLCount).
+		Where("user_id = ?", userID).
+		Order("name").
+		Find(&tags).Error
+	if err != nil {
+		r.log.Error("Failed to list tags",
+			logger.NamedError("error", err),
+			logger.String("userID", userID))
+		return nil, err
+	}
+	return tags, nil
+}
+
+func (r *tagRepository) FindByID(ctx context.Context, id string) (*models.Tag, error) {
+	var tag models.Tag
+	err := r.db.WithContext(ctx).Select("tags.*, "+tagURLCount).Where("id = ?", id).First(&tag).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrTagNotFound
+	}
+	if err != nil {
+		r.log.Error("Failed to find tag", logger.NamedError("error", err))
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// Delete removes a tag from every link and deletes it
+func (r *tagRepository) Delete(ctx context.Context, id string) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tag_id = ?", id).Delete(&urlTag{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("id = ?", id).Delete(&models.Tag{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return models.ErrTagNotFound
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, models.ErrTagNotFound) {
+		r.log.Error("Failed to delete tag",
+			logger.NamedError("error", err),
+			logger.String("tagID", id))
+	}
+	return err
+}
+
+// ownedURLs selects the IDs among urlIDs of live links userID owns
+func (r *tagRepository) ownedURLs(tx *gorm.DB, userID string, urlIDs []string) *gorm.DB {
+	return tx.Model(&models.URL{}).
+		Scopes(notDeleted).
+		Select("id").
+		Where("id IN ? AND user_id = ?", urlIDs, userID)
+}
+
+// AddToURLs tags the links among urlIDs owned by userID, creating missing
+// tags. It returns how many links were found and how many tag assignments
+// were added.
+func (r *tagRepository) AddToURLs(ctx context.Context, userID string, urlIDs, names []string) (int64, int64, error) {
+	var found, added int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var ids []string
+		if err := r.ownedURLs(tx, userID, urlIDs).Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		found = int64(len(ids))
+		if found == 0 {
+			return nil
+		}
+
+		tags, err := ensureTags(tx, userID, names)
+		if err != nil {
+			return err
+		}
+		rows := make([]urlTag, 0, len(ids)*len(tags))
+		for _, id := range ids {
+			for _, tag := range tags {
+				rows = append(rows, urlTag{URLID: id, TagID: tag.ID})
+			}
+		}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&rows, urlInsertBatchSize)
+		added = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		r.log.Error("Failed to tag urls",
+			logger.NamedError("error", err),
+			logger.String("userID", userID))
+		return 0, 0, err
+	}
+	return found, added, nil
+}
+
+// RemoveFromURLs untags the links among urlIDs owned by userID. Names that
+// are not tags of the user are ignored. It returns how many links were found
+// and how many tag assignments were removed.
+func (r *tagRepository) RemoveFromURLs(ctx context.Context, userID string, urlIDs, names []string) (int64, int64, error) {
+	var found, removed int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := r.ownedURLs(tx, userID, urlIDs).Count(&found).Error; err != nil {
+			return err
+		}
+		if found == 0 {
+			return nil
+		}
+
+		tagIDs := tx.Model(&models.Tag{}).Select("id").Where("user_id = ? AND name IN ?", userID, names)
+		result := tx.Where("url_id IN (?) AND tag_id IN (?)", r.ownedURLs(tx, userID, urlIDs), tagIDs).Delete(&urlTag{})
+		removed = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		r.log.Error("Failed to untag urls",
+			logger.NamedError("error", err),
+			logger.String("userID", userID))
+		return 0, 0, err
+	}
+	return found, removed, nil
+}
@@ -1 +1,484 @@
-package repository
\ No newline at end of file
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/imraushankr/brevity/server/src/internal/models"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/pagination"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const urlInsertBatchSize = 100
+
+type urlRepository struct {
+	db  *gorm.DB
+	log logger.Logger
+}
+
+func NewURLRepository(db *gorm.DB) URLRepository {
+	return &urlRepository{
+		db:  db,
+		log: logger.Get(),
+	}
+}
+
+// notDeleted scopes a query to URLs that have not been soft deleted
+func notDeleted(db *gorm.DB) *gorm.DB {
+	return db.Where("deleted_at IS NULL")
+}
+
+// withDomain loads the custom domain of URLs that have one, which their
+// short urls are built from
+func withDomain(db *gorm.DB) *gorm.DB {
+	return db.Preload("Domain")
+}
+
+func (r *urlRepository) Create(ctx context.Context, url *models.URL) error {
+	r.log.Debug("Creating new url",
+		logger.String("shortCode", url.ShortCode),
+		logger.String("userID", url.UserID))
+	if err := url.Validate(); err != nil {
+		r.log.Error("URL validation failed", logger.NamedError("error", err))
+		return err
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(url).Error; err != nil {
+			return err
+		}
+		return saveURLTags(tx, url)
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return models.ErrShortCodeExists
+	}
+	if err != nil {
+		r.log.Error("Failed to create url", logger.NamedError("error", err))
+	}
+	return err
+}
+
+// CreateBatch inserts all urls in one transaction. Nothing is stored if any
+// of them fails.
+func (r *urlRepository) CreateBatch(ctx context.Context, urls []*models.URL) error {
+	r.log.Debug("Creating url batch", logger.Int("count", len(urls)))
+	for _, url := range urls {
+		if err := url.Validate(); err != nil {
+			r.log.Error("URL validation failed", logger.NamedError("error", err))
+			return err
+		}
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).CreateInBatches(urls, urlInsertBatchSize).Error; err != nil {
+			return err
+		}
+		return saveURLTags(tx, urls...)
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return models.ErrShortCodeExists
+	}
+	if err != nil {
+		r.log.Error("Failed to create url batch", logger.NamedError("error", err))
+	}
+	return err
+}
+
+func (r *urlRepository) FindByID(ctx context.Context, id string) (*models.URL, error) {
+	r.log.Debug("Finding url by id", logger.String("urlID", id))
+
+	var url models.URL
+	err := r.db.WithContext(ctx).Scopes(notDeleted, withDomain, withTags).Where("id = ?", id).First(&url).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		r.log.Debug("URL not found", logger.String("urlID", id))
+		return nil, models.ErrURLNotFound
+	}
+	if err != nil {
+		r.log.Error("Failed to find url", logger.NamedError("error", err))
+		return nil, err
+	}
+	return &url, nil
+}
+
+func (r *urlRepository) FindByShortCode(ctx context.Context, domainID, shortCode string) (*models.URL, error) {
+	r.log.Debug("Finding url by short code",
+		logger.String("domainID", domainID),
+		logger.String("shortCode", shortCode))
+
+	var url models.URL
+	err := r.db.WithContext(ctx).Scopes(notDeleted, withDomain).
+		Where("domain_id = ? AND short_code = ?", domainID, shortCode).
+		First(&url).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		r.log.Debug("URL not found", logger.String("shortCode", shortCode))
+		return nil, models.ErrURLNotFound
+	}
+	if err != nil {
+		r.log.Error("Failed to find url", logger.NamedError("error", err))
+		return nil, err
+	}
+	return &url, nil
+}
+
+// urlList is what link listings can be sorted and filtered on
+var urlList = pagination.Resource[models.URL]{
+	Key: pagination.Field[models.URL]{
+		Column: "urls.id",
+		Value:  func(u *models.URL) any { return u.ID },
+	},
+	DefaultSort: "-created_at",
+	Fields: map[string]pagination.Field[models.URL]{
+		"created_at": {
+			Column:   "urls.created_at",
+			Kind:     pagination.Time,
+			Ops:      rangeOps,
+			Sortable: true,
+			Value:    func(u *models.URL) any { return u.CreatedAt },
+		},
+		"title": {
+			Column:   "COALESCE(urls.title, '')",
+			Ops:      []pagination.Op{pagination.Eq, pagination.Contains},
+			Sortable: true,
+			Value:    func(u *models.URL) any { return u.Title },
+		},
+		"short_code": {
+			Column:   "urls.short_code",
+			Ops:      []pagination.Op{pagination.Eq, pagination.Contains},
+			Sortable: true,
+			Value:    func(u *models.URL) any { return u.ShortCode },
+		},
+		"clicks": {
+			Column:   "urls.clicks",
+			Kind:     pagination.Int,
+			Ops:      append([]pagination.Op{pagination.Eq}, rangeOps...),
+			Sortable: true,
+			Value:    func(u *models.URL) any { return u.Clicks },
+		},
+		"expires_at": {
+			Column: "urls.expires_at",
+			Kind:   pagination.Time,
+			Ops:    rangeOps,
+		},
+		"is_active": {
+			Column: "urls.is_active",
+			Kind:   pagination.Bool,
+			Ops:    []pagination.Op{pagination.Eq},
+		},
+		"domain_id": {
+			Column: "urls.domain_id",
+			Ops:    []pagination.Op{pagination.Eq, pagination.In},
+		},
+		"campaign_id": {
+			Column: "urls.campaign_id",
+			Ops:    []pagination.Op{pagination.Eq, pagination.In},
+		},
+		// Repeating filter[tag] requires every tag, filter[tag][in] any of them
+		"tag": {
+			Ops:   []pagination.Op{pagination.Eq, pagination.In},
+			Parse: parseTagName,
+			Apply: func(db *gorm.DB, op pagination.Op, value any) *gorm.DB {
+				return db.Where(hasTag(pagination.Compare("tags.name", op)), value)
+			},
+		},
+	},
+}
+
+// rangeOps are the filter operators of ordered fields
+var rangeOps = []pagination.Op{pagination.Gt, pagination.Gte, pagination.Lt, pagination.Lte}
+
+// hasTag matches urls with a tag whose name satisfies cond
+func hasTag(cond string) string {
+	return `EXISTS (SELECT 1 FROM url_tags JOIN tags ON tags.id = url_tags.tag_id
+		WHERE url_tags.url_id = urls.id AND ` + cond + `)`
+}
+
+func parseTagName(s string) (any, error) {
+	names, err := models.NormalizeTags([]string{s})
+	if err != nil {
+		return nil, err
+	}
+	return names[0], nil
+}
+
+// FindByUserID lists a page of the user's links
+func (r *urlRepository) FindByUserID(ctx context.Context, userID string, q *pagination.Query) ([]models.URL, *pagination.Page, error) {
+	r.log.Debug("Listing urls for user",
+		logger.String("userID", userID),
+		logger.Int("limit", q.Limit),
+		logger.String("sort", q.Sort))
+
+	query := r.db.WithContext(ctx).Model(&models.URL{}).Scopes(notDeleted).Where("user_id = ?", userID)
+	urls, page, err := urlList.Find(query, q, withDomain, withTags)
+	if err != nil {
+		if !errors.Is(err, pagination.ErrInvalid) {
+			r.log.Error("Failed to list urls", logger.NamedError("error", err))
+		}
+		return nil, nil, err
+	}
+	return urls, page, nil
+}
+
+// searchRanking weighs matches in the columns of urls_fts: title,
+// description, original_url and short_code
+const searchRanking = "bm25(urls_fts, 10, 2, 4, 8)"
+
+// Search finds the links matching an FTS5 match expression, best match
+// first. Deleted links are left out, but inactive and expired ones are
+// found too. An empty userID searches every user's links.
+func (r *urlRepository) Search(ctx context.Context, userID, match string, offset, limit int) ([]models.URLSearchHit, int64, error) {
+	r.log.Debug("Searching urls",
+		logger.String("userID", userID),
+		logger.String("match", match))
+
+	query := r.db.WithContext(ctx).
+		Table("urls_fts").
+		Joins("JOIN urls ON urls.search_key = urls_fts.rowid").
+		Where("urls_fts MATCH ?", match).
+		Where("urls.deleted_at IS NULL")
+	if userID != "" {
+		query = query.Where("urls.user_id = ?", userID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		r.log.Error("Failed to count url search results", logger.NamedError("error", err))
+		return nil, 0, err
+	}
+	if total == 0 {
+		return nil, 0, nil
+	}
+
+	var rows []struct {
+		URLID       string
+		SearchRank  float64
+		Title       string
+		Description string
+		OriginalURL string
+		ShortCode   string
+	}
+	start, end := models.HighlightStart, models.HighlightEnd
+	err := query.
+		Select("urls.id AS url_id, "+searchRanking+" AS search_rank, "+
+			"highlight(urls_fts, 0, ?, ?) AS title, "+
+			"snippet(urls_fts, 1, ?, ?, '…', 24) AS description, "+
+			"highlight(urls_fts, 2, ?, ?) AS original_url, "+
+			"highlight(urls_fts, 3, ?, ?) AS short_code",
+			start, end, start, end, start, end, start, end).
+		Order("search_rank, urls.created_at DESC").
+		Offset(offset).
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		r.log.Error("Failed to search urls", logger.NamedError("error", err))
+		return nil, 0, err
+	}
+
+	ids := make([]string, len(rows))
+	for i, row := range rows {
+		ids[i] = row.URLID
+	}
+	var urls []models.URL
+	if err := r.db.WithContext(ctx).Scopes(withDomain, withTags).Where("id IN ?", ids).Find(&urls).Error; err != nil {
+		r.log.Error("Failed to load url search results", logger.NamedError("error", err))
+		return nil, 0, err
+	}
+	byID := make(map[string]*models.URL, len(urls))
+	for i := range urls {
+		byID[urls[i].ID] = &urls[i]
+	}
+
+	hits := make([]models.URLSearchHit, 0, len(rows))
+	for _, row := range rows {
+		url, ok := byID[row.URLID]
+		if !ok {
+			// Deleted between the two queries
+			continue
+		}
+		hits = append(hits, models.URLSearchHit{
+			URL: *url,
+			// bm25 scores are negative, better matches being lower
+			Score: -row.SearchRank,
+			Highlights: models.URLHighlights{
+				Title:       models.MarkHighlights(row.Title),
+				Description: models.MarkHighlights(row.Description),
+				OriginalURL: models.MarkHighlights(row.OriginalURL),
+				ShortCode:   models.MarkHighlights(row.ShortCode),
+			},
+		})
+	}
+	return hits, total, nil
+}
+
+// StreamByUserID walks a user's urls in creation order, calling fn with each
+// batch. It pages with a (created_at, id) cursor so memory use does not grow
+// with the account size.
+func (r *urlRepository) StreamByUserID(ctx context.Context, userID string, filter *models.URLExportFilter, batchSize int, fn func([]models.URL) error) error {
+	r.log.Debug("Streaming urls for user", logger.String("userID", userID))
+
+	query := r.db.WithContext(ctx).Model(&models.URL{}).Scopes(notDeleted).Where("user_id = ?", userID)
+	if filter.CreatedFrom != nil {
+		query = query.Where("created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		query = query.Where("created_at < ?", *filter.CreatedTo)
+	}
+	if filter.IsActive != nil {
+		query = query.Where("is_active = ?", *filter.IsActive)
+	}
+	for _, tag := range filter.Tags {
+		query = query.Where(hasTag("tags.name = ?"), tag)
+	}
+
+	var last *models.URL
+	for {
+		page := query.Session(&gorm.Session{})
+		if last != nil {
+			page = page.Where("(created_at > ? OR (created_at = ? AND id > ?))", last.CreatedAt, last.CreatedAt, last.ID)
+		}
+
+		var urls []models.URL
+		if err := page.Scopes(withDomain).Order("created_at, id").Limit(batchSize).Find(&urls).Error; err != nil {
+			r.log.Error("Failed to stream urls", logger.NamedError("error", err))
+			return err
+		}
+		if len(urls) == 0 {
+			return nil
+		}
+		if err := fn(urls); err != nil {
+			return err
+		}
+		if len(urls) < batchSize {
+			return nil
+		}
+		last = &urls[len(urls)-1]
+	}
+}
+
+func (r *urlRepository) ShortCodeExists(ctx context.Context, domainID, shortCode string) (bool, error) {
+	// Soft deleted rows still hold the unique constraint, so they are counted here
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.URL{}).
+		Where("domain_id = ? AND short_code = ?", domainID, shortCode).
+		Count(&count).Error
+	if err != nil {
+		r.log.Error("Failed to check short code", logger.NamedError("error", err))
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// editableColumns are the url columns its owner can change. The others are
+// kept by the click recorder, ClaimClick, the link scheduler and the
+// preview, safety and health workers, so writing back a copy of the row read
+// earlier would undo their changes.
+var editableColumns = []string{
+	"title", "description", "activates_at", "expires_at", "is_active",
+	"fallback_url", "rules", "variants", "forward_query", "redirect_type",
+	"utm_source", "utm_medium", "utm_campaign", "utm_term", "utm_content",
+	"og_title", "og_description", "og_image_url", "qr_logo_url",
+	"password_hash", "max_clicks", "campaign_id", "updated_at",
+}
+
+// Update writes the owner editable columns of url
+func (r *urlRepository) Update(ctx context.Context, url *models.URL) error {
+	r.log.Debug("Updating url", logger.String("urlID", url.ID))
+	err := r.db.WithContext(ctx).Model(url).Select(editableColumns).Updates(url).Error
+	if err != nil {
+		r.log.Error("Failed to update url", logger.NamedError("error", err))
+	}
+	return err
+}
+
+// ReplaceTags sets the url's tags to those named in url.Tags, creating
+// missing ones, and fills in their IDs
+func (r *urlRepository) ReplaceTags(ctx context.Context, url *models.URL) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("url_id = ?", url.ID).Delete(&urlTag{}).Error; err != nil {
+			return err
+		}
+		return saveURLTags(tx, url)
+	})
+	if err != nil {
+		r.log.Error("Failed to replace url tags",
+			logger.NamedError("error", err),
+			logger.String("urlID", url.ID))
+	}
+	return err
+}
+
+// ClaimClick spends one click of a click limited url and returns the new
+// claimed count. The conditional update makes concurrent redirects safe: once
+// the limit is reached no further click is claimed and ErrURLExhausted is
+// returned.
+func (r *urlRepository) ClaimClick(ctx context.Context, id string) (int, error) {
+	var claimed []int
+	err := r.db.WithContext(ctx).Raw(`UPDATE urls SET claimed_clicks = claimed_clicks + 1
+		WHERE id = ? AND deleted_at IS NULL AND max_clicks IS NOT NULL AND claimed_clicks < max_clicks
+		RETURNING claimed_clicks`, id).Scan(&claimed).Error
+	if err != nil {
+		r.log.Error("Failed to claim url click",
+			logger.NamedError("error", err),
+			logger.String("urlID", id))
+		return 0, err
+	}
+	if len(claimed) == 0 {
+		return 0, models.ErrURLExhausted
+	}
+	return claimed[0], nil
+}
+
+func (r *urlRepository) Deactivate(ctx context.Context, id string) error {
+	r.log.Debug("Deactivating url", logger.String("urlID", id))
+	err := r.db.WithContext(ctx).
+		Model(&models.URL{}).
+		Scopes(notDeleted).
+		Where("id = ?", id).
+		// Dropping the activation time keeps the scheduler from switching
+		// the link back on
+		Updates(map[string]interface{}{
+			"is_active":    false,
+			"activates_at": nil,
+		}).Error
+	if err != nil {
+		r.log.Error("Failed to deactivate url", logger.NamedError("error", err))
+	}
+	return err
+}
+
+// ExpiredBySchedule reports whether the last is_active flip of the url was
+// the scheduler switching it off at its expiry time
+func (r *urlRepository) ExpiredBySchedule(ctx context.Context, id string) (bool, error) {
+	var reasons []models.TransitionReason
+	err := r.db.WithContext(ctx).
+		Model(&models.URLTransition{}).
+		Where("url_id = ?", id).
+		Order("created_at DESC").
+		Limit(1).
+		Pluck("reason", &reasons).Error
+	if err != nil {
+		r.log.Error("Failed to find url transition",
+			logger.NamedError("error", err),
+			logger.String("urlID", id))
+		return false, err
+	}
+	return len(reasons) > 0 && reasons[0] == models.TransitionExpired, nil
+}
+
+func (r *urlRepository) Delete(ctx context.Context, id string) error {
+	r.log.Debug("Deleting url", logger.String("urlID", id))
+	err := r.db.WithContext(ctx).
+		Model(&models.URL{}).
+		Scopes(notDeleted).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"is_active":  false,
+			"deleted_at": time.Now(),
+		}).Error
+	if err != nil {
+		r.log.Error("Failed to delete url", logger.NamedError("error", err))
+	}
+	return err
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/imraushankr/brevity/server/src/internal/models"
 	"github.com/imraushankr/brevity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/pagination"
 	"gorm.io/gorm"
 )
 
@@ -161,4 +162,70 @@ func (r *userRepository) UpdateAvatar(ctx context.Context, userID, avatarURL str
 			logger.String("userID", userID))
 	}
 	return err
-}
\ No newline at end of file
+}
+
+// userList is what user listings can be sorted and filtered on
+var userList = pagination.Resource[models.User]{
+	Key: pagination.Field[models.User]{
+		Column: "users.id",
+		Value:  func(u *models.User) any { return u.ID },
+	},
+	DefaultSort: "-created_at",
+	Fields: map[string]pagination.Field[models.User]{
+		"created_at": {
+			Column:   "users.created_at",
+			Kind:     pagination.Time,
+			Ops:      rangeOps,
+			Sortable: true,
+			Value:    func(u *models.User) any { return u.CreatedAt },
+		},
+		"username": {
+			Column:   "users.username",
+			Ops:      []pagination.Op{pagination.Eq, pagination.Contains},
+			Sortable: true,
+			Value:    func(u *models.User) any { return u.Username },
+		},
+		"email": {
+			Column:   "users.email",
+			Ops:      []pagination.Op{pagination.Eq, pagination.Contains},
+			Sortable: true,
+			Value:    func(u *models.User) any { return u.Email },
+		},
+		"role": {
+			Column: "users.role",
+			Ops:    []pagination.Op{pagination.Eq, pagination.In},
+		},
+		"is_active": {
+			Column: "users.is_active",
+			Kind:   pagination.Bool,
+			Ops:    []pagination.Op{pagination.Eq},
+		},
+		"is_verified": {
+			Column: "users.is_verified",
+			Kind:   pagination.Bool,
+			Ops:    []pagination.Op{pagination.Eq},
+		},
+		"last_login_at": {
+			Column: "users.last_login_at",
+			Kind:   pagination.Time,
+			Ops:    rangeOps,
+		},
+	},
+}
+
+// List returns a page of all users
+func (r *userRepository) List(ctx context.Context, q *pagination.Query) ([]models.User, *pagination.Page, error) {
+	r.log.Debug("Listing users",
+		logger.Int("limit", q.Limit),
+		logger.String("sort", q.Sort))
+
+	query := r.db.WithContext(ctx).Model(&models.User{}).Where("deleted_at IS NULL")
+	users, page, err := userList.Find(query, q)
+	if err != nil {
+		if !errors.Is(err, pagination.ErrInvalid) {
+			r.log.Error("Failed to list users", logger.NamedError("error", err))
+		}
+		return nil, nil, err
+	}
+	return users, page, nil
+}
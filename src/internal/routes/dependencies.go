@@ -0,0 +1,40 @@
+package routes
+
+import (
+	"github.com/imraushankr/brevity/server/src/configs"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/auth"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/database"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/email"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/geoip"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/storage"
+	"github.com/imraushankr/brevity/server/src/internal/repository"
+	"github.com/imraushankr/brevity/server/src/internal/services"
+)
+
+// Dependencies holds everything the routes are built from. The server
+// creates it once, so the API and background jobs share the same
+// repositories and services.
+type Dependencies struct {
+	Config     *configs.Config
+	DB         *database.DB
+	Log        logger.Logger
+	Auth       *auth.Auth
+	Storage    storage.Storage
+	Email      *email.EmailService
+	GeoLocator *geoip.Locator
+	Repos      *repository.Repositories
+
+	ClickRecorder services.ClickRecorder
+	Users         services.UserService
+	URLs          services.URLService
+	Analytics     services.AnalyticsService
+	QR            services.QRService
+	Domains       services.DomainService
+	Exports       services.ExportService
+	Tags          services.TagService
+	Campaigns     services.CampaignService
+	LinkSafety    services.LinkSafetyService
+	LinkHealth    services.LinkHealthService
+	BulkImports   services.BulkImportService
+}
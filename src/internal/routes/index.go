@@ -1,47 +1,40 @@
 package routes
 
 import (
-	"fmt"
-
 	"github.com/gin-gonic/gin"
-	"github.com/imraushankr/brevity/server/src/configs"
 	"github.com/imraushankr/brevity/server/src/internal/handlers/middleware"
 	handlersV1 "github.com/imraushankr/brevity/server/src/internal/handlers/v1"
-	"github.com/imraushankr/brevity/server/src/internal/pkg/auth"
-	"github.com/imraushankr/brevity/server/src/internal/pkg/database"
-	"github.com/imraushankr/brevity/server/src/internal/pkg/email"
-	"github.com/imraushankr/brevity/server/src/internal/pkg/logger"
-	"github.com/imraushankr/brevity/server/src/internal/pkg/storage"
-	"github.com/imraushankr/brevity/server/src/internal/repository"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/useragent"
 	routesV1 "github.com/imraushankr/brevity/server/src/internal/routes/v1"
-	"github.com/imraushankr/brevity/server/src/internal/services"
 )
 
-func SetupRoutes(router *gin.Engine, cfg *configs.Config, db *database.DB, authService *auth.Auth, log logger.Logger) (*gin.Engine, error) {
+func SetupRoutes(router *gin.Engine, deps *Dependencies) (*gin.Engine, error) {
+	cfg := deps.Config
+	authService := deps.Auth
+
 	// Global middleware
 	router.Use(
 		gin.Recovery(),
-		middleware.RequestLogger(log),
+		middleware.RequestLogger(deps.Log),
 		middleware.CORS(),
 		middleware.RateLimiter(100, 10),
 		middleware.PrometheusMetricsMiddleware(),
 	)
 
-	// Initialize storage service
-	storageService, err := storage.NewStorage(cfg)
-	if err != nil {
-		return nil, fmt.Errorf("failed to initialize storage: %w", err)
-	}
-
-	// Initialize services
-	userSvc, err := initUserService(cfg, db, authService, storageService, log)
-	if err != nil {
-		return nil, fmt.Errorf("failed to initialize user service: %w", err)
-	}
-
 	// Initialize handlers
 	healthHandler := handlersV1.NewHealthHandler(cfg)
-	userHandler := handlersV1.NewUserHandler(userSvc)
+	userHandler := handlersV1.NewUserHandler(deps.Users)
+	urlHandler := handlersV1.NewURLHandler(deps.URLs, cfg)
+	analyticsHandler := handlersV1.NewAnalyticsHandler(deps.Analytics)
+	bulkHandler := handlersV1.NewBulkHandler(deps.URLs, deps.BulkImports, cfg)
+	exportHandler := handlersV1.NewExportHandler(deps.Exports, cfg)
+	domainHandler := handlersV1.NewDomainHandler(deps.Domains, cfg)
+	tagHandler := handlersV1.NewTagHandler(deps.Tags)
+	campaignHandler := handlersV1.NewCampaignHandler(deps.Campaigns)
+	qrHandler := handlersV1.NewQRHandler(deps.QR, cfg)
+	linkSafetyHandler := handlersV1.NewLinkSafetyHandler(deps.LinkSafety, cfg)
+	linkHealthHandler := handlersV1.NewLinkHealthHandler(deps.LinkHealth, cfg)
+	redirectHandler := handlersV1.NewRedirectHandler(deps.URLs, deps.ClickRecorder, useragent.Default(), deps.GeoLocator, cfg)
 
 	// API routes
 	api := router.Group("/api")
@@ -51,26 +44,24 @@ func SetupRoutes(router *gin.Engine, cfg *configs.Config, db *database.DB, authS
 		{
 			routesV1.RegisterAuthRoutes(v1Group, userHandler, authService, cfg)
 			routesV1.RegisterUserRoutes(v1Group, userHandler, authService, cfg)
+			routesV1.RegisterURLRoutes(v1Group, urlHandler, authService, cfg)
+			routesV1.RegisterLinkHealthRoutes(v1Group, linkHealthHandler, authService, cfg)
+			routesV1.RegisterAnalyticsRoutes(v1Group, analyticsHandler, authService, cfg)
+			routesV1.RegisterBulkRoutes(v1Group, bulkHandler, authService, cfg)
+			routesV1.RegisterExportRoutes(v1Group, exportHandler, authService, cfg)
+			routesV1.RegisterDomainRoutes(v1Group, domainHandler, authService, cfg)
+			routesV1.RegisterTagRoutes(v1Group, tagHandler, authService, cfg)
+			routesV1.RegisterCampaignRoutes(v1Group, campaignHandler, authService, cfg)
+			routesV1.RegisterQRRoutes(v1Group, qrHandler, authService, cfg)
+			routesV1.RegisterAdminRoutes(v1Group, linkSafetyHandler, authService, cfg)
 			routesV1.RegisterSystemRoutes(v1Group, healthHandler)
 		}
 
 		// Add future version groups here (v2, etc.)
 	}
 
+	// Public short link redirects
+	RegisterRedirectRoutes(router, redirectHandler, cfg.App.UploadDir)
+
 	return router, nil
 }
-
-func initUserService(
-	cfg *configs.Config,
-	db *database.DB,
-	authService *auth.Auth,
-	storageService storage.Storage,
-	log logger.Logger,
-) (services.UserService, error) {
-	emailService := email.NewEmailService(&cfg.Email, log)
-	userRepo := repository.NewUserRepository(db.DB)
-
-	userSvc := services.NewUserService(userRepo, authService, emailService, cfg, storageService)
-
-	return userSvc, nil
-}
\ No newline at end of file
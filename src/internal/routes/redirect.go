@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	handlersV1 "github.com/imraushankr/brevity/server/src/internal/handlers/v1"
+)
+
+// RegisterRedirectRoutes mounts the public short link resolver at the root of
+// the engine. Gin always prefers static segments, so /api/... and /uploads/...
+// keep working alongside the /:short_code wildcard.
+func RegisterRedirectRoutes(router *gin.Engine, handler *handlersV1.RedirectHandler, uploadDir string) {
+	// Files written by storage.LocalStorage
+	router.Static("/uploads", uploadDir)
+
+	router.GET("/:short_code", handler.Redirect)
+	router.HEAD("/:short_code", handler.Redirect)
+	router.POST("/:short_code", handler.Unlock)
+}
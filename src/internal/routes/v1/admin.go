@@ -0,0 +1,24 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/imraushankr/brevity/server/src/configs"
+	"github.com/imraushankr/brevity/server/src/internal/handlers/middleware"
+	"github.com/imraushankr/brevity/server/src/internal/handlers/v1"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/auth"
+)
+
+func RegisterAdminRoutes(r *gin.RouterGroup, safetyHandler *v1.LinkSafetyHandler, authService *auth.Auth, cfg *configs.Config) {
+	// Admin-only routes
+	adminGroup := r.Group("/admin", middleware.AuthMiddleware(authService, &cfg.JWT), middleware.RoleMiddleware("admin"))
+	{
+		// Destination blocklist
+		adminGroup.POST("/blocklist", safetyHandler.AddBlocklistEntry)
+		adminGroup.GET("/blocklist", safetyHandler.ListBlocklistEntries)
+		adminGroup.DELETE("/blocklist/:id", safetyHandler.DeleteBlocklistEntry)
+
+		// Review queue of links disabled as unsafe
+		adminGroup.GET("/flags", safetyHandler.ListLinkFlags)
+		adminGroup.POST("/flags/:id/review", safetyHandler.ReviewLinkFlag)
+	}
+}
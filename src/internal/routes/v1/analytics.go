@@ -0,0 +1,23 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/imraushankr/brevity/server/src/configs"
+	"github.com/imraushankr/brevity/server/src/internal/handlers/middleware"
+	"github.com/imraushankr/brevity/server/src/internal/handlers/v1"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/auth"
+)
+
+func RegisterAnalyticsRoutes(r *gin.RouterGroup, handler *v1.AnalyticsHandler, authService *auth.Auth, cfg *configs.Config) {
+	// Authenticated routes, ownership is checked by the service
+	analyticsGroup := r.Group("/urls", middleware.AuthMiddleware(authService, &cfg.JWT))
+	{
+		analyticsGroup.GET("/:id/analytics", handler.GetURLAnalytics)
+	}
+
+	groupAnalytics := r.Group("", middleware.AuthMiddleware(authService, &cfg.JWT))
+	{
+		groupAnalytics.GET("/tags/:id/analytics", handler.GetTagAnalytics)
+		groupAnalytics.GET("/campaigns/:id/analytics", handler.GetCampaignAnalytics)
+	}
+}
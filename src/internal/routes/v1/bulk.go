@@ -0,0 +1,18 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/imraushankr/brevity/server/src/configs"
+	"github.com/imraushankr/brevity/server/src/internal/handlers/middleware"
+	"github.com/imraushankr/brevity/server/src/internal/handlers/v1"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/auth"
+)
+
+func RegisterBulkRoutes(r *gin.RouterGroup, handler *v1.BulkHandler, authService *auth.Auth, cfg *configs.Config) {
+	// Authenticated routes
+	bulkGroup := r.Group("/urls/bulk", middleware.AuthMiddleware(authService, &cfg.JWT))
+	{
+		bulkGroup.POST("", handler.BulkCreateURLs)
+		bulkGroup.GET("/:job_id", handler.GetBulkImport)
+	}
+}
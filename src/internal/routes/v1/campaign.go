@@ -0,0 +1,21 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/imraushankr/brevity/server/src/configs"
+	"github.com/imraushankr/brevity/server/src/internal/handlers/middleware"
+	"github.com/imraushankr/brevity/server/src/internal/handlers/v1"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/auth"
+)
+
+func RegisterCampaignRoutes(r *gin.RouterGroup, handler *v1.CampaignHandler, authService *auth.Auth, cfg *configs.Config) {
+	// Authenticated routes
+	campaignGroup := r.Group("/campaigns", middleware.AuthMiddleware(authService, &cfg.JWT))
+	{
+		campaignGroup.POST("", handler.CreateCampaign)
+		campaignGroup.GET("", handler.ListCampaigns)
+		campaignGroup.GET("/:id", handler.GetCampaign)
+		campaignGroup.PUT("/:id", handler.UpdateCampaign)
+		campaignGroup.DELETE("/:id", handler.DeleteCampaign)
+	}
+}
@@ -0,0 +1,21 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/imraushankr/brevity/server/src/configs"
+	"github.com/imraushankr/brevity/server/src/internal/handlers/middleware"
+	"github.com/imraushankr/brevity/server/src/internal/handlers/v1"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/auth"
+)
+
+func RegisterDomainRoutes(r *gin.RouterGroup, handler *v1.DomainHandler, authService *auth.Auth, cfg *configs.Config) {
+	// Authenticated routes
+	domainGroup := r.Group("/domains", middleware.AuthMiddleware(authService, &cfg.JWT))
+	{
+		domainGroup.POST("", handler.AddDomain)
+		domainGroup.GET("", handler.ListDomains)
+		domainGroup.GET("/:id", handler.GetDomain)
+		domainGroup.POST("/:id/verify", handler.VerifyDomain)
+		domainGroup.DELETE("/:id", handler.DeleteDomain)
+	}
+}
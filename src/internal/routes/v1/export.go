@@ -0,0 +1,18 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/imraushankr/brevity/server/src/configs"
+	"github.com/imraushankr/brevity/server/src/internal/handlers/middleware"
+	"github.com/imraushankr/brevity/server/src/internal/handlers/v1"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/auth"
+)
+
+func RegisterExportRoutes(r *gin.RouterGroup, handler *v1.ExportHandler, authService *auth.Auth, cfg *configs.Config) {
+	// Authenticated routes
+	exportGroup := r.Group("/urls", middleware.AuthMiddleware(authService, &cfg.JWT))
+	{
+		exportGroup.GET("/export", handler.ExportURLs)
+		exportGroup.GET("/:id/clicks/export", handler.ExportClicks)
+	}
+}
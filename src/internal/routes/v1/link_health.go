@@ -0,0 +1,17 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/imraushankr/brevity/server/src/configs"
+	"github.com/imraushankr/brevity/server/src/internal/handlers/middleware"
+	"github.com/imraushankr/brevity/server/src/internal/handlers/v1"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/auth"
+)
+
+func RegisterLinkHealthRoutes(r *gin.RouterGroup, handler *v1.LinkHealthHandler, authService *auth.Auth, cfg *configs.Config) {
+	// Authenticated routes
+	urlGroup := r.Group("/urls", middleware.AuthMiddleware(authService, &cfg.JWT))
+	{
+		urlGroup.GET("/unhealthy", handler.ListUnhealthyURLs)
+	}
+}
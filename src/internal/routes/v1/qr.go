@@ -0,0 +1,19 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/imraushankr/brevity/server/src/configs"
+	"github.com/imraushankr/brevity/server/src/internal/handlers/middleware"
+	"github.com/imraushankr/brevity/server/src/internal/handlers/v1"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/auth"
+)
+
+func RegisterQRRoutes(r *gin.RouterGroup, handler *v1.QRHandler, authService *auth.Auth, cfg *configs.Config) {
+	// Authenticated routes, ownership is checked by the service
+	qrGroup := r.Group("/urls", middleware.AuthMiddleware(authService, &cfg.JWT))
+	{
+		qrGroup.GET("/:id/qr", handler.GetQRCode)
+		qrGroup.PUT("/:id/qr/logo", handler.UploadQRLogo)
+		qrGroup.DELETE("/:id/qr/logo", handler.DeleteQRLogo)
+	}
+}
@@ -0,0 +1,24 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/imraushankr/brevity/server/src/configs"
+	"github.com/imraushankr/brevity/server/src/internal/handlers/middleware"
+	"github.com/imraushankr/brevity/server/src/internal/handlers/v1"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/auth"
+)
+
+func RegisterTagRoutes(r *gin.RouterGroup, handler *v1.TagHandler, authService *auth.Auth, cfg *configs.Config) {
+	// Authenticated routes
+	tagGroup := r.Group("/tags", middleware.AuthMiddleware(authService, &cfg.JWT))
+	{
+		tagGroup.GET("", handler.ListTags)
+		tagGroup.DELETE("/:id", handler.DeleteTag)
+	}
+
+	bulkGroup := r.Group("/urls/bulk", middleware.AuthMiddleware(authService, &cfg.JWT))
+	{
+		bulkGroup.POST("/tag", handler.BulkTagURLs)
+		bulkGroup.POST("/untag", handler.BulkUntagURLs)
+	}
+}
@@ -0,0 +1,23 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/imraushankr/brevity/server/src/configs"
+	"github.com/imraushankr/brevity/server/src/internal/handlers/middleware"
+	"github.com/imraushankr/brevity/server/src/internal/handlers/v1"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/auth"
+)
+
+func RegisterURLRoutes(r *gin.RouterGroup, handler *v1.URLHandler, authService *auth.Auth, cfg *configs.Config) {
+	// Authenticated routes
+	urlGroup := r.Group("/urls", middleware.AuthMiddleware(authService, &cfg.JWT))
+	{
+		urlGroup.POST("", handler.CreateURL)
+		urlGroup.GET("", handler.ListURLs)
+		urlGroup.GET("/search", handler.SearchURLs)
+		urlGroup.GET("/:id", handler.GetURL)
+		urlGroup.PUT("/:id", handler.UpdateURL)
+		urlGroup.PATCH("/:id/deactivate", handler.DeactivateURL)
+		urlGroup.DELETE("/:id", handler.DeleteURL)
+	}
+}
@@ -25,11 +25,8 @@ func RegisterUserRoutes(r *gin.RouterGroup, handler *v1.UserHandler, authService
 		// Admin-only routes
 		adminGroup := userGroup.Group("", middleware.RoleMiddleware("admin"))
 		{
-			// adminGroup.GET("", handler.ListUsers)
+			adminGroup.GET("", handler.ListUsers)
 			// adminGroup.PUT("/:id/role", handler.ChangeUserRole)
-			adminGroup.GET("", func(ctx *gin.Context) {
-				fmt.Println("users lists routes")
-			})
 			adminGroup.PUT("/:id/role", func(ctx *gin.Context) {
 				fmt.Println("change the user role")
 			})
@@ -0,0 +1,313 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/imraushankr/brevity/server/src/internal/models"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/brevity/server/src/internal/repository"
+)
+
+const (
+	// maxAnalyticsBuckets bounds the size of a time-series response
+	maxAnalyticsBuckets = 2000
+	maxAnalyticsTop     = 50
+
+	directReferrer = "direct"
+	unknownValue   = "unknown"
+)
+
+// analyticsService implements AnalyticsService interface
+type analyticsService struct {
+	urlRepo       repository.URLRepository
+	tagRepo       repository.TagRepository
+	campaignRepo  repository.CampaignRepository
+	analyticsRepo repository.AnalyticsRepository
+	log           logger.Logger
+}
+
+// NewAnalyticsService creates a new analytics service instance
+func NewAnalyticsService(urlRepo repository.URLRepository, tagRepo repository.TagRepository, campaignRepo repository.CampaignRepository, analyticsRepo repository.AnalyticsRepository) AnalyticsService {
+	return &analyticsService{
+		urlRepo:       urlRepo,
+		tagRepo:       tagRepo,
+		campaignRepo:  campaignRepo,
+		analyticsRepo: analyticsRepo,
+		log:           logger.Get(),
+	}
+}
+
+func (s *analyticsService) GetURLAnalytics(ctx context.Context, userID string, isAdmin bool, urlID string, query *models.AnalyticsQuery) (*models.URLAnalytics, error) {
+	s.log.Info("Building url analytics",
+		logger.String("userID", userID),
+		logger.String("urlID", urlID))
+
+	if err := validateAnalyticsQuery(query); err != nil {
+		return nil, err
+	}
+
+	url, err := s.urlRepo.FindByID(ctx, urlID)
+	if err != nil {
+		if !errors.Is(err, models.ErrURLNotFound) {
+			s.log.Error("Failed to find url",
+				logger.NamedError("error", err),
+				logger.String("urlID", urlID))
+		}
+		return nil, err
+	}
+	if url.UserID != userID && !isAdmin {
+		s.log.Warn("URL analytics access denied",
+			logger.String("userID", userID),
+			logger.String("urlID", urlID))
+		return nil, models.ErrForbidden
+	}
+
+	result := &models.URLAnalytics{
+		URLID:      url.ID,
+		From:       query.From,
+		To:         query.To,
+		Interval:   query.Interval,
+		Breakdowns: make(map[models.AnalyticsDimension][]models.BreakdownItem, len(models.AnalyticsDimensions)),
+	}
+
+	scope := models.AnalyticsScope{URLID: url.ID}
+	result.TotalClicks, result.UniqueVisitors, result.TimeSeries, err = s.clickSummary(ctx, scope, query)
+	if err != nil {
+		return nil, err
+	}
+	result.UniqueVisitorsApproximate, err = s.visitorsApproximate(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.breakdowns(ctx, scope, query, result.Breakdowns); err != nil {
+		return nil, err
+	}
+
+	totals, err := s.analyticsRepo.VariantTotals(ctx, scope, query.From, query.To)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count clicks per variant: %w", err)
+	}
+	result.Variants = variantStats(url.Variants, totals)
+
+	return result, nil
+}
+
+// GetTagAnalytics aggregates the clicks on every link carrying a tag
+func (s *analyticsService) GetTagAnalytics(ctx context.Context, userID string, isAdmin bool, tagID string, query *models.AnalyticsQuery) (*models.GroupAnalytics, error) {
+	s.log.Info("Building tag analytics",
+		logger.String("userID", userID),
+		logger.String("tagID", tagID))
+
+	if err := validateAnalyticsQuery(query); err != nil {
+		return nil, err
+	}
+
+	tag, err := s.tagRepo.FindByID(ctx, tagID)
+	if err != nil {
+		return nil, err
+	}
+	if tag.UserID != userID && !isAdmin {
+		return nil, models.ErrForbidden
+	}
+
+	result := &models.GroupAnalytics{TagID: tag.ID, Name: tag.Name, URLCount: tag.URLCount}
+	return result, s.groupAnalytics(ctx, models.AnalyticsScope{TagID: tag.ID}, query, result)
+}
+
+// GetCampaignAnalytics aggregates the clicks on every link in a campaign
+func (s *analyticsService) GetCampaignAnalytics(ctx context.Context, userID string, isAdmin bool, campaignID string, query *models.AnalyticsQuery) (*models.GroupAnalytics, error) {
+	s.log.Info("Building campaign analytics",
+		logger.String("userID", userID),
+		logger.String("campaignID", campaignID))
+
+	if err := validateAnalyticsQuery(query); err != nil {
+		return nil, err
+	}
+
+	campaign, err := s.campaignRepo.FindByID(ctx, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	if campaign.UserID != userID && !isAdmin {
+		return nil, models.ErrForbidden
+	}
+
+	result := &models.GroupAnalytics{CampaignID: campaign.ID, Name: campaign.Name, URLCount: campaign.URLCount}
+	return result, s.groupAnalytics(ctx, models.AnalyticsScope{CampaignID: campaign.ID}, query, result)
+}
+
+// groupAnalytics fills in result with the clicks on the links in scope
+func (s *analyticsService) groupAnalytics(ctx context.Context, scope models.AnalyticsScope, query *models.AnalyticsQuery, result *models.GroupAnalytics) error {
+	result.From = query.From
+	result.To = query.To
+	result.Interval = query.Interval
+	result.Breakdowns = make(map[models.AnalyticsDimension][]models.BreakdownItem, len(models.AnalyticsDimensions))
+
+	var err error
+	result.TotalClicks, result.UniqueVisitors, result.TimeSeries, err = s.clickSummary(ctx, scope, query)
+	if err != nil {
+		return err
+	}
+	result.UniqueVisitorsApproximate, err = s.visitorsApproximate(ctx, query)
+	if err != nil {
+		return err
+	}
+	if err := s.breakdowns(ctx, scope, query, result.Breakdowns); err != nil {
+		return err
+	}
+
+	result.TopLinks, err = s.analyticsRepo.TopURLs(ctx, scope, query.From, query.To, query.Top)
+	if err != nil {
+		return fmt.Errorf("failed to count clicks per link: %w", err)
+	}
+	return nil
+}
+
+// clickSummary counts the clicks in scope and buckets them into a time
+// series
+func (s *analyticsService) clickSummary(ctx context.Context, scope models.AnalyticsScope, query *models.AnalyticsQuery) (int64, int64, []models.ClickBucket, error) {
+	clicks, visitors, err := s.analyticsRepo.ClickTotals(ctx, scope, query.From, query.To)
+	if err != nil {
+		return 0, 0, nil, fmt.Errorf("failed to count clicks: %w", err)
+	}
+
+	buckets, err := s.analyticsRepo.ClickTimeSeries(ctx, scope, query.From, query.To, query.Interval)
+	if err != nil {
+		return 0, 0, nil, fmt.Errorf("failed to aggregate click time series: %w", err)
+	}
+	return clicks, visitors, fillBuckets(buckets, query), nil
+}
+
+// visitorsApproximate reports whether the query reaches into compacted
+// clicks, whose unique visitors are summed per rollup bucket
+func (s *analyticsService) visitorsApproximate(ctx context.Context, query *models.AnalyticsQuery) (bool, error) {
+	watermark, err := s.analyticsRepo.Watermark(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to load rollup watermark: %w", err)
+	}
+	return query.From.Before(watermark), nil
+}
+
+// breakdowns fills in the top values of every dimension for the clicks in
+// scope
+func (s *analyticsService) breakdowns(ctx context.Context, scope models.AnalyticsScope, query *models.AnalyticsQuery, into map[models.AnalyticsDimension][]models.BreakdownItem) error {
+	for _, dimension := range models.AnalyticsDimensions {
+		items, err := s.analyticsRepo.TopValues(ctx, scope, dimension, query.From, query.To, query.Top)
+		if err != nil {
+			return fmt.Errorf("failed to aggregate %s breakdown: %w", dimension, err)
+		}
+		into[dimension] = labelBreakdown(dimension, items)
+	}
+	return nil
+}
+
+func validateAnalyticsQuery(query *models.AnalyticsQuery) error {
+	if !query.Interval.IsValid() {
+		return fmt.Errorf("%w: unsupported interval %q", models.ErrInvalidAnalyticsRange, query.Interval)
+	}
+	if !query.To.After(query.From) {
+		return fmt.Errorf("%w: from must be before to", models.ErrInvalidAnalyticsRange)
+	}
+	if query.Top < 1 || query.Top > maxAnalyticsTop {
+		return fmt.Errorf("%w: top must be between 1 and %d", models.ErrInvalidAnalyticsRange, maxAnalyticsTop)
+	}
+
+	buckets := 0
+	for start := query.Interval.Truncate(query.From); start.Before(query.To); start = query.Interval.Next(start) {
+		if buckets++; buckets > maxAnalyticsBuckets {
+			return fmt.Errorf("%w: range spans more than %d %s buckets",
+				models.ErrInvalidAnalyticsRange, maxAnalyticsBuckets, query.Interval)
+		}
+	}
+	return nil
+}
+
+// fillBuckets returns one bucket per interval in the query range, using zero
+// counts where no clicks were recorded
+func fillBuckets(buckets []models.ClickBucket, query *models.AnalyticsQuery) []models.ClickBucket {
+	byStart := make(map[int64]models.ClickBucket, len(buckets))
+	for _, bucket := range buckets {
+		byStart[bucket.Start.Unix()] = bucket
+	}
+
+	series := make([]models.ClickBucket, 0)
+	for start := query.Interval.Truncate(query.From); start.Before(query.To); start = query.Interval.Next(start) {
+		bucket, ok := byStart[start.Unix()]
+		if !ok {
+			bucket = models.ClickBucket{Start: start}
+		}
+		series = append(series, bucket)
+	}
+	return series
+}
+
+// variantStats lists the url's variants in order with their click totals,
+// followed by removed variants that still have clicks in the range
+func variantStats(variants []models.URLVariant, totals []models.VariantStats) []models.VariantStats {
+	if len(variants) == 0 && len(totals) == 0 {
+		return nil
+	}
+
+	byID := make(map[string]models.VariantStats, len(totals))
+	var clicks int64
+	for _, t := range totals {
+		byID[t.ID] = t
+		clicks += t.Clicks
+	}
+	weight := 0
+	for _, v := range variants {
+		weight += v.Weight
+	}
+
+	stats := make([]models.VariantStats, 0, len(variants)+len(totals))
+	for _, v := range variants {
+		t := byID[v.ID]
+		delete(byID, v.ID)
+		stats = append(stats, models.VariantStats{
+			ID:             v.ID,
+			Name:           v.Name,
+			TargetURL:      v.TargetURL,
+			Weight:         v.Weight,
+			ExpectedShare:  ratio(int64(v.Weight), int64(weight)),
+			Clicks:         t.Clicks,
+			UniqueVisitors: t.UniqueVisitors,
+		})
+	}
+	for _, t := range totals {
+		if _, ok := byID[t.ID]; ok {
+			t.Removed = true
+			stats = append(stats, t)
+		}
+	}
+
+	for i := range stats {
+		stats[i].Share = ratio(stats[i].Clicks, clicks)
+	}
+	return stats
+}
+
+// ratio returns part/whole rounded to four decimals, or 0 when whole is 0
+func ratio(part, whole int64) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return math.Round(float64(part)/float64(whole)*10000) / 10000
+}
+
+// labelBreakdown gives empty dimension values a readable name
+func labelBreakdown(dimension models.AnalyticsDimension, items []models.BreakdownItem) []models.BreakdownItem {
+	for i := range items {
+		if items[i].Value != "" {
+			continue
+		}
+		if dimension == models.DimensionReferrer {
+			items[i].Value = directReferrer
+		} else {
+			items[i].Value = unknownValue
+		}
+	}
+	return items
+}
@@ -0,0 +1,209 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/imraushankr/brevity/server/src/internal/models"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/auth"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/brevity/server/src/internal/repository"
+)
+
+// bulkImportService implements BulkImportService interface
+type bulkImportService struct {
+	jobRepo    repository.BulkImportRepository
+	urlService URLService
+	log        logger.Logger
+}
+
+// NewBulkImportService creates a new bulk import service instance
+func NewBulkImportService(jobRepo repository.BulkImportRepository, urlService URLService) BulkImportService {
+	return &bulkImportService{
+		jobRepo:    jobRepo,
+		urlService: urlService,
+		log:        logger.Get(),
+	}
+}
+
+func (s *bulkImportService) Enqueue(ctx context.Context, userID string, reqs []models.CreateURLRequest, partial bool) (*models.BulkImportJob, error) {
+	s.log.Info("Queueing bulk import",
+		logger.String("userID", userID),
+		logger.Int("count", len(reqs)))
+
+	rows := make([]bulkImportRow, len(reqs))
+	hashes := make(map[string]string)
+	for i := range reqs {
+		row, err := sealPassword(reqs[i], hashes)
+		if err != nil {
+			return nil, err
+		}
+		rows[i] = row
+	}
+	payload, err := json.Marshal(rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode bulk import rows: %w", err)
+	}
+
+	job := &models.BulkImportJob{
+		UserID:  userID,
+		Status:  models.BulkImportPending,
+		Partial: partial,
+		Total:   len(reqs),
+		Payload: string(payload),
+	}
+	if err := s.jobRepo.Create(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to queue bulk import: %w", err)
+	}
+
+	s.log.Info("Bulk import queued", logger.String("jobID", job.ID))
+	return job, nil
+}
+
+func (s *bulkImportService) GetJob(ctx context.Context, userID, id string) (*models.BulkImportJob, error) {
+	job, err := s.jobRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.UserID != userID {
+		s.log.Warn("Bulk import access denied",
+			logger.String("userID", userID),
+			logger.String("jobID", id))
+		return nil, models.ErrForbidden
+	}
+
+	if job.ResultsJSON != "" {
+		if err := json.Unmarshal([]byte(job.ResultsJSON), &job.Results); err != nil {
+			return nil, fmt.Errorf("failed to decode bulk import results: %w", err)
+		}
+	}
+	return job, nil
+}
+
+// ProcessPending runs queued jobs one at a time until none are left
+func (s *bulkImportService) ProcessPending(ctx context.Context) error {
+	for ctx.Err() == nil {
+		job, err := s.jobRepo.ClaimNext(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to claim bulk import: %w", err)
+		}
+		if job == nil {
+			return nil
+		}
+		s.run(ctx, job)
+	}
+	return ctx.Err()
+}
+
+// FailInterrupted marks jobs that were running when the server last stopped
+func (s *bulkImportService) FailInterrupted(ctx context.Context) error {
+	count, err := s.jobRepo.FailRunning(ctx, "import interrupted by server restart")
+	if err != nil {
+		return fmt.Errorf("failed to recover bulk imports: %w", err)
+	}
+	if count > 0 {
+		s.log.Warn("Marked interrupted bulk imports as failed", logger.Int64("count", count))
+	}
+	return nil
+}
+
+func (s *bulkImportService) run(ctx context.Context, job *models.BulkImportJob) {
+	s.log.Info("Running bulk import",
+		logger.String("jobID", job.ID),
+		logger.Int("total", job.Total))
+
+	result, err := s.execute(ctx, job)
+	now := time.Now().UTC()
+	job.CompletedAt = &now
+	if err != nil {
+		s.log.Error("Bulk import failed",
+			logger.NamedError("error", err),
+			logger.String("jobID", job.ID))
+		job.Status = models.BulkImportFailed
+		job.Error = "import failed"
+		if errors.Is(err, context.Canceled) {
+			job.Error = "import interrupted by shutdown"
+		}
+	} else {
+		job.Status = models.BulkImportCompleted
+		job.Created = result.Created
+		job.Failed = result.Failed
+		results, _ := json.Marshal(result.Results)
+		job.ResultsJSON = string(results)
+	}
+
+	// Record the outcome even if the scheduler is shutting down
+	if err := s.jobRepo.Finish(context.WithoutCancel(ctx), job); err != nil {
+		s.log.Error("Failed to record bulk import result",
+			logger.NamedError("error", err),
+			logger.String("jobID", job.ID))
+		return
+	}
+
+	s.log.Info("Bulk import finished",
+		logger.String("jobID", job.ID),
+		logger.String("status", string(job.Status)),
+		logger.Int("created", job.Created),
+		logger.Int("failed", job.Failed))
+}
+
+func (s *bulkImportService) execute(ctx context.Context, job *models.BulkImportJob) (*models.BulkCreateResult, error) {
+	var rows []bulkImportRow
+	if err := json.Unmarshal([]byte(job.Payload), &rows); err != nil {
+		return nil, fmt.Errorf("failed to decode bulk import rows: %w", err)
+	}
+	reqs := make([]models.CreateURLRequest, len(rows))
+	for i, row := range rows {
+		reqs[i] = row.CreateURLRequest
+		reqs[i].PasswordHash = row.PasswordHash
+	}
+	return s.urlService.BulkCreateURLs(ctx, job.UserID, reqs, job.Partial)
+}
+
+// bulkImportRow is a queued row. PasswordHash is stored in its own field
+// since CreateURLRequest keeps it out of JSON.
+type bulkImportRow struct {
+	models.CreateURLRequest
+	PasswordHash string `json:"password_hash,omitempty"`
+}
+
+// Link passwords must be this long, as on models.CreateURLRequest
+const (
+	minLinkPasswordLength = 4
+	maxLinkPasswordLength = 72
+)
+
+// sealPassword hashes a row's link password before it is queued, reusing the
+// hash of a password already seen in hashes since imports often protect
+// every row with the same one. A password of the wrong length cannot be
+// used, so it is replaced by one that fails validation the same way.
+func sealPassword(req models.CreateURLRequest, hashes map[string]string) (bulkImportRow, error) {
+	row := bulkImportRow{CreateURLRequest: req}
+	if req.Password == "" {
+		return row, nil
+	}
+
+	switch n := utf8.RuneCountInString(req.Password); {
+	case n < minLinkPasswordLength:
+		row.Password = strings.Repeat("*", minLinkPasswordLength-1)
+	case n > maxLinkPasswordLength:
+		row.Password = strings.Repeat("*", maxLinkPasswordLength+1)
+	default:
+		hash, ok := hashes[req.Password]
+		if !ok {
+			var err error
+			if hash, err = auth.EncryptPassword(req.Password); err != nil {
+				return row, fmt.Errorf("failed to hash link password: %w", err)
+			}
+			hashes[req.Password] = hash
+		}
+		row.Password = ""
+		row.PasswordHash = hash
+	}
+	return row, nil
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/imraushankr/brevity/server/src/internal/models"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/brevity/server/src/internal/repository"
+)
+
+// campaignService implements CampaignService interface
+type campaignService struct {
+	campaignRepo repository.CampaignRepository
+	log          logger.Logger
+}
+
+// NewCampaignService creates a new campaign service instance
+func NewCampaignService(campaignRepo repository.CampaignRepository) CampaignService {
+	return &campaignService{
+		campaignRepo: campaignRepo,
+		log:          logger.Get(),
+	}
+}
+
+func (s *campaignService) CreateCampaign(ctx context.Context, userID string, req *models.CreateCampaignRequest) (*models.Campaign, error) {
+	s.log.Info("Creating campaign",
+		logger.String("userID", userID),
+		logger.String("name", req.Name))
+
+	req.Name = strings.TrimSpace(req.Name)
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	campaign := &models.Campaign{
+		UserID:      userID,
+		Name:        req.Name,
+		Description: strings.TrimSpace(req.Description),
+	}
+	if err := s.campaignRepo.Create(ctx, campaign); err != nil {
+		return nil, err
+	}
+	return campaign, nil
+}
+
+func (s *campaignService) GetCampaign(ctx context.Context, userID, id string) (*models.Campaign, error) {
+	campaign, err := s.campaignRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if campaign.UserID != userID {
+		s.log.Warn("Campaign access denied",
+			logger.String("userID", userID),
+			logger.String("campaignID", id))
+		return nil, models.ErrForbidden
+	}
+	return campaign, nil
+}
+
+func (s *campaignService) ListCampaigns(ctx context.Context, userID string, page, limit int) ([]models.Campaign, int64, error) {
+	campaigns, total, err := s.campaignRepo.ListByUser(ctx, userID, (page-1)*limit, limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list campaigns: %w", err)
+	}
+	return campaigns, total, nil
+}
+
+func (s *campaignService) UpdateCampaign(ctx context.Context, userID, id string, req *models.UpdateCampaignRequest) (*models.Campaign, error) {
+	s.log.Info("Updating campaign",
+		logger.String("userID", userID),
+		logger.String("campaignID", id))
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		req.Name = &name
+	}
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	campaign, err := s.GetCampaign(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Name != nil && *req.Name != "" {
+		campaign.Name = *req.Name
+	}
+	if req.Description != nil {
+		campaign.Description = strings.TrimSpace(*req.Description)
+	}
+	if err := s.campaignRepo.Update(ctx, campaign); err != nil {
+		return nil, err
+	}
+	return campaign, nil
+}
+
+// DeleteCampaign removes a campaign. Its links are kept.
+func (s *campaignService) DeleteCampaign(ctx context.Context, userID, id string) error {
+	s.log.Info("Deleting campaign",
+		logger.String("userID", userID),
+		logger.String("campaignID", id))
+
+	if _, err := s.GetCampaign(ctx, userID, id); err != nil {
+		return err
+	}
+	return s.campaignRepo.Delete(ctx, id)
+}
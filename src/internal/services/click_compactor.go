@@ -0,0 +1,48 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/imraushankr/brevity/server/src/configs"
+	"github.com/imraushankr/brevity/server/src/internal/models"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/brevity/server/src/internal/repository"
+)
+
+// clickCompactor implements ClickCompactor interface
+type clickCompactor struct {
+	rollupRepo repository.RollupRepository
+	cfg        *configs.RollupsConfig
+	log        logger.Logger
+}
+
+// NewClickCompactor creates a compactor that folds raw clicks older than the
+// configured retention into the rollup tables
+func NewClickCompactor(rollupRepo repository.RollupRepository, cfg *configs.RollupsConfig) ClickCompactor {
+	return &clickCompactor{
+		rollupRepo: rollupRepo,
+		cfg:        cfg,
+		log:        logger.Get(),
+	}
+}
+
+// Compact rolls up whole UTC days only, so daily rollups never hold a
+// partial day
+func (c *clickCompactor) Compact(ctx context.Context) error {
+	cutoff := models.IntervalDay.Truncate(time.Now().Add(-c.cfg.RawRetention))
+
+	compacted, err := c.rollupRepo.Compact(ctx, cutoff, c.cfg.PruneRaw)
+	if err != nil {
+		return fmt.Errorf("click compaction failed: %w", err)
+	}
+
+	if compacted > 0 {
+		c.log.Info("Clicks compacted into rollups",
+			logger.Int64("clicks", compacted),
+			logger.Time("cutoff", cutoff),
+			logger.Bool("pruned", c.cfg.PruneRaw))
+	}
+	return nil
+}
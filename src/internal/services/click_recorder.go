@@ -0,0 +1,211 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/imraushankr/brevity/server/src/configs"
+	"github.com/imraushankr/brevity/server/src/internal/models"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/geoip"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/useragent"
+	"github.com/imraushankr/brevity/server/src/internal/repository"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	clickFlushTimeout = 10 * time.Second
+	clickCityMaxLen   = 100
+)
+
+var (
+	clickEventsQueued = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "click_events_queued_total",
+			Help: "Total number of click events accepted into the queue",
+		},
+	)
+
+	clickEventsDropped = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "click_events_dropped_total",
+			Help: "Total number of click events dropped because the queue was full or closed",
+		},
+	)
+
+	clickEventsFailed = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "click_events_failed_total",
+			Help: "Total number of click events lost because their batch could not be stored",
+		},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(clickEventsQueued)
+	prometheus.MustRegister(clickEventsDropped)
+	prometheus.MustRegister(clickEventsFailed)
+}
+
+// clickRecorder implements ClickRecorder with a bounded queue drained by a
+// fixed pool of workers that write clicks in batches
+type clickRecorder struct {
+	clickRepo repository.ClickRepository
+	uaParser  *useragent.Parser
+	geo       *geoip.Locator
+	cfg       *configs.ClicksConfig
+	log       logger.Logger
+
+	queue   chan *models.URLClick
+	mu      sync.RWMutex
+	closed  bool
+	wg      sync.WaitGroup
+	started sync.Once
+}
+
+// NewClickRecorder creates a new click recorder instance
+func NewClickRecorder(clickRepo repository.ClickRepository, uaParser *useragent.Parser, geo *geoip.Locator, cfg *configs.ClicksConfig) ClickRecorder {
+	return &clickRecorder{
+		clickRepo: clickRepo,
+		uaParser:  uaParser,
+		geo:       geo,
+		cfg:       cfg,
+		log:       logger.Get(),
+		queue:     make(chan *models.URLClick, max(cfg.QueueSize, 1)),
+	}
+}
+
+func (r *clickRecorder) Start() {
+	r.started.Do(func() {
+		workers := max(r.cfg.Workers, 1)
+		r.log.Info("Starting click recorder",
+			logger.Int("workers", workers),
+			logger.Int("queueSize", cap(r.queue)))
+
+		for i := 0; i < workers; i++ {
+			r.wg.Add(1)
+			go r.work()
+		}
+	})
+}
+
+// Record enqueues a click without blocking. It returns false when the click
+// was dropped.
+func (r *clickRecorder) Record(click *models.URLClick) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.closed {
+		clickEventsDropped.Inc()
+		return false
+	}
+
+	select {
+	case r.queue <- click:
+		clickEventsQueued.Inc()
+		return true
+	default:
+		clickEventsDropped.Inc()
+		r.log.Warn("Click queue full, dropping event", logger.String("urlID", click.URLID))
+		return false
+	}
+}
+
+// Stop closes the queue and waits for the workers to flush what is left
+func (r *clickRecorder) Stop(ctx context.Context) error {
+	r.mu.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.queue)
+	}
+	r.mu.Unlock()
+
+	r.log.Info("Flushing click recorder", logger.Int("pending", len(r.queue)))
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		r.log.Info("Click recorder stopped")
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *clickRecorder) work() {
+	defer r.wg.Done()
+
+	batchSize := max(r.cfg.BatchSize, 1)
+	interval := r.cfg.FlushInterval
+	if interval <= 0 {
+		interval = 2 * time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	batch := make([]models.URLClick, 0, batchSize)
+	for {
+		select {
+		case click, ok := <-r.queue:
+			if !ok {
+				r.flush(batch)
+				return
+			}
+			r.enrich(click)
+			batch = append(batch, *click)
+			if len(batch) >= batchSize {
+				r.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			r.flush(batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+// enrich fills the derived click columns. It runs on the worker so the
+// redirect path never pays for parsing.
+func (r *clickRecorder) enrich(click *models.URLClick) {
+	ua := r.uaParser.Parse(click.UserAgent)
+	click.Device = ua.Device
+	click.OS = ua.OS
+	click.Browser = ua.Browser
+	click.IsBot = ua.IsBot
+
+	loc := r.geo.Lookup(click.IPAddress)
+	click.Country = loc.Country
+	click.City = truncate(loc.City, clickCityMaxLen)
+}
+
+// truncate shortens s to at most n runes
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
+
+func (r *clickRecorder) flush(batch []models.URLClick) {
+	if len(batch) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), clickFlushTimeout)
+	defer cancel()
+
+	if err := r.clickRepo.CreateBatch(ctx, batch); err != nil {
+		clickEventsFailed.Add(float64(len(batch)))
+		r.log.Error("Failed to store click batch",
+			logger.NamedError("error", err),
+			logger.Int("count", len(batch)))
+	}
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	neturl "net/url"
+	"strings"
+	"time"
+
+	"github.com/imraushankr/brevity/server/src/configs"
+	"github.com/imraushankr/brevity/server/src/internal/models"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/brevity/server/src/internal/repository"
+)
+
+// domainService implements DomainService interface
+type domainService struct {
+	domainRepo repository.DomainRepository
+	resolver   TXTResolver
+	cfg        *configs.Config
+	log        logger.Logger
+}
+
+// NewDomainService creates a domain service that verifies domains through
+// resolver, usually net.DefaultResolver
+func NewDomainService(domainRepo repository.DomainRepository, resolver TXTResolver, cfg *configs.Config) DomainService {
+	return &domainService{
+		domainRepo: domainRepo,
+		resolver:   resolver,
+		cfg:        cfg,
+		log:        logger.Get(),
+	}
+}
+
+func (s *domainService) AddDomain(ctx context.Context, userID string, req *models.CreateDomainRequest) (*models.Domain, error) {
+	s.log.Info("Adding domain",
+		logger.String("userID", userID),
+		logger.String("hostname", req.Hostname))
+
+	req.Hostname = models.NormalizeHostname(req.Hostname)
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+	if s.isAppHost(req.Hostname) {
+		return nil, models.ErrDomainReserved
+	}
+
+	domains, err := s.domainRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list domains: %w", err)
+	}
+	if s.cfg.Domains.MaxPerUser > 0 && len(domains) >= s.cfg.Domains.MaxPerUser {
+		return nil, models.ErrDomainLimit
+	}
+
+	token, err := newVerificationToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate verification token: %w", err)
+	}
+
+	domain := &models.Domain{
+		UserID:            userID,
+		Hostname:          req.Hostname,
+		VerificationToken: token,
+	}
+	if err := s.domainRepo.Create(ctx, domain); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Domain added",
+		logger.String("domainID", domain.ID),
+		logger.String("hostname", domain.Hostname))
+	return domain, nil
+}
+
+func (s *domainService) ListDomains(ctx context.Context, userID string) ([]models.Domain, error) {
+	return s.domainRepo.FindByUserID(ctx, userID)
+}
+
+func (s *domainService) GetDomain(ctx context.Context, userID, id string) (*models.Domain, error) {
+	domain, err := s.domainRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if domain.UserID != userID {
+		s.log.Warn("Domain access denied",
+			logger.String("userID", userID),
+			logger.String("domainID", id))
+		return nil, models.ErrForbidden
+	}
+	return domain, nil
+}
+
+// VerifyDomain looks for the domain's TXT record and marks it verified when
+// one of the values matches. Verifying an already verified domain is a no-op.
+func (s *domainService) VerifyDomain(ctx context.Context, userID, id string) (*models.Domain, error) {
+	domain, err := s.GetDomain(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if domain.IsVerified() {
+		return domain, nil
+	}
+
+	record := domain.VerificationRecord(s.cfg.Domains.VerificationPrefix)
+
+	lookupCtx := ctx
+	if s.cfg.Domains.LookupTimeout > 0 {
+		var cancel context.CancelFunc
+		lookupCtx, cancel = context.WithTimeout(ctx, s.cfg.Domains.LookupTimeout)
+		defer cancel()
+	}
+	values, err := s.resolver.LookupTXT(lookupCtx, record.Name)
+	if err != nil {
+		s.log.Info("Domain verification lookup failed",
+			logger.NamedError("error", err),
+			logger.String("domainID", domain.ID),
+			logger.String("record", record.Name))
+		return nil, models.ErrDomainVerification
+	}
+
+	found := false
+	for _, value := range values {
+		if strings.TrimSpace(value) == record.Value {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, models.ErrDomainVerification
+	}
+
+	now := time.Now().UTC()
+	if err := s.domainRepo.MarkVerified(ctx, domain.ID, now); err != nil {
+		if errors.Is(err, models.ErrDomainTaken) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to mark domain verified: %w", err)
+	}
+	domain.VerifiedAt = &now
+
+	s.log.Info("Domain verified",
+		logger.String("domainID", domain.ID),
+		logger.String("hostname", domain.Hostname))
+	return domain, nil
+}
+
+// DeleteDomain removes a domain once none of its short urls are left
+func (s *domainService) DeleteDomain(ctx context.Context, userID, id string) error {
+	domain, err := s.GetDomain(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	inUse, err := s.domainRepo.HasURLs(ctx, domain.ID)
+	if err != nil {
+		return fmt.Errorf("failed to check domain urls: %w", err)
+	}
+	if inUse {
+		return models.ErrDomainInUse
+	}
+
+	if err := s.domainRepo.Delete(ctx, domain.ID); err != nil {
+		return err
+	}
+	s.log.Info("Domain deleted", logger.String("domainID", domain.ID))
+	return nil
+}
+
+// isAppHost reports whether hostname is the one the app itself runs on
+func (s *domainService) isAppHost(hostname string) bool {
+	base, err := neturl.Parse(s.cfg.App.BaseURL)
+	return err == nil && strings.EqualFold(base.Hostname(), hostname)
+}
+
+func newVerificationToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,209 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/imraushankr/brevity/server/src/configs"
+	"github.com/imraushankr/brevity/server/src/internal/models"
+	"github.com/imraushankr/brevity/server/src/internal/repository"
+)
+
+// fakeDomainRepository keeps domains in memory and, like the unique index
+// on verified hostnames, lets only one domain per hostname be verified
+type fakeDomainRepository struct {
+	repository.DomainRepository
+	domains  map[string]*models.Domain
+	verified []string
+	err      error
+}
+
+func (r *fakeDomainRepository) FindByID(_ context.Context, id string) (*models.Domain, error) {
+	domain, ok := r.domains[id]
+	if !ok {
+		return nil, models.ErrDomainNotFound
+	}
+	d := *domain
+	return &d, nil
+}
+
+func (r *fakeDomainRepository) FindVerifiedByHostname(_ context.Context, hostname string) (*models.Domain, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	for _, domain := range r.domains {
+		if domain.Hostname == hostname && domain.IsVerified() {
+			d := *domain
+			return &d, nil
+		}
+	}
+	return nil, models.ErrDomainNotFound
+}
+
+func (r *fakeDomainRepository) MarkVerified(_ context.Context, id string, at time.Time) error {
+	domain := r.domains[id]
+	for _, other := range r.domains {
+		if other.ID != id && other.Hostname == domain.Hostname && other.IsVerified() {
+			return models.ErrDomainTaken
+		}
+	}
+	domain.VerifiedAt = &at
+	r.verified = append(r.verified, id)
+	return nil
+}
+
+// fakeTXTResolver answers from a map of record names
+type fakeTXTResolver struct {
+	records     map[string][]string
+	err         error
+	lookups     []string
+	hadDeadline bool
+}
+
+func (r *fakeTXTResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	r.lookups = append(r.lookups, name)
+	_, r.hadDeadline = ctx.Deadline()
+	if r.err != nil {
+		return nil, r.err
+	}
+	return r.records[name], nil
+}
+
+func newTestDomainService(resolver TXTResolver) (DomainService, *fakeDomainRepository) {
+	verifiedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	repo := &fakeDomainRepository{domains: map[string]*models.Domain{
+		"d1": {ID: "d1", UserID: "alice", Hostname: "go.acme.example", VerificationToken: "tok1"},
+		"d2": {ID: "d2", UserID: "bob", Hostname: "go.acme.example", VerificationToken: "tok2"},
+		"d3": {ID: "d3", UserID: "bob", Hostname: "links.bob.example", VerificationToken: "tok3", VerifiedAt: &verifiedAt},
+		"d4": {ID: "d4", UserID: "carol", Hostname: "links.bob.example", VerificationToken: "tok4"},
+	}}
+	cfg := &configs.Config{
+		App:     configs.AppConfig{BaseURL: "https://sho.rt"},
+		Domains: configs.DomainsConfig{VerificationPrefix: "_brevity", LookupTimeout: time.Second},
+	}
+	return NewDomainService(repo, resolver, cfg), repo
+}
+
+func TestVerifyDomain(t *testing.T) {
+	tests := []struct {
+		name     string
+		userID   string
+		domainID string
+		records  map[string][]string
+		err      error
+		wantErr  error
+	}{
+		{
+			name:     "matching token",
+			userID:   "alice",
+			domainID: "d1",
+			records:  map[string][]string{"_brevity.go.acme.example": {"v=spf1 -all", " brevity-verification=tok1 "}},
+		},
+		{
+			name:     "missing record",
+			userID:   "alice",
+			domainID: "d1",
+			wantErr:  models.ErrDomainVerification,
+		},
+		{
+			name:     "wrong token",
+			userID:   "alice",
+			domainID: "d1",
+			records:  map[string][]string{"_brevity.go.acme.example": {"brevity-verification=tok2"}},
+			wantErr:  models.ErrDomainVerification,
+		},
+		{
+			name:     "record on the bare hostname",
+			userID:   "alice",
+			domainID: "d1",
+			records:  map[string][]string{"go.acme.example": {"brevity-verification=tok1"}},
+			wantErr:  models.ErrDomainVerification,
+		},
+		{
+			name:     "resolver error",
+			userID:   "alice",
+			domainID: "d1",
+			err:      errors.New("i/o timeout"),
+			wantErr:  models.ErrDomainVerification,
+		},
+		{
+			name:     "verified by another user",
+			userID:   "carol",
+			domainID: "d4",
+			records:  map[string][]string{"_brevity.links.bob.example": {"brevity-verification=tok4"}},
+			wantErr:  models.ErrDomainTaken,
+		},
+		{
+			name:     "someone else's domain",
+			userID:   "alice",
+			domainID: "d2",
+			records:  map[string][]string{"_brevity.go.acme.example": {"brevity-verification=tok2"}},
+			wantErr:  models.ErrForbidden,
+		},
+		{
+			name:     "unknown domain",
+			userID:   "alice",
+			domainID: "nope",
+			wantErr:  models.ErrDomainNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := &fakeTXTResolver{records: tt.records, err: tt.err}
+			svc, repo := newTestDomainService(resolver)
+
+			domain, err := svc.VerifyDomain(context.Background(), tt.userID, tt.domainID)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("VerifyDomain: err = %v, want %v", err, tt.wantErr)
+				}
+				if len(repo.verified) != 0 {
+					t.Errorf("marked %v verified", repo.verified)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("VerifyDomain: %v", err)
+			}
+			if !domain.IsVerified() || !slices.Equal(repo.verified, []string{tt.domainID}) {
+				t.Errorf("domain verified %v, repository marked %v", domain.IsVerified(), repo.verified)
+			}
+			if !resolver.hadDeadline {
+				t.Error("lookup ran without the configured timeout")
+			}
+		})
+	}
+}
+
+func TestVerifyDomainAlreadyVerified(t *testing.T) {
+	resolver := &fakeTXTResolver{}
+	svc, repo := newTestDomainService(resolver)
+
+	domain, err := svc.VerifyDomain(context.Background(), "bob", "d3")
+	if err != nil {
+		t.Fatalf("VerifyDomain: %v", err)
+	}
+	if !domain.IsVerified() {
+		t.Error("domain is not verified")
+	}
+	if len(resolver.lookups) != 0 || len(repo.verified) != 0 {
+		t.Errorf("looked up %v and marked %v, want neither", resolver.lookups, repo.verified)
+	}
+}
+
+func TestVerifyDomainFirstComesFirstServed(t *testing.T) {
+	resolver := &fakeTXTResolver{records: map[string][]string{
+		"_brevity.go.acme.example": {"brevity-verification=tok1", "brevity-verification=tok2"},
+	}}
+	svc, _ := newTestDomainService(resolver)
+
+	if _, err := svc.VerifyDomain(context.Background(), "bob", "d2"); err != nil {
+		t.Fatalf("VerifyDomain for bob: %v", err)
+	}
+	if _, err := svc.VerifyDomain(context.Background(), "alice", "d1"); !errors.Is(err, models.ErrDomainTaken) {
+		t.Errorf("VerifyDomain for alice: err = %v, want ErrDomainTaken", err)
+	}
+}
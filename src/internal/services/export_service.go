@@ -0,0 +1,66 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/imraushankr/brevity/server/src/internal/models"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/brevity/server/src/internal/repository"
+)
+
+// exportBatchSize is how many rows are read from the database at a time
+const exportBatchSize = 500
+
+// exportService implements ExportService interface
+type exportService struct {
+	urlRepo   repository.URLRepository
+	clickRepo repository.ClickRepository
+	log       logger.Logger
+}
+
+// NewExportService creates a new export service instance
+func NewExportService(urlRepo repository.URLRepository, clickRepo repository.ClickRepository) ExportService {
+	return &exportService{
+		urlRepo:   urlRepo,
+		clickRepo: clickRepo,
+		log:       logger.Get(),
+	}
+}
+
+func (s *exportService) ExportURLs(ctx context.Context, userID string, filter *models.URLExportFilter, fn func([]models.URL) error) error {
+	s.log.Info("Exporting urls", logger.String("userID", userID))
+
+	if err := s.urlRepo.StreamByUserID(ctx, userID, filter, exportBatchSize, fn); err != nil {
+		return fmt.Errorf("url export failed: %w", err)
+	}
+	return nil
+}
+
+func (s *exportService) ExportClicks(ctx context.Context, userID, urlID string, filter *models.ClickExportFilter, fn func([]models.URLClick) error) error {
+	s.log.Info("Exporting clicks",
+		logger.String("userID", userID),
+		logger.String("urlID", urlID))
+
+	url, err := s.urlRepo.FindByID(ctx, urlID)
+	if err != nil {
+		if !errors.Is(err, models.ErrURLNotFound) {
+			s.log.Error("Failed to find url",
+				logger.NamedError("error", err),
+				logger.String("urlID", urlID))
+		}
+		return err
+	}
+	if url.UserID != userID {
+		s.log.Warn("Click export access denied",
+			logger.String("userID", userID),
+			logger.String("urlID", urlID))
+		return models.ErrForbidden
+	}
+
+	if err := s.clickRepo.StreamByURLID(ctx, url.ID, filter, exportBatchSize, fn); err != nil {
+		return fmt.Errorf("click export failed: %w", err)
+	}
+	return nil
+}
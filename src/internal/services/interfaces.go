@@ -5,6 +5,9 @@ import (
 	"mime/multipart"
 
 	"github.com/imraushankr/brevity/server/src/internal/models"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/linkcheck"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/pagination"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/preview"
 )
 
 // UserService defines all user-related business operations
@@ -15,6 +18,7 @@ type UserService interface {
 	FindUser(ctx context.Context, identifier string) (*models.User, error)
 	UpdateUser(ctx context.Context, user *models.User) error
 	DeleteUser(ctx context.Context, id string) error
+	ListUsers(ctx context.Context, q *pagination.Query) ([]models.User, *pagination.Page, error)
 
 	// Email Verification
 	VerifyEmail(ctx context.Context, token string) error
@@ -28,4 +32,152 @@ type UserService interface {
 
 	// Avatar Management
 	UploadAvatar(ctx context.Context, userID string, file multipart.File, header *multipart.FileHeader) (string, error)
-}
\ No newline at end of file
+}
+
+// URLService defines all short link business operations
+type URLService interface {
+	// Link Management
+	CreateURL(ctx context.Context, userID string, req *models.CreateURLRequest) (*models.URL, error)
+	GetURL(ctx context.Context, userID, id string) (*models.URL, error)
+	ListURLs(ctx context.Context, userID string, q *pagination.Query) ([]models.URL, *pagination.Page, error)
+	SearchURLs(ctx context.Context, userID string, isAdmin bool, query string, page, limit int) ([]models.URLSearchHit, int64, error)
+	UpdateURL(ctx context.Context, userID, id string, req *models.UpdateURLRequest) (*models.URL, error)
+	DeactivateURL(ctx context.Context, userID, id string) error
+	DeleteURL(ctx context.Context, userID, id string) error
+
+	// Bulk Operations
+	BulkCreateURLs(ctx context.Context, userID string, reqs []models.CreateURLRequest, partial bool) (*models.BulkCreateResult, error)
+
+	// Redirects
+	ResolveShortCode(ctx context.Context, host, shortCode string) (*models.URL, error)
+	CheckURLPassword(url *models.URL, password string) error
+	ConsumeClick(ctx context.Context, url *models.URL) error
+}
+
+// DomainService manages the custom domains users serve short links from
+type DomainService interface {
+	AddDomain(ctx context.Context, userID string, req *models.CreateDomainRequest) (*models.Domain, error)
+	ListDomains(ctx context.Context, userID string) ([]models.Domain, error)
+	GetDomain(ctx context.Context, userID, id string) (*models.Domain, error)
+	VerifyDomain(ctx context.Context, userID, id string) (*models.Domain, error)
+	DeleteDomain(ctx context.Context, userID, id string) error
+}
+
+// TXTResolver looks up DNS TXT records for domain verification. It is
+// satisfied by *net.Resolver; tests can substitute a fake.
+type TXTResolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+// QRService renders QR codes for short links and manages their logos
+type QRService interface {
+	RenderQR(ctx context.Context, userID, urlID string, opts *models.QROptions) (*models.QRImage, error)
+	SetLogo(ctx context.Context, userID, urlID string, file multipart.File, header *multipart.FileHeader) (*models.URL, error)
+	RemoveLogo(ctx context.Context, userID, urlID string) (*models.URL, error)
+}
+
+// BulkImportService runs large bulk creates as background jobs
+type BulkImportService interface {
+	Enqueue(ctx context.Context, userID string, reqs []models.CreateURLRequest, partial bool) (*models.BulkImportJob, error)
+	GetJob(ctx context.Context, userID, id string) (*models.BulkImportJob, error)
+	ProcessPending(ctx context.Context) error
+	FailInterrupted(ctx context.Context) error
+}
+
+// AnalyticsService defines click reporting operations
+type AnalyticsService interface {
+	GetURLAnalytics(ctx context.Context, userID string, isAdmin bool, urlID string, query *models.AnalyticsQuery) (*models.URLAnalytics, error)
+	GetTagAnalytics(ctx context.Context, userID string, isAdmin bool, tagID string, query *models.AnalyticsQuery) (*models.GroupAnalytics, error)
+	GetCampaignAnalytics(ctx context.Context, userID string, isAdmin bool, campaignID string, query *models.AnalyticsQuery) (*models.GroupAnalytics, error)
+}
+
+// TagService manages the tags users label their links with
+type TagService interface {
+	ListTags(ctx context.Context, userID string) ([]models.Tag, error)
+	DeleteTag(ctx context.Context, userID, id string) error
+	BulkTag(ctx context.Context, userID string, req *models.BulkTagRequest) (*models.BulkTagResult, error)
+	BulkUntag(ctx context.Context, userID string, req *models.BulkTagRequest) (*models.BulkTagResult, error)
+}
+
+// CampaignService manages the campaigns users group their links into
+type CampaignService interface {
+	CreateCampaign(ctx context.Context, userID string, req *models.CreateCampaignRequest) (*models.Campaign, error)
+	GetCampaign(ctx context.Context, userID, id string) (*models.Campaign, error)
+	ListCampaigns(ctx context.Context, userID string, page, limit int) ([]models.Campaign, int64, error)
+	UpdateCampaign(ctx context.Context, userID, id string, req *models.UpdateCampaignRequest) (*models.Campaign, error)
+	DeleteCampaign(ctx context.Context, userID, id string) error
+}
+
+// ExportService streams a user's links and click history in batches
+type ExportService interface {
+	ExportURLs(ctx context.Context, userID string, filter *models.URLExportFilter, fn func([]models.URL) error) error
+	ExportClicks(ctx context.Context, userID, urlID string, filter *models.ClickExportFilter, fn func([]models.URLClick) error) error
+}
+
+// ClickRecorder queues redirect clicks and persists them in the background
+type ClickRecorder interface {
+	Start()
+	Record(click *models.URLClick) bool
+	Stop(ctx context.Context) error
+}
+
+// LinkScheduler flips IsActive when links reach their activation or expiry
+// time
+type LinkScheduler interface {
+	Apply(ctx context.Context) error
+}
+
+// LinkPreviewer fills in the title, description, image and favicon of new
+// links from their destination pages
+type LinkPreviewer interface {
+	FetchPending(ctx context.Context) error
+}
+
+// PreviewFetcher downloads a page and extracts its metadata. It is satisfied
+// by *preview.Fetcher; tests can substitute a fake.
+type PreviewFetcher interface {
+	Fetch(ctx context.Context, rawURL string) (*preview.Metadata, error)
+}
+
+// LinkSafetyService checks link destinations against the blocklists and
+// threat feed, and manages the blocklists and the review queue of links
+// disabled as unsafe
+type LinkSafetyService interface {
+	// CheckURL returns an error wrapping models.ErrUnsafeURL when rawURL
+	// may not be used as a destination
+	CheckURL(ctx context.Context, rawURL string) error
+	// Reload recompiles the blocklist and reloads the threat feed when its
+	// file changed
+	Reload(ctx context.Context) error
+	// ScanLinks flags existing links once the blocklist or feed changed
+	ScanLinks(ctx context.Context) error
+
+	AddBlocklistEntry(ctx context.Context, adminID string, req *models.CreateBlocklistEntryRequest) (*models.BlocklistEntry, error)
+	ListBlocklistEntries(ctx context.Context, kind string, page, limit int) ([]models.BlocklistEntry, int64, error)
+	DeleteBlocklistEntry(ctx context.Context, id string) error
+
+	ListFlags(ctx context.Context, status string, page, limit int) ([]models.LinkFlag, int64, error)
+	ReviewFlag(ctx context.Context, adminID, id string, req *models.ReviewLinkFlagRequest) (*models.LinkFlag, error)
+}
+
+// LinkHealthService checks that link destinations still respond, emails
+// owners about links that broke and lists broken links
+type LinkHealthService interface {
+	// CheckDue checks the destinations of links whose check is due
+	CheckDue(ctx context.Context) error
+	// SendDigests emails owners the links that broke since their last digest
+	SendDigests(ctx context.Context) error
+	ListUnhealthy(ctx context.Context, userID string, page, limit int) ([]models.URL, int64, error)
+}
+
+// DestinationChecker requests a destination to tell whether it still
+// responds. It is satisfied by *linkcheck.Checker; tests can substitute a
+// fake.
+type DestinationChecker interface {
+	Check(ctx context.Context, rawURL string) linkcheck.Result
+}
+
+// ClickCompactor folds old raw clicks into the analytics rollup tables
+type ClickCompactor interface {
+	Compact(ctx context.Context) error
+}
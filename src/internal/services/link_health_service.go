@@ -0,0 +1,225 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/imraushankr/brevity/server/src/configs"
+	"github.com/imraushankr/brevity/server/src/internal/models"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/email"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/brevity/server/src/internal/repository"
+)
+
+const (
+	// healthScheduleBatchSize is how many new links get their first check
+	// scheduled at a time
+	healthScheduleBatchSize = 500
+	healthErrorMaxLen       = 255
+)
+
+// linkHealthService implements LinkHealthService interface
+type linkHealthService struct {
+	repo    repository.LinkHealthRepository
+	checker DestinationChecker
+	email   *email.EmailService
+	cfg     *configs.Config
+	log     logger.Logger
+}
+
+// NewLinkHealthService creates the service behind the destination health
+// check and broken link digest jobs
+func NewLinkHealthService(repo repository.LinkHealthRepository, checker DestinationChecker, email *email.EmailService, cfg *configs.Config) LinkHealthService {
+	return &linkHealthService{
+		repo:    repo,
+		checker: checker,
+		email:   email,
+		cfg:     cfg,
+		log:     logger.Get(),
+	}
+}
+
+// threshold is how many checks in a row must fail for a link to be broken
+func (s *linkHealthService) threshold() int {
+	return max(s.cfg.HealthChecks.FailureThreshold, 1)
+}
+
+// CheckDue gives new links a random first check within the interval, then
+// checks a batch of links that are due, cfg.Workers at a time
+func (s *linkHealthService) CheckDue(ctx context.Context) error {
+	if err := s.scheduleNew(ctx); err != nil {
+		return err
+	}
+
+	urls, err := s.repo.FindDue(ctx, time.Now().UTC(), s.cfg.HealthChecks.BatchSize)
+	if err != nil || len(urls) == 0 {
+		return err
+	}
+
+	queue := make(chan *models.URL)
+	var wg sync.WaitGroup
+	for i := 0; i < max(s.cfg.HealthChecks.Workers, 1); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for url := range queue {
+				s.check(ctx, url)
+			}
+		}()
+	}
+
+	for i := range urls {
+		select {
+		case queue <- &urls[i]:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	close(queue)
+	wg.Wait()
+	return ctx.Err()
+}
+
+// scheduleNew spreads the first checks of new links across the interval, so
+// links created together, or existing ones when checks are first enabled,
+// are not all checked at once
+func (s *linkHealthService) scheduleNew(ctx context.Context) error {
+	interval := max(s.cfg.HealthChecks.Interval, time.Second)
+	for {
+		ids, err := s.repo.FindUnscheduled(ctx, healthScheduleBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to find unscheduled links: %w", err)
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		now := time.Now().UTC()
+		next := make(map[string]time.Time, len(ids))
+		for _, id := range ids {
+			next[id] = now.Add(rand.N(interval))
+		}
+		if err := s.repo.Schedule(ctx, next); err != nil {
+			return fmt.Errorf("failed to schedule link checks: %w", err)
+		}
+		if len(ids) < healthScheduleBatchSize {
+			return nil
+		}
+	}
+}
+
+// check requests a link's destination and stores the result. Failing links
+// are retried sooner until they count as broken; a success resets them.
+func (s *linkHealthService) check(ctx context.Context, url *models.URL) {
+	result := s.checker.Check(ctx, url.OriginalURL)
+	if ctx.Err() != nil {
+		// Shutting down; the link stays due for the next start
+		return
+	}
+
+	now := time.Now().UTC()
+	next := now.Add(s.cfg.HealthChecks.Interval)
+	health := models.LinkHealth{
+		StatusCode: result.StatusCode,
+		LatencyMS:  result.Latency.Milliseconds(),
+		CheckedAt:  &now,
+	}
+	if result.Err != nil {
+		health.Error = truncate(result.Err.Error(), healthErrorMaxLen)
+	}
+	if !result.OK() {
+		health.Failures = url.Health.Failures + 1
+		health.FailingSince = url.Health.FailingSince
+		if health.FailingSince == nil {
+			health.FailingSince = &now
+		}
+		health.Reported = url.Health.Reported
+		if health.Failures < s.threshold() {
+			next = now.Add(s.cfg.HealthChecks.RetryInterval)
+		}
+	}
+	health.NextCheckAt = &next
+
+	if err := s.repo.Save(ctx, url.ID, health); err != nil {
+		return
+	}
+
+	switch {
+	case health.Failures == s.threshold():
+		s.log.Info("Link destination is broken",
+			logger.String("urlID", url.ID),
+			logger.String("problem", result.Problem()))
+	case health.Failures == 0 && url.Health.Failures >= s.threshold():
+		s.log.Info("Link destination recovered", logger.String("urlID", url.ID))
+	}
+}
+
+// SendDigests emails each owner the links that broke since their last
+// digest. Links stay unreported when the email fails, so they are included
+// in the next digest.
+func (s *linkHealthService) SendDigests(ctx context.Context) error {
+	urls, err := s.repo.FindUnreported(ctx, s.threshold())
+	if err != nil {
+		return fmt.Errorf("failed to find broken links: %w", err)
+	}
+
+	for start := 0; start < len(urls); {
+		end := start + 1
+		for end < len(urls) && urls[end].UserID == urls[start].UserID {
+			end++
+		}
+		s.sendDigest(ctx, urls[start:end])
+		start = end
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// sendDigest emails the owner of urls, which all belong to the same user
+func (s *linkHealthService) sendDigest(ctx context.Context, urls []models.URL) {
+	owner := urls[0].User
+	links := make([]email.BrokenLink, 0, len(urls))
+	ids := make([]string, 0, len(urls))
+	for i := range urls {
+		url := &urls[i]
+		link := email.BrokenLink{
+			ShortURL:    models.ShortURL(s.cfg.App.BaseURL, url.Domain, url.ShortCode),
+			OriginalURL: url.OriginalURL,
+			Problem:     url.Health.Problem(),
+		}
+		if url.Health.FailingSince != nil {
+			link.FailingSince = *url.Health.FailingSince
+		}
+		links = append(links, link)
+		ids = append(ids, url.ID)
+	}
+
+	if err := s.email.SendBrokenLinksDigest(owner.Email, links); err != nil {
+		s.log.Error("Failed to send broken links digest",
+			logger.NamedError("error", err),
+			logger.String("userID", owner.ID))
+		return
+	}
+	if err := s.repo.MarkReported(ctx, ids); err != nil {
+		return
+	}
+	s.log.Info("Broken links digest sent",
+		logger.String("userID", owner.ID),
+		logger.Int("links", len(links)))
+}
+
+func (s *linkHealthService) ListUnhealthy(ctx context.Context, userID string, page, limit int) ([]models.URL, int64, error) {
+	urls, total, err := s.repo.ListUnhealthy(ctx, userID, s.threshold(), (page-1)*limit, limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list unhealthy links: %w", err)
+	}
+	return urls, total, nil
+}
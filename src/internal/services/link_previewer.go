@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/imraushankr/brevity/server/src/configs"
+	"github.com/imraushankr/brevity/server/src/internal/models"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/preview"
+	"github.com/imraushankr/brevity/server/src/internal/repository"
+)
+
+// linkPreviewer implements LinkPreviewer interface
+type linkPreviewer struct {
+	previewRepo repository.LinkPreviewRepository
+	fetcher     PreviewFetcher
+	cfg         *configs.PreviewConfig
+	log         logger.Logger
+}
+
+// NewLinkPreviewer creates a job that fills in link metadata from each new
+// link's destination page
+func NewLinkPreviewer(previewRepo repository.LinkPreviewRepository, fetcher PreviewFetcher, cfg *configs.PreviewConfig) LinkPreviewer {
+	return &linkPreviewer{
+		previewRepo: previewRepo,
+		fetcher:     fetcher,
+		cfg:         cfg,
+		log:         logger.Get(),
+	}
+}
+
+// FetchPending previews a batch of new links, cfg.Workers at a time. A link
+// whose page cannot be fetched is still marked done, so broken destinations
+// are not retried forever.
+func (p *linkPreviewer) FetchPending(ctx context.Context) error {
+	urls, err := p.previewRepo.FindPending(ctx, p.cfg.BatchSize)
+	if err != nil || len(urls) == 0 {
+		return err
+	}
+
+	queue := make(chan *models.URL)
+	var wg sync.WaitGroup
+	for i := 0; i < max(p.cfg.Workers, 1); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for url := range queue {
+				p.preview(ctx, url)
+			}
+		}()
+	}
+
+	for i := range urls {
+		select {
+		case queue <- &urls[i]:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	close(queue)
+	wg.Wait()
+	return ctx.Err()
+}
+
+func (p *linkPreviewer) preview(ctx context.Context, url *models.URL) {
+	meta, err := p.fetcher.Fetch(ctx, url.OriginalURL)
+	if ctx.Err() != nil {
+		// Shutting down; the link stays pending for the next start
+		return
+	}
+	if err != nil {
+		p.log.Info("Link preview fetch failed",
+			logger.NamedError("error", err),
+			logger.String("urlID", url.ID))
+		meta = &preview.Metadata{}
+	}
+
+	linkPreview := models.LinkPreview{
+		SiteName:   meta.SiteName,
+		ImageURL:   meta.ImageURL,
+		FaviconURL: meta.FaviconURL,
+	}
+	if err := p.previewRepo.Save(ctx, url.ID, meta.Title, meta.Description, linkPreview, time.Now().UTC()); err != nil {
+		return
+	}
+	p.log.Debug("Link preview saved",
+		logger.String("urlID", url.ID),
+		logger.String("title", meta.Title))
+}
@@ -0,0 +1,350 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/imraushankr/brevity/server/src/configs"
+	"github.com/imraushankr/brevity/server/src/internal/models"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/linksafety"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/brevity/server/src/internal/repository"
+)
+
+// linkSafetyService implements LinkSafetyService interface
+type linkSafetyService struct {
+	repo       repository.LinkSafetyRepository
+	domainRepo repository.DomainRepository
+	checker    *linksafety.Checker
+	cfg        *configs.LinkSafetyConfig
+	log        logger.Logger
+
+	// mu serializes reloads and scans, which run from the scheduler as well
+	// as after admin changes
+	mu             sync.Mutex
+	rulesLoaded    bool
+	rulesKey       string
+	feedModTime    time.Time
+	feedSize       int64
+	scannedVersion uint64
+}
+
+// NewLinkSafetyService creates the link safety service. Reload must be
+// called before the first check for the blocklist and feed to apply.
+// Verified custom domains in domainRepo count as the shortener's own hosts.
+func NewLinkSafetyService(repo repository.LinkSafetyRepository, domainRepo repository.DomainRepository, checker *linksafety.Checker, cfg *configs.LinkSafetyConfig) LinkSafetyService {
+	return &linkSafetyService{
+		repo:       repo,
+		domainRepo: domainRepo,
+		checker:    checker,
+		cfg:        cfg,
+		log:        logger.Get(),
+	}
+}
+
+// CheckURL refuses destinations matching the blocklist or threat feed, and
+// links back to the shortener itself, on its own host or on a verified
+// custom domain
+func (s *linkSafetyService) CheckURL(ctx context.Context, rawURL string) error {
+	var match *linksafety.Match
+	if s.cfg.ResolveHosts {
+		match = s.checker.Check(ctx, rawURL)
+	} else {
+		match = s.checker.Match(rawURL)
+	}
+	if match == nil {
+		var err error
+		if match, err = s.matchCustomDomain(ctx, rawURL); err != nil {
+			return err
+		}
+	}
+	if match == nil {
+		return nil
+	}
+
+	s.log.Warn("Unsafe destination refused",
+		logger.String("url", rawURL),
+		logger.String("source", match.Source),
+		logger.String("pattern", match.Pattern))
+	return fmt.Errorf("%w: %s", models.ErrUnsafeURL, match)
+}
+
+// matchCustomDomain refuses destinations on a verified custom domain. They
+// are looked up on every check, so a domain counts from the moment it is
+// verified.
+func (s *linkSafetyService) matchCustomDomain(ctx context.Context, rawURL string) (*linksafety.Match, error) {
+	host := linksafety.Hostname(rawURL)
+	if host == "" {
+		return nil, nil
+	}
+	if _, err := s.domainRepo.FindVerifiedByHostname(ctx, host); err != nil {
+		if errors.Is(err, models.ErrDomainNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to look up custom domain: %w", err)
+	}
+	return &linksafety.Match{Source: linksafety.SourceSelf, Pattern: host}, nil
+}
+
+// Reload recompiles the blocklist when entries were added or removed, and
+// reloads the threat feed when its file changed. A feed that cannot be read
+// keeps the previous one in use.
+func (s *linkSafetyService) Reload(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.reload(ctx)
+}
+
+func (s *linkSafetyService) reload(ctx context.Context) error {
+	entries, err := s.repo.AllEntries(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load blocklist: %w", err)
+	}
+
+	ids := make([]string, len(entries))
+	rules := make([]linksafety.Rule, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.ID
+		rules[i] = linksafety.Rule{ID: entry.ID, Kind: entry.Kind, Pattern: entry.Pattern}
+	}
+	// Entries are never edited, only added and removed, so their IDs
+	// identify the blocklist
+	if key := strings.Join(ids, ","); key != s.rulesKey || !s.rulesLoaded {
+		if err := s.checker.SetRules(rules); err != nil {
+			s.log.Error("Skipped invalid blocklist entries", logger.NamedError("error", err))
+		}
+		s.rulesLoaded, s.rulesKey = true, key
+		s.log.Info("Blocklist loaded", logger.Int("entries", len(entries)))
+	}
+
+	if s.cfg.ThreatFeed == "" {
+		return nil
+	}
+	info, err := os.Stat(s.cfg.ThreatFeed)
+	if err != nil {
+		s.log.Error("Failed to read threat feed",
+			logger.NamedError("error", err),
+			logger.String("path", s.cfg.ThreatFeed))
+		return nil
+	}
+	if info.ModTime().Equal(s.feedModTime) && info.Size() == s.feedSize {
+		return nil
+	}
+	feed, err := linksafety.LoadFeedFile(s.cfg.ThreatFeed)
+	if err != nil {
+		s.log.Error("Failed to load threat feed",
+			logger.NamedError("error", err),
+			logger.String("path", s.cfg.ThreatFeed))
+		return nil
+	}
+	s.checker.SetFeed(feed)
+	s.feedModTime, s.feedSize = info.ModTime(), info.Size()
+	s.log.Info("Threat feed loaded",
+		logger.String("path", s.cfg.ThreatFeed),
+		logger.Int("entries", feed.Len()))
+	return nil
+}
+
+// ScanLinks checks every link not yet flagged or reviewed once the blocklist
+// or feed changed, and flags those with an unsafe destination. Flagged links
+// stop redirecting until an admin reviews them. Addresses are not resolved,
+// so IP range entries only catch hosts written as addresses here.
+func (s *linkSafetyService) ScanLinks(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.reload(ctx); err != nil {
+		return err
+	}
+	version := s.checker.Version()
+	if version == s.scannedVersion {
+		return nil
+	}
+
+	var scanned, flagged int
+	afterID := ""
+	for {
+		urls, err := s.repo.FindUnchecked(ctx, afterID, s.cfg.ScanBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to load links: %w", err)
+		}
+		for i := range urls {
+			ok, err := s.scanLink(ctx, &urls[i])
+			if err != nil {
+				return err
+			}
+			if ok {
+				flagged++
+			}
+		}
+		scanned += len(urls)
+		if len(urls) < s.cfg.ScanBatchSize {
+			break
+		}
+		afterID = urls[len(urls)-1].ID
+	}
+
+	s.scannedVersion = version
+	s.log.Info("Link safety scan finished",
+		logger.Int("scanned", scanned),
+		logger.Int("flagged", flagged))
+	return nil
+}
+
+// scanLink flags url when one of its destinations matches, reporting
+// whether it did
+func (s *linkSafetyService) scanLink(ctx context.Context, url *models.URL) (bool, error) {
+	for _, destination := range url.Destinations() {
+		match := s.checker.Match(destination)
+		if match == nil {
+			var err error
+			if match, err = s.matchCustomDomain(ctx, destination); err != nil {
+				return false, err
+			}
+		}
+		if match == nil {
+			continue
+		}
+
+		flag := &models.LinkFlag{
+			URLID:       url.ID,
+			Destination: destination,
+			Source:      match.Source,
+			Reason:      match.String(),
+			EntryID:     match.RuleID,
+			Status:      models.LinkFlagPending,
+		}
+		ok, err := s.repo.Flag(ctx, flag)
+		if err != nil {
+			return false, fmt.Errorf("failed to flag link: %w", err)
+		}
+		if ok {
+			s.log.Warn("Link flagged as unsafe",
+				logger.String("urlID", url.ID),
+				logger.String("destination", destination),
+				logger.String("reason", flag.Reason))
+		}
+		return ok, nil
+	}
+	return false, nil
+}
+
+func (s *linkSafetyService) AddBlocklistEntry(ctx context.Context, adminID string, req *models.CreateBlocklistEntryRequest) (*models.BlocklistEntry, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	pattern, err := linksafety.NormalizeRule(req.Kind, req.Pattern)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", models.ErrInvalidBlocklistEntry, err)
+	}
+
+	entry := &models.BlocklistEntry{
+		Kind:      req.Kind,
+		Pattern:   pattern,
+		Reason:    strings.TrimSpace(req.Reason),
+		CreatedBy: adminID,
+	}
+	if err := s.repo.CreateEntry(ctx, entry); err != nil {
+		if errors.Is(err, models.ErrBlocklistEntryExists) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to create blocklist entry: %w", err)
+	}
+
+	s.log.Info("Blocklist entry added",
+		logger.String("entryID", entry.ID),
+		logger.String("kind", entry.Kind),
+		logger.String("pattern", entry.Pattern),
+		logger.String("adminID", adminID))
+
+	if err := s.Reload(ctx); err != nil {
+		s.log.Error("Failed to reload blocklist", logger.NamedError("error", err))
+	}
+	return entry, nil
+}
+
+func (s *linkSafetyService) ListBlocklistEntries(ctx context.Context, kind string, page, limit int) ([]models.BlocklistEntry, int64, error) {
+	entries, total, err := s.repo.ListEntries(ctx, kind, (page-1)*limit, limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list blocklist entries: %w", err)
+	}
+	return entries, total, nil
+}
+
+// DeleteBlocklistEntry removes an entry. Links it flagged stay in the review
+// queue.
+func (s *linkSafetyService) DeleteBlocklistEntry(ctx context.Context, id string) error {
+	if err := s.repo.DeleteEntry(ctx, id); err != nil {
+		if errors.Is(err, models.ErrBlocklistEntryNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to delete blocklist entry: %w", err)
+	}
+
+	s.log.Info("Blocklist entry deleted", logger.String("entryID", id))
+
+	if err := s.Reload(ctx); err != nil {
+		s.log.Error("Failed to reload blocklist", logger.NamedError("error", err))
+	}
+	return nil
+}
+
+func (s *linkSafetyService) ListFlags(ctx context.Context, status string, page, limit int) ([]models.LinkFlag, int64, error) {
+	switch status {
+	case "", models.LinkFlagPending, models.LinkFlagApproved, models.LinkFlagBlocked:
+	default:
+		return nil, 0, fmt.Errorf("%w: status must be pending, approved or blocked", models.ErrInvalidInput)
+	}
+
+	flags, total, err := s.repo.ListFlags(ctx, status, (page-1)*limit, limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list link flags: %w", err)
+	}
+	return flags, total, nil
+}
+
+// ReviewFlag settles a pending flag. Approved links redirect again and are
+// not flagged by later scans; blocked links stay disabled.
+func (s *linkSafetyService) ReviewFlag(ctx context.Context, adminID, id string, req *models.ReviewLinkFlagRequest) (*models.LinkFlag, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	flag, err := s.repo.FindFlag(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if flag.Status != models.LinkFlagPending {
+		return nil, models.ErrLinkFlagReviewed
+	}
+
+	urlStatus := models.URLSafetyApproved
+	flag.Status = models.LinkFlagApproved
+	if req.Action == "block" {
+		urlStatus = models.URLSafetyBlocked
+		flag.Status = models.LinkFlagBlocked
+	}
+	flag.ReviewedBy = adminID
+	flag.ReviewNote = strings.TrimSpace(req.Note)
+
+	if err := s.repo.Review(ctx, flag, urlStatus, time.Now().UTC()); err != nil {
+		if errors.Is(err, models.ErrLinkFlagReviewed) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to review link flag: %w", err)
+	}
+
+	s.log.Info("Link flag reviewed",
+		logger.String("flagID", flag.ID),
+		logger.String("urlID", flag.URLID),
+		logger.String("status", flag.Status),
+		logger.String("adminID", adminID))
+
+	return s.repo.FindFlag(ctx, id)
+}
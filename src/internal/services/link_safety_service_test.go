@@ -0,0 +1,55 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/imraushankr/brevity/server/src/configs"
+	"github.com/imraushankr/brevity/server/src/internal/models"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/linksafety"
+)
+
+func TestCheckURLSelfHosts(t *testing.T) {
+	verifiedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	domains := map[string]*models.Domain{
+		"d1": {ID: "d1", UserID: "alice", Hostname: "go.acme.example", VerifiedAt: &verifiedAt},
+		"d2": {ID: "d2", UserID: "bob", Hostname: "links.bob.example"},
+	}
+
+	tests := []struct {
+		name    string
+		url     string
+		repoErr error
+		wantErr error
+	}{
+		{name: "other host", url: "https://example.com/page"},
+		{name: "base url host", url: "https://sho.rt/abc", wantErr: models.ErrUnsafeURL},
+		{name: "verified custom domain", url: "https://go.acme.example/abc", wantErr: models.ErrUnsafeURL},
+		{name: "verified custom domain in another case", url: "https://GO.Acme.Example./abc", wantErr: models.ErrUnsafeURL},
+		{name: "unverified custom domain", url: "https://links.bob.example/abc"},
+		{name: "subdomain of a custom domain", url: "https://www.go.acme.example/abc"},
+		{name: "repository error", url: "https://example.com/page", repoErr: errors.New("database is locked")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeDomainRepository{domains: domains, err: tt.repoErr}
+			svc := NewLinkSafetyService(nil, repo, linksafety.New([]string{"sho.rt"}, nil, 0), &configs.LinkSafetyConfig{})
+
+			err := svc.CheckURL(context.Background(), tt.url)
+			switch {
+			case tt.repoErr != nil:
+				if !errors.Is(err, tt.repoErr) {
+					t.Errorf("CheckURL: err = %v, want %v", err, tt.repoErr)
+				}
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("CheckURL: err = %v, want %v", err, tt.wantErr)
+				}
+			case err != nil:
+				t.Errorf("CheckURL: %v", err)
+			}
+		})
+	}
+}
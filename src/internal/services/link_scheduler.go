@@ -0,0 +1,48 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/imraushankr/brevity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/brevity/server/src/internal/repository"
+)
+
+// linkScheduler implements LinkScheduler interface
+type linkScheduler struct {
+	scheduleRepo repository.LinkScheduleRepository
+	log          logger.Logger
+}
+
+// NewLinkScheduler creates a scheduler that keeps IsActive in step with each
+// link's activation window
+func NewLinkScheduler(scheduleRepo repository.LinkScheduleRepository) LinkScheduler {
+	return &linkScheduler{
+		scheduleRepo: scheduleRepo,
+		log:          logger.Get(),
+	}
+}
+
+// Apply expires links before activating others, so a link whose whole window
+// passed while the scheduler was down is never switched on
+func (s *linkScheduler) Apply(ctx context.Context) error {
+	now := time.Now().UTC()
+
+	expired, err := s.scheduleRepo.ExpireDue(ctx, now)
+	if err != nil {
+		return fmt.Errorf("failed to expire links: %w", err)
+	}
+	activated, err := s.scheduleRepo.ActivateDue(ctx, now)
+	if err != nil {
+		return fmt.Errorf("failed to activate links: %w", err)
+	}
+
+	for _, t := range append(expired, activated...) {
+		s.log.Info("Link schedule applied",
+			logger.String("urlID", t.URLID),
+			logger.String("reason", string(t.Reason)),
+			logger.Duration("delay", now.Sub(t.ScheduledAt)))
+	}
+	return nil
+}
@@ -0,0 +1,325 @@
+package services
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"mime/multipart"
+	"sync"
+
+	"github.com/imraushankr/brevity/server/src/configs"
+	"github.com/imraushankr/brevity/server/src/internal/models"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/qrcode"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/storage"
+	"github.com/imraushankr/brevity/server/src/internal/repository"
+)
+
+// maxQRLogoDimension caps logo width and height so a small file cannot
+// decode into a huge image
+const maxQRLogoDimension = 4096
+
+// qrLogoFolder is the storage folder logos are uploaded to
+const qrLogoFolder = "qr-logos"
+
+// qrService implements QRService interface
+type qrService struct {
+	urlRepo repository.URLRepository
+	storage storage.Storage
+	cache   *qrCache
+	cfg     *configs.Config
+	log     logger.Logger
+}
+
+// NewQRService creates a QR code service. Rendered images are kept in an
+// in-memory cache of cfg.QR.CacheSize entries.
+func NewQRService(urlRepo repository.URLRepository, storage storage.Storage, cfg *configs.Config) QRService {
+	return &qrService{
+		urlRepo: urlRepo,
+		storage: storage,
+		cache:   newQRCache(cfg.QR.CacheSize),
+		cfg:     cfg,
+		log:     logger.Get(),
+	}
+}
+
+// RenderQR draws the short url of a link as a QR code. Images are cached by
+// their parameters, so repeated requests skip encoding and, with a logo,
+// the storage download.
+func (s *qrService) RenderQR(ctx context.Context, userID, urlID string, opts *models.QROptions) (*models.QRImage, error) {
+	url, err := s.findOwnedURL(ctx, userID, urlID)
+	if err != nil {
+		return nil, err
+	}
+
+	withLogo := opts.Logo && url.QRLogoURL != ""
+	renderOpts, level, err := s.parseOptions(opts, withLogo)
+	if err != nil {
+		return nil, err
+	}
+
+	shortURL := url.ToResponse(s.cfg.App.BaseURL).ShortURL
+	key := fmt.Sprintf("%s|%s|%d|%d|%x|%x|%s", shortURL, opts.Format, renderOpts.Size,
+		renderOpts.Margin, renderOpts.Foreground, renderOpts.Background, level)
+	if withLogo {
+		// Logos are replaced under the same storage url, so the link's
+		// update time tells versions apart
+		key += fmt.Sprintf("|%s|%d", url.QRLogoURL, url.UpdatedAt.UnixNano())
+	}
+	if img, ok := s.cache.get(key); ok {
+		return img, nil
+	}
+
+	code, err := qrcode.Encode([]byte(shortURL), level)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode qr code: %w", err)
+	}
+	if renderOpts.Size < code.MinSize(renderOpts.Margin) {
+		return nil, fmt.Errorf("%w: size must be at least %d for this link",
+			models.ErrInvalidQROptions, code.MinSize(renderOpts.Margin))
+	}
+
+	if withLogo {
+		logo, err := s.loadLogo(ctx, url.QRLogoURL)
+		if err != nil {
+			s.log.Error("Failed to load qr logo",
+				logger.NamedError("error", err),
+				logger.String("urlID", url.ID))
+			return nil, fmt.Errorf("failed to load qr logo: %w", err)
+		}
+		renderOpts.Logo = logo
+	}
+
+	img := &models.QRImage{}
+	var buf bytes.Buffer
+	switch opts.Format {
+	case models.QRFormatSVG:
+		img.ContentType = "image/svg+xml"
+		err = code.WriteSVG(&buf, renderOpts)
+	default:
+		img.ContentType = "image/png"
+		err = code.WritePNG(&buf, renderOpts)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to render qr code: %w", err)
+	}
+	img.Data = buf.Bytes()
+	sum := sha256.Sum256([]byte(key))
+	img.ETag = `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	s.cache.add(key, img)
+	s.log.Debug("QR code rendered",
+		logger.String("urlID", url.ID),
+		logger.Int("version", code.Version),
+		logger.Int("bytes", len(img.Data)))
+	return img, nil
+}
+
+// SetLogo uploads the logo drawn over a link's QR codes. PNG, JPEG and GIF
+// images are accepted.
+func (s *qrService) SetLogo(ctx context.Context, userID, urlID string, file multipart.File, header *multipart.FileHeader) (*models.URL, error) {
+	s.log.Info("Uploading qr logo",
+		logger.String("userID", userID),
+		logger.String("urlID", urlID))
+
+	url, err := s.findOwnedURL(ctx, userID, urlID)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.cfg.QR.MaxLogoBytes > 0 && header.Size > s.cfg.QR.MaxLogoBytes {
+		return nil, fmt.Errorf("%w: file must be at most %d bytes", models.ErrInvalidQRLogo, s.cfg.QR.MaxLogoBytes)
+	}
+	config, _, err := image.DecodeConfig(file)
+	if err != nil {
+		return nil, fmt.Errorf("%w: file must be a png, jpeg or gif image", models.ErrInvalidQRLogo)
+	}
+	if config.Width > maxQRLogoDimension || config.Height > maxQRLogoDimension {
+		return nil, fmt.Errorf("%w: image must be at most %dx%d pixels",
+			models.ErrInvalidQRLogo, maxQRLogoDimension, maxQRLogoDimension)
+	}
+
+	logoURL, err := s.storage.UploadFile(ctx, file, header, qrLogoFolder, url.ID)
+	if err != nil {
+		s.log.Error("QR logo upload failed",
+			logger.NamedError("error", err),
+			logger.String("urlID", url.ID))
+		return nil, fmt.Errorf("qr logo upload failed: %w", err)
+	}
+
+	url.QRLogoURL = logoURL
+	if err := s.urlRepo.Update(ctx, url); err != nil {
+		return nil, fmt.Errorf("failed to update url: %w", err)
+	}
+
+	s.log.Info("QR logo uploaded",
+		logger.String("urlID", url.ID),
+		logger.String("logoURL", logoURL))
+	return url, nil
+}
+
+// RemoveLogo stops drawing a logo on a link's QR codes
+func (s *qrService) RemoveLogo(ctx context.Context, userID, urlID string) (*models.URL, error) {
+	url, err := s.findOwnedURL(ctx, userID, urlID)
+	if err != nil {
+		return nil, err
+	}
+	if url.QRLogoURL == "" {
+		return url, nil
+	}
+
+	url.QRLogoURL = ""
+	if err := s.urlRepo.Update(ctx, url); err != nil {
+		return nil, fmt.Errorf("failed to update url: %w", err)
+	}
+	s.log.Info("QR logo removed", logger.String("urlID", url.ID))
+	return url, nil
+}
+
+// parseOptions validates opts and converts them for the encoder
+func (s *qrService) parseOptions(opts *models.QROptions, withLogo bool) (qrcode.Options, qrcode.Level, error) {
+	var out qrcode.Options
+
+	if opts.Format != models.QRFormatPNG && opts.Format != models.QRFormatSVG {
+		return out, 0, fmt.Errorf("%w: format must be png or svg", models.ErrInvalidQROptions)
+	}
+	if opts.Size < 1 || opts.Size > s.cfg.QR.MaxSize {
+		return out, 0, fmt.Errorf("%w: size must be between 1 and %d", models.ErrInvalidQROptions, s.cfg.QR.MaxSize)
+	}
+	if opts.Margin < 0 || opts.Margin > models.MaxQRMargin {
+		return out, 0, fmt.Errorf("%w: margin must be between 0 and %d", models.ErrInvalidQROptions, models.MaxQRMargin)
+	}
+
+	fg, err := qrcode.ParseColor(opts.Foreground)
+	if err != nil {
+		return out, 0, fmt.Errorf("%w: fg: %v", models.ErrInvalidQROptions, err)
+	}
+	bg, err := qrcode.ParseColor(opts.Background)
+	if err != nil {
+		return out, 0, fmt.Errorf("%w: bg: %v", models.ErrInvalidQROptions, err)
+	}
+
+	level := qrcode.LevelM
+	if withLogo {
+		level = qrcode.LevelH
+	}
+	if opts.ECC != "" {
+		if level, err = qrcode.ParseLevel(opts.ECC); err != nil {
+			return out, 0, fmt.Errorf("%w: ecc must be L, M, Q or H", models.ErrInvalidQROptions)
+		}
+	}
+
+	out = qrcode.Options{
+		Size:       opts.Size,
+		Margin:     opts.Margin,
+		Foreground: fg,
+		Background: bg,
+	}
+	return out, level, nil
+}
+
+func (s *qrService) loadLogo(ctx context.Context, logoURL string) (image.Image, error) {
+	file, err := s.storage.OpenFile(ctx, logoURL)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	if s.cfg.QR.MaxLogoBytes > 0 {
+		r = io.LimitReader(file, s.cfg.QR.MaxLogoBytes)
+	}
+	logo, _, err := image.Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode logo: %w", err)
+	}
+	return logo, nil
+}
+
+func (s *qrService) findOwnedURL(ctx context.Context, userID, id string) (*models.URL, error) {
+	url, err := s.urlRepo.FindByID(ctx, id)
+	if err != nil {
+		if !errors.Is(err, models.ErrURLNotFound) {
+			s.log.Error("Failed to find url",
+				logger.NamedError("error", err),
+				logger.String("urlID", id))
+		}
+		return nil, err
+	}
+	if url.UserID != userID {
+		s.log.Warn("QR code access denied",
+			logger.String("userID", userID),
+			logger.String("urlID", id))
+		return nil, models.ErrForbidden
+	}
+	return url, nil
+}
+
+// qrCache is a fixed size, concurrency safe least-recently-used cache of
+// rendered QR codes keyed by their parameters
+type qrCache struct {
+	mu       sync.Mutex
+	capacity int
+	items    map[string]*list.Element
+	order    *list.List
+}
+
+type qrCacheEntry struct {
+	key   string
+	image *models.QRImage
+}
+
+func newQRCache(capacity int) *qrCache {
+	return &qrCache{
+		capacity: capacity,
+		items:    make(map[string]*list.Element, max(capacity, 0)),
+		order:    list.New(),
+	}
+}
+
+func (c *qrCache) get(key string) (*models.QRImage, bool) {
+	if c.capacity <= 0 {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*qrCacheEntry).image, true
+}
+
+func (c *qrCache) add(key string, image *models.QRImage) {
+	if c.capacity <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		el.Value.(*qrCacheEntry).image = image
+		c.order.MoveToFront(el)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&qrCacheEntry{key: key, image: image})
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*qrCacheEntry).key)
+	}
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/imraushankr/brevity/server/src/internal/models"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/brevity/server/src/internal/repository"
+)
+
+// tagService implements TagService interface
+type tagService struct {
+	tagRepo repository.TagRepository
+	log     logger.Logger
+}
+
+// NewTagService creates a new tag service instance
+func NewTagService(tagRepo repository.TagRepository) TagService {
+	return &tagService{
+		tagRepo: tagRepo,
+		log:     logger.Get(),
+	}
+}
+
+func (s *tagService) ListTags(ctx context.Context, userID string) ([]models.Tag, error) {
+	tags, err := s.tagRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+	return tags, nil
+}
+
+// DeleteTag removes a tag from all of the user's links
+func (s *tagService) DeleteTag(ctx context.Context, userID, id string) error {
+	s.log.Info("Deleting tag",
+		logger.String("userID", userID),
+		logger.String("tagID", id))
+
+	tag, err := s.tagRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if tag.UserID != userID {
+		s.log.Warn("Tag access denied",
+			logger.String("userID", userID),
+			logger.String("tagID", id))
+		return models.ErrForbidden
+	}
+	return s.tagRepo.Delete(ctx, id)
+}
+
+// BulkTag adds the tags to every link in the request the user owns
+func (s *tagService) BulkTag(ctx context.Context, userID string, req *models.BulkTagRequest) (*models.BulkTagResult, error) {
+	s.log.Info("Bulk tagging urls",
+		logger.String("userID", userID),
+		logger.Int("count", len(req.URLIDs)))
+
+	names, err := s.validateBulk(req)
+	if err != nil {
+		return nil, err
+	}
+	found, added, err := s.tagRepo.AddToURLs(ctx, userID, req.URLIDs, names)
+	if err != nil {
+		return nil, fmt.Errorf("failed to tag urls: %w", err)
+	}
+	return &models.BulkTagResult{URLs: found, Changed: added}, nil
+}
+
+// BulkUntag removes the tags from every link in the request the user owns
+func (s *tagService) BulkUntag(ctx context.Context, userID string, req *models.BulkTagRequest) (*models.BulkTagResult, error) {
+	s.log.Info("Bulk untagging urls",
+		logger.String("userID", userID),
+		logger.Int("count", len(req.URLIDs)))
+
+	names, err := s.validateBulk(req)
+	if err != nil {
+		return nil, err
+	}
+	found, removed, err := s.tagRepo.RemoveFromURLs(ctx, userID, req.URLIDs, names)
+	if err != nil {
+		return nil, fmt.Errorf("failed to untag urls: %w", err)
+	}
+	return &models.BulkTagResult{URLs: found, Changed: removed}, nil
+}
+
+func (s *tagService) validateBulk(req *models.BulkTagRequest) ([]string, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+	return models.NormalizeTags(req.Tags)
+}
@@ -1 +1,1038 @@
-package services
\ No newline at end of file
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	neturl "net/url"
+	"strings"
+	"time"
+
+	"github.com/imraushankr/brevity/server/src/configs"
+	"github.com/imraushankr/brevity/server/src/internal/models"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/auth"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/email"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/pagination"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/shortcode"
+	"github.com/imraushankr/brevity/server/src/internal/repository"
+	"github.com/imraushankr/brevity/server/src/internal/utils"
+)
+
+// maxRedirectRules caps the rules on one url, since every redirect walks them
+const maxRedirectRules = 20
+
+var (
+	errBulkBatchRejected = errors.New("not created: batch contains invalid rows")
+	errBulkRowNotCreated = errors.New("not created: failed to store url")
+)
+
+// urlService implements URLService interface
+type urlService struct {
+	urlRepo      repository.URLRepository
+	userRepo     repository.UserRepository
+	domainRepo   repository.DomainRepository
+	campaignRepo repository.CampaignRepository
+	safety       LinkSafetyService
+	generator    shortcode.Generator
+	email        *email.EmailService
+	reserved     map[string]struct{}
+	appHost      string
+	cfg          *configs.Config
+	log          logger.Logger
+}
+
+// NewURLService creates a new url service instance
+func NewURLService(
+	urlRepo repository.URLRepository,
+	userRepo repository.UserRepository,
+	domainRepo repository.DomainRepository,
+	campaignRepo repository.CampaignRepository,
+	safety LinkSafetyService,
+	generator shortcode.Generator,
+	email *email.EmailService,
+	cfg *configs.Config,
+) URLService {
+	reserved := make(map[string]struct{}, len(cfg.ShortCode.Reserved))
+	for _, word := range cfg.ShortCode.Reserved {
+		reserved[strings.ToLower(strings.TrimSpace(word))] = struct{}{}
+	}
+
+	var appHost string
+	if base, err := neturl.Parse(cfg.App.BaseURL); err == nil {
+		appHost = models.NormalizeHostname(base.Hostname())
+	}
+
+	return &urlService{
+		urlRepo:      urlRepo,
+		userRepo:     userRepo,
+		domainRepo:   domainRepo,
+		campaignRepo: campaignRepo,
+		safety:       safety,
+		generator:    generator,
+		email:        email,
+		reserved:     reserved,
+		appHost:      appHost,
+		cfg:          cfg,
+		log:          logger.Get(),
+	}
+}
+
+func (s *urlService) CreateURL(ctx context.Context, userID string, req *models.CreateURLRequest) (*models.URL, error) {
+	s.log.Info("Creating short url",
+		logger.String("userID", userID),
+		logger.String("originalURL", req.OriginalURL))
+
+	url, err := s.prepareURL(ctx, userID, req, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.createURL(ctx, url, req.CustomCode == "", nil); err != nil {
+		if errors.Is(err, models.ErrShortCodeExists) || errors.Is(err, models.ErrShortCodeGeneration) {
+			return nil, err
+		}
+		s.log.Error("URL creation failed",
+			logger.NamedError("error", err),
+			logger.String("userID", userID))
+		return nil, fmt.Errorf("url creation failed: %w", err)
+	}
+
+	s.log.Info("Short url created successfully",
+		logger.String("urlID", url.ID),
+		logger.String("shortCode", url.ShortCode))
+	return url, nil
+}
+
+func (s *urlService) BulkCreateURLs(ctx context.Context, userID string, reqs []models.CreateURLRequest, partial bool) (*models.BulkCreateResult, error) {
+	s.log.Info("Bulk creating short urls",
+		logger.String("userID", userID),
+		logger.Int("count", len(reqs)),
+		logger.Bool("partial", partial))
+
+	result := &models.BulkCreateResult{
+		Total:   len(reqs),
+		Results: make([]models.BulkRowResult, len(reqs)),
+	}
+
+	// Validate every row first, keeping codes unique within the batch
+	urls := make([]*models.URL, len(reqs))
+	valid := make([]*models.URL, 0, len(reqs))
+	taken := make(map[string]struct{}, len(reqs))
+	for i := range reqs {
+		result.Results[i].Row = i + 1
+
+		url, err := s.prepareURL(ctx, userID, &reqs[i], taken)
+		if err != nil {
+			if !isBulkRowError(err) {
+				return nil, err
+			}
+			setBulkRowError(&result.Results[i], err)
+			continue
+		}
+		taken[codeKey(url.DomainID, url.ShortCode)] = struct{}{}
+		urls[i] = url
+		valid = append(valid, url)
+	}
+
+	switch {
+	case len(valid) == 0:
+	case partial:
+		for i, url := range urls {
+			if url == nil {
+				continue
+			}
+			if err := s.createURL(ctx, url, reqs[i].CustomCode == "", taken); err != nil {
+				if !isBulkRowError(err) {
+					s.log.Error("Bulk url creation failed",
+						logger.NamedError("error", err),
+						logger.Int("row", i+1))
+					err = errBulkRowNotCreated
+				}
+				setBulkRowError(&result.Results[i], err)
+				continue
+			}
+			s.setBulkRowCreated(&result.Results[i], url)
+		}
+	case len(valid) < len(reqs):
+		// All or nothing: one bad row rejects the whole batch
+		for i, url := range urls {
+			if url != nil {
+				setBulkRowError(&result.Results[i], errBulkBatchRejected)
+			}
+		}
+	default:
+		if err := s.createBatch(ctx, valid, reqs, urls, taken); err != nil {
+			if !errors.Is(err, models.ErrShortCodeExists) {
+				s.log.Error("Bulk url creation failed",
+					logger.NamedError("error", err),
+					logger.String("userID", userID))
+				return nil, fmt.Errorf("bulk url creation failed: %w", err)
+			}
+			for i := range result.Results {
+				setBulkRowError(&result.Results[i], err)
+			}
+			break
+		}
+		for i, url := range urls {
+			s.setBulkRowCreated(&result.Results[i], url)
+		}
+	}
+
+	for _, row := range result.Results {
+		if row.Created {
+			result.Created++
+		} else {
+			result.Failed++
+		}
+	}
+
+	s.log.Info("Bulk url creation finished",
+		logger.String("userID", userID),
+		logger.Int("created", result.Created),
+		logger.Int("failed", result.Failed))
+	return result, nil
+}
+
+func (s *urlService) GetURL(ctx context.Context, userID, id string) (*models.URL, error) {
+	s.log.Debug("Fetching url",
+		logger.String("userID", userID),
+		logger.String("urlID", id))
+
+	return s.findOwnedURL(ctx, userID, id)
+}
+
+func (s *urlService) ListURLs(ctx context.Context, userID string, q *pagination.Query) ([]models.URL, *pagination.Page, error) {
+	s.log.Debug("Listing urls",
+		logger.String("userID", userID),
+		logger.Int("limit", q.Limit))
+
+	urls, page, err := s.urlRepo.FindByUserID(ctx, userID, q)
+	if err != nil {
+		if errors.Is(err, pagination.ErrInvalid) {
+			return nil, nil, err
+		}
+		return nil, nil, fmt.Errorf("failed to list urls: %w", err)
+	}
+	return urls, page, nil
+}
+
+// SearchURLs finds the user's links whose title, description, destination or
+// short code match query. Admins search every user's links.
+func (s *urlService) SearchURLs(ctx context.Context, userID string, isAdmin bool, query string, page, limit int) ([]models.URLSearchHit, int64, error) {
+	s.log.Debug("Searching urls",
+		logger.String("userID", userID),
+		logger.String("query", query))
+
+	match, err := models.SearchMatch(query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	owner := userID
+	if isAdmin {
+		owner = ""
+	}
+	hits, total, err := s.urlRepo.Search(ctx, owner, match, (page-1)*limit, limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search urls: %w", err)
+	}
+	return hits, total, nil
+}
+
+func (s *urlService) UpdateURL(ctx context.Context, userID, id string, req *models.UpdateURLRequest) (*models.URL, error) {
+	s.log.Info("Updating url",
+		logger.String("userID", userID),
+		logger.String("urlID", id))
+
+	if err := req.Validate(); err != nil {
+		s.log.Warn("URL update validation failed",
+			logger.NamedError("error", err),
+			logger.String("urlID", id))
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	url, err := s.findOwnedURL(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// The scheduler switches links off once they expire
+	expired := url.IsExpired() && !url.IsActive
+
+	if req.Title != nil {
+		url.Title = *req.Title
+	}
+	if req.Description != nil {
+		url.Description = *req.Description
+	}
+	switch {
+	case req.ClearExpiry:
+		url.ExpiresAt = nil
+	case req.ExpiresAt != nil:
+		if !req.ExpiresAt.After(time.Now()) {
+			return nil, models.ErrInvalidExpiry
+		}
+		expiresAt := req.ExpiresAt.UTC()
+		url.ExpiresAt = &expiresAt
+	}
+	switch {
+	case req.ClearActivation:
+		// A link waiting for its activation time goes live right away
+		if url.IsPending() {
+			url.IsActive = true
+		}
+		url.ActivatesAt = nil
+	case req.ActivatesAt != nil:
+		activatesAt := req.ActivatesAt.UTC()
+		url.ActivatesAt = &activatesAt
+		if url.IsPending() {
+			url.IsActive = false
+		}
+	}
+	if !validWindow(url.ActivatesAt, url.ExpiresAt) {
+		return nil, models.ErrInvalidActivationWindow
+	}
+	if expired && !url.IsExpired() && !url.IsPending() {
+		// Moving the expiry out puts a link the scheduler expired back live.
+		// One its owner switched off stays off.
+		revive, err := s.urlRepo.ExpiredBySchedule(ctx, url.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check url schedule: %w", err)
+		}
+		url.IsActive = revive
+	}
+	switch {
+	case req.ClearFallback:
+		url.FallbackURL = ""
+	case req.FallbackURL != nil:
+		if err := s.checkDestination(ctx, "fallback_url", *req.FallbackURL); err != nil {
+			return nil, err
+		}
+		url.FallbackURL = *req.FallbackURL
+	}
+	self := url.ToResponse(s.cfg.App.BaseURL).ShortURL
+	if req.Rules != nil {
+		if err := s.validateRules(ctx, self, *req.Rules); err != nil {
+			return nil, err
+		}
+		url.Rules = *req.Rules
+		if len(url.Rules) == 0 {
+			url.Rules = nil
+		}
+	}
+	if req.Variants != nil {
+		variants, err := s.prepareVariants(ctx, self, *req.Variants, url.Variants)
+		if err != nil {
+			return nil, err
+		}
+		url.Variants = variants
+	}
+	if req.UTM != nil {
+		url.UTM = trimUTM(req.UTM)
+	}
+	if req.OG != nil {
+		url.OG = trimOG(req.OG)
+	}
+	if req.ForwardQuery != nil {
+		url.ForwardQuery = *req.ForwardQuery
+	}
+	if req.RedirectType != nil {
+		url.RedirectType = *req.RedirectType
+	}
+	switch {
+	case req.ClearMaxClicks:
+		url.MaxClicks = nil
+	case req.MaxClicks != nil:
+		url.MaxClicks = req.MaxClicks
+	}
+	switch {
+	case req.ClearCampaign:
+		url.CampaignID = nil
+	case req.CampaignID != nil:
+		if err := s.checkCampaign(ctx, userID, *req.CampaignID); err != nil {
+			return nil, err
+		}
+		url.CampaignID = req.CampaignID
+	}
+	var tags []string
+	if req.Tags != nil {
+		if tags, err = models.NormalizeTags(*req.Tags); err != nil {
+			return nil, err
+		}
+	}
+	switch {
+	case req.ClearPassword:
+		url.PasswordHash = ""
+	case req.Password != nil:
+		hash, err := auth.EncryptPassword(*req.Password)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash link password: %w", err)
+		}
+		url.PasswordHash = hash
+	}
+
+	if err := s.urlRepo.Update(ctx, url); err != nil {
+		s.log.Error("Failed to update url",
+			logger.NamedError("error", err),
+			logger.String("urlID", id))
+		return nil, fmt.Errorf("failed to update url: %w", err)
+	}
+	if req.Tags != nil {
+		url.Tags = tagsNamed(userID, tags)
+		if err := s.urlRepo.ReplaceTags(ctx, url); err != nil {
+			return nil, fmt.Errorf("failed to update url tags: %w", err)
+		}
+	}
+
+	s.log.Info("URL updated successfully", logger.String("urlID", id))
+	return url, nil
+}
+
+func (s *urlService) DeactivateURL(ctx context.Context, userID, id string) error {
+	s.log.Info("Deactivating url",
+		logger.String("userID", userID),
+		logger.String("urlID", id))
+
+	if _, err := s.findOwnedURL(ctx, userID, id); err != nil {
+		return err
+	}
+
+	if err := s.urlRepo.Deactivate(ctx, id); err != nil {
+		s.log.Error("Failed to deactivate url",
+			logger.NamedError("error", err),
+			logger.String("urlID", id))
+		return fmt.Errorf("failed to deactivate url: %w", err)
+	}
+
+	s.log.Info("URL deactivated successfully", logger.String("urlID", id))
+	return nil
+}
+
+func (s *urlService) DeleteURL(ctx context.Context, userID, id string) error {
+	s.log.Info("Deleting url",
+		logger.String("userID", userID),
+		logger.String("urlID", id))
+
+	if _, err := s.findOwnedURL(ctx, userID, id); err != nil {
+		return err
+	}
+
+	if err := s.urlRepo.Delete(ctx, id); err != nil {
+		s.log.Error("Failed to delete url",
+			logger.NamedError("error", err),
+			logger.String("urlID", id))
+		return fmt.Errorf("failed to delete url: %w", err)
+	}
+
+	s.log.Info("URL deleted successfully", logger.String("urlID", id))
+	return nil
+}
+
+// ResolveShortCode finds the link for shortCode on the domain serving host.
+// Hosts that are not a verified custom domain serve the default domain.
+func (s *urlService) ResolveShortCode(ctx context.Context, host, shortCode string) (*models.URL, error) {
+	s.log.Debug("Resolving short code",
+		logger.String("host", host),
+		logger.String("shortCode", shortCode))
+
+	domainID, err := s.domainForHost(ctx, host)
+	if err != nil {
+		s.log.Error("Failed to resolve domain",
+			logger.NamedError("error", err),
+			logger.String("host", host))
+		return nil, err
+	}
+
+	url, err := s.urlRepo.FindByShortCode(ctx, domainID, shortCode)
+	if err != nil {
+		if !errors.Is(err, models.ErrURLNotFound) {
+			s.log.Error("Failed to resolve short code",
+				logger.NamedError("error", err),
+				logger.String("shortCode", shortCode))
+		}
+		return nil, err
+	}
+
+	if url.IsDisabledForSafety() {
+		return url, models.ErrURLFlagged
+	}
+	// The window is checked here rather than trusting IsActive, so links
+	// open and close on time even when the scheduler runs late
+	if url.IsExpired() {
+		return url, models.ErrURLExpired
+	}
+	if url.IsPending() {
+		return url, models.ErrURLNotYetActive
+	}
+	if !url.IsLive() {
+		return url, models.ErrURLInactive
+	}
+	if url.IsExhausted() {
+		return url, models.ErrURLExhausted
+	}
+	return url, nil
+}
+
+// ConsumeClick spends one click of a click limited url before it is followed.
+// The owner is emailed when the last click is spent.
+func (s *urlService) ConsumeClick(ctx context.Context, url *models.URL) error {
+	if url.MaxClicks == nil {
+		return nil
+	}
+
+	claimed, err := s.urlRepo.ClaimClick(ctx, url.ID)
+	if err != nil {
+		if errors.Is(err, models.ErrURLExhausted) {
+			return err
+		}
+		return fmt.Errorf("failed to claim click: %w", err)
+	}
+
+	url.ClaimedClicks = claimed
+	if url.IsExhausted() {
+		s.log.Info("URL reached its click limit",
+			logger.String("urlID", url.ID),
+			logger.Int("maxClicks", *url.MaxClicks))
+		go s.notifyExhausted(*url)
+	}
+	return nil
+}
+
+// CheckURLPassword verifies a visitor's password for a protected url
+func (s *urlService) CheckURLPassword(url *models.URL, password string) error {
+	if !url.HasPassword() {
+		return nil
+	}
+	if err := auth.IsPasswordCorrect(password, url.PasswordHash); err != nil {
+		s.log.Debug("Link password rejected", logger.String("shortCode", url.ShortCode))
+		return models.ErrInvalidLinkPassword
+	}
+	return nil
+}
+
+// notifyExhausted emails the owner of a url that ran out of clicks. It runs
+// in the background so redirects never wait on SMTP.
+func (s *urlService) notifyExhausted(url models.URL) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	owner, err := s.userRepo.FindByID(ctx, url.UserID)
+	if err != nil {
+		s.log.Error("Failed to load url owner for click limit notification",
+			logger.NamedError("error", err),
+			logger.String("urlID", url.ID))
+		return
+	}
+
+	shortURL := url.ToResponse(s.cfg.App.BaseURL).ShortURL
+	if err := s.email.SendLinkExhaustedEmail(owner.Email, shortURL, url.OriginalURL, *url.MaxClicks); err != nil {
+		s.log.Error("Failed to send click limit notification",
+			logger.NamedError("error", err),
+			logger.String("urlID", url.ID))
+	}
+}
+
+// prepareURL validates a create request and builds the URL with its short
+// code. Codes in taken count as used, so a batch never repeats a code.
+func (s *urlService) prepareURL(ctx context.Context, userID string, req *models.CreateURLRequest, taken map[string]struct{}) (*models.URL, error) {
+	if err := req.Validate(); err != nil {
+		s.log.Warn("URL request validation failed",
+			logger.NamedError("error", err),
+			logger.String("userID", userID))
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, models.ErrInvalidExpiry
+	}
+	if !validWindow(req.ActivatesAt, req.ExpiresAt) {
+		return nil, models.ErrInvalidActivationWindow
+	}
+
+	tags, err := models.NormalizeTags(req.Tags)
+	if err != nil {
+		return nil, err
+	}
+	if req.CampaignID != "" {
+		if err := s.checkCampaign(ctx, userID, req.CampaignID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.checkDestination(ctx, "original_url", req.OriginalURL); err != nil {
+		return nil, err
+	}
+	if req.FallbackURL != "" {
+		if err := s.checkDestination(ctx, "fallback_url", req.FallbackURL); err != nil {
+			return nil, err
+		}
+	}
+
+	var domain *models.Domain
+	var domainID string
+	if req.Domain != "" {
+		d, err := s.findVerifiedDomain(ctx, userID, req.Domain)
+		if err != nil {
+			return nil, err
+		}
+		domain, domainID = d, d.ID
+	}
+
+	// Generated codes cannot collide with a target, so only custom codes
+	// are checked for self references
+	var self string
+	if req.CustomCode != "" {
+		self = models.ShortURL(s.cfg.App.BaseURL, domain, req.CustomCode)
+	}
+	if err := s.validateRules(ctx, self, req.Rules); err != nil {
+		return nil, err
+	}
+	variants, err := s.prepareVariants(ctx, self, req.Variants, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	shortCode := req.CustomCode
+	if shortCode != "" {
+		if s.isReserved(shortCode) {
+			s.log.Warn("Custom short code is reserved", logger.String("shortCode", shortCode))
+			return nil, models.ErrShortCodeReserved
+		}
+		if _, ok := taken[codeKey(domainID, shortCode)]; ok {
+			return nil, models.ErrShortCodeExists
+		}
+		exists, err := s.urlRepo.ShortCodeExists(ctx, domainID, shortCode)
+		if err != nil {
+			return nil, fmt.Errorf("error checking short code existence: %w", err)
+		}
+		if exists {
+			s.log.Warn("Custom short code already exists", logger.String("shortCode", shortCode))
+			return nil, models.ErrShortCodeExists
+		}
+	} else {
+		code, err := s.generateShortCode(ctx, domainID, taken)
+		if err != nil {
+			s.log.Error("Short code generation failed", logger.NamedError("error", err))
+			return nil, err
+		}
+		shortCode = code
+	}
+
+	redirectType := req.RedirectType
+	if redirectType == 0 {
+		redirectType = models.DefaultRedirectType
+	}
+
+	passwordHash := req.PasswordHash
+	if req.Password != "" {
+		hash, err := auth.EncryptPassword(req.Password)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash link password: %w", err)
+		}
+		passwordHash = hash
+	}
+
+	url := &models.URL{
+		OriginalURL:  req.OriginalURL,
+		DomainID:     domainID,
+		Domain:       domain,
+		ShortCode:    shortCode,
+		UserID:       userID,
+		Title:        req.Title,
+		Description:  req.Description,
+		FallbackURL:  req.FallbackURL,
+		IsActive:     true,
+		RedirectType: redirectType,
+		ForwardQuery: req.ForwardQuery,
+		PasswordHash: passwordHash,
+		MaxClicks:    req.MaxClicks,
+	}
+	if req.UTM != nil {
+		url.UTM = trimUTM(req.UTM)
+	}
+	if req.OG != nil {
+		url.OG = trimOG(req.OG)
+	}
+	if len(req.Rules) > 0 {
+		url.Rules = req.Rules
+	}
+	url.Variants = variants
+	url.Tags = tagsNamed(userID, tags)
+	if req.CampaignID != "" {
+		url.CampaignID = &req.CampaignID
+	}
+	// Times are stored in UTC so the scheduler can compare them in SQL
+	if req.ActivatesAt != nil {
+		activatesAt := req.ActivatesAt.UTC()
+		url.ActivatesAt = &activatesAt
+		url.IsActive = !url.IsPending()
+	}
+	if req.ExpiresAt != nil {
+		expiresAt := req.ExpiresAt.UTC()
+		url.ExpiresAt = &expiresAt
+	}
+	return url, nil
+}
+
+func (s *urlService) setBulkRowCreated(row *models.BulkRowResult, url *models.URL) {
+	row.Created = true
+	row.ID = url.ID
+	row.ShortCode = url.ShortCode
+	row.ShortURL = url.ToResponse(s.cfg.App.BaseURL).ShortURL
+}
+
+// isBulkRowError reports whether err is a problem with a single row rather
+// than a failure of the whole batch
+func isBulkRowError(err error) bool {
+	if _, ok := utils.ValidationErrors(err); ok {
+		return true
+	}
+	var itemErr *models.InvalidItemError
+	if errors.As(err, &itemErr) {
+		return true
+	}
+	return errors.Is(err, models.ErrInvalidExpiry) ||
+		errors.Is(err, models.ErrUnsafeURL) ||
+		errors.Is(err, models.ErrDomainNotFound) ||
+		errors.Is(err, models.ErrDomainNotVerified) ||
+		errors.Is(err, models.ErrInvalidTag) ||
+		errors.Is(err, models.ErrCampaignNotFound) ||
+		errors.Is(err, models.ErrShortCodeExists) ||
+		errors.Is(err, models.ErrShortCodeReserved) ||
+		errors.Is(err, models.ErrShortCodeGeneration)
+}
+
+func setBulkRowError(row *models.BulkRowResult, err error) {
+	row.Created = false
+	if fields, ok := utils.ValidationErrors(err); ok {
+		row.Error = "validation failed"
+		row.Fields = fields
+		return
+	}
+	var itemErr *models.InvalidItemError
+	if errors.As(err, &itemErr) {
+		row.Error = "validation failed"
+		row.Fields = map[string]string{itemErr.Field(): itemErr.Reason}
+		return
+	}
+	row.Error = err.Error()
+}
+
+// validateRules checks every redirect rule. Targets may not point back at
+// the link itself, self, which would loop forever, nor at unsafe
+// destinations.
+func (s *urlService) validateRules(ctx context.Context, self string, rules []models.RedirectRule) error {
+	if len(rules) > maxRedirectRules {
+		return ruleError(maxRedirectRules+1, fmt.Sprintf("at most %d rules are allowed", maxRedirectRules))
+	}
+
+	for i := range rules {
+		if err := rules[i].Validate(); err != nil {
+			return ruleError(i+1, err.Error())
+		}
+		if isSameLink(rules[i].TargetURL, self) {
+			return ruleError(i+1, "target_url must not point at the short link itself")
+		}
+		if err := s.checkDestination(ctx, fmt.Sprintf("rules[%d].target_url", i+1), rules[i].TargetURL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func ruleError(item int, reason string) error {
+	return &models.InvalidItemError{List: "rules", Item: item, Reason: reason, Err: models.ErrInvalidRedirectRule}
+}
+
+// prepareVariants validates a url's split variants and fills in what the
+// owner may leave out: IDs, keeping those of existing variants so their
+// analytics carry over, and names A, B, C and so on. An empty list turns the
+// split off.
+func (s *urlService) prepareVariants(ctx context.Context, self string, variants, existing []models.URLVariant) ([]models.URLVariant, error) {
+	if len(variants) == 0 {
+		return nil, nil
+	}
+	if len(variants) < models.MinVariants {
+		return nil, variantError(1, fmt.Sprintf("a split needs at least %d variants", models.MinVariants))
+	}
+	if len(variants) > models.MaxVariants {
+		return nil, variantError(models.MaxVariants+1, fmt.Sprintf("at most %d variants are allowed", models.MaxVariants))
+	}
+
+	known := make(map[string]struct{}, len(existing))
+	for _, v := range existing {
+		known[v.ID] = struct{}{}
+	}
+
+	prepared := make([]models.URLVariant, len(variants))
+	seen := make(map[string]struct{}, len(variants))
+	for i, v := range variants {
+		if err := v.Validate(); err != nil {
+			return nil, variantError(i+1, err.Error())
+		}
+		if isSameLink(v.TargetURL, self) {
+			return nil, variantError(i+1, "target_url must not point at the short link itself")
+		}
+		if err := s.checkDestination(ctx, fmt.Sprintf("variants[%d].target_url", i+1), v.TargetURL); err != nil {
+			return nil, err
+		}
+
+		if _, ok := known[v.ID]; !ok {
+			id, err := models.NewVariantID()
+			if err != nil {
+				return nil, fmt.Errorf("failed to generate variant id: %w", err)
+			}
+			v.ID = id
+		}
+		if _, dup := seen[v.ID]; dup {
+			return nil, variantError(i+1, "id is used by another variant")
+		}
+		seen[v.ID] = struct{}{}
+
+		if v.Name = strings.TrimSpace(v.Name); v.Name == "" {
+			v.Name = string(rune('A' + i))
+		}
+		prepared[i] = v
+	}
+	return prepared, nil
+}
+
+// trimUTM drops surrounding whitespace from the tags, which would otherwise
+// end up encoded in every destination
+func trimUTM(p *models.UTMParams) models.UTMParams {
+	return models.UTMParams{
+		Source:   strings.TrimSpace(p.Source),
+		Medium:   strings.TrimSpace(p.Medium),
+		Campaign: strings.TrimSpace(p.Campaign),
+		Term:     strings.TrimSpace(p.Term),
+		Content:  strings.TrimSpace(p.Content),
+	}
+}
+
+// trimOG drops surrounding whitespace from the overrides, so a blank value
+// falls back like an empty one
+func trimOG(o *models.OGOverrides) models.OGOverrides {
+	return models.OGOverrides{
+		Title:       strings.TrimSpace(o.Title),
+		Description: strings.TrimSpace(o.Description),
+		ImageURL:    strings.TrimSpace(o.ImageURL),
+	}
+}
+
+func variantError(item int, reason string) error {
+	return &models.InvalidItemError{List: "variants", Item: item, Reason: reason, Err: models.ErrInvalidVariant}
+}
+
+// checkDestination refuses destinations the link safety checks block. field
+// names the offending request field in the error.
+func (s *urlService) checkDestination(ctx context.Context, field, rawURL string) error {
+	if err := s.safety.CheckURL(ctx, rawURL); err != nil {
+		return fmt.Errorf("%s: %w", field, err)
+	}
+	return nil
+}
+
+// isSameLink reports whether target is the short url self. Hosts compare
+// case-insensitively, paths and so short codes exactly. An empty self
+// matches nothing.
+func isSameLink(target, self string) bool {
+	if self == "" {
+		return false
+	}
+	link, err := neturl.Parse(self)
+	if err != nil {
+		return false
+	}
+	u, err := neturl.Parse(target)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, link.Host) &&
+		strings.TrimSuffix(u.Path, "/") == link.Path
+}
+
+// findVerifiedDomain returns the user's domain for hostname, which must be
+// verified before links can use it
+func (s *urlService) findVerifiedDomain(ctx context.Context, userID, hostname string) (*models.Domain, error) {
+	domain, err := s.domainRepo.FindByUserAndHostname(ctx, userID, models.NormalizeHostname(hostname))
+	if err != nil {
+		if errors.Is(err, models.ErrDomainNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to find domain: %w", err)
+	}
+	if !domain.IsVerified() {
+		return nil, models.ErrDomainNotVerified
+	}
+	return domain, nil
+}
+
+// domainForHost returns the ID of the verified domain serving host, or ""
+// for the default domain
+func (s *urlService) domainForHost(ctx context.Context, host string) (string, error) {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = models.NormalizeHostname(host)
+	if host == "" || host == s.appHost {
+		return "", nil
+	}
+
+	domain, err := s.domainRepo.FindVerifiedByHostname(ctx, host)
+	if errors.Is(err, models.ErrDomainNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return domain.ID, nil
+}
+
+// codeKey identifies a short code on a domain in the taken sets used while
+// creating batches
+func codeKey(domainID, code string) string {
+	return domainID + "/" + code
+}
+
+// validWindow reports whether an activation time, when set, comes before the
+// expiry time
+func validWindow(activatesAt, expiresAt *time.Time) bool {
+	return activatesAt == nil || expiresAt == nil || activatesAt.Before(*expiresAt)
+}
+
+// findOwnedURL loads a URL and makes sure it belongs to the given user
+func (s *urlService) findOwnedURL(ctx context.Context, userID, id string) (*models.URL, error) {
+	url, err := s.urlRepo.FindByID(ctx, id)
+	if err != nil {
+		if !errors.Is(err, models.ErrURLNotFound) {
+			s.log.Error("Failed to find url",
+				logger.NamedError("error", err),
+				logger.String("urlID", id))
+		}
+		return nil, err
+	}
+
+	if url.UserID != userID {
+		s.log.Warn("URL access denied",
+			logger.String("userID", userID),
+			logger.String("urlID", id))
+		return nil, models.ErrForbidden
+	}
+	return url, nil
+}
+
+// checkCampaign makes sure the campaign exists and belongs to the given user
+func (s *urlService) checkCampaign(ctx context.Context, userID, id string) error {
+	campaign, err := s.campaignRepo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, models.ErrCampaignNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to find campaign: %w", err)
+	}
+	if campaign.UserID != userID {
+		return models.ErrCampaignNotFound
+	}
+	return nil
+}
+
+// tagsNamed builds unsaved tags of the user with the given names, which the
+// repository resolves to existing tags or creates
+func tagsNamed(userID string, names []string) []models.Tag {
+	if len(names) == 0 {
+		return nil
+	}
+	tags := make([]models.Tag, len(names))
+	for i, name := range names {
+		tags[i] = models.Tag{UserID: userID, Name: name}
+	}
+	return tags
+}
+
+// createURL stores url. When its short code was generated and loses a race
+// with another insert, a fresh code is generated and the insert retried.
+func (s *urlService) createURL(ctx context.Context, url *models.URL, generated bool, taken map[string]struct{}) error {
+	for attempt := 1; ; attempt++ {
+		err := s.urlRepo.Create(ctx, url)
+		if !generated || !errors.Is(err, models.ErrShortCodeExists) || attempt >= s.maxAttempts() {
+			return err
+		}
+
+		s.log.Warn("Generated short code collided, retrying",
+			logger.String("shortCode", url.ShortCode),
+			logger.Int("attempt", attempt))
+		code, err := s.generateShortCode(ctx, url.DomainID, taken)
+		if err != nil {
+			return err
+		}
+		url.ShortCode = code
+		if taken != nil {
+			taken[codeKey(url.DomainID, code)] = struct{}{}
+		}
+	}
+}
+
+// createBatch stores a whole batch atomically. On a short code collision the
+// generated codes are replaced and the batch retried; custom codes are kept.
+func (s *urlService) createBatch(ctx context.Context, batch []*models.URL, reqs []models.CreateURLRequest, urls []*models.URL, taken map[string]struct{}) error {
+	for attempt := 1; ; attempt++ {
+		err := s.urlRepo.CreateBatch(ctx, batch)
+		if !errors.Is(err, models.ErrShortCodeExists) || attempt >= s.maxAttempts() {
+			return err
+		}
+
+		s.log.Warn("Bulk short code collided, retrying", logger.Int("attempt", attempt))
+		for i, url := range urls {
+			if url == nil || reqs[i].CustomCode != "" {
+				continue
+			}
+			code, err := s.generateShortCode(ctx, url.DomainID, taken)
+			if err != nil {
+				return err
+			}
+			url.ShortCode = code
+			taken[codeKey(url.DomainID, code)] = struct{}{}
+		}
+	}
+}
+
+// generateShortCode returns a code from the configured generator that is not
+// reserved and not yet in use on the domain
+func (s *urlService) generateShortCode(ctx context.Context, domainID string, taken map[string]struct{}) (string, error) {
+	for attempt := 0; attempt < s.maxAttempts(); attempt++ {
+		code, err := s.generator.Generate(ctx)
+		if err != nil {
+			return "", fmt.Errorf("short code generation failed: %w", err)
+		}
+		if s.isReserved(code) {
+			continue
+		}
+		if _, ok := taken[codeKey(domainID, code)]; ok {
+			continue
+		}
+
+		exists, err := s.urlRepo.ShortCodeExists(ctx, domainID, code)
+		if err != nil {
+			return "", fmt.Errorf("error checking short code existence: %w", err)
+		}
+		if !exists {
+			return code, nil
+		}
+	}
+	return "", models.ErrShortCodeGeneration
+}
+
+// isReserved reports whether code is on the reserved list, ignoring case
+func (s *urlService) isReserved(code string) bool {
+	_, ok := s.reserved[strings.ToLower(code)]
+	return ok
+}
+
+func (s *urlService) maxAttempts() int {
+	if s.cfg.ShortCode.MaxAttempts > 0 {
+		return s.cfg.ShortCode.MaxAttempts
+	}
+	return 1
+}
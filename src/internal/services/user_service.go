@@ -12,6 +12,7 @@ import (
 	"github.com/imraushankr/brevity/server/src/internal/pkg/auth"
 	"github.com/imraushankr/brevity/server/src/internal/pkg/email"
 	"github.com/imraushankr/brevity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/brevity/server/src/internal/pkg/pagination"
 	"github.com/imraushankr/brevity/server/src/internal/pkg/storage"
 	"github.com/imraushankr/brevity/server/src/internal/repository"
 )
@@ -227,6 +228,23 @@ func (s *userService) DeleteUser(ctx context.Context, id string) error {
 	return nil
 }
 
+// ListUsers returns a page of all users, for admins
+func (s *userService) ListUsers(ctx context.Context, q *pagination.Query) ([]models.User, *pagination.Page, error) {
+	s.log.Debug("Listing users", logger.Int("limit", q.Limit))
+
+	users, page, err := s.userRepo.List(ctx, q)
+	if err != nil {
+		if errors.Is(err, pagination.ErrInvalid) {
+			return nil, nil, err
+		}
+		return nil, nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	for i := range users {
+		users[i].Sanitize()
+	}
+	return users, page, nil
+}
+
 func (s *userService) VerifyEmail(ctx context.Context, token string) error {
 	s.log.Info("Verifying email with token")
 
@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// ValidationErrors converts validator errors into a field -> message map.
+// It returns false if err does not contain validation errors.
+func ValidationErrors(err error) (map[string]string, bool) {
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return nil, false
+	}
+
+	fields := make(map[string]string, len(verrs))
+	for _, fe := range verrs {
+		field := toSnakeCase(fe.Field())
+		if fe.Param() != "" {
+			fields[field] = fmt.Sprintf("failed on '%s=%s'", fe.Tag(), fe.Param())
+		} else {
+			fields[field] = fmt.Sprintf("failed on '%s'", fe.Tag())
+		}
+	}
+	return fields, true
+}
+
+func toSnakeCase(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if r >= 'A' && r <= 'Z' {
+			if i > 0 && !(s[i-1] >= 'A' && s[i-1] <= 'Z') {
+				b.WriteByte('_')
+			}
+			r += 'a' - 'A'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
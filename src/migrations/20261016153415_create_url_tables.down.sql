@@ -0,0 +1,13 @@
+-- Brevity Migration: create_url_tables
+-- Generated: 2026-10-16T15:34:15Z
+-- Direction: DOWN
+
+-- Add your SQL below this line
+
+DROP INDEX IF EXISTS idx_url_clicks_url_id;
+DROP TABLE IF EXISTS url_clicks;
+
+DROP TRIGGER IF EXISTS update_urls_updated_at;
+DROP INDEX IF EXISTS idx_urls_deleted_at;
+DROP INDEX IF EXISTS idx_urls_user_id;
+DROP TABLE IF EXISTS urls;
@@ -0,0 +1,46 @@
+-- Brevity Migration: create_url_tables
+-- Generated: 2026-10-16T15:34:15Z
+-- Direction: UP
+
+-- Add your SQL below this line
+
+CREATE TABLE urls (
+    id VARCHAR(20) PRIMARY KEY,
+    original_url TEXT NOT NULL,
+    short_code VARCHAR(10) NOT NULL UNIQUE,
+    user_id VARCHAR(20) REFERENCES users(id) ON DELETE CASCADE,
+    title VARCHAR(100),
+    description VARCHAR(255),
+    clicks INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP,
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+CREATE INDEX idx_urls_user_id ON urls(user_id);
+CREATE INDEX idx_urls_deleted_at ON urls(deleted_at);
+
+CREATE TABLE url_clicks (
+    id VARCHAR(20) PRIMARY KEY,
+    url_id VARCHAR(20) NOT NULL REFERENCES urls(id) ON DELETE CASCADE,
+    ip_address VARCHAR(45),
+    referrer TEXT,
+    user_agent TEXT,
+    country VARCHAR(2),
+    city VARCHAR(100),
+    device VARCHAR(20),
+    os VARCHAR(20),
+    browser VARCHAR(20),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_url_clicks_url_id ON url_clicks(url_id);
+
+-- Trigger to update updated_at automatically
+CREATE TRIGGER update_urls_updated_at
+AFTER UPDATE ON urls
+BEGIN
+    UPDATE urls SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
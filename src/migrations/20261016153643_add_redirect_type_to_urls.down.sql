@@ -0,0 +1,7 @@
+-- Brevity Migration: add_redirect_type_to_urls
+-- Generated: 2026-10-16T15:36:43Z
+-- Direction: DOWN
+
+-- Add your SQL below this line
+
+ALTER TABLE urls DROP COLUMN redirect_type;
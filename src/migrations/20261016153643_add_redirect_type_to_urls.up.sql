@@ -0,0 +1,7 @@
+-- Brevity Migration: add_redirect_type_to_urls
+-- Generated: 2026-10-16T15:36:43Z
+-- Direction: UP
+
+-- Add your SQL below this line
+
+ALTER TABLE urls ADD COLUMN redirect_type INTEGER NOT NULL DEFAULT 302 CHECK (redirect_type IN (301, 302, 307, 308));
@@ -0,0 +1,9 @@
+-- Brevity Migration: add_is_bot_to_url_clicks
+-- Generated: 2026-10-16T15:40:30Z
+-- Direction: DOWN
+
+-- Add your SQL below this line
+
+DROP INDEX IF EXISTS idx_url_clicks_is_bot;
+
+ALTER TABLE url_clicks DROP COLUMN is_bot;
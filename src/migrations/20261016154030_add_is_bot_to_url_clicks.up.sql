@@ -0,0 +1,9 @@
+-- Brevity Migration: add_is_bot_to_url_clicks
+-- Generated: 2026-10-16T15:40:30Z
+-- Direction: UP
+
+-- Add your SQL below this line
+
+ALTER TABLE url_clicks ADD COLUMN is_bot BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX idx_url_clicks_is_bot ON url_clicks(is_bot);
@@ -0,0 +1,10 @@
+-- Brevity Migration: create_click_rollup_tables
+-- Generated: 2026-10-16T15:47:32Z
+-- Direction: DOWN
+
+-- Add your SQL below this line
+
+DROP INDEX IF EXISTS idx_url_clicks_created_at;
+DROP TABLE IF EXISTS click_rollup_state;
+DROP TABLE IF EXISTS click_rollups_daily;
+DROP TABLE IF EXISTS click_rollups_hourly;
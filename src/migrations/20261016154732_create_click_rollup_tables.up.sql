@@ -0,0 +1,40 @@
+-- Brevity Migration: create_click_rollup_tables
+-- Generated: 2026-10-16T15:47:32Z
+-- Direction: UP
+
+-- Add your SQL below this line
+
+-- Click counts per URL, bucket and dimension value. The 'total' dimension
+-- (value '') also carries unique visitors for the bucket.
+CREATE TABLE IF NOT EXISTS click_rollups_hourly (
+    url_id VARCHAR(20) NOT NULL,
+    bucket_start DATETIME NOT NULL,
+    dimension VARCHAR(20) NOT NULL,
+    value VARCHAR(255) NOT NULL DEFAULT '',
+    clicks INTEGER NOT NULL DEFAULT 0,
+    unique_visitors INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (url_id, bucket_start, dimension, value),
+    FOREIGN KEY (url_id) REFERENCES urls(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS click_rollups_daily (
+    url_id VARCHAR(20) NOT NULL,
+    bucket_start DATETIME NOT NULL,
+    dimension VARCHAR(20) NOT NULL,
+    value VARCHAR(255) NOT NULL DEFAULT '',
+    clicks INTEGER NOT NULL DEFAULT 0,
+    unique_visitors INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (url_id, bucket_start, dimension, value),
+    FOREIGN KEY (url_id) REFERENCES urls(id) ON DELETE CASCADE
+);
+
+-- Raw clicks created before compacted_until are covered by the rollups
+CREATE TABLE IF NOT EXISTS click_rollup_state (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    compacted_until DATETIME NOT NULL,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO click_rollup_state (id, compacted_until) VALUES (1, '1970-01-01 00:00:00');
+
+CREATE INDEX IF NOT EXISTS idx_url_clicks_created_at ON url_clicks(created_at);
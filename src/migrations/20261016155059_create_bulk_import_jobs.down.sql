@@ -0,0 +1,10 @@
+-- Brevity Migration: create_bulk_import_jobs
+-- Generated: 2026-10-16T15:50:59Z
+-- Direction: DOWN
+
+-- Add your SQL below this line
+
+DROP TRIGGER IF EXISTS update_bulk_import_jobs_updated_at;
+DROP INDEX IF EXISTS idx_bulk_import_jobs_status;
+DROP INDEX IF EXISTS idx_bulk_import_jobs_user_id;
+DROP TABLE IF EXISTS bulk_import_jobs;
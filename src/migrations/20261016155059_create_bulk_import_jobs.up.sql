@@ -0,0 +1,31 @@
+-- Brevity Migration: create_bulk_import_jobs
+-- Generated: 2026-10-16T15:50:59Z
+-- Direction: UP
+
+-- Add your SQL below this line
+
+CREATE TABLE IF NOT EXISTS bulk_import_jobs (
+    id VARCHAR(20) PRIMARY KEY,
+    user_id VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'completed', 'failed')),
+    partial BOOLEAN NOT NULL DEFAULT FALSE,
+    total INTEGER NOT NULL DEFAULT 0,
+    created INTEGER NOT NULL DEFAULT 0,
+    failed INTEGER NOT NULL DEFAULT 0,
+    error TEXT,
+    payload TEXT NOT NULL,
+    results TEXT,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    completed_at DATETIME,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_bulk_import_jobs_user_id ON bulk_import_jobs(user_id);
+CREATE INDEX IF NOT EXISTS idx_bulk_import_jobs_status ON bulk_import_jobs(status, created_at);
+
+CREATE TRIGGER update_bulk_import_jobs_updated_at
+AFTER UPDATE ON bulk_import_jobs
+BEGIN
+    UPDATE bulk_import_jobs SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
@@ -0,0 +1,7 @@
+-- Brevity Migration: create_sequences
+-- Generated: 2026-10-16T15:57:29Z
+-- Direction: DOWN
+
+-- Add your SQL below this line
+
+DROP TABLE IF EXISTS sequences;
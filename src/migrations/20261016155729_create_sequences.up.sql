@@ -0,0 +1,11 @@
+-- Brevity Migration: create_sequences
+-- Generated: 2026-10-16T15:57:29Z
+-- Direction: UP
+
+-- Add your SQL below this line
+
+-- Named counters, e.g. for counter based short codes
+CREATE TABLE IF NOT EXISTS sequences (
+    name VARCHAR(50) PRIMARY KEY,
+    value INTEGER NOT NULL DEFAULT 0
+);
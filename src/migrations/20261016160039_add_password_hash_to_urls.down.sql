@@ -0,0 +1,7 @@
+-- Brevity Migration: add_password_hash_to_urls
+-- Generated: 2026-10-16T16:00:39Z
+-- Direction: DOWN
+
+-- Add your SQL below this line
+
+ALTER TABLE urls DROP COLUMN password_hash;
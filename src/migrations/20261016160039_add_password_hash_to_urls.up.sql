@@ -0,0 +1,8 @@
+-- Brevity Migration: add_password_hash_to_urls
+-- Generated: 2026-10-16T16:00:39Z
+-- Direction: UP
+
+-- Add your SQL below this line
+
+-- bcrypt hash; empty when the link is not password protected
+ALTER TABLE urls ADD COLUMN password_hash VARCHAR(255) NOT NULL DEFAULT '';
@@ -0,0 +1,8 @@
+-- Brevity Migration: add_click_limits_to_urls
+-- Generated: 2026-10-16T16:03:23Z
+-- Direction: DOWN
+
+-- Add your SQL below this line
+
+ALTER TABLE urls DROP COLUMN claimed_clicks;
+ALTER TABLE urls DROP COLUMN max_clicks;
@@ -0,0 +1,11 @@
+-- Brevity Migration: add_click_limits_to_urls
+-- Generated: 2026-10-16T16:03:23Z
+-- Direction: UP
+
+-- Add your SQL below this line
+
+-- max_clicks caps how often a link can be followed; NULL means unlimited.
+-- claimed_clicks counts the redirects spent against that cap and is bumped
+-- with a conditional update so concurrent redirects cannot overshoot it.
+ALTER TABLE urls ADD COLUMN max_clicks INTEGER CHECK (max_clicks IS NULL OR max_clicks > 0);
+ALTER TABLE urls ADD COLUMN claimed_clicks INTEGER NOT NULL DEFAULT 0;
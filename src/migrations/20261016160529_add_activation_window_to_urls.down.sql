@@ -0,0 +1,13 @@
+-- Brevity Migration: add_activation_window_to_urls
+-- Generated: 2026-10-16T16:05:29Z
+-- Direction: DOWN
+
+-- Add your SQL below this line
+
+DROP TABLE IF EXISTS url_transitions;
+
+DROP INDEX IF EXISTS idx_urls_expires_at;
+DROP INDEX IF EXISTS idx_urls_activates_at;
+
+ALTER TABLE urls DROP COLUMN fallback_url;
+ALTER TABLE urls DROP COLUMN activates_at;
@@ -0,0 +1,25 @@
+-- Brevity Migration: add_activation_window_to_urls
+-- Generated: 2026-10-16T16:05:29Z
+-- Direction: UP
+
+-- Add your SQL below this line
+
+-- Links go live at activates_at and shut off at expires_at; outside that
+-- window visitors are sent to fallback_url when one is set
+ALTER TABLE urls ADD COLUMN activates_at TIMESTAMP;
+ALTER TABLE urls ADD COLUMN fallback_url TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX idx_urls_activates_at ON urls(activates_at);
+CREATE INDEX idx_urls_expires_at ON urls(expires_at);
+
+-- Audit trail of is_active flips made by the link scheduler
+CREATE TABLE url_transitions (
+    id VARCHAR(20) PRIMARY KEY,
+    url_id VARCHAR(20) NOT NULL REFERENCES urls(id) ON DELETE CASCADE,
+    is_active BOOLEAN NOT NULL,
+    reason VARCHAR(20) NOT NULL CHECK (reason IN ('activated', 'expired')),
+    scheduled_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_url_transitions_url_id ON url_transitions(url_id, created_at);
@@ -0,0 +1,7 @@
+-- Brevity Migration: add_rules_to_urls
+-- Generated: 2026-10-16T16:09:15Z
+-- Direction: DOWN
+
+-- Add your SQL below this line
+
+ALTER TABLE urls DROP COLUMN rules;
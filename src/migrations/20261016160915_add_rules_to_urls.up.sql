@@ -0,0 +1,8 @@
+-- Brevity Migration: add_rules_to_urls
+-- Generated: 2026-10-16T16:09:15Z
+-- Direction: UP
+
+-- Add your SQL below this line
+
+-- Ordered redirect rules as a JSON array; NULL when the link has none
+ALTER TABLE urls ADD COLUMN rules TEXT;
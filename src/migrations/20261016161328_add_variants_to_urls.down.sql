@@ -0,0 +1,7 @@
+-- Brevity Migration: add_variants_to_urls
+-- Generated: 2026-10-16T16:13:28Z
+-- Direction: DOWN
+
+-- Add your SQL below this line
+ALTER TABLE url_clicks DROP COLUMN variant_id;
+ALTER TABLE urls DROP COLUMN variants;
@@ -0,0 +1,10 @@
+-- Brevity Migration: add_variants_to_urls
+-- Generated: 2026-10-16T16:13:28Z
+-- Direction: UP
+
+-- Add your SQL below this line
+-- A/B split destinations as a JSON array; NULL when the link is not split
+ALTER TABLE urls ADD COLUMN variants TEXT;
+
+-- The variant a click was sent to; NULL for links without a split
+ALTER TABLE url_clicks ADD COLUMN variant_id VARCHAR(20);
@@ -0,0 +1,11 @@
+-- Brevity Migration: add_utm_to_urls
+-- Generated: 2026-10-16T16:18:00Z
+-- Direction: DOWN
+
+-- Add your SQL below this line
+ALTER TABLE urls DROP COLUMN forward_query;
+ALTER TABLE urls DROP COLUMN utm_content;
+ALTER TABLE urls DROP COLUMN utm_term;
+ALTER TABLE urls DROP COLUMN utm_campaign;
+ALTER TABLE urls DROP COLUMN utm_medium;
+ALTER TABLE urls DROP COLUMN utm_source;
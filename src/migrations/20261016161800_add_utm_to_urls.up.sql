@@ -0,0 +1,14 @@
+-- Brevity Migration: add_utm_to_urls
+-- Generated: 2026-10-16T16:18:00Z
+-- Direction: UP
+
+-- Add your SQL below this line
+-- Campaign tags added to the destination on every redirect
+ALTER TABLE urls ADD COLUMN utm_source VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE urls ADD COLUMN utm_medium VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE urls ADD COLUMN utm_campaign VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE urls ADD COLUMN utm_term VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE urls ADD COLUMN utm_content VARCHAR(255) NOT NULL DEFAULT '';
+
+-- Forward the visitor's query parameters to the destination
+ALTER TABLE urls ADD COLUMN forward_query BOOLEAN NOT NULL DEFAULT 0;
@@ -0,0 +1,76 @@
+-- Brevity Migration: add_custom_domains
+-- Generated: 2026-10-16T16:20:18Z
+-- Direction: DOWN
+
+-- Add your SQL below this line
+-- Rebuild urls with globally unique short codes again. This fails if the
+-- same code is in use on several domains.
+CREATE TEMP TABLE url_clicks_backup AS SELECT * FROM url_clicks;
+CREATE TEMP TABLE click_rollups_hourly_backup AS SELECT * FROM click_rollups_hourly;
+CREATE TEMP TABLE click_rollups_daily_backup AS SELECT * FROM click_rollups_daily;
+CREATE TEMP TABLE url_transitions_backup AS SELECT * FROM url_transitions;
+
+CREATE TABLE urls_old (
+    id VARCHAR(20) PRIMARY KEY,
+    original_url TEXT NOT NULL,
+    short_code VARCHAR(10) NOT NULL UNIQUE,
+    user_id VARCHAR(20) REFERENCES users(id) ON DELETE CASCADE,
+    title VARCHAR(100),
+    description VARCHAR(255),
+    clicks INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP,
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP,
+    redirect_type INTEGER NOT NULL DEFAULT 302 CHECK (redirect_type IN (301, 302, 307, 308)),
+    password_hash VARCHAR(255) NOT NULL DEFAULT '',
+    max_clicks INTEGER CHECK (max_clicks IS NULL OR max_clicks > 0),
+    claimed_clicks INTEGER NOT NULL DEFAULT 0,
+    activates_at TIMESTAMP,
+    fallback_url TEXT NOT NULL DEFAULT '',
+    rules TEXT,
+    variants TEXT,
+    utm_source VARCHAR(255) NOT NULL DEFAULT '',
+    utm_medium VARCHAR(255) NOT NULL DEFAULT '',
+    utm_campaign VARCHAR(255) NOT NULL DEFAULT '',
+    utm_term VARCHAR(255) NOT NULL DEFAULT '',
+    utm_content VARCHAR(255) NOT NULL DEFAULT '',
+    forward_query BOOLEAN NOT NULL DEFAULT 0
+);
+
+INSERT INTO urls_old
+SELECT
+    id, original_url, short_code, user_id, title, description, clicks,
+    expires_at, is_active, created_at, updated_at, deleted_at, redirect_type,
+    password_hash, max_clicks, claimed_clicks, activates_at, fallback_url,
+    rules, variants, utm_source, utm_medium, utm_campaign, utm_term,
+    utm_content, forward_query
+FROM urls;
+
+DROP TABLE urls;
+ALTER TABLE urls_old RENAME TO urls;
+
+CREATE INDEX idx_urls_user_id ON urls(user_id);
+CREATE INDEX idx_urls_deleted_at ON urls(deleted_at);
+CREATE INDEX idx_urls_activates_at ON urls(activates_at);
+CREATE INDEX idx_urls_expires_at ON urls(expires_at);
+
+CREATE TRIGGER update_urls_updated_at
+AFTER UPDATE ON urls
+BEGIN
+    UPDATE urls SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
+
+INSERT INTO url_clicks SELECT * FROM url_clicks_backup;
+INSERT INTO click_rollups_hourly SELECT * FROM click_rollups_hourly_backup;
+INSERT INTO click_rollups_daily SELECT * FROM click_rollups_daily_backup;
+INSERT INTO url_transitions SELECT * FROM url_transitions_backup;
+
+DROP TABLE url_clicks_backup;
+DROP TABLE click_rollups_hourly_backup;
+DROP TABLE click_rollups_daily_backup;
+DROP TABLE url_transitions_backup;
+
+DROP TRIGGER IF EXISTS update_domains_updated_at;
+DROP TABLE IF EXISTS domains;
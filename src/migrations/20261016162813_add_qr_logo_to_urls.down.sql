@@ -0,0 +1,6 @@
+-- Brevity Migration: add_qr_logo_to_urls
+-- Generated: 2026-10-16T16:28:13Z
+-- Direction: DOWN
+
+-- Add your SQL below this line
+ALTER TABLE urls DROP COLUMN qr_logo_url;
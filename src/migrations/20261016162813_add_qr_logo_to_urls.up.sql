@@ -0,0 +1,7 @@
+-- Brevity Migration: add_qr_logo_to_urls
+-- Generated: 2026-10-16T16:28:13Z
+-- Direction: UP
+
+-- Add your SQL below this line
+-- Logo drawn over the middle of the link's QR codes, a storage url
+ALTER TABLE urls ADD COLUMN qr_logo_url TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,10 @@
+-- Brevity Migration: add_link_previews_to_urls
+-- Generated: 2026-10-16T16:32:14Z
+-- Direction: DOWN
+
+-- Add your SQL below this line
+DROP INDEX IF EXISTS idx_urls_preview_pending;
+ALTER TABLE urls DROP COLUMN previewed_at;
+ALTER TABLE urls DROP COLUMN preview_favicon_url;
+ALTER TABLE urls DROP COLUMN preview_image_url;
+ALTER TABLE urls DROP COLUMN preview_site_name;
@@ -0,0 +1,17 @@
+-- Brevity Migration: add_link_previews_to_urls
+-- Generated: 2026-10-16T16:32:14Z
+-- Direction: UP
+
+-- Add your SQL below this line
+-- Metadata read from the destination page by the preview fetcher
+ALTER TABLE urls ADD COLUMN preview_site_name VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE urls ADD COLUMN preview_image_url TEXT NOT NULL DEFAULT '';
+ALTER TABLE urls ADD COLUMN preview_favicon_url TEXT NOT NULL DEFAULT '';
+
+-- NULL until the fetcher has looked at the link, successfully or not
+ALTER TABLE urls ADD COLUMN previewed_at DATETIME;
+
+-- Existing links were created before previews and are not fetched
+UPDATE urls SET previewed_at = CURRENT_TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_urls_preview_pending ON urls(created_at) WHERE previewed_at IS NULL;
@@ -0,0 +1,8 @@
+-- Brevity Migration: add_og_overrides_to_urls
+-- Generated: 2026-10-16T16:36:36Z
+-- Direction: DOWN
+
+-- Add your SQL below this line
+ALTER TABLE urls DROP COLUMN og_image_url;
+ALTER TABLE urls DROP COLUMN og_description;
+ALTER TABLE urls DROP COLUMN og_title;
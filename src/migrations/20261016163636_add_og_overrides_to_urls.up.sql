@@ -0,0 +1,10 @@
+-- Brevity Migration: add_og_overrides_to_urls
+-- Generated: 2026-10-16T16:36:36Z
+-- Direction: UP
+
+-- Add your SQL below this line
+-- Owner supplied OpenGraph tags for the social preview page. Empty values
+-- fall back to the link's title, description and preview image.
+ALTER TABLE urls ADD COLUMN og_title VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE urls ADD COLUMN og_description VARCHAR(300) NOT NULL DEFAULT '';
+ALTER TABLE urls ADD COLUMN og_image_url TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,11 @@
+-- Brevity Migration: add_link_safety
+-- Generated: 2026-10-16T16:39:57Z
+-- Direction: DOWN
+
+-- Add your SQL below this line
+ALTER TABLE urls DROP COLUMN safety_status;
+DROP INDEX IF EXISTS idx_link_flags_status;
+DROP INDEX IF EXISTS idx_link_flags_url_id;
+DROP TABLE IF EXISTS link_flags;
+DROP INDEX IF EXISTS idx_blocklist_kind_pattern;
+DROP TABLE IF EXISTS blocklist_entries;
@@ -0,0 +1,38 @@
+-- Brevity Migration: add_link_safety
+-- Generated: 2026-10-16T16:39:57Z
+-- Direction: UP
+
+-- Add your SQL below this line
+-- Admin managed rules destinations are checked against
+CREATE TABLE IF NOT EXISTS blocklist_entries (
+    id VARCHAR(20) PRIMARY KEY,
+    kind VARCHAR(20) NOT NULL,
+    pattern VARCHAR(512) NOT NULL,
+    reason VARCHAR(255) NOT NULL DEFAULT '',
+    created_by VARCHAR(20) REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_blocklist_kind_pattern ON blocklist_entries(kind, pattern);
+
+-- Links the safety scanner disabled, reviewed by admins
+CREATE TABLE IF NOT EXISTS link_flags (
+    id VARCHAR(20) PRIMARY KEY,
+    url_id VARCHAR(20) NOT NULL REFERENCES urls(id) ON DELETE CASCADE,
+    destination TEXT NOT NULL,
+    source VARCHAR(20) NOT NULL,
+    reason TEXT NOT NULL,
+    entry_id VARCHAR(20) NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    reviewed_by VARCHAR(20) NOT NULL DEFAULT '',
+    review_note VARCHAR(255) NOT NULL DEFAULT '',
+    reviewed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_link_flags_url_id ON link_flags(url_id);
+CREATE INDEX IF NOT EXISTS idx_link_flags_status ON link_flags(status, created_at);
+
+-- Flagged and blocked links are not followed; approved ones are not
+-- flagged again
+ALTER TABLE urls ADD COLUMN safety_status VARCHAR(20) NOT NULL DEFAULT '';
@@ -0,0 +1,15 @@
+-- Brevity Migration: add_link_health_to_urls
+-- Generated: 2026-10-16T16:45:28Z
+-- Direction: DOWN
+
+-- Add your SQL below this line
+DROP INDEX IF EXISTS idx_urls_health_failures;
+DROP INDEX IF EXISTS idx_urls_health_next_check_at;
+ALTER TABLE urls DROP COLUMN health_next_check_at;
+ALTER TABLE urls DROP COLUMN health_reported;
+ALTER TABLE urls DROP COLUMN health_failing_since;
+ALTER TABLE urls DROP COLUMN health_failures;
+ALTER TABLE urls DROP COLUMN health_checked_at;
+ALTER TABLE urls DROP COLUMN health_error;
+ALTER TABLE urls DROP COLUMN health_latency_ms;
+ALTER TABLE urls DROP COLUMN health_status_code;
@@ -0,0 +1,18 @@
+-- Brevity Migration: add_link_health_to_urls
+-- Generated: 2026-10-16T16:45:28Z
+-- Direction: UP
+
+-- Add your SQL below this line
+-- Result of the latest destination health check. Failures counts checks
+-- failed in a row; reported is set once the owner got a digest about it.
+ALTER TABLE urls ADD COLUMN health_status_code INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE urls ADD COLUMN health_latency_ms INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE urls ADD COLUMN health_error VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE urls ADD COLUMN health_checked_at TIMESTAMP;
+ALTER TABLE urls ADD COLUMN health_failures INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE urls ADD COLUMN health_failing_since TIMESTAMP;
+ALTER TABLE urls ADD COLUMN health_reported BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE urls ADD COLUMN health_next_check_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_urls_health_next_check_at ON urls(health_next_check_at);
+CREATE INDEX IF NOT EXISTS idx_urls_health_failures ON urls(health_failures);
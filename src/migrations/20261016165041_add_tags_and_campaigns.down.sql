@@ -0,0 +1,13 @@
+-- Brevity Migration: add_tags_and_campaigns
+-- Generated: 2026-10-16T16:50:41Z
+-- Direction: DOWN
+
+-- Add your SQL below this line
+DROP INDEX IF EXISTS idx_urls_campaign_id;
+ALTER TABLE urls DROP COLUMN campaign_id;
+DROP INDEX IF EXISTS idx_url_tags_tag_id;
+DROP TABLE IF EXISTS url_tags;
+DROP INDEX IF EXISTS idx_tags_user_name;
+DROP TABLE IF EXISTS tags;
+DROP INDEX IF EXISTS idx_campaigns_user_name;
+DROP TABLE IF EXISTS campaigns;
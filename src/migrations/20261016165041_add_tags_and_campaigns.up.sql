@@ -0,0 +1,40 @@
+-- Brevity Migration: add_tags_and_campaigns
+-- Generated: 2026-10-16T16:50:41Z
+-- Direction: UP
+
+-- Add your SQL below this line
+-- Campaigns group links for reporting; a link belongs to at most one
+CREATE TABLE IF NOT EXISTS campaigns (
+    id VARCHAR(20) PRIMARY KEY,
+    user_id VARCHAR(20) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_campaigns_user_name ON campaigns(user_id, name);
+
+-- Tags are free form labels, any number per link
+CREATE TABLE IF NOT EXISTS tags (
+    id VARCHAR(20) PRIMARY KEY,
+    user_id VARCHAR(20) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_user_name ON tags(user_id, name);
+
+CREATE TABLE IF NOT EXISTS url_tags (
+    url_id VARCHAR(20) NOT NULL REFERENCES urls(id) ON DELETE CASCADE,
+    tag_id VARCHAR(20) NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (url_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_url_tags_tag_id ON url_tags(tag_id);
+
+-- Deleting a campaign keeps its links
+ALTER TABLE urls ADD COLUMN campaign_id VARCHAR(20) REFERENCES campaigns(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_urls_campaign_id ON urls(campaign_id);
@@ -0,0 +1,11 @@
+-- Brevity Migration: add_urls_fts
+-- Generated: 2026-10-16T16:57:03Z
+-- Direction: DOWN
+
+-- Add your SQL below this line
+DROP TRIGGER IF EXISTS urls_fts_delete;
+DROP TRIGGER IF EXISTS urls_fts_update;
+DROP TRIGGER IF EXISTS urls_fts_insert;
+DROP TABLE IF EXISTS urls_fts;
+DROP INDEX IF EXISTS idx_urls_search_key;
+ALTER TABLE urls DROP COLUMN search_key;
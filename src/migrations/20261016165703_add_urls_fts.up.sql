@@ -0,0 +1,57 @@
+-- Brevity Migration: add_urls_fts
+-- Generated: 2026-10-16T16:57:03Z
+-- Direction: UP
+
+-- Add your SQL below this line
+-- Requires SQLite built with FTS5 (the sqlite_fts5 build tag)
+-- The index keeps its own copy of the text. Its rows are keyed by
+-- urls.search_key, a stable integer key, since urls are keyed by text ids
+-- and their implicit rowids may change on VACUUM.
+ALTER TABLE urls ADD COLUMN search_key INTEGER;
+UPDATE urls SET search_key = rowid;
+CREATE UNIQUE INDEX idx_urls_search_key ON urls(search_key);
+
+CREATE VIRTUAL TABLE urls_fts USING fts5(
+    title,
+    description,
+    original_url,
+    short_code,
+    tokenize = 'unicode61 remove_diacritics 2'
+);
+
+INSERT INTO urls_fts (rowid, title, description, original_url, short_code)
+SELECT search_key, COALESCE(title, ''), COALESCE(description, ''), original_url, short_code FROM urls;
+
+-- Triggers to keep the index in sync with urls
+CREATE TRIGGER urls_fts_insert
+AFTER INSERT ON urls
+BEGIN
+    UPDATE urls
+    SET search_key = (SELECT COALESCE(MAX(search_key), 0) + 1 FROM urls)
+    WHERE id = NEW.id AND search_key IS NULL;
+
+    INSERT INTO urls_fts (rowid, title, description, original_url, short_code)
+    SELECT search_key, COALESCE(title, ''), COALESCE(description, ''), original_url, short_code
+    FROM urls WHERE id = NEW.id;
+END;
+
+CREATE TRIGGER urls_fts_update
+AFTER UPDATE OF title, description, original_url, short_code ON urls
+WHEN OLD.title IS NOT NEW.title
+    OR OLD.description IS NOT NEW.description
+    OR OLD.original_url IS NOT NEW.original_url
+    OR OLD.short_code IS NOT NEW.short_code
+BEGIN
+    UPDATE urls_fts
+    SET title = COALESCE(NEW.title, ''),
+        description = COALESCE(NEW.description, ''),
+        original_url = NEW.original_url,
+        short_code = NEW.short_code
+    WHERE rowid = NEW.search_key;
+END;
+
+CREATE TRIGGER urls_fts_delete
+AFTER DELETE ON urls
+BEGIN
+    DELETE FROM urls_fts WHERE rowid = OLD.search_key;
+END;